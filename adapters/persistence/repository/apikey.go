@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"log"
+	"time"
+)
+
+// APIKeyRepository stores the API keys of the machine clients along with the hashes of the keys
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository is the constructor of an APIKeyRepository with the database dependency injected
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &APIKeyRepository{db: db}
+}
+
+// Create creates a new API key, errs.ErrConflict is returned if its name is already used in the tenant
+func (a *APIKeyRepository) Create(key *entity.APIKey) error {
+	tx := a.db.Create(key)
+	return translateError(tx.Error)
+}
+
+// FindAll returns all the API keys ordered by name
+func (a *APIKeyRepository) FindAll() ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	tx := a.db.Order("name").Find(&keys)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return keys, nil
+}
+
+// FindByHash finds an API key by the hash of the key, errs.ErrNotFound is returned if there is no such key
+func (a *APIKeyRepository) FindByHash(hash string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	tx := a.db.Where("hash = ?", hash).First(&key)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &key, nil
+}
+
+// Touch records the time the API key was last used, the update time of the key is left as it is since the key did not change
+func (a *APIKeyRepository) Touch(id string, usedAt time.Time) error {
+	tx := a.db.Model(&entity.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt)
+	return translateError(tx.Error)
+}
+
+// DeleteByID deletes the API key, errs.ErrNotFound is returned if there is no such key
+func (a *APIKeyRepository) DeleteByID(id string) error {
+	tx := a.db.Where("id = ?", id).Delete(&entity.APIKey{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find API key with id '%s'", id)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyRepository_Create(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	a := NewAPIKeyRepository(testSuite.gormDB).WithTenant("acme")
+	key := &entity.APIKey{ID: "1", Hash: "hash", Prefix: "tsk_abcdef", CreatedBy: "root",
+		APIKeyDescription: entity.APIKeyDescription{Name: "ci", Scopes: entity.ScopeList{entity.ScopeTasksRead, entity.ScopeTasksWrite}}}
+
+	// the scopes are stored as a comma separated column
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "api_keys" ("id","tenant_id","created_at","updated_at","hash","prefix","created_by","last_used_at","name","scopes","expires_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`)).
+		WithArgs("1", "acme", AnyTime{}, AnyTime{}, "hash", "tsk_abcdef", "root", nil, "ci", "tasks:read,tasks:write", nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := a.Create(key); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAPIKeyRepository_FindByHash(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	a := NewAPIKeyRepository(testSuite.gormDB)
+
+	// the authentication looks the keys up in all the tenants
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "api_keys" WHERE hash = $1 ORDER BY "api_keys"."id" LIMIT 1`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "name", "scopes"}).AddRow("1", "acme", "ci", "tasks:read"))
+	got, err := a.AllTenants().FindByHash("hash")
+	if err != nil {
+		t1.Fatalf("FindByHash() error = %v", err)
+	}
+	if got.TenantID != "acme" || !reflect.DeepEqual(got.Scopes, entity.ScopeList{entity.ScopeTasksRead}) {
+		t1.Errorf("FindByHash() got = %v, want the key ci of acme", got)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "api_keys" WHERE hash = $1 ORDER BY "api_keys"."id" LIMIT 1`)).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err = a.AllTenants().FindByHash("unknown"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("FindByHash() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAPIKeyRepository_Touch(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	a := NewAPIKeyRepository(testSuite.gormDB)
+	usedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// the update time is not changed by the use of the key
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "api_keys" SET "last_used_at"=$1 WHERE id = $2`)).
+		WithArgs(usedAt, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := a.AllTenants().Touch("1", usedAt); err != nil {
+		t1.Errorf("Touch() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAPIKeyRepository_DeleteByID(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	a := NewAPIKeyRepository(testSuite.gormDB).WithTenant("acme")
+
+	for _, affected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "api_keys" WHERE id = $1 AND "api_keys"."tenant_id" = $2`)).
+			WithArgs("1", "acme").
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		testSuite.mock.ExpectCommit()
+
+		err := a.DeleteByID("1")
+		if affected == 1 && err != nil {
+			t1.Errorf("DeleteByID() error = %v", err)
+		}
+		if affected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"log"
+)
+
+// AttachmentRepository stores the metadata of the files attached to the tasks
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAttachmentRepository is the constructor of an AttachmentRepository with the database dependency injected
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &AttachmentRepository{db: db}
+}
+
+// Create records the metadata of an attached file
+func (a *AttachmentRepository) Create(attachment *entity.Attachment) error {
+	tx := a.db.Create(attachment)
+	return translateError(tx.Error)
+}
+
+// FindByTask returns the files attached to the task, from the oldest one
+func (a *AttachmentRepository) FindByTask(taskID string) ([]*entity.Attachment, error) {
+	var attachments []*entity.Attachment
+	tx := a.db.Where("task_id = ?", taskID).Order("created_at").Order("id").Find(&attachments)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return attachments, nil
+}
+
+// FindByID finds a file attached to the task by its ID, errs.ErrNotFound is returned if the task has no such file
+func (a *AttachmentRepository) FindByID(taskID string, id string) (*entity.Attachment, error) {
+	var attachment entity.Attachment
+	tx := a.db.Where("task_id = ?", taskID).Where("id = ?", id).First(&attachment)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &attachment, nil
+}
+
+// DeleteByID removes the metadata of a file attached to the task, errs.ErrNotFound is returned if the task has no such file
+func (a *AttachmentRepository) DeleteByID(taskID string, id string) error {
+	tx := a.db.Where("task_id = ?", taskID).Where("id = ?", id).Delete(&entity.Attachment{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find attachment with id '%s'", id)
+	}
+	return nil
+}
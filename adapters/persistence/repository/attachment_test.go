@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"testing"
+)
+
+func TestAttachmentRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	a := NewAttachmentRepository(testSuite.gormDB)
+	attachment := &entity.Attachment{ID: "a1", TaskID: "1", Name: "server.log", Size: 42, ContentType: "text/plain; charset=utf-8",
+		Checksum: "abc", UploadedBy: "alice", BlobKey: "default/1/a1"}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "attachments" ("id","tenant_id","task_id","name","size","content_type","checksum","uploaded_by","created_at","blob_key") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`)).
+		WithArgs("a1", entity.DefaultTenantID, "1", "server.log", 42, "text/plain; charset=utf-8", "abc", "alice", AnyTime{}, "default/1/a1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := a.Create(attachment); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAttachmentRepository_FindByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	a := NewAttachmentRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "attachments" WHERE task_id = $1 AND id = $2 ORDER BY "attachments"."id" LIMIT 1`)).
+		WithArgs("1", "a1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "name", "blob_key"}).AddRow("a1", "1", "server.log", "default/1/a1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "attachments" WHERE task_id = $1 AND id = $2 ORDER BY "attachments"."id" LIMIT 1`)).
+		WithArgs("2", "a1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	got, err := a.FindByID("1", "a1")
+	if err != nil {
+		t1.Fatalf("FindByID() error = %v", err)
+	}
+	if got.Name != "server.log" || got.BlobKey != "default/1/a1" {
+		t1.Errorf("FindByID() got = %+v, want the metadata of server.log", got)
+	}
+	if _, err = a.FindByID("2", "a1"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("FindByID() error = %v, want %v for the attachment of another task", err, errs.ErrNotFound)
+	}
+}
+
+func TestAttachmentRepository_DeleteByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	a := NewAttachmentRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "attachments" WHERE task_id = $1 AND id = $2`)).
+		WithArgs("1", "a2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectCommit()
+
+	if err := a.DeleteByID("1", "a2"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+// ChecklistRepository stores the checklist items of the tasks. The items of a task are numbered from 1 without gaps,
+// and the number of items and of checked ones is kept on the task in the same transactions.
+type ChecklistRepository struct {
+	db *gorm.DB
+}
+
+// NewChecklistRepository is the constructor of a ChecklistRepository with the database dependency injected
+func NewChecklistRepository(db *gorm.DB) *ChecklistRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &ChecklistRepository{db: db}
+}
+
+// Create adds the item at its position, the following items moving down, or at the end when the position is 0 or after the last item.
+// The position of the item is set to the one it gets. errs.ErrNotFound is returned if there is no such task or it is in the trash.
+func (c *ChecklistRepository) Create(item *entity.ChecklistItem) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, item.TaskID); err != nil {
+			return err
+		}
+		total, err := countItems(tx, item.TaskID)
+		if err != nil {
+			return err
+		}
+		if item.Position < 1 || item.Position > total {
+			item.Position = total + 1
+		} else if err = shiftItems(tx, item.TaskID, item.Position, total, 1); err != nil {
+			return err
+		}
+		if err = tx.Create(item).Error; err != nil {
+			return err
+		}
+		return countChecklist(tx, item.TaskID, 1, 0)
+	}))
+}
+
+// FindByTask returns the items of the checklist of the task in their order
+func (c *ChecklistRepository) FindByTask(taskID string) ([]*entity.ChecklistItem, error) {
+	var items []*entity.ChecklistItem
+	tx := c.db.Where("task_id = ?", taskID).Order("position").Find(&items)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return items, nil
+}
+
+// FindByID finds an item of the checklist of the task by its ID, errs.ErrNotFound is returned if the task has no such item
+func (c *ChecklistRepository) FindByID(taskID string, id string) (*entity.ChecklistItem, error) {
+	var item entity.ChecklistItem
+	tx := c.db.Where("task_id = ?", taskID).Where("id = ?", id).First(&item)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &item, nil
+}
+
+// Move puts the item at the position, or at the end when the position is after the last item, the items in between moving by one place
+func (c *ChecklistRepository) Move(taskID string, id string, position int) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, taskID); err != nil {
+			return err
+		}
+		var item entity.ChecklistItem
+		if err := tx.Where("task_id = ?", taskID).Where("id = ?", id).First(&item).Error; err != nil {
+			return err
+		}
+		total, err := countItems(tx, taskID)
+		if err != nil {
+			return err
+		}
+		if position > total {
+			position = total
+		}
+		switch {
+		case position < item.Position:
+			err = shiftItems(tx, taskID, position, item.Position-1, 1)
+		case position > item.Position:
+			err = shiftItems(tx, taskID, item.Position+1, position, -1)
+		default:
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entity.ChecklistItem{}).Where("task_id = ?", taskID).Where("id = ?", id).Update("position", position).Error
+	}))
+}
+
+// SetDone checks or unchecks the item, nothing is changed if it already is
+func (c *ChecklistRepository) SetDone(taskID string, id string, done bool) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, taskID); err != nil {
+			return err
+		}
+		var item entity.ChecklistItem
+		if err := tx.Where("task_id = ?", taskID).Where("id = ?", id).First(&item).Error; err != nil {
+			return err
+		}
+		if item.Done == done {
+			return nil
+		}
+		if err := tx.Model(&entity.ChecklistItem{}).Where("task_id = ?", taskID).Where("id = ?", id).Update("done", done).Error; err != nil {
+			return err
+		}
+		if done {
+			return countChecklist(tx, taskID, 0, 1)
+		}
+		return countChecklist(tx, taskID, 0, -1)
+	}))
+}
+
+// DeleteByID removes the item from the checklist of the task, the following items moving up, errs.ErrNotFound is returned if the task has no such item
+func (c *ChecklistRepository) DeleteByID(taskID string, id string) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockTask(tx, taskID); err != nil {
+			return err
+		}
+		var item entity.ChecklistItem
+		if err := tx.Where("task_id = ?", taskID).Where("id = ?", id).First(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ?", taskID).Where("id = ?", id).Delete(&entity.ChecklistItem{}).Error; err != nil {
+			return err
+		}
+		total, err := countItems(tx, taskID)
+		if err != nil {
+			return err
+		}
+		if err = shiftItems(tx, taskID, item.Position+1, total+1, -1); err != nil {
+			return err
+		}
+		if item.Done {
+			return countChecklist(tx, taskID, -1, -1)
+		}
+		return countChecklist(tx, taskID, -1, 0)
+	}))
+}
+
+// lockTask locks the task until the end of the transaction, so that the checklist of a task is changed by one transaction at a time
+// and its positions and counts stay consistent. errs.ErrNotFound is returned if there is no such task or it is in the trash.
+func lockTask(tx *gorm.DB, taskID string) error {
+	var task entity.Task
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", taskID).Where("deleted_at IS NULL").Limit(1).Find(&task)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find task with id '%s'", taskID)
+	}
+	return nil
+}
+
+// countItems returns the number of items of the checklist of the task
+func countItems(tx *gorm.DB, taskID string) (int, error) {
+	var total int64
+	err := tx.Model(&entity.ChecklistItem{}).Where("task_id = ?", taskID).Count(&total).Error
+	return int(total), err
+}
+
+// shiftItems moves the items of the task from the position from to the position to, both included, by delta places
+func shiftItems(tx *gorm.DB, taskID string, from int, to int, delta int) error {
+	return tx.Model(&entity.ChecklistItem{}).Where("task_id = ?", taskID).Where("position BETWEEN ? AND ?", from, to).
+		Update("position", gorm.Expr("position + ?", delta)).Error
+}
+
+// countChecklist adds the deltas to the number of items and of checked items of the task. Its version is incremented too,
+// since the progress of the checklist is part of the task and the clients holding a copy of it need to see it changed.
+func countChecklist(tx *gorm.DB, taskID string, items int, done int) error {
+	values := map[string]interface{}{"checklist_items": gorm.Expr("checklist_items + ?", items), "checklist_done": gorm.Expr("checklist_done + ?", done),
+		"version": gorm.Expr("version + 1")}
+	return tx.Model(&entity.Task{}).Where("id = ?", taskID).Updates(values).Error
+}
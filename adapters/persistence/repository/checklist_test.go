@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+const (
+	// lockChecklist is the statement locking the task whose checklist is changed
+	lockChecklist = `SELECT "id" FROM "tasks" WHERE id = $1 AND deleted_at IS NULL LIMIT 1 FOR UPDATE`
+	// countChecklistItems is the statement counting the items of the checklist of a task
+	countChecklistItems = `SELECT count(*) FROM "checklist_items" WHERE task_id = $1`
+	// shiftChecklistItems is the statement moving the items of a checklist between two positions
+	shiftChecklistItems = `UPDATE "checklist_items" SET "position"=position + $1,"updated_at"=$2 WHERE task_id = $3 AND (position BETWEEN $4 AND $5)`
+	// countChecklistProgress is the statement keeping the progress of the checklist on its task
+	countChecklistProgress = `UPDATE "tasks" SET "checklist_done"=checklist_done + $1,"checklist_items"=checklist_items + $2,"version"=version + 1,"updated_at"=$3 WHERE id = $4`
+	// findChecklistItem is the statement finding an item of a checklist
+	findChecklistItem = `SELECT * FROM "checklist_items" WHERE task_id = $1 AND id = $2 ORDER BY "checklist_items"."id" LIMIT 1`
+)
+
+func TestChecklistRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewChecklistRepository(testSuite.gormDB)
+	insert := regexp.QuoteMeta(`INSERT INTO "checklist_items" ("id","tenant_id","task_id","position","done","created_at","updated_at","text") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)
+
+	// appended after the 2 items of the checklist
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(countChecklistItems)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	testSuite.mock.ExpectExec(insert).
+		WithArgs("i3", entity.DefaultTenantID, "1", 3, false, AnyTime{}, AnyTime{}, "deploy").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countChecklistProgress)).WithArgs(0, 1, AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	item := &entity.ChecklistItem{ID: "i3", TaskID: "1", ChecklistItemBody: entity.ChecklistItemBody{Text: "deploy"}}
+	if err := c.Create(item); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+	if item.Position != 3 {
+		t1.Errorf("Create() position = %d, want 3", item.Position)
+	}
+
+	// inserted first, the 2 items of the checklist moving down
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(countChecklistItems)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(shiftChecklistItems)).WithArgs(1, AnyTime{}, "1", 1, 2).WillReturnResult(sqlmock.NewResult(0, 2))
+	testSuite.mock.ExpectExec(insert).
+		WithArgs("i4", entity.DefaultTenantID, "1", 1, false, AnyTime{}, AnyTime{}, "review").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countChecklistProgress)).WithArgs(0, 1, AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.Create(&entity.ChecklistItem{ID: "i4", TaskID: "1", Position: 1, ChecklistItemBody: entity.ChecklistItemBody{Text: "review"}}); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+
+	// the task is in the trash or does not exist
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("2").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	testSuite.mock.ExpectRollback()
+	if err := c.Create(&entity.ChecklistItem{ID: "i5", TaskID: "2"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Create() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChecklistRepository_FindByTask(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewChecklistRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "checklist_items" WHERE task_id = $1 ORDER BY position`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "position", "text"}).AddRow("i2", "1", 1, "review").AddRow("i1", "1", 2, "deploy"))
+
+	got, err := c.FindByTask("1")
+	if err != nil {
+		t1.Fatalf("FindByTask() error = %v", err)
+	}
+	want := []*entity.ChecklistItem{
+		{ID: "i2", TaskID: "1", Position: 1, ChecklistItemBody: entity.ChecklistItemBody{Text: "review"}},
+		{ID: "i1", TaskID: "1", Position: 2, ChecklistItemBody: entity.ChecklistItemBody{Text: "deploy"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindByTask() got = %v, want %v", got, want)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChecklistRepository_Move(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewChecklistRepository(testSuite.gormDB)
+	update := regexp.QuoteMeta(`UPDATE "checklist_items" SET "position"=$1,"updated_at"=$2 WHERE task_id = $3 AND id = $4`)
+
+	// the last of 3 items moved to the second place, the second one moving down
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "i3").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "position"}).AddRow("i3", "1", 3))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(countChecklistItems)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(shiftChecklistItems)).WithArgs(1, AnyTime{}, "1", 2, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(update).WithArgs(2, AnyTime{}, "1", "i3").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.Move("1", "i3", 2); err != nil {
+		t1.Errorf("Move() error = %v", err)
+	}
+
+	// the first of 3 items moved after the last one, the others moving up
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "i1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "position"}).AddRow("i1", "1", 1))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(countChecklistItems)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(shiftChecklistItems)).WithArgs(-1, AnyTime{}, "1", 2, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+	testSuite.mock.ExpectExec(update).WithArgs(3, AnyTime{}, "1", "i1").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.Move("1", "i1", 10); err != nil {
+		t1.Errorf("Move() error = %v", err)
+	}
+
+	// the task has no such item
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "missing").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	testSuite.mock.ExpectRollback()
+	if err := c.Move("1", "missing", 1); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Move() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChecklistRepository_SetDone(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewChecklistRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "i1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "done"}).AddRow("i1", "1", false))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "checklist_items" SET "done"=$1,"updated_at"=$2 WHERE task_id = $3 AND id = $4`)).
+		WithArgs(true, AnyTime{}, "1", "i1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countChecklistProgress)).WithArgs(1, 0, AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.SetDone("1", "i1", true); err != nil {
+		t1.Errorf("SetDone() error = %v", err)
+	}
+
+	// already checked, nothing is counted twice
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "i1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "done"}).AddRow("i1", "1", true))
+	testSuite.mock.ExpectCommit()
+	if err := c.SetDone("1", "i1", true); err != nil {
+		t1.Errorf("SetDone() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestChecklistRepository_DeleteByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewChecklistRepository(testSuite.gormDB)
+
+	// the checked first of 3 items is removed, the 2 others moving up
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(lockChecklist)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(findChecklistItem)).WithArgs("1", "i1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "position", "done"}).AddRow("i1", "1", 1, true))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "checklist_items" WHERE task_id = $1 AND id = $2`)).
+		WithArgs("1", "i1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(countChecklistItems)).WithArgs("1").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(shiftChecklistItems)).WithArgs(-1, AnyTime{}, "1", 2, 3).WillReturnResult(sqlmock.NewResult(0, 2))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countChecklistProgress)).WithArgs(-1, -1, AnyTime{}, "1").WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.DeleteByID("1", "i1"); err != nil {
+		t1.Errorf("DeleteByID() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
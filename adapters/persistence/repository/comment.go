@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"log"
+)
+
+// CommentRepository stores the comments of the tasks, the number of comments of a task is kept on it in the same transactions
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository is the constructor of a CommentRepository with the database dependency injected
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &CommentRepository{db: db}
+}
+
+// Create posts the comment and counts it on its task, errs.ErrNotFound is returned if there is no such task or it is in the trash
+func (c *CommentRepository) Create(comment *entity.Comment) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		if err := countComments(tx, comment.TaskID, 1); err != nil {
+			return err
+		}
+		return tx.Create(comment).Error
+	}))
+}
+
+// FindByTask returns the page of the comments of the task, from the oldest one
+func (c *CommentRepository) FindByTask(taskID string, query *entity.CommentQuery) ([]*entity.Comment, error) {
+	var comments []*entity.Comment
+	tx := c.db.Where("task_id = ?", taskID).Order("created_at").Order("id").Limit(query.Limit).Offset(query.Offset).Find(&comments)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return comments, nil
+}
+
+// CountByTask returns the total number of comments of the task
+func (c *CommentRepository) CountByTask(taskID string) (int64, error) {
+	var total int64
+	tx := c.db.Model(&entity.Comment{}).Where("task_id = ?", taskID).Count(&total)
+	if tx.Error != nil {
+		return 0, translateError(tx.Error)
+	}
+	return total, nil
+}
+
+// FindByID finds a comment of the task by its ID, errs.ErrNotFound is returned if the task has no such comment
+func (c *CommentRepository) FindByID(taskID string, id string) (*entity.Comment, error) {
+	var comment entity.Comment
+	tx := c.db.Where("task_id = ?", taskID).Where("id = ?", id).First(&comment)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &comment, nil
+}
+
+// Update sets the new body of the comment and records its previous one as a revision, both are saved or none
+func (c *CommentRepository) Update(comment *entity.Comment, revision *entity.CommentRevision) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		values := map[string]interface{}{"body": comment.Body, "edited_at": comment.EditedAt}
+		res := tx.Model(&entity.Comment{}).Where("task_id = ?", comment.TaskID).Where("id = ?", comment.ID).Updates(values)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errs.New(errs.ErrNotFound, "could not find comment with id '%s'", comment.ID)
+		}
+		return tx.Create(revision).Error
+	}))
+}
+
+// DeleteByID removes the comment of the task along with its revisions, errs.ErrNotFound is returned if the task has no such comment
+func (c *CommentRepository) DeleteByID(taskID string, id string) error {
+	return translateError(c.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("task_id = ?", taskID).Where("id = ?", id).Delete(&entity.Comment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errs.New(errs.ErrNotFound, "could not find comment with id '%s'", id)
+		}
+		return countComments(tx, taskID, -1)
+	}))
+}
+
+// FindRevisions returns the previous bodies of the comment, from the most recent edit
+func (c *CommentRepository) FindRevisions(commentID string) ([]*entity.CommentRevision, error) {
+	var revisions []*entity.CommentRevision
+	tx := c.db.Where("comment_id = ?", commentID).Order("edited_at DESC").Order("id").Find(&revisions)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return revisions, nil
+}
+
+// countComments adds delta to the number of comments of the task. Its version is incremented too, since the number is part of the task
+// and the clients holding a copy of it need to see it changed.
+func countComments(tx *gorm.DB, taskID string, delta int) error {
+	values := map[string]interface{}{"comments": gorm.Expr("comments + ?", delta), "version": gorm.Expr("version + 1")}
+	res := tx.Model(&entity.Task{}).Where("id = ?", taskID).Where("deleted_at IS NULL").Updates(values)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find task with id '%s'", taskID)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// countComment is the statement counting a comment on its task, or uncounting it
+const countComment = `UPDATE "tasks" SET "comments"=comments + $1,"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL`
+
+func TestCommentRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewCommentRepository(testSuite.gormDB)
+	comment := &entity.Comment{ID: "c1", TaskID: "1", Author: "alice", CommentBody: entity.CommentBody{Body: "looks good"}}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countComment)).
+		WithArgs(1, AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "comments" ("id","tenant_id","task_id","author","created_at","updated_at","edited_at","body") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+		WithArgs("c1", entity.DefaultTenantID, "1", "alice", AnyTime{}, AnyTime{}, nil, "looks good").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.Create(comment); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+
+	// the task is in the trash or does not exist
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countComment)).
+		WithArgs(1, AnyTime{}, "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectRollback()
+	if err := c.Create(&entity.Comment{ID: "c2", TaskID: "2"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Create() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommentRepository_FindByTask(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewCommentRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "comments" WHERE task_id = $1 ORDER BY created_at,id LIMIT 2 OFFSET 1`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "body"}).AddRow("c2", "1", "second").AddRow("c3", "1", "third"))
+
+	got, err := c.FindByTask("1", &entity.CommentQuery{Limit: 2, Offset: 1})
+	if err != nil {
+		t1.Fatalf("FindByTask() error = %v", err)
+	}
+	want := []*entity.Comment{
+		{ID: "c2", TaskID: "1", CommentBody: entity.CommentBody{Body: "second"}},
+		{ID: "c3", TaskID: "1", CommentBody: entity.CommentBody{Body: "third"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindByTask() got = %v, want %v", got, want)
+	}
+}
+
+func TestCommentRepository_Update(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewCommentRepository(testSuite.gormDB)
+	editedAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	comment := &entity.Comment{ID: "c1", TaskID: "1", EditedAt: &editedAt, CommentBody: entity.CommentBody{Body: "looks great"}}
+	revision := &entity.CommentRevision{ID: "r1", CommentID: "c1", Body: "looks good", EditedBy: "alice", EditedAt: editedAt}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "comments" SET "body"=$1,"edited_at"=$2,"updated_at"=$3 WHERE task_id = $4 AND id = $5`)).
+		WithArgs("looks great", &editedAt, AnyTime{}, "1", "c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "comment_revisions" ("id","tenant_id","comment_id","body","edited_by","edited_at") VALUES ($1,$2,$3,$4,$5,$6)`)).
+		WithArgs("r1", entity.DefaultTenantID, "c1", "looks good", "alice", editedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := c.Update(comment, revision); err != nil {
+		t1.Errorf("Update() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommentRepository_DeleteByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	c := NewCommentRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "comments" WHERE task_id = $1 AND id = $2`)).
+		WithArgs("1", "c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(countComment)).
+		WithArgs(-1, AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := c.DeleteByID("1", "c1"); err != nil {
+		t1.Errorf("DeleteByID() error = %v", err)
+	}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "comments" WHERE task_id = $1 AND id = $2`)).
+		WithArgs("1", "c2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectRollback()
+	if err := c.DeleteByID("1", "c2"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+)
+
+// openBlockers joins the dependencies to their blockers that are neither closed nor in the trash
+const openBlockers = "JOIN tasks AS b ON b.id = task_dependencies.blocker_id AND b.status <> ? AND b.deleted_at IS NULL"
+
+// AddDependency records that a task is blocked by another one, errs.ErrConflict is returned if it is already
+func (t *TaskRepository) AddDependency(dependency *entity.TaskDependency) error {
+	tx := t.db.Create(dependency)
+	return translateError(tx.Error)
+}
+
+// RemoveDependency removes the dependency of a task on its blocker, errs.ErrNotFound is returned if there is no such dependency
+func (t *TaskRepository) RemoveDependency(taskID string, blockerID string) error {
+	tx := t.db.Where("task_id = ?", taskID).Where("blocker_id = ?", blockerID).Delete(&entity.TaskDependency{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "task with id '%s' is not blocked by task with id '%s'", taskID, blockerID)
+	}
+	return nil
+}
+
+// FindBlockers returns the tasks blocking the task, the most important first, the ones in the trash excluded
+func (t *TaskRepository) FindBlockers(taskID string) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+	tx := t.db.Joins("JOIN task_dependencies AS d ON d.blocker_id = tasks.id").Where("d.task_id = ?", taskID).Where("tasks.deleted_at IS NULL").
+		Order("tasks.priority DESC").Order("tasks.created_at").Order("tasks.id").Find(&tasks)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return tasks, nil
+}
+
+// FindDependencies returns the dependencies of the given tasks on their blockers, whatever their status
+func (t *TaskRepository) FindDependencies(taskIDs []string) ([]*entity.TaskDependency, error) {
+	var dependencies []*entity.TaskDependency
+	tx := t.db.Where("task_id IN ?", taskIDs).Find(&dependencies)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return dependencies, nil
+}
+
+// FindOpenDependencies returns the dependencies between the tasks that are neither closed nor in the trash
+func (t *TaskRepository) FindOpenDependencies() ([]*entity.TaskDependency, error) {
+	var dependencies []*entity.TaskDependency
+	tx := t.db.Joins(openBlockers, entity.Closed).
+		Joins("JOIN tasks AS t ON t.id = task_dependencies.task_id AND t.status <> ? AND t.deleted_at IS NULL", entity.Closed).
+		Order("task_dependencies.created_at").Find(&dependencies)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return dependencies, nil
+}
+
+// CountOpenBlockers returns the number of tasks blocking the task that are neither closed nor in the trash
+func (t *TaskRepository) CountOpenBlockers(taskID string) (int64, error) {
+	var count int64
+	tx := t.db.Model(&entity.TaskDependency{}).Joins(openBlockers, entity.Closed).Where("task_dependencies.task_id = ?", taskID).Count(&count)
+	if tx.Error != nil {
+		return 0, translateError(tx.Error)
+	}
+	return count, nil
+}
+
+// FindOpenTasks returns up to limit tasks that are neither closed nor in the trash, the most important first
+func (t *TaskRepository) FindOpenTasks(limit int) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+	tx := t.db.Where("status <> ?", entity.Closed).Where("deleted_at IS NULL").Order("priority DESC").Order("created_at").Order("id").Limit(limit).Find(&tasks)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestTaskRepository_AddDependency(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "task_dependencies" ("task_id","blocker_id","tenant_id","created_at","created_by") VALUES ($1,$2,$3,$4,$5)`)).
+		WithArgs("1", "2", entity.DefaultTenantID, AnyTime{}, "admin").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.AddDependency(&entity.TaskDependency{TaskID: "1", BlockerID: "2", CreatedBy: "admin"}); err != nil {
+		t1.Errorf("AddDependency() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_RemoveDependency(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	for _, rowsAffected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "task_dependencies" WHERE task_id = $1 AND blocker_id = $2`)).
+			WithArgs("1", "2").
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		testSuite.mock.ExpectCommit()
+
+		err := testSuite.repository.RemoveDependency("1", "2")
+		if rowsAffected == 1 && err != nil {
+			t1.Errorf("RemoveDependency() error = %v", err)
+		}
+		if rowsAffected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("RemoveDependency() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+}
+
+func TestTaskRepository_CountOpenBlockers(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "task_dependencies" `+
+		`JOIN tasks AS b ON b.id = task_dependencies.blocker_id AND b.status <> $1 AND b.deleted_at IS NULL WHERE task_dependencies.task_id = $2`)).
+		WithArgs(entity.Closed, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	got, err := testSuite.repository.CountOpenBlockers("1")
+	if err != nil || got != 2 {
+		t1.Errorf("CountOpenBlockers() = %d, %v, want 2", got, err)
+	}
+}
+
+func TestTaskRepository_FindOpenDependencies(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "task_dependencies"."task_id","task_dependencies"."blocker_id","task_dependencies"."tenant_id","task_dependencies"."created_at","task_dependencies"."created_by" FROM "task_dependencies" `+
+		`JOIN tasks AS b ON b.id = task_dependencies.blocker_id AND b.status <> $1 AND b.deleted_at IS NULL `+
+		`JOIN tasks AS t ON t.id = task_dependencies.task_id AND t.status <> $2 AND t.deleted_at IS NULL ORDER BY task_dependencies.created_at`)).
+		WithArgs(entity.Closed, entity.Closed).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}).AddRow("1", "2"))
+
+	got, err := testSuite.repository.FindOpenDependencies()
+	if err != nil {
+		t1.Fatalf("FindOpenDependencies() error = %v", err)
+	}
+	if want := []*entity.TaskDependency{{TaskID: "1", BlockerID: "2"}}; !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindOpenDependencies() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/jackc/pgconn"
+	"gorm.io/gorm"
+	"net"
+	"strings"
+)
+
+// uniqueViolation is the postgres error code raised when a unique constraint is violated
+const uniqueViolation = "23505"
+
+// translateError converts the errors returned by gorm and the postgres driver to the kinds defined in the domain,
+// so that the service and the handlers do not need to know anything about the database.
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errs.Wrap(errs.ErrNotFound, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == uniqueViolation:
+			return errs.Wrap(errs.ErrConflict, err)
+		// class 08 is for connection exceptions, 53 for insufficient resources and 57P for the server shutting down
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57P"):
+			return errs.Wrap(errs.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || pgconn.Timeout(err) || pgconn.SafeToRetry(err) ||
+		errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return errs.Wrap(errs.ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"gorm.io/gorm"
+)
+
+// FindChildren returns the subtasks of all the given tasks, ordered by creation, the ones in the trash excluded
+func (t *TaskRepository) FindChildren(parentIDs []string) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+	tx := t.db.Where("parent_id IN ?", parentIDs).Where("deleted_at IS NULL").Order("created_at").Order("id").Find(&tasks)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return tasks, nil
+}
+
+// RefreshCompletion computes the number of subtasks of a task and their completion again from the subtasks themselves.
+// A closed subtask is fully done, an open one counts for the completion of its own subtasks if it has some.
+// The version of the task is incremented, since its representation changes.
+func (t *TaskRepository) RefreshCompletion(id string) error {
+	children := "FROM tasks AS c WHERE c.parent_id = tasks.id AND c.deleted_at IS NULL"
+	values := map[string]interface{}{
+		"subtasks":   gorm.Expr("(SELECT count(*) " + children + ")"),
+		"completion": gorm.Expr("(SELECT round(avg(CASE WHEN c.status = ? THEN 100 ELSE coalesce(c.completion, 0) END)) "+children+")", entity.Closed),
+		"version":    gorm.Expr("version + 1"),
+	}
+	tx := t.db.Model(entity.Task{}).Where("id = ?", id).Where("deleted_at IS NULL").Updates(values)
+	return translateError(tx.Error)
+}
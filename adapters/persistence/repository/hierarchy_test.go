@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestTaskRepository_FindChildren(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE parent_id IN ($1,$2) AND deleted_at IS NULL ORDER BY created_at,id`)).
+		WithArgs("1", "2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id"}).AddRow("3", "1").AddRow("4", "2"))
+
+	got, err := testSuite.repository.FindChildren([]string{"1", "2"})
+	if err != nil {
+		t1.Fatalf("FindChildren() error = %v", err)
+	}
+	want := []*entity.Task{{ID: "3", TaskDescription: entity.TaskDescription{ParentID: "1"}}, {ID: "4", TaskDescription: entity.TaskDescription{ParentID: "2"}}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindChildren() got = %v, want %v", got, want)
+	}
+}
+
+func TestTaskRepository_RefreshCompletion(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "completion"=(SELECT round(avg(CASE WHEN c.status = $1 THEN 100 ELSE coalesce(c.completion, 0) END)) `+
+		`FROM tasks AS c WHERE c.parent_id = tasks.id AND c.deleted_at IS NULL),`+
+		`"subtasks"=(SELECT count(*) FROM tasks AS c WHERE c.parent_id = tasks.id AND c.deleted_at IS NULL),`+
+		`"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL`)).
+		WithArgs(entity.Closed, AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.RefreshCompletion("1"); err != nil {
+		t1.Errorf("RefreshCompletion() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"gorm.io/gorm"
+)
+
+// Transaction runs fn with a repository bound to a single database transaction, so that a change and its event are recorded together or not at all
+func (t *TaskRepository) Transaction(fn func(repo interfaces.ITaskRepository) error) error {
+	return translateError(t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&TaskRepository{db: tx})
+	}))
+}
+
+// AppendEvent records a change event of a task, events are never updated afterwards
+func (t *TaskRepository) AppendEvent(event *entity.TaskEvent) error {
+	tx := t.db.Create(event)
+	return translateError(tx.Error)
+}
+
+// FindEvents returns the page of the change events of the task, from the most recent one.
+// The events are kept when the task is purged, so that it is still known who removed what.
+func (t *TaskRepository) FindEvents(taskID string, query *entity.HistoryQuery) ([]*entity.TaskEvent, error) {
+	var events []*entity.TaskEvent
+	tx := t.db.Where("task_id = ?", taskID).Order("at DESC").Order("id").Limit(query.Limit).Offset(query.Offset).Find(&events)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return events, nil
+}
+
+// CountEvents returns the total number of change events of the task
+func (t *TaskRepository) CountEvents(taskID string) (int64, error) {
+	var total int64
+	tx := t.db.Model(&entity.TaskEvent{}).Where("task_id = ?", taskID).Count(&total)
+	if tx.Error != nil {
+		return 0, translateError(tx.Error)
+	}
+	return total, nil
+}
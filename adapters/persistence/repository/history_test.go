@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_AppendEvent(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	event := &entity.TaskEvent{ID: "e1", TaskID: "1", Type: entity.Updated, Actor: "admin", At: time.Now(),
+		Changes: []entity.FieldChange{{Field: "status", Old: "new", New: "closed"}}}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "task_events" ("id","tenant_id","task_id","type","actor","at","changes") VALUES ($1,$2,$3,$4,$5,$6,$7)`)).
+		WithArgs(event.ID, entity.DefaultTenantID, event.TaskID, event.Type, event.Actor, AnyTime{}, `[{"field":"status","old":"new","new":"closed"}]`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.AppendEvent(event); err != nil {
+		t1.Fatalf("AppendEvent() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_FindEvents(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	at := time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "task_events" WHERE task_id = $1 ORDER BY at DESC,id LIMIT 10 OFFSET 10`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "type", "actor", "at", "changes"}).
+			AddRow("e1", "1", "updated", "admin", at, []byte(`[{"field":"priority","old":1,"new":5}]`)))
+
+	got, err := testSuite.repository.FindEvents("1", &entity.HistoryQuery{Limit: 10, Offset: 10})
+	if err != nil {
+		t1.Fatalf("FindEvents() error = %v", err)
+	}
+	// numbers are decoded as float64 from the json column
+	want := []*entity.TaskEvent{{ID: "e1", TaskID: "1", Type: entity.Updated, Actor: "admin", At: at,
+		Changes: []entity.FieldChange{{Field: "priority", Old: float64(1), New: float64(5)}}}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindEvents() got = %v, want %v", got, want)
+	}
+}
+
+func TestTaskRepository_Transaction(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	failure := errors.New("event could not be recorded")
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "deleted_at"=$1,"deleted_by"=$2,"version"=version + 1,"updated_at"=$3 WHERE id = $4 AND deleted_at IS NULL`)).
+		WithArgs(AnyTime{}, "admin", AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectRollback()
+
+	// the deletion is rolled back since the rest of the transaction failed
+	err := testSuite.repository.Transaction(func(repo interfaces.ITaskRepository) error {
+		if err := repo.DeleteByID("1", "admin"); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t1.Errorf("Transaction() error = %v, want %v", err, failure)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"log"
+)
+
+// labelledBy matches the tasks the label given as argument is attached to
+const labelledBy = "id IN (SELECT task_id FROM task_labels WHERE label_id = ?)"
+
+// LabelRepository stores the catalogue of the labels, their attachments to the tasks are managed by the TaskRepository
+type LabelRepository struct {
+	db *gorm.DB
+}
+
+// NewLabelRepository is the constructor of a LabelRepository with the database dependency injected
+func NewLabelRepository(db *gorm.DB) *LabelRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &LabelRepository{db: db}
+}
+
+// Create creates a new label, errs.ErrConflict is returned if there is already a label with the same name
+func (l *LabelRepository) Create(label *entity.Label) error {
+	tx := l.db.Create(label)
+	return translateError(tx.Error)
+}
+
+// FindAll returns all the labels of the catalogue ordered by name
+func (l *LabelRepository) FindAll() ([]*entity.Label, error) {
+	var labels []*entity.Label
+	tx := l.db.Order("name").Find(&labels)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return labels, nil
+}
+
+// FindByID finds a label by its ID, errs.ErrNotFound is returned if there is no such label
+func (l *LabelRepository) FindByID(id string) (*entity.Label, error) {
+	var label entity.Label
+	tx := l.db.Where("id = ?", id).First(&label)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &label, nil
+}
+
+// Update sets the given values of the label, errs.ErrConflict is returned if it is renamed after another label.
+// The version of the tasks it is attached to is incremented in the same transaction.
+func (l *LabelRepository) Update(fields map[string]interface{}, id string) error {
+	return translateError(l.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entity.Label{}).Where("id = ?", id).Updates(fields)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errs.New(errs.ErrNotFound, "could not find label with id '%s'", id)
+		}
+		return labelsChanged(tx, labelledBy, id)
+	}))
+}
+
+// DeleteByID removes the label from the catalogue and detaches it from all the tasks, whose version is incremented in the same transaction
+func (l *LabelRepository) DeleteByID(id string) error {
+	return translateError(l.db.Transaction(func(tx *gorm.DB) error {
+		// before the attachments are removed along with the label
+		if err := labelsChanged(tx, labelledBy, id); err != nil {
+			return err
+		}
+		res := tx.Where("id = ?", id).Delete(&entity.Label{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errs.New(errs.ErrNotFound, "could not find label with id '%s'", id)
+		}
+		return nil
+	}))
+}
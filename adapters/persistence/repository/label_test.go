@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/jackc/pgconn"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLabelRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	l := NewLabelRepository(testSuite.gormDB)
+	label := &entity.Label{ID: "1", LabelDescription: entity.LabelDescription{Name: "bug", Color: "#ff0000"}}
+
+	for _, duplicate := range []bool{false, true} {
+		testSuite.mock.ExpectBegin()
+		exec := testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "labels" ("id","tenant_id","created_at","updated_at","name","color") VALUES ($1,$2,$3,$4,$5,$6)`)).
+			WithArgs("1", entity.DefaultTenantID, AnyTime{}, AnyTime{}, "bug", "#ff0000")
+		if duplicate {
+			exec.WillReturnError(&pgconn.PgError{Code: "23505"})
+			testSuite.mock.ExpectRollback()
+		} else {
+			exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			testSuite.mock.ExpectCommit()
+		}
+
+		err := l.Create(label)
+		if !duplicate && err != nil {
+			t1.Errorf("Create() error = %v", err)
+		}
+		if duplicate && !errors.Is(err, errs.ErrConflict) {
+			t1.Errorf("Create() error = %v, want %v", err, errs.ErrConflict)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLabelRepository_FindAll(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	l := NewLabelRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "labels" ORDER BY name`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow("2", "bug").AddRow("1", "urgent"))
+
+	got, err := l.FindAll()
+	if err != nil {
+		t1.Fatalf("FindAll() error = %v", err)
+	}
+	want := []*entity.Label{{ID: "2", LabelDescription: entity.LabelDescription{Name: "bug"}}, {ID: "1", LabelDescription: entity.LabelDescription{Name: "urgent"}}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindAll() got = %v, want %v", got, want)
+	}
+}
+
+func TestLabelRepository_Update(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	l := NewLabelRepository(testSuite.gormDB)
+
+	for _, rowsAffected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "labels" SET "name"=$1,"updated_at"=$2 WHERE id = $3`)).
+			WithArgs("defect", AnyTime{}, "1").
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		if rowsAffected == 1 {
+			// the tasks the label is attached to change with it
+			testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "version"=version + 1,"updated_at"=$1 WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = $2)`)).
+				WithArgs(AnyTime{}, "1").
+				WillReturnResult(sqlmock.NewResult(0, 2))
+			testSuite.mock.ExpectCommit()
+		} else {
+			testSuite.mock.ExpectRollback()
+		}
+
+		err := l.Update(map[string]interface{}{"name": "defect"}, "1")
+		if rowsAffected == 1 && err != nil {
+			t1.Errorf("Update() error = %v", err)
+		}
+		if rowsAffected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("Update() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestLabelRepository_DeleteByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	l := NewLabelRepository(testSuite.gormDB)
+
+	for _, rowsAffected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "version"=version + 1,"updated_at"=$1 WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = $2)`)).
+			WithArgs(AnyTime{}, "1").
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "labels" WHERE id = $1`)).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		if rowsAffected == 1 {
+			testSuite.mock.ExpectCommit()
+		} else {
+			testSuite.mock.ExpectRollback()
+		}
+
+		err := l.DeleteByID("1")
+		if rowsAffected == 1 && err != nil {
+			t1.Errorf("DeleteByID() error = %v", err)
+		}
+		if rowsAffected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+// defaultProjectName is the name of the default project when it is created
+const defaultProjectName = "Default"
+
+// ProjectRepository stores the projects, the tasks referencing them are stored by the TaskRepository
+type ProjectRepository struct {
+	db *gorm.DB
+}
+
+// NewProjectRepository is the constructor of a ProjectRepository with the database dependency injected
+func NewProjectRepository(db *gorm.DB) *ProjectRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &ProjectRepository{db: db}
+}
+
+// Create creates a new project, errs.ErrConflict is returned if there is already a project with the same name
+func (p *ProjectRepository) Create(project *entity.Project) error {
+	tx := p.db.Create(project)
+	return translateError(tx.Error)
+}
+
+// FindAll returns all the projects ordered by name, including the default project which is created if needed
+func (p *ProjectRepository) FindAll() ([]*entity.Project, error) {
+	if _, err := findProject(p.db, entity.DefaultProjectID); err != nil {
+		return nil, err
+	}
+	var projects []*entity.Project
+	tx := p.db.Order("name").Find(&projects)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return projects, nil
+}
+
+// FindByID finds a project by its ID, errs.ErrNotFound is returned if there is no such project
+func (p *ProjectRepository) FindByID(id string) (*entity.Project, error) {
+	return findProject(p.db, id)
+}
+
+// Update sets the given values of the project, errs.ErrConflict is returned if it is renamed after another project
+func (p *ProjectRepository) Update(fields map[string]interface{}, id string) error {
+	if id == entity.DefaultProjectID {
+		// the default project of the tenant may not have been created yet
+		if _, err := findProject(p.db, id); err != nil {
+			return err
+		}
+	}
+	tx := p.db.Model(&entity.Project{}).Where("id = ?", id).Updates(fields)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find project with id '%s'", id)
+	}
+	return nil
+}
+
+// DeleteByID deletes the project if it has no tasks, errs.ErrConflict is returned otherwise.
+// The tasks in the trash are counted too, since they would be restored into the project.
+func (p *ProjectRepository) DeleteByID(id string) error {
+	tx := p.db.Where("id = ?", id).Where("NOT EXISTS (SELECT 1 FROM tasks WHERE tasks.tenant_id = projects.tenant_id AND tasks.project_id = projects.id)").Delete(&entity.Project{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		// nothing was deleted, either the project does not exist or it still has tasks
+		if _, err := p.FindByID(id); err != nil {
+			return err
+		}
+		return errs.New(errs.ErrConflict, "project with id '%s' still has tasks", id)
+	}
+	return nil
+}
+
+// FindProject finds the project of the tasks by its ID, so that its settings are read in the same transaction as the tasks
+func (t *TaskRepository) FindProject(id string) (*entity.Project, error) {
+	return findProject(t.db, id)
+}
+
+// FindProjectOf returns the ID of the project of the task, the task being in the trash or not
+func (t *TaskRepository) FindProjectOf(taskID string) (string, error) {
+	var task entity.Task
+	tx := t.db.Select("project_id").Where("id = ?", taskID).First(&task)
+	if tx.Error != nil {
+		return "", translateError(tx.Error)
+	}
+	return task.ProjectID, nil
+}
+
+// findProject finds a project by its ID. The default project of the tenant is created the first time it is looked up,
+// concurrent creations are ignored and the project is read again so that the one that was stored is returned.
+func findProject(db *gorm.DB, id string) (*entity.Project, error) {
+	var project entity.Project
+	tx := db.Where("id = ?", id).First(&project)
+	if errors.Is(tx.Error, gorm.ErrRecordNotFound) && id == entity.DefaultProjectID {
+		defaultProject := entity.Project{ID: entity.DefaultProjectID, ProjectDescription: entity.ProjectDescription{Name: defaultProjectName}}
+		if tx = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&defaultProject); tx.Error == nil {
+			tx = db.Where("id = ?", id).First(&project)
+		}
+	}
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &project, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestProjectRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	p := NewProjectRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "projects" ("tenant_id","id","created_at","updated_at","name","description","default_priority","allowed_statuses","require_checklist") VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)`)).
+		WithArgs(entity.DefaultTenantID, "1", AnyTime{}, AnyTime{}, "backend", "", 3, "active,closed", false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	project := &entity.Project{ID: "1", ProjectDescription: entity.ProjectDescription{Name: "backend", ProjectSettings: entity.ProjectSettings{
+		DefaultPriority: 3, AllowedStatuses: entity.StatusList{entity.Active, entity.Closed}}}}
+	if err := p.Create(project); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestProjectRepository_FindByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	p := NewProjectRepository(testSuite.gormDB)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "projects" WHERE id = $1 ORDER BY "projects"."id" LIMIT 1`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "default_priority", "allowed_statuses"}).AddRow("1", "backend", 3, "active,closed"))
+
+	got, err := p.FindByID("1")
+	if err != nil {
+		t1.Fatalf("FindByID() error = %v", err)
+	}
+	want := &entity.Project{ID: "1", ProjectDescription: entity.ProjectDescription{Name: "backend", ProjectSettings: entity.ProjectSettings{
+		DefaultPriority: 3, AllowedStatuses: entity.StatusList{entity.Active, entity.Closed}}}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindByID() got = %v, want %v", got, want)
+	}
+}
+
+func TestTaskRepository_FindProjectOf(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	// the task is found whether it is in the trash or not
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "project_id" FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT 1`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"project_id"}).AddRow("backend"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "project_id" FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT 1`)).
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"project_id"}))
+
+	if got, err := testSuite.repository.FindProjectOf("1"); err != nil || got != "backend" {
+		t1.Errorf("FindProjectOf() = %s, %v, want backend", got, err)
+	}
+	if _, err := testSuite.repository.FindProjectOf("2"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("FindProjectOf() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
+
+func TestProjectRepository_DeleteByID(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	p := NewProjectRepository(testSuite.gormDB)
+
+	for _, exists := range []bool{true, false} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "projects" WHERE id = $1 AND (NOT EXISTS (SELECT 1 FROM tasks WHERE tasks.tenant_id = projects.tenant_id AND tasks.project_id = projects.id))`)).
+			WithArgs("1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		testSuite.mock.ExpectCommit()
+		rows := sqlmock.NewRows([]string{"id"})
+		if exists {
+			rows.AddRow("1")
+		}
+		testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "projects" WHERE id = $1`)).
+			WithArgs("1").
+			WillReturnRows(rows)
+
+		err := p.DeleteByID("1")
+		// a project that was not deleted while it exists still has tasks
+		if exists && !errors.Is(err, errs.ErrConflict) {
+			t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrConflict)
+		}
+		if !exists && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("DeleteByID() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+}
+
+func TestTaskRepository_Count_Project(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND project_id = $1`)).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{ProjectID: "1"}); err != nil || got != 4 {
+		t1.Errorf("Count() got = %v, %v, want 4", got, err)
+	}
+}
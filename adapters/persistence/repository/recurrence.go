@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// CreateOccurrence creates the next occurrence of a series unless it already exists, created is false in that case.
+// The unique index on (template_id, occurrence) makes spawning idempotent when a close and the scheduler race.
+func (t *TaskRepository) CreateOccurrence(task *entity.Task) (created bool, err error) {
+	tx := t.db.Clauses(clause.OnConflict{DoNothing: true}).Create(task)
+	if tx.Error != nil {
+		return false, translateError(tx.Error)
+	}
+	return tx.RowsAffected > 0, nil
+}
+
+// FindDueOccurrences returns up to limit occurrences of the series whose next occurrence should be spawned:
+// the ones that are closed or due at now, and are the last of their series which has not ended.
+func (t *TaskRepository) FindDueOccurrences(now time.Time, limit int) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+	tx := t.db.Where("template_id <> ''").Where("deleted_at IS NULL").Where("NOT series_ended").
+		Where("status = ? OR due_at <= ?", entity.Closed, now).
+		Where("NOT EXISTS (SELECT 1 FROM tasks AS n WHERE n.template_id = tasks.template_id AND n.occurrence = tasks.occurrence + 1)").
+		Order("due_at").Limit(limit).Find(&tasks)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return tasks, nil
+}
+
+// EndSeries marks the occurrence as the end of its series, FindDueOccurrences does not return it anymore.
+// The flag is bookkeeping for the scheduler, the version of the task is left as it is.
+func (t *TaskRepository) EndSeries(id string) error {
+	tx := t.db.Model(&entity.Task{}).Where("id = ?", id).UpdateColumn("series_ended", true)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	return nil
+}
+
+// ResumeSeries clears the end of the series of the template, so that the scheduler looks at its last occurrence again
+func (t *TaskRepository) ResumeSeries(templateID string) error {
+	tx := t.db.Model(&entity.Task{}).Where("template_id = ? AND series_ended", templateID).UpdateColumn("series_ended", false)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	return nil
+}
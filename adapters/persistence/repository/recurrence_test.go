@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestTaskRepository_CreateOccurrence(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	task := &entity.Task{ID: "2", Version: 1, TemplateID: "1", Occurrence: 2, TaskDescription: entity.TaskDescription{Title: "weekly", Status: entity.New}}
+
+	for _, rowsAffected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "tasks"`) + ".*" + regexp.QuoteMeta(`ON CONFLICT DO NOTHING`)).
+			WillReturnResult(sqlmock.NewResult(0, rowsAffected))
+		testSuite.mock.ExpectCommit()
+
+		created, err := testSuite.repository.CreateOccurrence(task)
+		if err != nil {
+			t1.Fatalf("CreateOccurrence() error = %v", err)
+		}
+		if created != (rowsAffected == 1) {
+			t1.Errorf("CreateOccurrence() got = %v, want %v", created, rowsAffected == 1)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_FindDueOccurrences(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	now := time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC)
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE template_id <> '' AND deleted_at IS NULL AND NOT series_ended AND (status = $1 OR due_at <= $2) `+
+		`AND (NOT EXISTS (SELECT 1 FROM tasks AS n WHERE n.template_id = tasks.template_id AND n.occurrence = tasks.occurrence + 1)) ORDER BY due_at LIMIT 10`)).
+		WithArgs(entity.Closed, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "template_id", "occurrence"}).AddRow("1", "1", 1))
+
+	got, err := testSuite.repository.FindDueOccurrences(now, 10)
+	if err != nil {
+		t1.Fatalf("FindDueOccurrences() error = %v", err)
+	}
+	if want := []*entity.Task{{ID: "1", TemplateID: "1", Occurrence: 1}}; !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindDueOccurrences() got = %v, want %v", got, want)
+	}
+}
+
+func TestTaskRepository_EndSeries(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "series_ended"=$1 WHERE id = $2`)).
+		WithArgs(true, "2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "series_ended"=$1 WHERE template_id = $2 AND series_ended`)).
+		WithArgs(false, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.EndSeries("2"); err != nil {
+		t1.Errorf("EndSeries() error = %v", err)
+	}
+	if err := testSuite.repository.ResumeSeries("1"); err != nil {
+		t1.Errorf("ResumeSeries() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -3,10 +3,25 @@ package repository
 import (
 	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
+	"time"
 )
 
+// sortColumns maps the sortable fields of a task to their column in the database
+var sortColumns = map[string]string{
+	"title":     "title",
+	"priority":  "priority",
+	"status":    "status",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+	"deletedAt": "deleted_at",
+	"startAt":   "start_at",
+	"dueAt":     "due_at",
+}
+
 // TaskRepository The attributes should be the dependencies needed from the outer layer's stuff, those will be injected
 type TaskRepository struct {
 	db *gorm.DB
@@ -23,36 +38,175 @@ func NewTaskRepository(db *gorm.DB) *TaskRepository {
 // Create creates a new task in the database
 func (t *TaskRepository) Create(task *entity.Task) error {
 	tx := t.db.Create(task)
-	return tx.Error
+	return translateError(tx.Error)
+}
+
+// FindAll returns the page of tasks in the database matching the given query, ordered by the requested fields.
+// The id is always used as last ordering column so that pages are stable when the sorted values are equal.
+func (t *TaskRepository) FindAll(query *entity.TaskQuery) ([]*entity.Task, error) {
+	if query.Keyset {
+		return t.findAfter(query)
+	}
+	var tasks []*entity.Task
+	tx := t.filter(query)
+	for _, sort := range query.Sort {
+		column, ok := sortColumns[sort.Field]
+		if !ok {
+			// the service validates the query first, this only guards the columns put in the statement
+			return nil, errs.Validation([]errs.Violation{{Field: "sort", Message: fmt.Sprintf("cannot sort by unknown field '%s'", sort.Field)}})
+		}
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: sort.Desc})
+	}
+	// SELECT * FROM tasks WHERE ... ORDER BY ... LIMIT ... OFFSET ...;
+	tx = tx.Order("id").Limit(query.Limit).Offset(query.Offset).Find(&tasks) // pointer to our array because it needs to be modified
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return tasks, nil
 }
 
-// FindAll returns all the tasks in the database
-func (t *TaskRepository) FindAll() ([]*entity.Task, error) {
+// findAfter returns the tasks matching the query that come after its cursor in the (created_at, id) order.
+// Comparing the row values lets postgres seek directly in an index on (created_at, id), so the cost of a page does not grow with its position.
+func (t *TaskRepository) findAfter(query *entity.TaskQuery) ([]*entity.Task, error) {
 	var tasks []*entity.Task
-	// SELECT * FROM users;
-	tx := t.db.Find(&tasks) // pointer to our array because it needs to be modified
+	tx := t.filter(query)
+	if query.After != nil {
+		tx = tx.Where("(created_at, id) > (?, ?)", query.After.CreatedAt, query.After.ID)
+	}
+	tx = tx.Order("created_at").Order("id").Limit(query.Limit).Find(&tasks)
 	if tx.Error != nil {
-		return nil, tx.Error
+		return nil, translateError(tx.Error)
 	}
 	return tasks, nil
 }
 
-// DeleteByID Deletes a task identified by its uuid given as parameter
-func (t *TaskRepository) DeleteByID(id string) error {
-	tx := t.db.Where("id = ?", id).Delete(&entity.Task{})
+// Count returns the total number of tasks in the database matching the filters of the given query, pagination is ignored
+func (t *TaskRepository) Count(query *entity.TaskQuery) (int64, error) {
+	var total int64
+	tx := t.filter(query).Count(&total)
+	if tx.Error != nil {
+		return 0, translateError(tx.Error)
+	}
+	return total, nil
+}
+
+// filter applies the filters of the query to a new statement on the tasks table.
+// Only the live tasks are selected, unless the query asks for the ones in the trash.
+func (t *TaskRepository) filter(query *entity.TaskQuery) *gorm.DB {
+	tx := t.db.Model(&entity.Task{})
+	if query.Trashed {
+		tx = tx.Where("deleted_at IS NOT NULL")
+	} else {
+		tx = tx.Where("deleted_at IS NULL")
+	}
+	if query.ProjectID != "" {
+		tx = tx.Where("project_id = ?", query.ProjectID)
+	}
+	if len(query.Statuses) > 0 {
+		tx = tx.Where("status IN ?", query.Statuses)
+	}
+	if query.MinPriority != nil {
+		tx = tx.Where("priority >= ?", *query.MinPriority)
+	}
+	if query.MaxPriority != nil {
+		tx = tx.Where("priority <= ?", *query.MaxPriority)
+	}
+	if query.CreatedAfter != nil {
+		tx = tx.Where("created_at >= ?", *query.CreatedAfter)
+	}
+	if query.CreatedBefore != nil {
+		tx = tx.Where("created_at < ?", *query.CreatedBefore)
+	}
+	if query.UpdatedAfter != nil {
+		tx = tx.Where("updated_at >= ?", *query.UpdatedAfter)
+	}
+	if query.UpdatedBefore != nil {
+		tx = tx.Where("updated_at < ?", *query.UpdatedBefore)
+	}
+	if query.DueAfter != nil {
+		tx = tx.Where("due_at >= ?", *query.DueAfter)
+	}
+	if query.DueBefore != nil {
+		tx = tx.Where("due_at < ?", *query.DueBefore)
+	}
+	// same rule as entity.Task.IsOverdue, evaluated with the clock of the service rather than the one of the database
+	if query.Overdue != nil && *query.Overdue {
+		tx = tx.Where("due_at < ? AND status <> ?", t.db.NowFunc(), entity.Closed)
+	} else if query.Overdue != nil {
+		tx = tx.Where("due_at IS NULL OR due_at >= ? OR status = ?", t.db.NowFunc(), entity.Closed)
+	}
+	if query.Assignee != "" {
+		tx = tx.Where("assignee_id = ?", query.Assignee)
+	}
+	if query.Unassigned != nil && *query.Unassigned {
+		tx = tx.Where("assignee_id = ''")
+	} else if query.Unassigned != nil {
+		tx = tx.Where("assignee_id <> ''")
+	}
+	return filterLabels(tx, query)
+}
+
+// DeleteByID moves a task identified by its uuid given as parameter to the trash, recording when and by whom it was deleted.
+// The row is kept until it is purged, errs.ErrNotFound is returned if there is no such task or if it is already in the trash.
+func (t *TaskRepository) DeleteByID(id string, deletedBy string) error {
+	values := map[string]interface{}{"deleted_at": t.db.NowFunc(), "deleted_by": deletedBy, "version": gorm.Expr("version + 1")}
+	tx := t.db.Model(entity.Task{}).Where("id = ?", id).Where("deleted_at IS NULL").Updates(values)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find task with id '%s'", id)
+	}
+	return nil
+}
+
+// Restore takes a task identified by its uuid given as parameter out of the trash, errs.ErrNotFound is returned if there is no such task in the trash
+func (t *TaskRepository) Restore(id string) error {
+	values := map[string]interface{}{"deleted_at": nil, "deleted_by": "", "version": gorm.Expr("version + 1")}
+	tx := t.db.Model(entity.Task{}).Where("id = ?", id).Where("deleted_at IS NOT NULL").Updates(values)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find task with id '%s' in the trash", id)
+	}
+	return nil
+}
 
-	return tx.Error
+// Purge permanently removes the tasks that were moved to the trash before the given time and returns how many were removed.
+// Their attachments are removed with them, the keys of their content are returned for the caller to remove it from the blob store.
+func (t *TaskRepository) Purge(deletedBefore time.Time) (purged int64, blobKeys []string, err error) {
+	err = t.db.Transaction(func(tx *gorm.DB) error {
+		// the tasks are locked so that none of them is restored once the keys of its attachments are read
+		var ids []string
+		if err := tx.Model(&entity.Task{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("deleted_at < ?", deletedBefore).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(&entity.Attachment{}).Where("task_id IN ?", ids).Pluck("blob_key", &blobKeys).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id IN ?", ids).Delete(&entity.Task{})
+		if res.Error != nil {
+			return res.Error
+		}
+		purged = res.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, nil, translateError(err)
+	}
+	return purged, blobKeys, nil
 }
 
-// FindByID Finds a task identified by its uuid given as parameter
+// FindByID Finds a task identified by its uuid given as parameter, errs.ErrNotFound is returned if there is no such task or if it is in the trash
 func (t *TaskRepository) FindByID(id string) (*entity.Task, error) {
 	var task entity.Task //This is necessary, should not create pointer and pass it directly
-	tx := t.db.Where("id = ?", id).First(&task)
-	if &task == nil {
-		return nil, fmt.Errorf("could not find task")
-	}
+	tx := t.db.Where("id = ?", id).Where("deleted_at IS NULL").First(&task)
 	if tx.Error != nil {
-		return nil, tx.Error
+		return nil, translateError(tx.Error)
 	}
 	return &task, nil
 }
@@ -60,7 +214,30 @@ func (t *TaskRepository) FindByID(id string) (*entity.Task, error) {
 // Update updates a task by the new values passed as parameters. The ID of the task to update would be part of the task given as argument.
 // When update with struct, GORM will only update non-zero fields. So better use map to make sure.
 // Will be used for both patch and PUT, checking for empty values will be done in the Service function.
-func (t *TaskRepository) Update(fields map[string]interface{}, id string) error {
-	tx := t.db.Model(entity.Task{}).Where("id = ?", id).Updates(fields)
-	return tx.Error
+// The version of the task is incremented. If version is not 0, the task is only updated if it still has this version, otherwise
+// errs.ErrPreconditionFailed is returned: checking it in the same statement as the update prevents overwriting a concurrent change.
+// Tasks in the trash cannot be updated until they are restored.
+func (t *TaskRepository) Update(fields map[string]interface{}, id string, version int) error {
+	values := make(map[string]interface{}, len(fields)+1)
+	for k, v := range fields {
+		values[k] = v
+	}
+	values["version"] = gorm.Expr("version + 1")
+
+	tx := t.db.Model(entity.Task{}).Where("id = ?", id).Where("deleted_at IS NULL")
+	if version != 0 {
+		tx = tx.Where("version = ?", version)
+	}
+	tx = tx.Updates(values)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		// nothing was updated, either the task does not exist or its version has moved on
+		if _, err := t.FindByID(id); err != nil {
+			return err
+		}
+		return errs.New(errs.ErrPreconditionFailed, "task with id '%s' is not at version %d anymore", id, version)
+	}
+	return nil
 }
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/jackc/pgconn"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log"
@@ -96,6 +99,7 @@ func TestTaskRepository_Create(t *testing.T) {
 				ID:        "1",
 				CreatedAt: time.Time{},
 				UpdatedAt: time.Time{},
+				Version:   1,
 				TaskDescription: entity.TaskDescription{
 					Title:       "test",
 					Description: "test",
@@ -114,7 +118,7 @@ func TestTaskRepository_Create(t *testing.T) {
 			testSuite.mock.ExpectBegin()
 			testSuite.mock.ExpectExec(regexp.QuoteMeta(
 				`INSERT INTO "tasks"`)).
-				WithArgs(tt.args.task.ID, AnyTime{}, AnyTime{}, tt.args.task.Title, tt.args.task.Description, tt.args.task.Priority, tt.args.task.Status).
+				WithArgs(tt.args.task.ID, entity.DefaultTenantID, AnyTime{}, AnyTime{}, tt.args.task.Version, nil, "", "", "", "", "", 0, false, 0, nil, 0, 0, 0, tt.args.task.Title, tt.args.task.Description, tt.args.task.Priority, tt.args.task.Status, nil, nil, "", "", "", entity.DefaultProjectID).
 				WillReturnResult(sqlmock.NewResult(0, 0))
 			testSuite.mock.ExpectCommit()
 
@@ -136,16 +140,25 @@ func TestTaskRepository_DeleteByID(t1 *testing.T) {
 		id string
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name         string
+		fields       fields
+		args         args
+		rowsAffected int64
+		wantErr      error
 	}{
 		{
-			name:    "should pass and delete Item",
-			fields:  fields{db: testSuite.gormDB},
-			args:    args{id: "3"},
-			wantErr: false,
+			name:         "should pass and delete Item",
+			fields:       fields{db: testSuite.gormDB},
+			args:         args{id: "3"},
+			rowsAffected: 1,
+			wantErr:      nil,
+		},
+		{
+			name:         "should fail with not found because there is no such item",
+			fields:       fields{db: testSuite.gormDB},
+			args:         args{id: "4"},
+			rowsAffected: 0,
+			wantErr:      errs.ErrNotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -156,33 +169,115 @@ func TestTaskRepository_DeleteByID(t1 *testing.T) {
 
 			testSuite.mock.ExpectBegin()
 			testSuite.mock.ExpectExec(regexp.QuoteMeta(
-				`DELETE FROM "tasks" WHERE id = $1`)).
-				WithArgs(tt.args.id).
-				WillReturnResult(sqlmock.NewResult(0, 0))
+				`UPDATE "tasks" SET "deleted_at"=$1,"deleted_by"=$2,"version"=version + 1,"updated_at"=$3 WHERE id = $4 AND deleted_at IS NULL`)).
+				WithArgs(AnyTime{}, "admin", AnyTime{}, tt.args.id).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
 			testSuite.mock.ExpectCommit()
 
-			if err := t.DeleteByID(tt.args.id); (err != nil) != tt.wantErr {
+			if err := t.DeleteByID(tt.args.id, "admin"); !errors.Is(err, tt.wantErr) {
 				t1.Errorf("DeleteByID() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestTaskRepository_Restore(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	tests := []struct {
+		name         string
+		id           string
+		rowsAffected int64
+		wantErr      error
+	}{
+		{
+			name:         "should pass and restore item",
+			id:           "3",
+			rowsAffected: 1,
+			wantErr:      nil,
+		},
+		{
+			name:         "should fail with not found because the item is not in the trash",
+			id:           "4",
+			rowsAffected: 0,
+			wantErr:      errs.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			testSuite.mock.ExpectBegin()
+			testSuite.mock.ExpectExec(regexp.QuoteMeta(
+				`UPDATE "tasks" SET "deleted_at"=$1,"deleted_by"=$2,"version"=version + 1,"updated_at"=$3 WHERE id = $4 AND deleted_at IS NOT NULL`)).
+				WithArgs(nil, "", AnyTime{}, tt.id).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+			testSuite.mock.ExpectCommit()
+
+			if err := testSuite.repository.Restore(tt.id); !errors.Is(err, tt.wantErr) {
+				t1.Errorf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+				t1.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestTaskRepository_Purge(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	before := time.Now().Add(-24 * time.Hour)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tasks" WHERE deleted_at < $1 FOR UPDATE`)).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2").AddRow("3"))
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "blob_key" FROM "attachments" WHERE task_id IN ($1,$2,$3)`)).
+		WithArgs("1", "2", "3").
+		WillReturnRows(sqlmock.NewRows([]string{"blob_key"}).AddRow("default/1/a").AddRow("default/3/b"))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tasks" WHERE id IN ($1,$2,$3)`)).
+		WithArgs("1", "2", "3").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	testSuite.mock.ExpectCommit()
+
+	got, blobKeys, err := testSuite.repository.Purge(before)
+	if err != nil {
+		t1.Fatalf("Purge() error = %v", err)
+	}
+	if want := []string{"default/1/a", "default/3/b"}; got != 3 || !reflect.DeepEqual(blobKeys, want) {
+		t1.Errorf("Purge() got = %d, %v, want 3, %v", got, blobKeys, want)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
 func TestTaskRepository_FindAll(t1 *testing.T) {
 	var testSuite Suite
 	testSuite.SetupSuite()
+	min := 3
 	type fields struct {
 		db *gorm.DB
 	}
+	type args struct {
+		query *entity.TaskQuery
+	}
 	tests := []struct {
 		name    string
 		fields  fields
+		args    args
 		want    []*entity.Task
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name:   "should return instances correctly",
 			fields: fields{db: testSuite.gormDB},
+			args: args{query: &entity.TaskQuery{
+				Limit:       10,
+				Offset:      20,
+				Statuses:    []entity.Status{entity.New},
+				MinPriority: &min,
+				Sort:        []entity.SortField{{Field: "priority", Desc: true}, {Field: "createdAt"}},
+			}},
 			want: []*entity.Task{{
 				ID: "1",
 				TaskDescription: entity.TaskDescription{
@@ -200,7 +295,14 @@ func TestTaskRepository_FindAll(t1 *testing.T) {
 					Status:      "New",
 				},
 			}},
-			wantErr: false,
+			wantErr: nil,
+		},
+		{
+			name:    "should fail because of unknown sort field",
+			fields:  fields{db: testSuite.gormDB},
+			args:    args{query: &entity.TaskQuery{Sort: []entity.SortField{{Field: "unknown"}}}},
+			want:    nil,
+			wantErr: errs.ErrValidation,
 		},
 	}
 	for _, tt := range tests {
@@ -212,10 +314,13 @@ func TestTaskRepository_FindAll(t1 *testing.T) {
 			rows := testSuite.mock.NewRows(columns).AddRow("1", "test", "test", 5, "New").
 				AddRow("2", "test", "test", 5, "New")
 
-			testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks"`)).WillReturnRows(rows)
+			testSuite.mock.ExpectQuery(regexp.QuoteMeta(
+				`SELECT * FROM "tasks" WHERE deleted_at IS NULL AND status IN ($1) AND priority >= $2 ORDER BY "priority" DESC,"created_at",id LIMIT 10 OFFSET 20`)).
+				WithArgs(entity.New, min).
+				WillReturnRows(rows)
 
-			got, err := t.FindAll()
-			if (err != nil) != tt.wantErr {
+			got, err := t.FindAll(tt.args.query)
+			if !errors.Is(err, tt.wantErr) {
 				t1.Errorf("FindAll() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -226,6 +331,125 @@ func TestTaskRepository_FindAll(t1 *testing.T) {
 	}
 }
 
+func TestTaskRepository_FindAll_Keyset(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	after := &entity.TaskCursor{CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), ID: "1"}
+
+	t := &TaskRepository{db: testSuite.gormDB}
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(
+		`SELECT * FROM "tasks" WHERE deleted_at IS NULL AND status IN ($1) AND (created_at, id) > ($2, $3) ORDER BY created_at,id LIMIT 2`)).
+		WithArgs(entity.Active, after.CreatedAt, after.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("2").AddRow("3"))
+
+	got, err := t.FindAll(&entity.TaskQuery{Limit: 2, Statuses: []entity.Status{entity.Active}, Keyset: true, After: after})
+	if err != nil {
+		t1.Fatalf("FindAll() error = %v", err)
+	}
+	want := []*entity.Task{{ID: "2"}, {ID: "3"}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindAll() got = %v, want %v", got, want)
+	}
+}
+
+func TestTaskRepository_Count_Overdue(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	before := time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC)
+	overdue, notOverdue := true, false
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND due_at < $1 AND (due_at < $2 AND status <> $3)`)).
+		WithArgs(before, AnyTime{}, entity.Closed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{DueBefore: &before, Overdue: &overdue}); err != nil || got != 3 {
+		t1.Errorf("Count() got = %v, %v, want 3", got, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND (due_at IS NULL OR due_at >= $1 OR status = $2)`)).
+		WithArgs(AnyTime{}, entity.Closed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Overdue: &notOverdue}); err != nil || got != 5 {
+		t1.Errorf("Count() got = %v, %v, want 5", got, err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_Count_Assignee(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	unassigned, assigned := true, false
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND assignee_id = $1`)).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Assignee: "alice"}); err != nil || got != 2 {
+		t1.Errorf("Count() got = %v, %v, want 2", got, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND assignee_id = ''`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Unassigned: &unassigned}); err != nil || got != 4 {
+		t1.Errorf("Count() got = %v, %v, want 4", got, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND assignee_id <> ''`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Unassigned: &assigned}); err != nil || got != 6 {
+		t1.Errorf("Count() got = %v, %v, want 6", got, err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_Count(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	after := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	type fields struct {
+		db *gorm.DB
+	}
+	type args struct {
+		query *entity.TaskQuery
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "should count the filtered instances ignoring pagination",
+			fields:  fields{db: testSuite.gormDB},
+			args:    args{query: &entity.TaskQuery{Limit: 10, Offset: 20, CreatedAfter: &after}},
+			want:    42,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			t := &TaskRepository{
+				db: tt.fields.db,
+			}
+			testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND created_at >= $1`)).
+				WithArgs(after).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.want))
+
+			got, err := t.Count(tt.args.query)
+			if (err != nil) != tt.wantErr {
+				t1.Errorf("Count() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t1.Errorf("Count() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestTaskRepository_FindByID(t1 *testing.T) {
 	var testSuite Suite
 	testSuite.SetupSuite()
@@ -262,7 +486,7 @@ func TestTaskRepository_FindByID(t1 *testing.T) {
 			}
 
 			testSuite.mock.ExpectQuery(regexp.QuoteMeta(
-				`SELECT * FROM "tasks" WHERE id = $1 ORDER BY "tasks"."id" LIMIT 1`)).
+				`SELECT * FROM "tasks" WHERE id = $1 AND deleted_at IS NULL ORDER BY "tasks"."id" LIMIT 1`)).
 				WithArgs(tt.args.id).
 				WillReturnRows(sqlmock.NewRows([]string{"id"}).
 					AddRow(tt.args.id))
@@ -287,14 +511,16 @@ func TestTaskRepository_Update(t1 *testing.T) {
 		db *gorm.DB
 	}
 	type args struct {
-		fields map[string]interface{}
-		id     string
+		fields  map[string]interface{}
+		id      string
+		version int
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		wantErr bool
+		expect  func(args)
+		wantErr error
 	}{
 		{
 			name:   "should pass and update entry",
@@ -303,7 +529,71 @@ func TestTaskRepository_Update(t1 *testing.T) {
 				fields: map[string]interface{}{"description": "updated-description", "priority": 5, "status": "New"},
 				id:     "1",
 			},
-			wantErr: false,
+			expect: func(a args) {
+				testSuite.mock.ExpectBegin()
+				testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "description"=$1,"priority"=$2,"status"=$3,"version"=version + 1,"updated_at"=$4 WHERE id = $5 AND deleted_at IS NULL`)).
+					WithArgs(a.fields["description"], a.fields["priority"], a.fields["status"], AnyTime{}, a.id).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				testSuite.mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name:   "should pass and update entry at the expected version",
+			fields: fields{db: testSuite.gormDB},
+			args: args{
+				fields:  map[string]interface{}{"title": "updated-title"},
+				id:      "1",
+				version: 3,
+			},
+			expect: func(a args) {
+				testSuite.mock.ExpectBegin()
+				testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "title"=$1,"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL AND version = $4`)).
+					WithArgs(a.fields["title"], AnyTime{}, a.id, a.version).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				testSuite.mock.ExpectCommit()
+			},
+			wantErr: nil,
+		},
+		{
+			name:   "should fail with precondition failed because entry has moved on",
+			fields: fields{db: testSuite.gormDB},
+			args: args{
+				fields:  map[string]interface{}{"title": "updated-title"},
+				id:      "1",
+				version: 2,
+			},
+			expect: func(a args) {
+				testSuite.mock.ExpectBegin()
+				testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "title"=$1,"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL AND version = $4`)).
+					WithArgs(a.fields["title"], AnyTime{}, a.id, a.version).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				testSuite.mock.ExpectCommit()
+				testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE id = $1 AND deleted_at IS NULL`)).
+					WithArgs(a.id).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(a.id, 3))
+			},
+			wantErr: errs.ErrPreconditionFailed,
+		},
+		{
+			name:   "should fail with not found because there is no such entry",
+			fields: fields{db: testSuite.gormDB},
+			args: args{
+				fields:  map[string]interface{}{"title": "updated-title"},
+				id:      "2",
+				version: 2,
+			},
+			expect: func(a args) {
+				testSuite.mock.ExpectBegin()
+				testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "title"=$1,"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL AND version = $4`)).
+					WithArgs(a.fields["title"], AnyTime{}, a.id, a.version).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				testSuite.mock.ExpectCommit()
+				testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE id = $1 AND deleted_at IS NULL`)).
+					WithArgs(a.id).
+					WillReturnError(gorm.ErrRecordNotFound)
+			},
+			wantErr: errs.ErrNotFound,
 		},
 	}
 	for _, tt := range tests {
@@ -311,15 +601,53 @@ func TestTaskRepository_Update(t1 *testing.T) {
 			t := &TaskRepository{
 				db: tt.fields.db,
 			}
-			testSuite.mock.ExpectBegin()
-			testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "description"=$1,"priority"=$2,"status"=$3,"updated_at"=$4 WHERE id = $5`)).
-				WithArgs(tt.args.fields["description"], tt.args.fields["priority"], tt.args.fields["status"], AnyTime{}, tt.args.id).
-				WillReturnResult(sqlmock.NewResult(1, 1))
-			testSuite.mock.ExpectCommit()
+			tt.expect(tt.args)
 
-			if err := t.Update(tt.args.fields, tt.args.id); (err != nil) != tt.wantErr {
+			if err := t.Update(tt.args.fields, tt.args.id, tt.args.version); !errors.Is(err, tt.wantErr) {
 				t1.Errorf("Update() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+				t1.Errorf("unfulfilled expectations: %v", err)
+			}
 		})
 	}
 }
+
+func TestTranslateError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "should translate missing records to not found",
+			err:  gorm.ErrRecordNotFound,
+			want: errs.ErrNotFound,
+		},
+		{
+			name: "should translate unique violations to conflict",
+			err:  &pgconn.PgError{Code: "23505"},
+			want: errs.ErrConflict,
+		},
+		{
+			name: "should translate connection exceptions to unavailable",
+			err:  &pgconn.PgError{Code: "08006"},
+			want: errs.ErrUnavailable,
+		},
+		{
+			name: "should translate bad connections to unavailable",
+			err:  fmt.Errorf("query failed: %w", driver.ErrBadConn),
+			want: errs.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := translateError(tt.err); !errors.Is(got, tt.want) {
+				t.Errorf("translateError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if translateError(nil) != nil {
+		t.Errorf("translateError() of a nil error should be nil")
+	}
+}
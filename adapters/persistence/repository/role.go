@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+)
+
+// RoleRepository stores the roles granted to the subjects, on all the projects of their tenant or on a single one
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository is the constructor of a RoleRepository with the database dependency injected
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &RoleRepository{db: db}
+}
+
+// Assign grants the role of the assignment, replacing the role the subject had on the same projects
+func (r *RoleRepository) Assign(assignment *entity.RoleAssignment) error {
+	tx := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "subject"}, {Name: "project_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(assignment)
+	return translateError(tx.Error)
+}
+
+// FindAll returns all the assignments ordered by subject, the ones on all the projects first
+func (r *RoleRepository) FindAll() ([]*entity.RoleAssignment, error) {
+	var assignments []*entity.RoleAssignment
+	tx := r.db.Order("subject").Order("project_id").Find(&assignments)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return assignments, nil
+}
+
+// FindBySubject returns the assignments of the subject on all the projects and on the given one
+func (r *RoleRepository) FindBySubject(subject string, projectID string) ([]*entity.RoleAssignment, error) {
+	var assignments []*entity.RoleAssignment
+	tx := r.db.Where("subject = ? AND project_id IN ?", subject, []string{"", projectID}).Find(&assignments)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return assignments, nil
+}
+
+// Revoke removes the role of the subject on the projects, errs.ErrNotFound is returned if there is no such assignment
+func (r *RoleRepository) Revoke(subject string, projectID string) error {
+	tx := r.db.Where("subject = ? AND project_id = ?", subject, projectID).Delete(&entity.RoleAssignment{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "subject '%s' has no role on project '%s'", subject, projectID)
+	}
+	return nil
+}
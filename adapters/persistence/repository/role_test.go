@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRoleRepository_Assign(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	r := NewRoleRepository(testSuite.gormDB).WithTenant("acme")
+
+	// granting a role again replaces the previous one
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "role_assignments" ("tenant_id","subject","project_id","role","created_at","updated_at") VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT ("tenant_id","subject","project_id") DO UPDATE SET "role"="excluded"."role","updated_at"="excluded"."updated_at"`)).
+		WithArgs("acme", "alice", "p1", entity.Editor, AnyTime{}, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := r.Assign(&entity.RoleAssignment{Subject: "alice", ProjectID: "p1", Role: entity.Editor}); err != nil {
+		t1.Errorf("Assign() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRoleRepository_FindBySubject(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	r := NewRoleRepository(testSuite.gormDB).WithTenant("acme")
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "role_assignments" WHERE (subject = $1 AND project_id IN ($2,$3)) AND "role_assignments"."tenant_id" = $4`)).
+		WithArgs("alice", "", "p1", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"subject", "project_id", "role"}).AddRow("alice", "", "viewer").AddRow("alice", "p1", "admin"))
+	got, err := r.FindBySubject("alice", "p1")
+	if err != nil {
+		t1.Fatalf("FindBySubject() error = %v", err)
+	}
+	want := []*entity.RoleAssignment{{Subject: "alice", Role: entity.Viewer}, {Subject: "alice", ProjectID: "p1", Role: entity.Admin}}
+	if !reflect.DeepEqual(got, want) {
+		t1.Errorf("FindBySubject() got = %v, want %v", got, want)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRoleRepository_Revoke(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	r := NewRoleRepository(testSuite.gormDB).WithTenant("acme")
+
+	for _, affected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "role_assignments" WHERE (subject = $1 AND project_id = $2) AND "role_assignments"."tenant_id" = $3`)).
+			WithArgs("alice", "", "acme").
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		testSuite.mock.ExpectCommit()
+
+		err := r.Revoke("alice", "")
+		if affected == 1 && err != nil {
+			t1.Errorf("Revoke() error = %v", err)
+		}
+		if affected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("Revoke() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// SessionRepository stores the sessions of the browser clients and the logins they started. The sessions are looked up by their cookie
+// before the tenant of the caller is known, so the repository sees the sessions of all the tenants and the created ones keep their tenant.
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository is the constructor of a SessionRepository with the database dependency injected
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &SessionRepository{db: forAllTenants(db)}
+}
+
+// Create creates a new session
+func (s *SessionRepository) Create(session *entity.Session) error {
+	tx := s.db.Create(session)
+	return translateError(tx.Error)
+}
+
+// FindByID finds a session by the hash of its cookie, errs.ErrNotFound is returned if there is no such session, the expired ones included
+func (s *SessionRepository) FindByID(id string) (*entity.Session, error) {
+	var session entity.Session
+	tx := s.db.Where("id = ?", id).First(&session)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &session, nil
+}
+
+// DeleteByID deletes the session, errs.ErrNotFound is returned if there is no such session
+func (s *SessionRepository) DeleteByID(id string) error {
+	tx := s.db.Where("id = ?", id).Delete(&entity.Session{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find session")
+	}
+	return nil
+}
+
+// CreateLogin creates a new login attempt
+func (s *SessionRepository) CreateLogin(attempt *entity.LoginAttempt) error {
+	tx := s.db.Create(attempt)
+	return translateError(tx.Error)
+}
+
+// TakeLogin deletes the login attempt and returns it, errs.ErrNotFound is returned if there is no such attempt.
+// Deleting it in the same statement makes sure that it is used once even when the callback is replayed concurrently.
+func (s *SessionRepository) TakeLogin(id string) (*entity.LoginAttempt, error) {
+	var attempts []*entity.LoginAttempt
+	tx := s.db.Clauses(clause.Returning{}).Where("id = ?", id).Delete(&attempts)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	if len(attempts) == 0 {
+		return nil, errs.New(errs.ErrNotFound, "could not find login attempt")
+	}
+	return attempts[0], nil
+}
+
+// PurgeExpired removes the sessions and the login attempts that expired before the given time and returns how many were removed
+func (s *SessionRepository) PurgeExpired(now time.Time) (int64, error) {
+	var purged int64
+	for _, model := range []interface{}{&entity.Session{}, &entity.LoginAttempt{}} {
+		tx := s.db.Where("expires_at < ?", now).Delete(model)
+		if tx.Error != nil {
+			return purged, translateError(tx.Error)
+		}
+		purged += tx.RowsAffected
+	}
+	return purged, nil
+}
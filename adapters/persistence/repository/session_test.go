@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestSessionRepository_Create(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	s := NewSessionRepository(testSuite.gormDB)
+	expiresAt := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	// the session keeps the tenant it is given, the repository is not bound to one
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "sessions" ("id","tenant_id","subject","admin","role","id_token","created_at","expires_at") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+		WithArgs("hash", "acme", "alice", false, entity.Editor, "id-token", AnyTime{}, expiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := s.Create(&entity.Session{ID: "hash", TenantID: "acme", Subject: "alice", Role: entity.Editor, IDToken: "id-token", ExpiresAt: expiresAt}); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionRepository_TakeLogin(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	s := NewSessionRepository(testSuite.gormDB)
+
+	// the attempt is deleted by the statement returning it, so a second callback with the same state finds nothing
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE id = $1 RETURNING *`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "nonce", "code_verifier", "return_to"}).AddRow("hash", "nonce", "verifier", "/"))
+	testSuite.mock.ExpectCommit()
+	got, err := s.TakeLogin("hash")
+	if err != nil {
+		t1.Fatalf("TakeLogin() error = %v", err)
+	}
+	if got.Nonce != "nonce" || got.CodeVerifier != "verifier" || got.ReturnTo != "/" {
+		t1.Errorf("TakeLogin() got = %v, want the attempt", got)
+	}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM "login_attempts" WHERE id = $1 RETURNING *`)).
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	testSuite.mock.ExpectCommit()
+	if _, err = s.TakeLogin("hash"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("TakeLogin() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSessionRepository_PurgeExpired(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	s := NewSessionRepository(testSuite.gormDB)
+	now := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+
+	for i, table := range []string{"sessions", "login_attempts"} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "` + table + `" WHERE expires_at < $1`)).
+			WithArgs(now).
+			WillReturnResult(sqlmock.NewResult(0, int64(2-i)))
+		testSuite.mock.ExpectCommit()
+	}
+	purged, err := s.PurgeExpired(now)
+	if err != nil {
+		t1.Fatalf("PurgeExpired() error = %v", err)
+	}
+	if purged != 3 {
+		t1.Errorf("PurgeExpired() got = %d, want 3", purged)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// labelledTask matches the tasks having the labels named by the argument, the labels being joined to the attachments of the task
+const labelledTask = "FROM task_labels AS tl JOIN labels AS l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND l.name IN ?"
+
+// labelsChanged increments the version of the tasks matched by the condition, whose labels changed. The labels are part of the tasks,
+// so the clients holding a copy of them need to see them changed, as for the comments.
+func labelsChanged(tx *gorm.DB, condition string, args ...interface{}) error {
+	return tx.Model(&entity.Task{}).Where(condition, args...).Update("version", gorm.Expr("version + 1")).Error
+}
+
+// filterLabels restricts the query to the tasks having any or all of the labels of the query
+func filterLabels(tx *gorm.DB, query *entity.TaskQuery) *gorm.DB {
+	if len(query.Labels) == 0 {
+		return tx
+	}
+	if query.LabelMode == entity.AllLabels {
+		return tx.Where("(SELECT count(DISTINCT l.name) "+labelledTask+") = ?", query.Labels, len(query.Labels))
+	}
+	return tx.Where("EXISTS (SELECT 1 "+labelledTask+")", query.Labels)
+}
+
+// AttachLabel attaches the label to the task, attached is false if it was already.
+// errs.ErrNotFound is returned if there is no such label.
+func (t *TaskRepository) AttachLabel(taskID string, labelID string) (attached bool, err error) {
+	var count int64
+	if tx := t.db.Model(&entity.Label{}).Where("id = ?", labelID).Count(&count); tx.Error != nil {
+		return false, translateError(tx.Error)
+	}
+	if count == 0 {
+		return false, errs.New(errs.ErrNotFound, "could not find label with id '%s'", labelID)
+	}
+	tx := t.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.TaskLabel{TaskID: taskID, LabelID: labelID})
+	if tx.Error != nil {
+		return false, translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return false, nil
+	}
+	return true, translateError(labelsChanged(t.db, "id = ?", taskID))
+}
+
+// DetachLabel detaches the label from the task, errs.ErrNotFound is returned if it is not attached to it
+func (t *TaskRepository) DetachLabel(taskID string, labelID string) error {
+	tx := t.db.Where("task_id = ?", taskID).Where("label_id = ?", labelID).Delete(&entity.TaskLabel{})
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "label with id '%s' is not attached to task with id '%s'", labelID, taskID)
+	}
+	return translateError(labelsChanged(t.db, "id = ?", taskID))
+}
+
+// FindLabels returns the labels attached to each of the given tasks, ordered by name
+func (t *TaskRepository) FindLabels(taskIDs []string) (map[string][]*entity.Label, error) {
+	var rows []struct {
+		TaskID string
+		entity.Label
+	}
+	tx := t.db.Model(&entity.Label{}).Select("labels.*, task_labels.task_id").Joins("JOIN task_labels ON task_labels.label_id = labels.id").
+		Where("task_labels.task_id IN ?", taskIDs).Order("labels.name").Scan(&rows)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	labels := make(map[string][]*entity.Label)
+	for i := range rows {
+		labels[rows[i].TaskID] = append(labels[rows[i].TaskID], &rows[i].Label)
+	}
+	return labels, nil
+}
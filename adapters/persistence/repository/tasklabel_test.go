@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"testing"
+)
+
+func TestTaskRepository_AttachLabel(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "labels" WHERE id = $1`)).
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "task_labels" ("task_id","label_id","tenant_id","created_at") VALUES ($1,$2,$3,$4) ON CONFLICT DO NOTHING`)).
+		WithArgs("1", "2", entity.DefaultTenantID, AnyTime{}).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectCommit()
+
+	// the label was already attached
+	if attached, err := testSuite.repository.AttachLabel("1", "2"); err != nil || attached {
+		t1.Errorf("AttachLabel() = %t, %v, want false", attached, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "labels" WHERE id = $1`)).
+		WithArgs("2").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "task_labels"`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "version"=version + 1,"updated_at"=$1 WHERE id = $2`)).
+		WithArgs(AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	// the task changes with its labels
+	if attached, err := testSuite.repository.AttachLabel("1", "2"); err != nil || !attached {
+		t1.Errorf("AttachLabel() = %t, %v, want true", attached, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "labels" WHERE id = $1`)).
+		WithArgs("3").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := testSuite.repository.AttachLabel("1", "3"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("AttachLabel() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_DetachLabel(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "task_labels" WHERE task_id = $1 AND label_id = $2`)).
+		WithArgs("1", "2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.DetachLabel("1", "2"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("DetachLabel() error = %v, want %v", err, errs.ErrNotFound)
+	}
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "task_labels" WHERE task_id = $1 AND label_id = $2`)).
+		WithArgs("1", "2").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "version"=version + 1,"updated_at"=$1 WHERE id = $2`)).
+		WithArgs(AnyTime{}, "1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+
+	if err := testSuite.repository.DetachLabel("1", "2"); err != nil {
+		t1.Errorf("DetachLabel() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTaskRepository_FindLabels(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT labels.*, task_labels.task_id FROM "labels" JOIN task_labels ON task_labels.label_id = labels.id `+
+		`WHERE task_labels.task_id IN ($1,$2) ORDER BY labels.name`)).
+		WithArgs("1", "2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "task_id"}).AddRow("10", "bug", "1").AddRow("11", "urgent", "1").AddRow("10", "bug", "2"))
+
+	got, err := testSuite.repository.FindLabels([]string{"1", "2"})
+	if err != nil {
+		t1.Fatalf("FindLabels() error = %v", err)
+	}
+	if len(got["1"]) != 2 || got["1"][1].Name != "urgent" || len(got["2"]) != 1 || got["2"][0].ID != "10" {
+		t1.Errorf("FindLabels() got = %v, want 2 labels for task 1 and bug for task 2", got)
+	}
+}
+
+func TestTaskRepository_Count_Labels(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND `+
+		`(EXISTS (SELECT 1 FROM task_labels AS tl JOIN labels AS l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND l.name IN ($1,$2)))`)).
+		WithArgs("bug", "urgent").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Labels: []string{"bug", "urgent"}}); err != nil || got != 3 {
+		t1.Errorf("Count() got = %v, %v, want 3", got, err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND `+
+		`((SELECT count(DISTINCT l.name) FROM task_labels AS tl JOIN labels AS l ON l.id = tl.label_id WHERE tl.task_id = tasks.id AND l.name IN ($1,$2)) = $3)`)).
+		WithArgs("bug", "urgent", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if got, err := testSuite.repository.Count(&entity.TaskQuery{Labels: []string{"bug", "urgent"}, LabelMode: entity.AllLabels}); err != nil || got != 1 {
+		t1.Errorf("Count() got = %v, %v, want 1", got, err)
+	}
+}
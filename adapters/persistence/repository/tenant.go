@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+)
+
+// tenantColumn is the column of the tables holding data owned by a tenant, the entities map it to their TenantID field
+const tenantColumn = "tenant_id"
+
+// settings of the gorm statements read by the tenant callbacks, they are carried by the sessions returned by forTenant and forAllTenants
+const (
+	tenantSetting     = "tenant:id"
+	allTenantsSetting = "tenant:all"
+)
+
+// errNoTenant is returned for the statements on tenant data run by a repository that is not bound to a tenant,
+// failing is safer than guessing the tenant or returning the data of all of them
+var errNoTenant = errors.New("statement on tenant data without tenant scope")
+
+// RegisterTenantScope registers the gorm callbacks isolating the tenants. Every query, update and delete on a table having a tenant_id
+// column is restricted to the tenant of the repository, and every created row is stamped with it. Since this is done below the
+// repositories, a method forgetting the condition cannot read or change the data of another tenant.
+// Raw SQL statements are not scoped, the repositories build all their statements from the entities.
+func RegisterTenantScope(db *gorm.DB) error {
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("tenant:stamp", stampTenant),
+		callbacks.Query().Before("gorm:query").Register("tenant:scope", scopeTenant),
+		callbacks.Row().Before("gorm:row").Register("tenant:scope", scopeTenant),
+		callbacks.Update().Before("gorm:update").Register("tenant:scope", scopeTenant),
+		callbacks.Delete().Before("gorm:delete").Register("tenant:scope", scopeTenant),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forTenant returns a session whose statements only see the data of the tenant
+func forTenant(db *gorm.DB, tenant string) *gorm.DB {
+	return db.Set(tenantSetting, tenant).Set(allTenantsSetting, false).Session(&gorm.Session{})
+}
+
+// forAllTenants returns a session whose statements see the data of all the tenants, it is reserved to the background jobs and the authentication
+func forAllTenants(db *gorm.DB) *gorm.DB {
+	return db.Set(allTenantsSetting, true).Session(&gorm.Session{})
+}
+
+// WithTenant returns a repository whose statements only see the tasks of the tenant
+func (t *TaskRepository) WithTenant(tenant string) interfaces.ITaskRepository {
+	return &TaskRepository{db: forTenant(t.db, tenant)}
+}
+
+// AllTenants returns a repository whose statements see the tasks of all the tenants, the rows it creates keep the tenant they are given
+func (t *TaskRepository) AllTenants() interfaces.ITaskRepository {
+	return &TaskRepository{db: forAllTenants(t.db)}
+}
+
+// WithTenant returns a repository whose statements only see the labels of the tenant
+func (l *LabelRepository) WithTenant(tenant string) interfaces.ILabelRepository {
+	return &LabelRepository{db: forTenant(l.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the comments of the tenant
+func (c *CommentRepository) WithTenant(tenant string) interfaces.ICommentRepository {
+	return &CommentRepository{db: forTenant(c.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the attachments of the tenant
+func (a *AttachmentRepository) WithTenant(tenant string) interfaces.IAttachmentRepository {
+	return &AttachmentRepository{db: forTenant(a.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the checklist items of the tenant
+func (c *ChecklistRepository) WithTenant(tenant string) interfaces.IChecklistRepository {
+	return &ChecklistRepository{db: forTenant(c.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the projects of the tenant
+func (p *ProjectRepository) WithTenant(tenant string) interfaces.IProjectRepository {
+	return &ProjectRepository{db: forTenant(p.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the users of the tenant
+func (u *UserRepository) WithTenant(tenant string) interfaces.IUserRepository {
+	return &UserRepository{db: forTenant(u.db, tenant)}
+}
+
+// AllTenants returns a repository whose statements see the users of all the tenants, to authenticate the callers before their tenant is known
+func (u *UserRepository) AllTenants() interfaces.IUserRepository {
+	return &UserRepository{db: forAllTenants(u.db)}
+}
+
+// WithTenant returns a repository whose statements only see the roles granted in the tenant
+func (r *RoleRepository) WithTenant(tenant string) interfaces.IRoleRepository {
+	return &RoleRepository{db: forTenant(r.db, tenant)}
+}
+
+// WithTenant returns a repository whose statements only see the API keys of the tenant
+func (a *APIKeyRepository) WithTenant(tenant string) interfaces.IAPIKeyRepository {
+	return &APIKeyRepository{db: forTenant(a.db, tenant)}
+}
+
+// AllTenants returns a repository whose statements see the API keys of all the tenants, to authenticate the keys before their tenant is known
+func (a *APIKeyRepository) AllTenants() interfaces.IAPIKeyRepository {
+	return &APIKeyRepository{db: forAllTenants(a.db)}
+}
+
+// tenantScope returns the tenant the statement is bound to, all is true when it is not restricted to one
+func tenantScope(db *gorm.DB) (tenant string, all bool) {
+	if v, ok := db.Get(allTenantsSetting); ok {
+		all, _ = v.(bool)
+	}
+	if v, ok := db.Get(tenantSetting); ok {
+		tenant, _ = v.(string)
+	}
+	return tenant, all
+}
+
+// tenantOwned reports whether the statement is on a table holding tenant data
+func tenantOwned(db *gorm.DB) bool {
+	return db.Statement.Schema != nil && db.Statement.Schema.LookUpField(tenantColumn) != nil
+}
+
+// scopeTenant adds the condition on the tenant to the statement, the column is qualified since the statement may join other tenant tables
+func scopeTenant(db *gorm.DB) {
+	if db.Error != nil || !tenantOwned(db) {
+		return
+	}
+	tenant, all := tenantScope(db)
+	if all {
+		return
+	}
+	if tenant == "" {
+		_ = db.AddError(errNoTenant)
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: tenantColumn}, Value: tenant},
+	}})
+}
+
+// stampTenant sets the tenant of the created rows, overwriting any tenant they were given so that a row cannot be created for another tenant
+func stampTenant(db *gorm.DB) {
+	if db.Error != nil || !tenantOwned(db) {
+		return
+	}
+	tenant, all := tenantScope(db)
+	if all {
+		return
+	}
+	if tenant == "" {
+		_ = db.AddError(errNoTenant)
+		return
+	}
+	field := db.Statement.Schema.LookUpField(tenantColumn)
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := field.Set(db.Statement.Context, reflect.Indirect(rv.Index(i)), tenant); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := field.Set(db.Statement.Context, rv, tenant); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
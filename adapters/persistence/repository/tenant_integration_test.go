@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+)
+
+// openIntegrationDB connects to the postgres database given by the same environment variables as the server,
+// the test is skipped when POSTGRES_HOST is not set, e.g. after `make run_postgres`
+func openIntegrationDB(t1 *testing.T) *gorm.DB {
+	host := os.Getenv("POSTGRES_HOST")
+	if host == "" {
+		t1.Skip("POSTGRES_HOST is not set, skipping the integration test")
+	}
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable", host,
+		os.Getenv("POSTGRES_USER"), os.Getenv("POSTGRES_PASSWORD"), os.Getenv("POSTGRES_DB"), os.Getenv("POSTGRES_PORT"))
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t1.Fatalf("failed to connect to the database: %v", err)
+	}
+	if err = db.AutoMigrate(&entity.Project{}, &entity.Task{}, &entity.TaskEvent{}, &entity.TaskDependency{}, &entity.Label{}, &entity.TaskLabel{}, &entity.User{}, &entity.RoleAssignment{}, &entity.APIKey{}, &entity.Session{}, &entity.LoginAttempt{}, &entity.Comment{}, &entity.CommentRevision{}, &entity.Attachment{}, &entity.ChecklistItem{}); err != nil {
+		t1.Fatalf("failed to migrate the database: %v", err)
+	}
+	if err = RegisterTenantScope(db); err != nil {
+		t1.Fatalf("RegisterTenantScope() error = %v", err)
+	}
+	return db
+}
+
+func TestTenantIsolation_Integration(t1 *testing.T) {
+	db := openIntegrationDB(t1)
+	// random tenants so that the test does not see the data of previous runs
+	tenantA, tenantB := "a-"+uuid.NewString(), "b-"+uuid.NewString()
+	repoA := NewTaskRepository(db).WithTenant(tenantA)
+	repoB := NewTaskRepository(db).WithTenant(tenantB)
+
+	newTask := func(repo interfaces.ITaskRepository, title string) *entity.Task {
+		task := &entity.Task{ID: uuid.NewString(), Version: 1, TaskDescription: entity.TaskDescription{
+			Title: title, Priority: 1, Status: entity.New, ProjectID: entity.DefaultProjectID}}
+		if err := repo.Create(task); err != nil {
+			t1.Fatalf("Create() error = %v", err)
+		}
+		return task
+	}
+	taskA := newTask(repoA, "task of A")
+	taskB := newTask(repoB, "task of B")
+	t1.Cleanup(func() {
+		forAllTenants(db).Where("id IN ?", []string{taskA.ID, taskB.ID}).Delete(&entity.Task{})
+		forAllTenants(db).Where("tenant_id IN ?", []string{tenantA, tenantB}).Delete(&entity.Label{})
+		forAllTenants(db).Where("tenant_id IN ?", []string{tenantA, tenantB}).Delete(&entity.Project{})
+	})
+
+	t1.Run("reads", func(t1 *testing.T) {
+		if _, err := repoA.FindByID(taskB.ID); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("FindByID() error = %v, want not found", err)
+		}
+		tasks, err := repoA.FindAll(&entity.TaskQuery{Limit: 100})
+		if err != nil {
+			t1.Fatalf("FindAll() error = %v", err)
+		}
+		if len(tasks) != 1 || tasks[0].ID != taskA.ID {
+			t1.Errorf("FindAll() got %v, want only %s", tasks, taskA.ID)
+		}
+		if total, err := repoA.Count(&entity.TaskQuery{}); err != nil || total != 1 {
+			t1.Errorf("Count() = %d, %v, want 1", total, err)
+		}
+		if children, err := repoA.FindChildren([]string{taskB.ID}); err != nil || len(children) != 0 {
+			t1.Errorf("FindChildren() = %v, %v, want none", children, err)
+		}
+	})
+
+	t1.Run("updates", func(t1 *testing.T) {
+		if err := repoA.Update(map[string]interface{}{"title": "stolen"}, taskB.ID, 0); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("Update() error = %v, want not found", err)
+		}
+		if err := repoA.Restore(taskB.ID); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("Restore() error = %v, want not found", err)
+		}
+		task, err := repoB.FindByID(taskB.ID)
+		if err != nil {
+			t1.Fatalf("FindByID() error = %v", err)
+		}
+		if task.Title != "task of B" || task.Version != 1 {
+			t1.Errorf("FindByID() got %v, want the task of B unchanged", task)
+		}
+	})
+
+	t1.Run("deletes", func(t1 *testing.T) {
+		if err := repoA.DeleteByID(taskB.ID, "intruder"); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("DeleteByID() error = %v, want not found", err)
+		}
+		if _, err := repoB.FindByID(taskB.ID); err != nil {
+			t1.Errorf("FindByID() error = %v, want the task of B still live", err)
+		}
+	})
+
+	t1.Run("catalogues", func(t1 *testing.T) {
+		labelB := &entity.Label{ID: uuid.NewString(), LabelDescription: entity.LabelDescription{Name: "bug"}}
+		if err := NewLabelRepository(db).WithTenant(tenantB).Create(labelB); err != nil {
+			t1.Fatalf("Create() label error = %v", err)
+		}
+		// the same name can be used by every tenant
+		labelA := &entity.Label{ID: uuid.NewString(), LabelDescription: entity.LabelDescription{Name: "bug"}}
+		if err := NewLabelRepository(db).WithTenant(tenantA).Create(labelA); err != nil {
+			t1.Errorf("Create() label error = %v, want the name free in tenant A", err)
+		}
+		if _, err := repoA.AttachLabel(taskA.ID, labelB.ID); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("AttachLabel() error = %v, want the label of B not found", err)
+		}
+		if err := NewLabelRepository(db).WithTenant(tenantA).DeleteByID(labelB.ID); !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("DeleteByID() label error = %v, want not found", err)
+		}
+
+		// every tenant has its own default project
+		projectA, err := NewProjectRepository(db).WithTenant(tenantA).FindByID(entity.DefaultProjectID)
+		if err != nil {
+			t1.Fatalf("FindByID() project error = %v", err)
+		}
+		if err = NewProjectRepository(db).WithTenant(tenantB).Update(map[string]interface{}{"default_priority": 9}, entity.DefaultProjectID); err != nil {
+			t1.Fatalf("Update() project error = %v", err)
+		}
+		if projectA, err = NewProjectRepository(db).WithTenant(tenantA).FindByID(projectA.ID); err != nil || projectA.DefaultPriority != 0 {
+			t1.Errorf("FindByID() project = %v, %v, want the default project of A unchanged", projectA, err)
+		}
+	})
+}
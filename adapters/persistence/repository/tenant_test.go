@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// setupTenantSuite returns a suite whose database isolates the tenants, as it is set up by the server
+func setupTenantSuite(t1 *testing.T) *Suite {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	if err := RegisterTenantScope(testSuite.gormDB); err != nil {
+		t1.Fatalf("RegisterTenantScope() error = %v", err)
+	}
+	return &testSuite
+}
+
+func TestTenantScope_NoTenant(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+
+	// no statement reaches the database, whether the repository is not bound to a tenant or bound to an empty one,
+	// only the transaction wrapping a creation is started before being rolled back
+	if _, err := testSuite.repository.FindByID("1"); !errors.Is(err, errNoTenant) {
+		t1.Errorf("FindByID() error = %v, want %v", err, errNoTenant)
+	}
+	if _, err := testSuite.repository.WithTenant("").FindAll(&entity.TaskQuery{Limit: 10}); !errors.Is(err, errNoTenant) {
+		t1.Errorf("FindAll() error = %v, want %v", err, errNoTenant)
+	}
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectRollback()
+	if err := testSuite.repository.Create(&entity.Task{ID: "1"}); !errors.Is(err, errNoTenant) {
+		t1.Errorf("Create() error = %v, want %v", err, errNoTenant)
+	}
+	if _, err := NewLabelRepository(testSuite.gormDB).FindAll(); !errors.Is(err, errNoTenant) {
+		t1.Errorf("FindAll() labels error = %v, want %v", err, errNoTenant)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTenantScope_Read(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	repo := testSuite.repository.WithTenant("acme")
+
+	// the task of another tenant is not found
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE id = $1 AND deleted_at IS NULL AND "tasks"."tenant_id" = $2 ORDER BY "tasks"."id" LIMIT 1`)).
+		WithArgs("1", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err := repo.FindByID("1"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("FindByID() error = %v, want not found", err)
+	}
+
+	// the tables joined to the tasks are scoped too, the column is qualified by the table of the statement
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT labels.*, task_labels.task_id FROM "labels" JOIN task_labels ON task_labels.label_id = labels.id WHERE task_labels.task_id IN ($1) AND "labels"."tenant_id" = $2`)).
+		WithArgs("1", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "task_id"}))
+	if _, err := repo.FindLabels([]string{"1"}); err != nil {
+		t1.Errorf("FindLabels() error = %v", err)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND "tasks"."tenant_id" = $1`)).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := repo.Count(&entity.TaskQuery{}); err != nil {
+		t1.Errorf("Count() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTenantScope_Write(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	repo := testSuite.repository.WithTenant("acme")
+
+	// a task created with the tenant of another caller still belongs to the tenant of the repository
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "tasks"`)).
+		WithArgs("1", "acme", AnyTime{}, AnyTime{}, 1, nil, "", "", "", "", "", 0, false, 0, nil, 0, 0, 0, "", "", 0, "", nil, nil, "", "", "", entity.DefaultProjectID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	testSuite.mock.ExpectCommit()
+	if err := repo.Create(&entity.Task{ID: "1", TenantID: "other", Version: 1}); err != nil {
+		t1.Errorf("Create() error = %v", err)
+	}
+
+	// the task of another tenant is not updated, and then not found
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "title"=$1,"version"=version + 1,"updated_at"=$2 WHERE id = $3 AND deleted_at IS NULL AND "tasks"."tenant_id" = $4`)).
+		WithArgs("renamed", AnyTime{}, "2", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectCommit()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tasks" WHERE id = $1 AND deleted_at IS NULL AND "tasks"."tenant_id" = $2`)).
+		WithArgs("2", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := repo.Update(map[string]interface{}{"title": "renamed"}, "2", 0); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Update() error = %v, want not found", err)
+	}
+
+	// nor deleted, the transaction keeps the tenant of the repository
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "deleted_at"=$1,"deleted_by"=$2,"version"=version + 1,"updated_at"=$3 WHERE id = $4 AND deleted_at IS NULL AND "tasks"."tenant_id" = $5`)).
+		WithArgs(AnyTime{}, "admin", AnyTime{}, "2", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectRollback()
+	err := repo.Transaction(func(tx interfaces.ITaskRepository) error {
+		return tx.DeleteByID("2", "admin")
+	})
+	if !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("DeleteByID() error = %v, want not found", err)
+	}
+
+	// the labels of the catalogue are deleted in the tenant only, as are the versions of its tasks incremented
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "tasks" SET "version"=version + 1,"updated_at"=$1 WHERE id IN (SELECT task_id FROM task_labels WHERE label_id = $2) AND "tasks"."tenant_id" = $3`)).
+		WithArgs(AnyTime{}, "3", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "labels" WHERE id = $1 AND "labels"."tenant_id" = $2`)).
+		WithArgs("3", "acme").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	testSuite.mock.ExpectRollback()
+	if err = NewLabelRepository(testSuite.gormDB).WithTenant("acme").DeleteByID("3"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("DeleteByID() label error = %v, want not found", err)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestTenantScope_AllTenants(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	before := time.Now().Add(-24 * time.Hour)
+
+	testSuite.mock.ExpectBegin()
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT "id" FROM "tasks" WHERE deleted_at < $1 FOR UPDATE`)).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	testSuite.mock.ExpectCommit()
+	if _, _, err := testSuite.repository.AllTenants().Purge(before); err != nil {
+		t1.Errorf("Purge() error = %v", err)
+	}
+
+	// binding the repository to a tenant again restricts it to this tenant
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "tasks" WHERE deleted_at IS NULL AND "tasks"."tenant_id" = $1`)).
+		WithArgs("acme").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := testSuite.repository.AllTenants().WithTenant("acme").Count(&entity.TaskQuery{}); err != nil {
+		t1.Errorf("Count() error = %v", err)
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"gorm.io/gorm"
+	"log"
+)
+
+// UserRepository stores the user accounts along with the hashes of their passwords
+type UserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository is the constructor of a UserRepository with the database dependency injected
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	if db == nil {
+		log.Fatalf("nil db provided")
+	}
+	return &UserRepository{db: db}
+}
+
+// Create creates a new user, errs.ErrConflict is returned if its username is already used, in any tenant
+func (u *UserRepository) Create(user *entity.User) error {
+	tx := u.db.Create(user)
+	return translateError(tx.Error)
+}
+
+// FindAll returns all the users ordered by username
+func (u *UserRepository) FindAll() ([]*entity.User, error) {
+	var users []*entity.User
+	tx := u.db.Order("username").Find(&users)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return users, nil
+}
+
+// FindByID finds a user by its ID, errs.ErrNotFound is returned if there is no such user
+func (u *UserRepository) FindByID(id string) (*entity.User, error) {
+	var user entity.User
+	tx := u.db.Where("id = ?", id).First(&user)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &user, nil
+}
+
+// FindByUsername finds a user by the name it authenticates with, errs.ErrNotFound is returned if there is no such user
+func (u *UserRepository) FindByUsername(username string) (*entity.User, error) {
+	var user entity.User
+	tx := u.db.Where("username = ?", username).First(&user)
+	if tx.Error != nil {
+		return nil, translateError(tx.Error)
+	}
+	return &user, nil
+}
+
+// Update sets the given values of the user
+func (u *UserRepository) Update(fields map[string]interface{}, id string) error {
+	tx := u.db.Model(&entity.User{}).Where("id = ?", id).Updates(fields)
+	if tx.Error != nil {
+		return translateError(tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errs.New(errs.ErrNotFound, "could not find user with id '%s'", id)
+	}
+	return nil
+}
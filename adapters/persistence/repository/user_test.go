@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/jackc/pgconn"
+	"regexp"
+	"testing"
+)
+
+func TestUserRepository_Create(t1 *testing.T) {
+	var testSuite Suite
+	testSuite.SetupSuite()
+	u := NewUserRepository(testSuite.gormDB)
+	user := &entity.User{ID: "1", PasswordHash: "hash", UserDescription: entity.UserDescription{Username: "alice"}}
+
+	for _, duplicate := range []bool{false, true} {
+		testSuite.mock.ExpectBegin()
+		exec := testSuite.mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "users" ("id","tenant_id","created_at","updated_at","password_hash","disabled","username","admin") VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`)).
+			WithArgs("1", entity.DefaultTenantID, AnyTime{}, AnyTime{}, "hash", false, "alice", false)
+		if duplicate {
+			exec.WillReturnError(&pgconn.PgError{Code: "23505"})
+			testSuite.mock.ExpectRollback()
+		} else {
+			exec.WillReturnResult(sqlmock.NewResult(0, 1))
+			testSuite.mock.ExpectCommit()
+		}
+
+		err := u.Create(user)
+		if !duplicate && err != nil {
+			t1.Errorf("Create() error = %v", err)
+		}
+		if duplicate && !errors.Is(err, errs.ErrConflict) {
+			t1.Errorf("Create() error = %v, want %v", err, errs.ErrConflict)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUserRepository_FindByUsername(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	u := NewUserRepository(testSuite.gormDB)
+
+	// the authentication looks the users up in all the tenants
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "username", "password_hash"}).AddRow("1", "acme", "alice", "hash"))
+	got, err := u.AllTenants().FindByUsername("alice")
+	if err != nil {
+		t1.Fatalf("FindByUsername() error = %v", err)
+	}
+	if got.ID != "1" || got.TenantID != "acme" || got.PasswordHash != "hash" {
+		t1.Errorf("FindByUsername() got = %v, want the user of acme", got)
+	}
+
+	testSuite.mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE username = $1 ORDER BY "users"."id" LIMIT 1`)).
+		WithArgs("bob").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if _, err = u.AllTenants().FindByUsername("bob"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("FindByUsername() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if err = testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUserRepository_Update(t1 *testing.T) {
+	testSuite := setupTenantSuite(t1)
+	u := NewUserRepository(testSuite.gormDB).WithTenant("acme")
+
+	// the administrators only change the users of their tenant
+	for _, affected := range []int64{1, 0} {
+		testSuite.mock.ExpectBegin()
+		testSuite.mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "disabled"=$1,"updated_at"=$2 WHERE id = $3 AND "users"."tenant_id" = $4`)).
+			WithArgs(true, AnyTime{}, "1", "acme").
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		testSuite.mock.ExpectCommit()
+
+		err := u.Update(map[string]interface{}{"disabled": true}, "1")
+		if affected == 1 && err != nil {
+			t1.Errorf("Update() error = %v", err)
+		}
+		if affected == 0 && !errors.Is(err, errs.ErrNotFound) {
+			t1.Errorf("Update() error = %v, want %v", err, errs.ErrNotFound)
+		}
+	}
+	if err := testSuite.mock.ExpectationsWereMet(); err != nil {
+		t1.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// ListAPIKeys represents the handler listing the API keys of the tenant, it is reserved to the administrators
+type ListAPIKeys struct {
+	APIKeyService interfaces.IAPIKeyService
+}
+
+// APIKeysResponse represents the API keys of the tenant, ordered by name
+type APIKeysResponse struct {
+	Keys []*entity.APIKey `json:"keys"`
+}
+
+// @Summary list the API keys
+// @Description  list the API keys of the tenant of the administrator ordered by name, the keys themselves are never returned
+// @Produce json
+// @Success 200 {object} handlers.APIKeysResponse
+// @Failure 405,403,500,503
+// @Router /apikeys [get]
+//
+// ServeHTTP implements the handler interface to handle listing the API keys
+func (l ListAPIKeys) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	keys, err := l.APIKeyService.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list API keys")
+		return
+	}
+	res := APIKeysResponse{Keys: keys}
+	if res.Keys == nil {
+		res.Keys = []*entity.APIKey{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// IssueAPIKey represents the handler issuing an API key in the tenant of the administrator
+type IssueAPIKey struct {
+	APIKeyService interfaces.IAPIKeyService
+}
+
+// @Summary issue an API key
+// @Description  issue an API key with the scopes tasks:read and/or tasks:write and an optional expiry, the key is only returned in this response
+// @Produce json
+// @Accept	json
+// @Param   key  body  entity.APIKeyDescription  true  "New API key"
+// @Success 201 {object} entity.IssuedAPIKey
+// @Failure 405,400,403,409,500,503
+// @Router /apikeys [post]
+//
+// ServeHTTP implements the handler interface to handle issuing an API key
+func (i IssueAPIKey) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var req entity.APIKeyDescription
+	if !decodeBody(w, r, &req, "API key") {
+		return
+	}
+	key, err := i.APIKeyService.Issue(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err, "failed to issue API key")
+		return
+	}
+	// the key must not be kept by a cache between the administrator and the service
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusCreated, key)
+}
+
+// RevokeAPIKey represents the handler revoking an API key of the tenant
+type RevokeAPIKey struct {
+	APIKeyService interfaces.IAPIKeyService
+}
+
+// @Summary revoke an API key
+// @Description  delete an API key of the tenant of the administrator, the requests made with it are refused from then on
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /apikeys/{id} [delete]
+//
+// ServeHTTP implements the handler interface to handle revoking an API key by ID
+func (d RevokeAPIKey) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("API key ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "API key ID not provided in path")
+		return
+	}
+	if err := d.APIKeyService.Revoke(r.Context(), id); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to revoke API key with id %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAPIKey = &entity.APIKey{ID: "1", Prefix: "tsk_abcdef", Hash: "hash", CreatedBy: "admin",
+	APIKeyDescription: entity.APIKeyDescription{Name: "ci", Scopes: entity.ScopeList{entity.ScopeTasksWrite}}}
+
+// mockAPIKeyService only lets the requests carrying an administrator principal manage the API keys
+type mockAPIKeyService struct{}
+
+func (m mockAPIKeyService) Authenticate(ctx context.Context, key string) (principal.Principal, error) {
+	return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "invalid API key")
+}
+
+func (m mockAPIKeyService) Issue(ctx context.Context, req *entity.APIKeyDescription) (*entity.IssuedAPIKey, error) {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	req, err := validation.ValidateAPIKey(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &entity.IssuedAPIKey{APIKey: &entity.APIKey{ID: "2", Hash: "hash", APIKeyDescription: *req}, Key: "tsk_secret"}, nil
+}
+
+func (m mockAPIKeyService) List(ctx context.Context) ([]*entity.APIKey, error) {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return []*entity.APIKey{testAPIKey}, nil
+}
+
+func (m mockAPIKeyService) Revoke(ctx context.Context, id string) error {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return err
+	}
+	if id != testAPIKey.ID {
+		return errs.New(errs.ErrNotFound, "API key with id '%s' not found", id)
+	}
+	return nil
+}
+
+func TestListAPIKeys_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	ListAPIKeys{APIKeyService: mockAPIKeyService{}}.ServeHTTP(response, asAdmin(httptest.NewRequest("GET", "http://localhost:8080/v1/api/apikeys", nil)))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	// neither the key nor its hash are returned
+	if strings.Contains(response.Body.String(), "hash") {
+		t.Errorf("the response should not contain the hash of the key: %s", response.Body.String())
+	}
+	var got APIKeysResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Keys) != 1 || got.Keys[0].Name != "ci" || got.Keys[0].Prefix != "tsk_abcdef" {
+		t.Errorf("invalid response, expected the key ci, got: %v", got.Keys)
+	}
+
+	response = httptest.NewRecorder()
+	ListAPIKeys{APIKeyService: mockAPIKeyService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/apikeys", nil))
+	if response.Code != http.StatusForbidden {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusForbidden, response.Code)
+	}
+}
+
+func TestIssueAPIKey_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		admin  bool
+		status int
+	}{
+		{name: "should issue the key", body: `{"name": "ci", "scopes": ["tasks:write"]}`, admin: true, status: http.StatusCreated},
+		{name: "should fail with StatusForbidden because the caller is not an administrator", body: `{"name": "ci", "scopes": ["tasks:write"]}`, status: http.StatusForbidden},
+		{name: "should fail because the scope is unknown", body: `{"name": "ci", "scopes": ["users:write"]}`, admin: true, status: http.StatusBadRequest},
+		{name: "should fail because the key already expired", body: `{"name": "ci", "scopes": ["tasks:read"], "expiresAt": "2000-01-01T00:00:00Z"}`, admin: true, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "http://localhost:8080/v1/api/apikeys", strings.NewReader(tt.body))
+			if tt.admin {
+				req = asAdmin(req)
+			}
+
+			IssueAPIKey{APIKeyService: mockAPIKeyService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got map[string]interface{}
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got["key"] != "tsk_secret" || got["name"] != "ci" || got["hash"] != nil {
+				t.Errorf("invalid response, expected the key without its hash, got: %v", got)
+			}
+			if response.Header().Get("Cache-Control") != "no-store" {
+				t.Errorf("invalid Cache-Control, expected: no-store, got: %s", response.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestRevokeAPIKey_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should revoke the key", id: "1", status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because the key does not exist", id: "2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/apikeys/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			RevokeAPIKey{APIKeyService: mockAPIKeyService{}}.ServeHTTP(response, asAdmin(req))
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// Assign represents the handler assigning a task to someone, or unassigning it
+type Assign struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary assign a task
+// @Description  make someone, named by the subject of their principal (e.g. their username), responsible for the task in place of its current assignee.
+// @Description  DELETE leaves the task assigned to nobody. The change is recorded in the history of the task.
+// @Param id path string true "task ID"
+// @Param   assignment  body  entity.TaskAssignment  false  "New assignee, only for PUT"
+// @Param If-Match header string false "ETag of the task as last seen by the client, the assignment fails with 412 if it was modified since"
+// @Produce json
+// @Accept	json
+// @Success 200 {object} entity.Task
+// @Header 200 {string} ETag "new version of the task"
+// @Failure 405,400,403,404,412,500,503
+// @Router /tasks/{id}/assignee [put]
+// @Router /tasks/{id}/assignee [delete]
+//
+// ServeHTTP implements the handler interface to handle assigning and unassigning a task
+func (a Assign) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var req entity.TaskAssignment
+	if r.Method == http.MethodPut && !decodeBody(w, r, &req, "assignment") {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	if r.Method == http.MethodPut && req.AssigneeID == "" {
+		writeStatus(w, r, http.StatusBadRequest, "assigneeId not provided, use DELETE to unassign the task")
+		return
+	}
+	version, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		writeError(w, r, err, "failed to check If-Match header")
+		return
+	}
+	task, err := a.TaskService.Assign(r.Context(), id, req.AssigneeID, version)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to assign task with id %s", id))
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, http.StatusOK, task)
+}
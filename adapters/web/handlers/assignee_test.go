@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var assignTaskDB = []*entity.Task{{ID: "1", Version: 2, TaskDescription: entity.TaskDescription{Title: "test1"}}}
+
+func TestAssign_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		id       string
+		body     string
+		ifMatch  string
+		status   int
+		assignee string
+	}{
+		{name: "should assign the task", method: "PUT", id: "1", body: `{"assigneeId": "bob"}`, status: http.StatusOK, assignee: "bob"},
+		{name: "should reassign the task at its version", method: "PUT", id: "1", body: `{"assigneeId": "carol"}`, ifMatch: `"2"`, status: http.StatusOK, assignee: "carol"},
+		{name: "should unassign the task", method: "DELETE", id: "1", status: http.StatusOK},
+		{name: "should fail because the task moved on", method: "PUT", id: "1", body: `{"assigneeId": "bob"}`, ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{name: "should fail because the assignee is missing", method: "PUT", id: "1", body: `{}`, status: http.StatusBadRequest},
+		{name: "should fail because the body is not JSON", method: "PUT", id: "1", body: "no-json", status: http.StatusBadRequest},
+		{name: "should fail because the task does not exist", method: "PUT", id: "missing", body: `{"assigneeId": "bob"}`, status: http.StatusNotFound},
+		{name: "should fail because the method is not allowed", method: "POST", id: "1", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/assignee", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			response := httptest.NewRecorder()
+			Assign{TaskService: newMockTaskService(assignTaskDB)}.ServeHTTP(response, req)
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got entity.Task
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.AssigneeID != tt.assignee {
+				t.Errorf("invalid assignee, expected: %s, got: %s", tt.assignee, got.AssigneeID)
+			}
+			if response.Header().Get("ETag") == "" {
+				t.Errorf("the ETag of the task is missing")
+			}
+		})
+	}
+}
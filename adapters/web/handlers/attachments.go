@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// multipartOverhead is the room left in an upload for the headers and boundaries of the multipart body around the file
+const multipartOverhead = 1 << 20
+
+// ListAttachments represents the handler listing the files attached to a task
+type ListAttachments struct {
+	AttachmentService interfaces.IAttachmentService
+}
+
+// AttachmentsResponse represents the files attached to a task, from the oldest one
+type AttachmentsResponse struct {
+	Attachments []*entity.Attachment `json:"attachments"`
+}
+
+// @Summary list the files attached to a task
+// @Description  list the metadata of the files attached to a task from the oldest one, the content is downloaded separately
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} handlers.AttachmentsResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/attachments [get]
+//
+// ServeHTTP implements the handler interface to handle listing the attachments of a task
+func (l ListAttachments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	attachments, err := l.AttachmentService.List(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to list attachments of task with id %s", id))
+		return
+	}
+	res := AttachmentsResponse{Attachments: attachments}
+	if res.Attachments == nil {
+		res.Attachments = []*entity.Attachment{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// UploadAttachment represents the handler attaching a file to a task
+type UploadAttachment struct {
+	AttachmentService interfaces.IAttachmentService
+	MaxSize           int64 // maximum size of a file in bytes, the body is cut a bit above it
+}
+
+// @Summary attach a file to a task
+// @Description  upload a file as the part "file" of a multipart form, its name is the file name of the part.
+// @Description  The content type is sniffed from the content, and the size is limited by the configuration of the service.
+// @Produce json
+// @Accept	mpfd
+// @Param id path string true "task ID"
+// @Param file formData file true "file to attach"
+// @Success 201 {object} entity.Attachment
+// @Failure 405,400,403,404,413,500,503
+// @Router /tasks/{id}/attachments [post]
+//
+// ServeHTTP implements the handler interface to handle uploading an attachment
+func (u UploadAttachment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, u.MaxSize+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read multipart body")
+		writeStatus(w, r, http.StatusBadRequest, "request body is not a multipart form")
+		return
+	}
+	// the file is streamed to the service rather than parsed into memory or a temporary file first
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				err = errors.New("no part named file")
+			}
+			writeBodyError(w, r, err)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+		body := &partReader{reader: part}
+		attachment, err := u.AttachmentService.Upload(r.Context(), id, part.FileName(), body)
+		if body.err != nil && body.err != io.EOF {
+			writeBodyError(w, r, body.err)
+			return
+		}
+		if err != nil {
+			writeError(w, r, err, fmt.Sprintf("failed to attach file to task with id %s", id))
+			return
+		}
+		writeJSON(w, http.StatusCreated, attachment)
+		return
+	}
+}
+
+// partReader records the error of the part being read, so that a broken or oversized body is told apart from the failures of the service
+type partReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (p *partReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	if err != nil {
+		p.err = err
+	}
+	return n, err
+}
+
+// writeBodyError writes 413 when the body exceeds its limit, and 400 for the other failures to read a multipart body
+func writeBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Warn().Err(err).Msg("failed to read multipart body")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeStatus(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit))
+		return
+	}
+	writeStatus(w, r, http.StatusBadRequest, "request body is not a multipart form with a part named file")
+}
+
+// DownloadAttachment represents the handler downloading a file attached to a task
+type DownloadAttachment struct {
+	AttachmentService interfaces.IAttachmentService
+}
+
+// @Summary download a file attached to a task
+// @Description  download the content of a file with its content type, the browsers are told to save it rather than display it.
+// @Description  The checksum of the content is its entity tag.
+// @Produce octet-stream
+// @Param id path string true "task ID"
+// @Param attachmentId path string true "attachment ID"
+// @Success 200 {file} binary
+// @Success 304 "the file held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/attachments/{attachmentId} [get]
+//
+// ServeHTTP implements the handler interface to handle downloading an attachment
+func (d DownloadAttachment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, attachmentID := mux.Vars(r)["id"], mux.Vars(r)["attachmentId"]
+	if id == "" || attachmentID == "" {
+		log.Warn().Msg("task or attachment ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and attachment IDs not provided in path")
+		return
+	}
+	attachment, content, err := d.AttachmentService.Download(r.Context(), id, attachmentID)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to download attachment with id %s", attachmentID))
+		return
+	}
+	defer func(content io.ReadCloser) {
+		if err := content.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close attachment content")
+		}
+	}(content)
+
+	// the content never changes, so its checksum is a strong entity tag
+	etag := strconv.Quote(attachment.Checksum)
+	if notModified(r, etag, time.Time{}) {
+		writeNotModified(w, etag, time.Time{})
+		return
+	}
+	setValidators(w, etag, time.Time{})
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	// the sniffed type is trusted as is, the browsers must not guess another one
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	if _, err = io.Copy(w, content); err != nil {
+		log.Error().Err(err).Msg("failed to write attachment content")
+	}
+}
+
+// DeleteAttachment represents the handler deleting a file attached to a task
+type DeleteAttachment struct {
+	AttachmentService interfaces.IAttachmentService
+}
+
+// @Summary delete a file attached to a task
+// @Description  delete a file along with its content, the uploaders delete their files and the admins of the project the ones of the others
+// @Param id path string true "task ID"
+// @Param attachmentId path string true "attachment ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/attachments/{attachmentId} [delete]
+//
+// ServeHTTP implements the handler interface to handle deleting an attachment
+func (d DeleteAttachment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, attachmentID := mux.Vars(r)["id"], mux.Vars(r)["attachmentId"]
+	if id == "" || attachmentID == "" {
+		log.Warn().Msg("task or attachment ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and attachment IDs not provided in path")
+		return
+	}
+	if err := d.AttachmentService.Delete(r.Context(), id, attachmentID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to delete attachment with id %s", attachmentID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
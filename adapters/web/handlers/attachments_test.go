@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/gorilla/mux"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mockAttachmentService knows the task "1" with the file "a1", and reads the uploads up to 8 bytes
+type mockAttachmentService struct{}
+
+var testAttachment = &entity.Attachment{ID: "a1", TaskID: "1", Name: "résumé.txt", Size: 5, ContentType: "text/plain; charset=utf-8",
+	Checksum: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", UploadedBy: "alice"}
+
+func (m mockAttachmentService) List(ctx context.Context, taskID string) ([]*entity.Attachment, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	return []*entity.Attachment{testAttachment}, nil
+}
+
+func (m mockAttachmentService) Upload(ctx context.Context, taskID string, name string, content io.Reader) (*entity.Attachment, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	b, err := io.ReadAll(io.LimitReader(content, 9))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > 8 {
+		return nil, errs.New(errs.ErrTooLarge, "file is larger than 8 bytes")
+	}
+	return &entity.Attachment{ID: "a2", TaskID: taskID, Name: name, Size: int64(len(b))}, nil
+}
+
+func (m mockAttachmentService) Download(ctx context.Context, taskID string, id string) (*entity.Attachment, io.ReadCloser, error) {
+	if taskID != "1" || id != "a1" {
+		return nil, nil, errs.New(errs.ErrNotFound, "attachment not found")
+	}
+	return testAttachment, io.NopCloser(strings.NewReader("hello")), nil
+}
+
+func (m mockAttachmentService) Delete(ctx context.Context, taskID string, id string) error {
+	_, _, err := m.Download(ctx, taskID, id)
+	return err
+}
+
+// multipartBody returns a multipart form with the file in the part named field, and its content type
+func multipartBody(t *testing.T, field string, name string, content string) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = part.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, writer.FormDataContentType()
+}
+
+func TestListAttachments_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should list the attachments", id: "1", status: http.StatusOK},
+		{name: "should fail with StatusNotFound because task does not exist", id: "2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/"+tt.id+"/attachments", nil), map[string]string{"id": tt.id})
+
+			ListAttachments{AttachmentService: mockAttachmentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got AttachmentsResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Attachments) != 1 || got.Attachments[0].ID != "a1" {
+				t.Errorf("invalid attachments, expected: [a1], got: %v", got.Attachments)
+			}
+			if strings.Contains(response.Body.String(), "blobKey") {
+				t.Errorf("the key of the blob should not be exposed: %s", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestUploadAttachment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		field   string
+		content string
+		raw     string // body sent as is instead of a multipart form
+		maxSize int64
+		status  int
+	}{
+		{name: "should attach the file", id: "1", field: "file", content: "hello", maxSize: 8, status: http.StatusCreated},
+		{name: "should fail with StatusNotFound because task does not exist", id: "2", field: "file", content: "hello", maxSize: 8, status: http.StatusNotFound},
+		{name: "should fail with StatusRequestEntityTooLarge because the file is over the limit", id: "1", field: "file", content: "hello, world", maxSize: 8, status: http.StatusRequestEntityTooLarge},
+		{name: "should fail with StatusRequestEntityTooLarge because the body is over the limit", id: "1", field: "document", content: strings.Repeat("a", 2*multipartOverhead), maxSize: 8, status: http.StatusRequestEntityTooLarge},
+		{name: "should fail with StatusBadRequest because there is no part named file", id: "1", field: "document", content: "hello", maxSize: 8, status: http.StatusBadRequest},
+		{name: "should fail with StatusBadRequest because the body is not a multipart form", id: "1", raw: `{"name": "hello.txt"}`, maxSize: 8, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.raw != "" {
+				req = httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/"+tt.id+"/attachments", strings.NewReader(tt.raw))
+				req.Header.Set("Content-Type", "application/json")
+			} else {
+				body, contentType := multipartBody(t, tt.field, "hello.txt", tt.content)
+				req = httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/"+tt.id+"/attachments", body)
+				req.Header.Set("Content-Type", contentType)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			response := httptest.NewRecorder()
+
+			UploadAttachment{AttachmentService: mockAttachmentService{}, MaxSize: tt.maxSize}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d: %s", tt.status, response.Code, response.Body.String())
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.Attachment
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "hello.txt" || got.Size != int64(len(tt.content)) {
+				t.Errorf("invalid attachment, expected hello.txt of %d bytes, got: %+v", len(tt.content), got)
+			}
+		})
+	}
+}
+
+func TestDownloadAttachment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		ifNoneMatch string
+		status      int
+	}{
+		{name: "should download the file", id: "a1", status: http.StatusOK},
+		{name: "should not send the file again when the client has it", id: "a1", ifNoneMatch: `"` + testAttachment.Checksum + `"`, status: http.StatusNotModified},
+		{name: "should fail with StatusNotFound because the attachment does not exist", id: "a2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1/attachments/"+tt.id, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			req = mux.SetURLVars(req, map[string]string{"id": "1", "attachmentId": tt.id})
+
+			DownloadAttachment{AttachmentService: mockAttachmentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if response.Body.String() != "hello" {
+				t.Errorf("invalid content, expected: hello, got: %s", response.Body.String())
+			}
+			for header, want := range map[string]string{
+				"Content-Type":           "text/plain; charset=utf-8",
+				"Content-Length":         "5",
+				"Content-Disposition":    "attachment; filename*=utf-8''r%C3%A9sum%C3%A9.txt",
+				"X-Content-Type-Options": "nosniff",
+				"ETag":                   `"` + testAttachment.Checksum + `"`,
+			} {
+				if got := response.Header().Get(header); got != want {
+					t.Errorf("invalid %s header, expected: %s, got: %s", header, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteAttachment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should delete the attachment", id: "a1", status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because the attachment does not exist", id: "a2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/tasks/1/attachments/"+tt.id, nil), map[string]string{"id": "1", "attachmentId": tt.id})
+
+			DeleteAttachment{AttachmentService: mockAttachmentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
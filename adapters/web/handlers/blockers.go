@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Blockers represents the handler listing the tasks blocking a task
+type Blockers struct {
+	TaskService interfaces.ITaskService
+}
+
+// BlockersResponse represents the tasks blocking a task, the most important first
+type BlockersResponse struct {
+	Tasks []*entity.Task `json:"tasks"`
+}
+
+// BlockerRequest represents the task to add as a blocker of another one
+type BlockerRequest struct {
+	BlockerID string `json:"blockerId"` // ID of the task that has to be closed before the other one is started or closed
+}
+
+// @Summary list the blockers of a task
+// @Description  list the tasks blocking a task, closed or not, the most important first. The ones in the trash are not listed.
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} handlers.BlockersResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/blockers [get]
+//
+// ServeHTTP implements the handler interface to handle listing the blockers of a task
+func (b Blockers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	blockers, err := b.TaskService.Blockers(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to list blockers of task with id %s", id))
+		return
+	}
+	res := BlockersResponse{Tasks: blockers}
+	if res.Tasks == nil {
+		res.Tasks = []*entity.Task{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// AddBlocker represents the handler recording that a task is blocked by another one
+type AddBlocker struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary add a blocker to a task
+// @Description  record that a task is blocked by another one: it cannot become active or closed while its blocker is open.
+// @Description  Dependencies making a cycle are refused.
+// @Produce json
+// @Accept	json
+// @Param id path string true "task ID"
+// @Param   blocker  body  handlers.BlockerRequest  true  "Blocking task"
+// @Success 201 {object} entity.TaskDependency
+// @Failure 405,400,404,409,500,503
+// @Router /tasks/{id}/blockers [post]
+//
+// ServeHTTP implements the handler interface to handle adding a blocker to a task
+func (a AddBlocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	var req BlockerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("failed close body")
+		}
+	}(r.Body)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to decode body")
+		writeStatus(w, r, http.StatusBadRequest, "request body is not a valid JSON blocker")
+		return
+	}
+
+	dependency, err := a.TaskService.AddBlocker(r.Context(), id, req.BlockerID)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to add blocker to task with id %s", id))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(dependency); err != nil {
+		log.Error().Err(err).Msg("failed to write response")
+	}
+}
+
+// RemoveBlocker represents the handler removing the dependency of a task on one of its blockers
+type RemoveBlocker struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary remove a blocker from a task
+// @Description  remove the dependency of a task on one of its blockers
+// @Param id path string true "task ID"
+// @Param blockerId path string true "ID of the blocking task"
+// @Success 204
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/blockers/{blockerId} [delete]
+//
+// ServeHTTP implements the handler interface to handle removing a blocker from a task
+func (rb RemoveBlocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, blockerID := mux.Vars(r)["id"], mux.Vars(r)["blockerId"]
+	if id == "" || blockerID == "" {
+		log.Warn().Msg("task or blocker ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and blocker IDs not provided in path")
+		return
+	}
+	if err := rb.TaskService.RemoveBlocker(r.Context(), id, blockerID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to remove blocker %s from task with id %s", blockerID, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBlockers_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		status int
+	}{
+		{name: "should list the blockers of the task", method: "GET", id: "1", status: http.StatusOK},
+		{name: "should fail with StatusNotFound because task does not exist", method: "GET", id: "non-existing-id", status: http.StatusNotFound},
+		{name: "should fail to list blockers with StatusMethodNotAllowed", method: "PUT", id: "1", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/blockers", nil), map[string]string{"id": tt.id})
+
+			Blockers{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status == http.StatusOK && strings.TrimSpace(response.Body.String()) != `{"tasks":[]}` {
+				t.Errorf("invalid response, expected an empty list, got: %s", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestAddBlocker_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{name: "should add the blocker to the task", id: "1", body: `{"blockerId": "2"}`, status: http.StatusCreated},
+		{name: "should fail because a task cannot block itself", id: "1", body: `{"blockerId": "1"}`, status: http.StatusBadRequest},
+		{name: "should fail because body is not a JSON blocker", id: "1", body: `"2"`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because task does not exist", id: "non-existing-id", body: `{"blockerId": "2"}`, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/"+tt.id+"/blockers", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			AddBlocker{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.TaskDependency
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.TaskID != "1" || got.BlockerID != "2" {
+				t.Errorf("invalid response, expected task 1 blocked by 2, got: %v", got)
+			}
+		})
+	}
+}
+
+func TestRemoveBlocker_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name   string
+		method string
+		vars   map[string]string
+		status int
+	}{
+		{name: "should remove the blocker of the task", method: "DELETE", vars: map[string]string{"id": "1", "blockerId": "2"}, status: http.StatusNoContent},
+		{name: "should fail because blocker ID is missing", method: "DELETE", vars: map[string]string{"id": "1"}, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because task does not exist", method: "DELETE", vars: map[string]string{"id": "non-existing-id", "blockerId": "2"}, status: http.StatusNotFound},
+		{name: "should fail to remove blocker with StatusMethodNotAllowed", method: "GET", vars: map[string]string{"id": "1", "blockerId": "2"}, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/1/blockers/2", nil), tt.vars)
+
+			RemoveBlocker{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// ListChecklist represents the handler listing the checklist of a task
+type ListChecklist struct {
+	ChecklistService interfaces.IChecklistService
+}
+
+// ChecklistResponse represents the checklist of a task in its order, along with its progress
+type ChecklistResponse struct {
+	Items    []*entity.ChecklistItem   `json:"items"`
+	Progress *entity.ChecklistProgress `json:"progress,omitempty"` // absent when the checklist is empty
+}
+
+// @Summary list the checklist of a task
+// @Description  list the items of the checklist of a task in their order, along with how many of them are done
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} handlers.ChecklistResponse
+// @Success 304 "the checklist held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/checklist [get]
+//
+// ServeHTTP implements the handler interface to handle listing the checklist of a task
+func (l ListChecklist) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	items, err := l.ChecklistService.List(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to list checklist of task with id %s", id))
+		return
+	}
+	done := 0
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+	res := ChecklistResponse{Items: items, Progress: entity.NewChecklistProgress(done, len(items))}
+	if res.Items == nil {
+		res.Items = []*entity.ChecklistItem{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// AddChecklistItem represents the handler adding an item to the checklist of a task
+type AddChecklistItem struct {
+	ChecklistService interfaces.IChecklistService
+}
+
+// @Summary add an item to the checklist of a task
+// @Description  add an unchecked item to the checklist of a task, at the end unless a position is given, the following items then moving down.
+// @Description  The text is a single line of at most 500 characters.
+// @Produce json
+// @Accept	json
+// @Param id path string true "task ID"
+// @Param   item  body  entity.NewChecklistItem  true  "New item"
+// @Success 201 {object} entity.ChecklistItem
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/checklist [post]
+//
+// ServeHTTP implements the handler interface to handle adding a checklist item
+func (a AddChecklistItem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	var req entity.NewChecklistItem
+	if !decodeBody(w, r, &req, "checklist item") {
+		return
+	}
+	item, err := a.ChecklistService.Add(r.Context(), id, &req)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to add checklist item to task with id %s", id))
+		return
+	}
+	writeJSON(w, http.StatusCreated, item)
+}
+
+// MoveChecklistItem represents the handler reordering the checklist of a task
+type MoveChecklistItem struct {
+	ChecklistService interfaces.IChecklistService
+}
+
+// @Summary move an item of a checklist
+// @Description  put an item of the checklist of a task at a new position from 1, or at the end when the position is after the last item.
+// @Description  The items in between move by one place.
+// @Produce json
+// @Accept	json
+// @Param id path string true "task ID"
+// @Param itemId path string true "checklist item ID"
+// @Param   move  body  entity.ChecklistMove  true  "New position of the item"
+// @Success 200 {object} entity.ChecklistItem
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/checklist/{itemId}/position [put]
+//
+// ServeHTTP implements the handler interface to handle moving a checklist item
+func (m MoveChecklistItem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, itemID := mux.Vars(r)["id"], mux.Vars(r)["itemId"]
+	if id == "" || itemID == "" {
+		log.Warn().Msg("task or checklist item ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and checklist item IDs not provided in path")
+		return
+	}
+	var req entity.ChecklistMove
+	if !decodeBody(w, r, &req, "position") {
+		return
+	}
+	item, err := m.ChecklistService.Move(r.Context(), id, itemID, &req)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to move checklist item with id %s", itemID))
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// SetChecklistItemDone represents the handler checking or unchecking an item of the checklist of a task
+type SetChecklistItemDone struct {
+	ChecklistService interfaces.IChecklistService
+	Done             bool // whether the handler checks the item or unchecks it
+}
+
+// @Summary check or uncheck an item of a checklist
+// @Description  mark an item of the checklist of a task as done, or as not done again. Checking an item twice changes nothing.
+// @Produce json
+// @Param id path string true "task ID"
+// @Param itemId path string true "checklist item ID"
+// @Success 200 {object} entity.ChecklistItem
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/checklist/{itemId}/check [post]
+// @Router /tasks/{id}/checklist/{itemId}/uncheck [post]
+//
+// ServeHTTP implements the handler interface to handle checking and unchecking the checklist items
+func (s SetChecklistItemDone) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, itemID := mux.Vars(r)["id"], mux.Vars(r)["itemId"]
+	if id == "" || itemID == "" {
+		log.Warn().Msg("task or checklist item ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and checklist item IDs not provided in path")
+		return
+	}
+	item, err := s.ChecklistService.SetDone(r.Context(), id, itemID, s.Done)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to update checklist item with id %s", itemID))
+		return
+	}
+	writeJSON(w, http.StatusOK, item)
+}
+
+// RemoveChecklistItem represents the handler removing an item from the checklist of a task
+type RemoveChecklistItem struct {
+	ChecklistService interfaces.IChecklistService
+}
+
+// @Summary remove an item from a checklist
+// @Description  remove an item from the checklist of a task, the following items move up
+// @Param id path string true "task ID"
+// @Param itemId path string true "checklist item ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/checklist/{itemId} [delete]
+//
+// ServeHTTP implements the handler interface to handle removing a checklist item
+func (rm RemoveChecklistItem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, itemID := mux.Vars(r)["id"], mux.Vars(r)["itemId"]
+	if id == "" || itemID == "" {
+		log.Warn().Msg("task or checklist item ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and checklist item IDs not provided in path")
+		return
+	}
+	if err := rm.ChecklistService.Remove(r.Context(), id, itemID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to remove checklist item with id %s", itemID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// mockChecklistService knows the task "1" whose checklist has the unchecked item "i1" and the checked item "i2"
+type mockChecklistService struct{}
+
+func (m mockChecklistService) find(taskID string, id string) (*entity.ChecklistItem, error) {
+	items, err := m.List(context.Background(), taskID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.ID == id {
+			return item, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "checklist item not found")
+}
+
+func (m mockChecklistService) List(ctx context.Context, taskID string) ([]*entity.ChecklistItem, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	return []*entity.ChecklistItem{
+		{ID: "i1", TaskID: "1", Position: 1, ChecklistItemBody: entity.ChecklistItemBody{Text: "test"}},
+		{ID: "i2", TaskID: "1", Position: 2, Done: true, ChecklistItemBody: entity.ChecklistItemBody{Text: "review"}},
+	}, nil
+}
+
+func (m mockChecklistService) Add(ctx context.Context, taskID string, req *entity.NewChecklistItem) (*entity.ChecklistItem, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	item, err := validation.ValidateChecklistItem(req)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.ChecklistItem{ID: "i3", TaskID: taskID, Position: 3, ChecklistItemBody: item.ChecklistItemBody}, nil
+}
+
+func (m mockChecklistService) Move(ctx context.Context, taskID string, id string, move *entity.ChecklistMove) (*entity.ChecklistItem, error) {
+	if err := validation.ValidateChecklistMove(move); err != nil {
+		return nil, err
+	}
+	item, err := m.find(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Position = move.Position
+	return item, nil
+}
+
+func (m mockChecklistService) SetDone(ctx context.Context, taskID string, id string, done bool) (*entity.ChecklistItem, error) {
+	item, err := m.find(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	item.Done = done
+	return item, nil
+}
+
+func (m mockChecklistService) Remove(ctx context.Context, taskID string, id string) error {
+	_, err := m.find(taskID, id)
+	return err
+}
+
+func TestListChecklist_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should list the checklist with its progress", id: "1", status: http.StatusOK},
+		{name: "should fail with StatusNotFound because task does not exist", id: "2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/"+tt.id+"/checklist", nil), map[string]string{"id": tt.id})
+
+			ListChecklist{ChecklistService: mockChecklistService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got ChecklistResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Items) != 2 || got.Items[0].ID != "i1" {
+				t.Errorf("invalid items, expected: [i1 i2], got: %v", got.Items)
+			}
+			if want := (entity.ChecklistProgress{Done: 1, Total: 2, Summary: "1/2 done"}); got.Progress == nil || *got.Progress != want {
+				t.Errorf("invalid progress, expected: %v, got: %v", want, got.Progress)
+			}
+		})
+	}
+}
+
+func TestAddChecklistItem_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{name: "should add the item", id: "1", body: `{"text": "deploy"}`, status: http.StatusCreated},
+		{name: "should fail with StatusBadRequest because text is missing", id: "1", body: `{"position": 1}`, status: http.StatusBadRequest},
+		{name: "should fail with StatusBadRequest because body is not JSON", id: "1", body: `deploy`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because task does not exist", id: "2", body: `{"text": "deploy"}`, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/"+tt.id+"/checklist", strings.NewReader(tt.body)), map[string]string{"id": tt.id})
+
+			AddChecklistItem{ChecklistService: mockChecklistService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestMoveChecklistItem_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		itemID string
+		body   string
+		status int
+	}{
+		{name: "should move the item", itemID: "i2", body: `{"position": 1}`, status: http.StatusOK},
+		{name: "should fail with StatusBadRequest because position is not positive", itemID: "i2", body: `{"position": 0}`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because the item does not exist", itemID: "i3", body: `{"position": 1}`, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks/1/checklist/"+tt.itemID+"/position", strings.NewReader(tt.body)),
+				map[string]string{"id": "1", "itemId": tt.itemID})
+
+			MoveChecklistItem{ChecklistService: mockChecklistService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestSetChecklistItemDone_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		itemID string
+		done   bool
+		status int
+	}{
+		{name: "should check the item", itemID: "i1", done: true, status: http.StatusOK},
+		{name: "should uncheck the item", itemID: "i2", done: false, status: http.StatusOK},
+		{name: "should fail with StatusNotFound because the item does not exist", itemID: "i3", done: true, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/1/checklist/"+tt.itemID+"/check", nil),
+				map[string]string{"id": "1", "itemId": tt.itemID})
+
+			SetChecklistItemDone{ChecklistService: mockChecklistService{}, Done: tt.done}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got entity.ChecklistItem
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Done != tt.done {
+				t.Errorf("invalid done flag, expected: %t, got: %t", tt.done, got.Done)
+			}
+		})
+	}
+}
+
+func TestRemoveChecklistItem_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		itemID string
+		status int
+	}{
+		{name: "should remove the item", itemID: "i1", status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because the item does not exist", itemID: "i3", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/tasks/1/checklist/"+tt.itemID, nil), map[string]string{"id": "1", "itemId": tt.itemID})
+
+			RemoveChecklistItem{ChecklistService: mockChecklistService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestGet_ServeHTTP_checklistProgress(t *testing.T) {
+	tasks := []*entity.Task{
+		{ID: "1", ChecklistItems: 5, ChecklistDone: 3, TaskDescription: entity.TaskDescription{Title: "with checklist"}},
+		{ID: "2", TaskDescription: entity.TaskDescription{Title: "without checklist"}},
+	}
+	for _, task := range tasks {
+		response := httptest.NewRecorder()
+		req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/"+task.ID, nil), map[string]string{"id": task.ID})
+
+		Get{TaskService: newMockTaskService(tasks)}.ServeHTTP(response, req)
+
+		var got struct {
+			Checklist *entity.ChecklistProgress `json:"checklist"`
+		}
+		if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if task.ChecklistItems == 0 && got.Checklist != nil {
+			t.Errorf("invalid progress for task %s, expected none, got: %v", task.ID, got.Checklist)
+		}
+		if want := (entity.ChecklistProgress{Done: 3, Total: 5, Summary: "3/5 done"}); task.ChecklistItems != 0 && (got.Checklist == nil || *got.Checklist != want) {
+			t.Errorf("invalid progress for task %s, expected: %v, got: %v", task.ID, want, got.Checklist)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// Children represents the handler listing the direct subtasks of a task
+type Children struct {
+	TaskService interfaces.ITaskService
+}
+
+// ChildrenResponse represents the direct subtasks of a task, ordered by creation
+type ChildrenResponse struct {
+	Tasks []*entity.Task `json:"tasks"`
+}
+
+// @Summary list the subtasks of a task
+// @Description  list the direct subtasks of a task, ordered by creation. The ones in the trash are not listed.
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} handlers.ChildrenResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/children [get]
+//
+// ServeHTTP implements the handler interface to handle listing the subtasks of a task
+func (c Children) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	children, err := c.TaskService.Children(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to list subtasks of task with id %s", id))
+		return
+	}
+	res := ChildrenResponse{Tasks: children}
+	if res.Tasks == nil {
+		res.Tasks = []*entity.Task{}
+	}
+	// a subtask moved elsewhere does not change the remaining ones, only the ETag can tell it
+	writeConditionalJSON(w, r, res, time.Time{})
+}
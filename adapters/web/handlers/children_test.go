@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// hierarchyTaskDB is not shared with the other handler tests, since they modify their DB
+var hierarchyTaskDB = []*entity.Task{{
+	ID:              "epic",
+	Subtasks:        2,
+	TaskDescription: entity.TaskDescription{Title: "epic"},
+}, {
+	ID:              "story",
+	TaskDescription: entity.TaskDescription{Title: "story", ParentID: "epic"},
+}, {
+	ID:              "bug",
+	TaskDescription: entity.TaskDescription{Title: "bug", ParentID: "epic"},
+}, {
+	ID:              "subtask",
+	TaskDescription: entity.TaskDescription{Title: "subtask", ParentID: "story"},
+}}
+
+func TestChildren_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(hierarchyTaskDB)
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		status int
+		want   []string
+	}{
+		{name: "should list the subtasks of the task", method: "GET", id: "epic", status: http.StatusOK, want: []string{"story", "bug"}},
+		{name: "should list no subtask of a task without any", method: "GET", id: "bug", status: http.StatusOK, want: []string{}},
+		{name: "should fail with StatusNotFound because task does not exist", method: "GET", id: "non-existing-id", status: http.StatusNotFound},
+		{name: "should fail to list subtasks with StatusMethodNotAllowed", method: "POST", id: "epic", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/children", nil), map[string]string{"id": tt.id})
+
+			Children{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got ChildrenResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Tasks == nil || len(got.Tasks) != len(tt.want) {
+				t.Fatalf("invalid response, expected subtasks %v, got: %v", tt.want, got.Tasks)
+			}
+			for i, task := range got.Tasks {
+				if task.ID != tt.want[i] {
+					t.Errorf("invalid response, expected subtasks %v, got: %v", tt.want, got.Tasks)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// ListComments represents the handler listing the comments of a task
+type ListComments struct {
+	CommentService interfaces.ICommentService
+}
+
+// CommentsResponse represents a page of the comments of a task, from the oldest one
+type CommentsResponse struct {
+	Comments []*entity.Comment `json:"comments"`
+	Total    int64             `json:"total"`  // total number of comments of the task
+	Limit    int               `json:"limit"`  // maximum number of comments in the page
+	Offset   int               `json:"offset"` // position of the first comment of the page
+	Links    PageLinks         `json:"links"`
+}
+
+// @Summary list the comments of a task
+// @Description  list the comments posted on a task from the oldest one, their bodies are Markdown
+// @Produce json
+// @Param id path string true "task ID"
+// @Param limit query int false "maximum number of comments to return (1-100)" default(20)
+// @Param offset query int false "number of comments to skip" default(0)
+// @Success 200 {object} handlers.CommentsResponse
+// @Success 304 "the page held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/comments [get]
+//
+// ServeHTTP implements the handler interface to handle listing the comments of a task
+func (l ListComments) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	parser := queryParser{values: r.URL.Query()}
+	query := entity.CommentQuery{Limit: parser.int("limit"), Offset: parser.int("offset")}
+	if err := errs.Validation(parser.violations); err != nil {
+		writeError(w, r, err, "failed to parse query parameters")
+		return
+	}
+
+	list, err := l.CommentService.List(r.Context(), id, &query)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to list comments of task with id %s", id))
+		return
+	}
+	res := CommentsResponse{Comments: list.Comments, Total: list.Total, Limit: list.Limit, Offset: list.Offset,
+		Links: offsetLinks(r.URL, list.Offset, list.Limit, list.Total)}
+	if res.Comments == nil {
+		res.Comments = []*entity.Comment{}
+	}
+	// comments are edited and deleted, so only the ETag tells whether the page changed
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// CreateComment represents the handler posting a comment on a task
+type CreateComment struct {
+	CommentService interfaces.ICommentService
+}
+
+// @Summary comment a task
+// @Description  post a comment on a task as the caller, the body is Markdown of at most 10000 characters
+// @Produce json
+// @Accept	json
+// @Param id path string true "task ID"
+// @Param   comment  body  entity.CommentBody  true  "New comment"
+// @Success 201 {object} entity.Comment
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/comments [post]
+//
+// ServeHTTP implements the handler interface to handle posting a comment
+func (c CreateComment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	var req entity.CommentBody
+	if !decodeBody(w, r, &req, "comment") {
+		return
+	}
+	comment, err := c.CommentService.Create(r.Context(), id, &req)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to comment task with id %s", id))
+		return
+	}
+	writeJSON(w, http.StatusCreated, comment)
+}
+
+// UpdateComment represents the handler editing a comment
+type UpdateComment struct {
+	CommentService interfaces.ICommentService
+}
+
+// @Summary edit a comment
+// @Description  replace the body of a comment, only its author can edit it. The previous body is kept in the revisions of the comment.
+// @Produce json
+// @Accept	json
+// @Param id path string true "task ID"
+// @Param commentId path string true "comment ID"
+// @Param   comment  body  entity.CommentBody  true  "New body of the comment"
+// @Success 200 {object} entity.Comment
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/comments/{commentId} [put]
+// @Router /tasks/{id}/comments/{commentId} [patch]
+//
+// ServeHTTP implements the handler interface to handle editing a comment
+func (u UpdateComment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, commentID := mux.Vars(r)["id"], mux.Vars(r)["commentId"]
+	if id == "" || commentID == "" {
+		log.Warn().Msg("task or comment ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and comment IDs not provided in path")
+		return
+	}
+	var req entity.CommentBody
+	if !decodeBody(w, r, &req, "comment") {
+		return
+	}
+	comment, err := u.CommentService.Update(r.Context(), id, commentID, &req)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to edit comment with id %s", commentID))
+		return
+	}
+	writeJSON(w, http.StatusOK, comment)
+}
+
+// DeleteComment represents the handler deleting a comment
+type DeleteComment struct {
+	CommentService interfaces.ICommentService
+}
+
+// @Summary delete a comment
+// @Description  delete a comment along with its revisions, the authors delete their comments and the admins of the project the ones of the others
+// @Param id path string true "task ID"
+// @Param commentId path string true "comment ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /tasks/{id}/comments/{commentId} [delete]
+//
+// ServeHTTP implements the handler interface to handle deleting a comment
+func (d DeleteComment) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, commentID := mux.Vars(r)["id"], mux.Vars(r)["commentId"]
+	if id == "" || commentID == "" {
+		log.Warn().Msg("task or comment ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and comment IDs not provided in path")
+		return
+	}
+	if err := d.CommentService.Delete(r.Context(), id, commentID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to delete comment with id %s", commentID))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CommentRevisions represents the handler listing the previous bodies of a comment
+type CommentRevisions struct {
+	CommentService interfaces.ICommentService
+}
+
+// RevisionsResponse represents the previous bodies of a comment, from the most recent edit
+type RevisionsResponse struct {
+	Revisions []*entity.CommentRevision `json:"revisions"`
+}
+
+// @Summary get the edit history of a comment
+// @Description  list the previous bodies of a comment from the most recent edit, empty if the comment was never edited
+// @Produce json
+// @Param id path string true "task ID"
+// @Param commentId path string true "comment ID"
+// @Success 200 {object} handlers.RevisionsResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/comments/{commentId}/revisions [get]
+//
+// ServeHTTP implements the handler interface to handle listing the revisions of a comment
+func (c CommentRevisions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, commentID := mux.Vars(r)["id"], mux.Vars(r)["commentId"]
+	if id == "" || commentID == "" {
+		log.Warn().Msg("task or comment ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and comment IDs not provided in path")
+		return
+	}
+	revisions, err := c.CommentService.Revisions(r.Context(), id, commentID)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to get revisions of comment with id %s", commentID))
+		return
+	}
+	res := RevisionsResponse{Revisions: revisions}
+	if res.Revisions == nil {
+		res.Revisions = []*entity.CommentRevision{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockCommentService knows the task "1" with three comments, "c1" being posted by the caller and the others by someone else
+type mockCommentService struct{}
+
+var testComments = []*entity.Comment{
+	{ID: "c1", TaskID: "1", Author: "alice", CommentBody: entity.CommentBody{Body: "first"}},
+	{ID: "c2", TaskID: "1", Author: "bob", CommentBody: entity.CommentBody{Body: "second"}},
+	{ID: "c3", TaskID: "1", Author: "bob", CommentBody: entity.CommentBody{Body: "third"}},
+}
+
+func (m mockCommentService) find(taskID string, id string) (*entity.Comment, error) {
+	for _, comment := range testComments {
+		if comment.TaskID == taskID && comment.ID == id {
+			return comment, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "comment not found")
+}
+
+func (m mockCommentService) List(ctx context.Context, taskID string, query *entity.CommentQuery) (*entity.CommentList, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	query, err := validation.ValidateCommentQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	list := &entity.CommentList{Total: int64(len(testComments)), Limit: query.Limit, Offset: query.Offset}
+	for i := query.Offset; i < len(testComments) && i < query.Offset+query.Limit; i++ {
+		list.Comments = append(list.Comments, testComments[i])
+	}
+	return list, nil
+}
+
+func (m mockCommentService) Create(ctx context.Context, taskID string, req *entity.CommentBody) (*entity.Comment, error) {
+	if taskID != "1" {
+		return nil, errs.New(errs.ErrNotFound, "task not found")
+	}
+	body, err := validation.ValidateComment(req)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Comment{ID: "c4", TaskID: taskID, Author: "alice", CommentBody: *body}, nil
+}
+
+func (m mockCommentService) Update(ctx context.Context, taskID string, id string, req *entity.CommentBody) (*entity.Comment, error) {
+	comment, err := m.find(taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Author != "alice" {
+		return nil, errs.New(errs.ErrForbidden, "only the author can edit it")
+	}
+	now := time.Now()
+	return &entity.Comment{ID: id, TaskID: taskID, Author: comment.Author, EditedAt: &now, CommentBody: *req}, nil
+}
+
+func (m mockCommentService) Delete(ctx context.Context, taskID string, id string) error {
+	_, err := m.find(taskID, id)
+	return err
+}
+
+func (m mockCommentService) Revisions(ctx context.Context, taskID string, id string) ([]*entity.CommentRevision, error) {
+	if _, err := m.find(taskID, id); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func TestListComments_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		id     string
+		status int
+		ids    []string
+		links  PageLinks
+	}{
+		{name: "should list the comments from the oldest one", target: "/v1/api/tasks/1/comments", id: "1", status: http.StatusOK, ids: []string{"c1", "c2", "c3"}},
+		{
+			name: "should link the pages next to the page", target: "/v1/api/tasks/1/comments?limit=1&offset=1", id: "1", status: http.StatusOK, ids: []string{"c2"},
+			links: PageLinks{Next: "/v1/api/tasks/1/comments?limit=1&offset=2", Prev: "/v1/api/tasks/1/comments?limit=1&offset=0"},
+		},
+		{name: "should fail because limit is not an integer", target: "/v1/api/tasks/1/comments?limit=all", id: "1", status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because task does not exist", target: "/v1/api/tasks/2/comments", id: "2", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080"+tt.target, nil), map[string]string{"id": tt.id})
+
+			ListComments{CommentService: mockCommentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got CommentsResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, comment := range got.Comments {
+				ids = append(ids, comment.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.ids, ",") || got.Total != 3 {
+				t.Errorf("invalid comments, expected: %v of 3, got: %v of %d", tt.ids, ids, got.Total)
+			}
+			if got.Links != tt.links {
+				t.Errorf("invalid links, expected: %v, got: %v", tt.links, got.Links)
+			}
+		})
+	}
+}
+
+func TestCreateComment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		body   string
+		status int
+	}{
+		{name: "should post the comment", method: "POST", id: "1", body: `{"body": "Looks **good**"}`, status: http.StatusCreated},
+		{name: "should fail with StatusBadRequest because body is empty", method: "POST", id: "1", body: `{"body": " "}`, status: http.StatusBadRequest},
+		{name: "should fail with StatusBadRequest because JSON is invalid", method: "POST", id: "1", body: `{"body":`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because task does not exist", method: "POST", id: "2", body: `{"body": "hello"}`, status: http.StatusNotFound},
+		{name: "should fail with StatusMethodNotAllowed", method: "PUT", id: "1", body: `{"body": "hello"}`, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/comments", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			CreateComment{CommentService: mockCommentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.Comment
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Body != "Looks **good**" || got.TaskID != tt.id {
+				t.Errorf("invalid comment, expected the body posted on task %s, got: %+v", tt.id, got)
+			}
+		})
+	}
+}
+
+func TestUpdateComment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		commentID string
+		status    int
+	}{
+		{name: "should edit the comment of the caller", method: "PATCH", commentID: "c1", status: http.StatusOK},
+		{name: "should edit the comment of the caller with PUT", method: "PUT", commentID: "c1", status: http.StatusOK},
+		{name: "should fail with StatusForbidden because comment was posted by someone else", method: "PATCH", commentID: "c2", status: http.StatusForbidden},
+		{name: "should fail with StatusNotFound because comment does not exist", method: "PATCH", commentID: "c9", status: http.StatusNotFound},
+		{name: "should fail with StatusBadRequest because comment ID is missing", method: "PATCH", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/1/comments/"+tt.commentID, strings.NewReader(`{"body": "edited"}`))
+			req = mux.SetURLVars(req, map[string]string{"id": "1", "commentId": tt.commentID})
+
+			UpdateComment{CommentService: mockCommentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteComment_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		commentID string
+		status    int
+	}{
+		{name: "should delete the comment", method: "DELETE", commentID: "c1", status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because comment does not exist", method: "DELETE", commentID: "c9", status: http.StatusNotFound},
+		{name: "should fail with StatusMethodNotAllowed", method: "POST", commentID: "c1", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/1/comments/"+tt.commentID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "1", "commentId": tt.commentID})
+
+			DeleteComment{CommentService: mockCommentService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestCommentRevisions_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1/comments/c1/revisions", nil), map[string]string{"id": "1", "commentId": "c1"})
+
+	CommentRevisions{CommentService: mockCommentService{}}.ServeHTTP(response, req)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	if got := strings.TrimSpace(response.Body.String()); got != `{"revisions":[]}` {
+		t.Errorf("invalid response, expected no revision, got: %s", got)
+	}
+}
@@ -2,8 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"io"
 	"net/http"
@@ -20,13 +22,15 @@ type Create struct {
 // @Description  add a new task to the tasks list
 // @Produce json
 // @Accept	json
+// @Param pid path string false "project ID, only on the nested route"
 // @Param   task  body  entity.TaskDescription  true  "New task"
 // @Success 201 {object} entity.Task
-// @Failure 405,400,500
+// @Failure 405,400,404,409,500,503
 // @Router /tasks [post]
+// @Router /projects/{pid}/tasks [post]
 func (c Create) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
 		return
 	}
 	decoder := json.NewDecoder(r.Body) // we use decoder instead of unmarshall
@@ -38,20 +42,24 @@ func (c Create) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest) //bed request because body is not json
-		log.Error().Err(err).Msg("failed to decode body")
+		log.Warn().Err(err).Msg("failed to decode body")
+		writeStatus(w, r, http.StatusBadRequest, "request body is not a valid JSON task description") //bad request because body is not json
 		return
 	}
 
-	response, err := c.TaskService.Create(&c.req)
+	// the project of the nested route takes precedence over the one of the body
+	if pid := mux.Vars(r)["pid"]; pid != "" {
+		c.req.ProjectID = pid
+	}
+	response, err := c.TaskService.Create(r.Context(), &c.req)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Error().Err(err).Msg("failed to create task")
+		writeError(w, r, err, "failed to create task")
 		return
 	}
 
 	c.res = *response
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(response))
 	w.WriteHeader(http.StatusCreated)
 	encoder := json.NewEncoder(w)
 	err = encoder.Encode(c.res)
@@ -2,15 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
+	"github.com/gorilla/mux"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 var testCreateTask entity.Task = entity.Task{
@@ -47,7 +52,10 @@ func newMockTaskService(tasks []*entity.Task) *mockTaskService {
 	return &mockTaskService{tasks}
 }
 
-func (t mockTaskService) Create(taskDescription *entity.TaskDescription) (*entity.Task, error) {
+func (t mockTaskService) Create(ctx context.Context, taskDescription *entity.TaskDescription) (*entity.Task, error) {
+	if taskDescription.ProjectID != "" && taskDescription.ProjectID != entity.DefaultProjectID {
+		return nil, errs.New(errs.ErrNotFound, "project with id '%s' not found", taskDescription.ProjectID)
+	}
 	task := entity.Task{
 		ID:              testCreateTask.ID,
 		TaskDescription: *taskDescription,
@@ -56,20 +64,49 @@ func (t mockTaskService) Create(taskDescription *entity.TaskDescription) (*entit
 	return &task, nil
 }
 
-func (t mockTaskService) Get() ([]*entity.Task, error) {
-	return t.tasks, nil
+func (t mockTaskService) Get(ctx context.Context, query *entity.TaskQuery) (*entity.TaskList, error) {
+	if query.ProjectID != "" && query.ProjectID != entity.DefaultProjectID {
+		return nil, errs.New(errs.ErrNotFound, "project with id '%s' not found", query.ProjectID)
+	}
+	var tasks []*entity.Task
+	for _, task := range t.tasks {
+		if (task.DeletedAt != nil) == query.Trashed {
+			tasks = append(tasks, task)
+		}
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = 20
+	}
+	page := &entity.TaskList{Tasks: []*entity.Task{}, Total: int64(len(tasks)), Limit: limit, Offset: query.Offset}
+	start := query.Offset
+	if query.After != nil { // in this simple mock the tasks are already in keyset order
+		for i := range tasks {
+			if tasks[i].ID == query.After.ID {
+				start = i + 1
+			}
+		}
+	}
+	for i := start; i < len(tasks) && i < start+limit; i++ {
+		page.Tasks = append(page.Tasks, tasks[i])
+	}
+	if query.Keyset && start+limit < len(tasks) {
+		last := page.Tasks[len(page.Tasks)-1]
+		page.Next = &entity.TaskCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return page, nil
 }
 
-func (t mockTaskService) GetByID(id string) (*entity.Task, error) {
+func (t mockTaskService) GetByID(ctx context.Context, id string) (*entity.Task, error) {
 	for i := range t.tasks {
 		if t.tasks[i].ID == id {
 			return t.tasks[i], nil
 		}
 	}
-	return nil, fmt.Errorf("element with ID %s not found", id)
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
 }
 
-func (t mockTaskService) DeleteByID(id string) error {
+func (t mockTaskService) DeleteByID(ctx context.Context, id string) error {
 	for i := range t.tasks {
 		if t.tasks[i].ID == id {
 			t.tasks[i] = t.tasks[len(t.tasks)-1] // put last element there since order does not matter
@@ -77,28 +114,180 @@ func (t mockTaskService) DeleteByID(id string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf("element with ID %s not found", id)
+	return errs.New(errs.ErrNotFound, "element with ID %s not found", id)
+}
+
+func (t mockTaskService) Restore(ctx context.Context, id string) (*entity.Task, error) {
+	for i := range t.tasks {
+		if t.tasks[i].ID == id && t.tasks[i].DeletedAt != nil {
+			restored := *t.tasks[i]
+			restored.DeletedAt, restored.DeletedBy = nil, ""
+			restored.Version++
+			return &restored, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found in the trash", id)
+}
+
+func (t mockTaskService) ProjectOf(ctx context.Context, id string) (string, error) {
+	for i := range t.tasks {
+		if t.tasks[i].ID == id {
+			return t.tasks[i].ProjectID, nil
+		}
+	}
+	return "", errs.New(errs.ErrNotFound, "element with ID %s not found", id)
+}
+
+func (t mockTaskService) History(ctx context.Context, id string, query *entity.HistoryQuery) (*entity.TaskHistory, error) {
+	for i := range t.tasks {
+		if t.tasks[i].ID == id {
+			event := &entity.TaskEvent{ID: "event-" + id, TaskID: id, Type: entity.Created, Actor: "admin", At: t.tasks[i].CreatedAt}
+			return &entity.TaskHistory{Events: []*entity.TaskEvent{event}, Total: 1, Limit: 20}, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
+}
+
+func (t mockTaskService) Children(ctx context.Context, id string) ([]*entity.Task, error) {
+	if _, err := t.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	var children []*entity.Task
+	for _, task := range t.tasks {
+		if task.ParentID == id {
+			children = append(children, task)
+		}
+	}
+	return children, nil
+}
+
+func (t mockTaskService) Tree(ctx context.Context, id string) (*entity.TaskNode, error) {
+	task, err := t.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	node := &entity.TaskNode{Task: task, Children: []*entity.TaskNode{}}
+	children, _ := t.Children(ctx, id)
+	for _, child := range children {
+		subtree, _ := t.Tree(ctx, child.ID)
+		node.Children = append(node.Children, subtree)
+	}
+	return node, nil
+}
+
+func (t mockTaskService) RemoveParent(ctx context.Context, id string, version int) (*entity.Task, error) {
+	for i := range t.tasks {
+		if t.tasks[i].ID == id {
+			if version != 0 && version != t.tasks[i].Version {
+				return nil, errs.New(errs.ErrPreconditionFailed, "element with ID %s has moved on", id)
+			}
+			t.tasks[i].ParentID = ""
+			return t.tasks[i], nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
+}
+
+func (t mockTaskService) Blockers(ctx context.Context, id string) ([]*entity.Task, error) {
+	if _, err := t.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return []*entity.Task{}, nil
+}
+
+func (t mockTaskService) AddBlocker(ctx context.Context, id string, blockerID string) (*entity.TaskDependency, error) {
+	if _, err := t.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	if id == blockerID {
+		return nil, errs.Validation([]errs.Violation{{Field: "blockerId", Message: "a task cannot block itself"}})
+	}
+	return &entity.TaskDependency{TaskID: id, BlockerID: blockerID, CreatedBy: "admin"}, nil
+}
+
+func (t mockTaskService) RemoveBlocker(ctx context.Context, id string, blockerID string) error {
+	_, err := t.GetByID(ctx, id)
+	return err
+}
+
+func (t mockTaskService) Next(ctx context.Context, limit int) ([]*entity.PlannedTask, error) {
+	plan := []*entity.PlannedTask{}
+	for _, task := range t.tasks {
+		if len(plan) < limit || limit == 0 {
+			plan = append(plan, &entity.PlannedTask{Task: task, BlockedBy: []string{}})
+		}
+	}
+	return plan, nil
+}
+
+func (t mockTaskService) AddLabel(ctx context.Context, id string, labelID string) error {
+	if _, err := t.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if labelID != testLabel.ID {
+		return errs.New(errs.ErrNotFound, "label with id '%s' not found", labelID)
+	}
+	return nil
+}
+
+func (t mockTaskService) RemoveLabel(ctx context.Context, id string, labelID string) error {
+	return t.AddLabel(ctx, id, labelID)
+}
+
+func (t mockTaskService) GetWorkflow(ctx context.Context) *workflow.Workflow {
+	return workflow.Default()
+}
+
+func (t mockTaskService) GetDeletePolicy(ctx context.Context) hierarchy.DeletePolicy {
+	return hierarchy.Refuse
+}
+
+func (t mockTaskService) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (t mockTaskService) SpawnOccurrences(ctx context.Context) (int, error) {
+	return 0, nil
 }
 
 // we tested the functionality already in the service package, so no need to put in a lot of logic in this simple mock
-func (t mockTaskService) UpdatePartial(taskDescription *entity.TaskDescription, id string) (*entity.Task, error) {
+func (t mockTaskService) UpdatePartial(ctx context.Context, taskDescription *entity.TaskDescription, id string, version int) (*entity.Task, error) {
 	for i := range t.tasks {
 		if t.tasks[i].ID == id {
+			if version != 0 && version != t.tasks[i].Version {
+				return nil, errs.New(errs.ErrPreconditionFailed, "element with ID %s has moved on", id)
+			}
 			t.tasks[i].TaskDescription = *taskDescription
 			return t.tasks[i], nil
 		}
 	}
-	return nil, fmt.Errorf("element with ID %s not found", id)
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
 }
 
-func (t mockTaskService) UpdateFully(taskDescription *entity.TaskDescription, id string) (*entity.Task, error) {
+func (t mockTaskService) UpdateFully(ctx context.Context, taskDescription *entity.TaskDescription, id string, version int) (*entity.Task, error) {
 	for i := range t.tasks {
 		if t.tasks[i].ID == id {
+			if version != 0 && version != t.tasks[i].Version {
+				return nil, errs.New(errs.ErrPreconditionFailed, "element with ID %s has moved on", id)
+			}
 			t.tasks[i].TaskDescription = *taskDescription
 			return t.tasks[i], nil
 		}
 	}
-	return nil, fmt.Errorf("element with ID %s not found", id)
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
+}
+
+func (t mockTaskService) Assign(ctx context.Context, id string, assigneeID string, version int) (*entity.Task, error) {
+	for i := range t.tasks {
+		if t.tasks[i].ID == id {
+			if version != 0 && version != t.tasks[i].Version {
+				return nil, errs.New(errs.ErrPreconditionFailed, "element with ID %s has moved on", id)
+			}
+			t.tasks[i].AssigneeID = assigneeID
+			return t.tasks[i], nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "element with ID %s not found", id)
 }
 
 func TestCreate_ServeHTTP(t *testing.T) {
@@ -107,7 +296,13 @@ func TestCreate_ServeHTTP(t *testing.T) {
 		validReq             = httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks", bytes.NewReader(reqBodyToJson(testCreateTask.TaskDescription, t)))
 		methodNotAllowedReq  = httptest.NewRequest("PATCH", "http://localhost:8080/v1/api/tasks", bytes.NewReader(reqBodyToJson(testCreateTask.TaskDescription, t)))
 		invalidBodyFormatReq = httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks/1", strings.NewReader("no-json"))
+		projectReq           = mux.SetURLVars(httptest.NewRequest("POST", "http://localhost:8080/v1/api/projects/default/tasks",
+			bytes.NewReader(reqBodyToJson(testCreateTask.TaskDescription, t))), map[string]string{"pid": entity.DefaultProjectID})
+		unknownProjectReq = mux.SetURLVars(httptest.NewRequest("POST", "http://localhost:8080/v1/api/projects/non-existing-id/tasks",
+			bytes.NewReader(reqBodyToJson(testCreateTask.TaskDescription, t))), map[string]string{"pid": "non-existing-id"})
+		projectTask = testCreateTask
 	)
+	projectTask.ProjectID = entity.DefaultProjectID
 
 	type want struct {
 		body   entity.Task
@@ -130,6 +325,28 @@ func TestCreate_ServeHTTP(t *testing.T) {
 				status: http.StatusCreated,
 			},
 		},
+		{
+			name: "should create item in the project of the path",
+			fields: Create{
+				TaskService: taskService,
+			},
+			request: projectReq,
+			want: want{
+				body:   projectTask,
+				status: http.StatusCreated,
+			},
+		},
+		{
+			name: "should fail to create with StatusNotFound because project does not exist",
+			fields: Create{
+				TaskService: taskService,
+			},
+			request: unknownProjectReq,
+			want: want{
+				body:   entity.Task{},
+				status: http.StatusNotFound,
+			},
+		},
 		{
 			name: "should fail to create tasks with StatusMethodNotAllowed",
 			fields: Create{
@@ -192,7 +409,8 @@ func TestCreate_ServeHTTP(t *testing.T) {
 }
 
 func readTaskResponse(response *httptest.ResponseRecorder) (entity.Task, error) {
-	if response.Body.Len() == 0 { // this way when method not allowed and we got no body in response, this function would not return an error
+	// this way when the request failed and we got a problem or no body in response, this function would not return an error
+	if response.Body.Len() == 0 || response.Header().Get("Content-Type") == problemContentType {
 		return entity.Task{}, nil
 	}
 	var got entity.Task
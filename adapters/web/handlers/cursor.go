@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor when the cursor given by the client was not issued by this service or was tampered with
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// cursorPayload is the content of a cursor before it is signed, short keys keep the tokens small in the URLs
+type cursorPayload struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// encodeCursor returns an opaque token for the given position, made of the base64 payload and its HMAC-SHA256 signature.
+// The signature prevents clients from crafting positions, so the cursor can be changed later without breaking anyone relying on its format.
+func encodeCursor(key []byte, cursor *entity.TaskCursor) (string, error) {
+	payload, err := json.Marshal(cursorPayload{CreatedAt: cursor.CreatedAt, ID: cursor.ID})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(key, encoded)), nil
+}
+
+// decodeCursor verifies the signature of the token and returns the position it holds
+func decodeCursor(key []byte, token string) (*entity.TaskCursor, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+	got, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, sign(key, encoded)) {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor cursorPayload
+	if err = json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" {
+		return nil, ErrInvalidCursor
+	}
+	return &entity.TaskCursor{CreatedAt: cursor.CreatedAt, ID: cursor.ID}, nil
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
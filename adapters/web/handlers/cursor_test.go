@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	key := []byte("test-key")
+	cursor := &entity.TaskCursor{CreatedAt: time.Date(2022, 12, 1, 10, 30, 0, 123000, time.UTC), ID: "task-id"}
+
+	token, err := encodeCursor(key, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := decodeCursor(key, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cursor) {
+		t.Errorf("decodeCursor() got = %v, want %v", got, cursor)
+	}
+
+	tests := []struct {
+		name  string
+		key   []byte
+		token string
+	}{
+		{name: "should fail because signed with another key", key: []byte("other-key"), token: token},
+		{name: "should fail because payload was tampered with", key: key, token: "x" + token},
+		{name: "should fail because signature is missing", key: key, token: strings.Split(token, ".")[0]},
+		{name: "should fail because token is not a cursor", key: key, token: "not-a-cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.key, tt.token); err != ErrInvalidCursor {
+				t.Errorf("decodeCursor() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
@@ -12,36 +13,28 @@ type Delete struct {
 }
 
 // @Summary delete a task
-// @Description  delete a task from the list
+// @Description  move a task to the trash, it can be restored until it is purged after the retention period
 // @Param id path string true "task ID"
 // @Success 200
-// @Failure 405,400,500
+// @Failure 405,400,404,500,503
 // @Router /tasks/{id} [delete]
 //
 // ServeHTTP implements the handler interface to handle deleting the tasks
 func (d Delete) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
 		return
 	}
 
 	id := mux.Vars(r)["id"]
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		log.Error().Msg("task ID not provided in request path")
+		log.Warn().Msg("task ID not provided in request path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
 		return
 	}
-	_, err := d.TaskService.GetByID(id)
+	err := d.TaskService.DeleteByID(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		log.Error().Msgf("error: %s, occurred when getting task with ID %s", err.Error(), id)
-		return
-	}
-
-	err = d.TaskService.DeleteByID(id)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Error().Err(err).Msg("failed to delete instance")
+		writeError(w, r, err, fmt.Sprintf("failed to delete task with id %s", id))
 		return
 	}
 	// why we are using http.StatusNoContent: https://stackoverflow.com/questions/2342579/http-status-code-for-update-and-delete#:~:text=For%20a%20DELETE%20request%3A%20HTTP,but%20not%20fully%20applied%20yet.
@@ -44,6 +44,16 @@ func TestDelete_ServeHTTP(t *testing.T) {
 				status: http.StatusMethodNotAllowed,
 			},
 		},
+		{
+			name: "should fail with StatusNotFound because task does not exist",
+			fields: Delete{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(validReq, map[string]string{"id": "non-existing-id"}),
+			want: want{
+				status: http.StatusNotFound,
+			},
+		},
 		{
 			name: "should fail because no id provided as path parameter",
 			fields: Delete{
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// problemContentType is the media type of the RFC 7807 problem details documents
+const problemContentType = "application/problem+json"
+
+// problem types identify the kind of failure, the generic "about:blank" type is used when the status code says it all
+const (
+	problemTypeBlank        = "about:blank"
+	problemTypeValidation   = "urn:tasks-web-service:problem:validation"
+	problemTypeNotFound     = "urn:tasks-web-service:problem:not-found"
+	problemTypeConflict     = "urn:tasks-web-service:problem:conflict"
+	problemTypePrecondition = "urn:tasks-web-service:problem:precondition-failed"
+	problemTypeUnavailable  = "urn:tasks-web-service:problem:unavailable"
+	problemTypeForbidden    = "urn:tasks-web-service:problem:forbidden"
+	problemTypeTooLarge     = "urn:tasks-web-service:problem:too-large"
+)
+
+// Problem represents an RFC 7807 problem details document, returned as body of all the failed requests
+type Problem struct {
+	Type       string           `json:"type"`                 // URI identifying the kind of problem
+	Title      string           `json:"title"`                // short summary of the kind of problem, the same for all its occurrences
+	Status     int              `json:"status"`               // HTTP status code of the response
+	Detail     string           `json:"detail,omitempty"`     // explanation specific to this occurrence of the problem
+	Instance   string           `json:"instance,omitempty"`   // path of the request that failed
+	Violations []errs.Violation `json:"violations,omitempty"` // the invalid fields of the request, only set for validation problems
+}
+
+// statusCode maps the kind of error returned by the service to the HTTP status code,
+// so that the clients can tell whether they should fix their request or retry it later.
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, errs.ErrValidation):
+		return http.StatusBadRequest
+	case errors.Is(err, errs.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, errs.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, errs.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errs.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, errs.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, errs.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errs.ErrUnavailable):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// newProblem returns the problem describing the error. The details of the server side failures are not exposed to the clients.
+func newProblem(r *http.Request, err error) Problem {
+	status := statusCode(err)
+	problem := Problem{Type: problemTypeBlank, Title: http.StatusText(status), Status: status, Instance: r.URL.Path}
+	switch status {
+	case http.StatusBadRequest:
+		problem.Type, problem.Title, problem.Detail = problemTypeValidation, "Your request parameters didn't validate", err.Error()
+		var validationErr *errs.ValidationError
+		if errors.As(err, &validationErr) {
+			problem.Violations = validationErr.Violations
+		}
+	case http.StatusForbidden:
+		problem.Type, problem.Detail = problemTypeForbidden, err.Error()
+	case http.StatusNotFound:
+		problem.Type, problem.Detail = problemTypeNotFound, err.Error()
+	case http.StatusConflict:
+		problem.Type, problem.Detail = problemTypeConflict, err.Error()
+	case http.StatusPreconditionFailed:
+		problem.Type, problem.Detail = problemTypePrecondition, err.Error()
+	case http.StatusRequestEntityTooLarge:
+		problem.Type, problem.Detail = problemTypeTooLarge, err.Error()
+	case http.StatusServiceUnavailable:
+		problem.Type, problem.Detail = problemTypeUnavailable, "a dependency of the service is unavailable, please retry later"
+	}
+	return problem
+}
+
+// writeError writes the problem matching the error. Server side failures are logged as errors, while the client side ones are only warnings.
+func writeError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	problem := newProblem(r, err)
+	if problem.Status >= http.StatusInternalServerError {
+		log.Error().Err(err).Msg(msg)
+	} else {
+		log.Warn().Err(err).Msg(msg)
+	}
+	writeProblem(w, problem)
+}
+
+// writeStatus writes a problem of the generic type for failures detected by the handlers themselves, e.g. an unsupported method
+func writeStatus(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblem(w, Problem{Type: problemTypeBlank, Title: http.StatusText(status), Status: status, Detail: detail, Instance: r.URL.Path})
+}
+
+func writeProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	err := json.NewEncoder(w).Encode(problem)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write problem")
+	}
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "should map validation errors to bad request",
+			err:  errs.New(errs.ErrValidation, "invalid title"),
+			want: http.StatusBadRequest,
+		},
+		{
+			name: "should map wrapped not found errors to not found",
+			err:  fmt.Errorf("failed to get task: %w", errs.New(errs.ErrNotFound, "task 1")),
+			want: http.StatusNotFound,
+		},
+		{
+			name: "should map wrong credentials to unauthorized",
+			err:  errs.New(errs.ErrUnauthenticated, "wrong password"),
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "should map denied operations to forbidden",
+			err:  errs.New(errs.ErrForbidden, "not an administrator"),
+			want: http.StatusForbidden,
+		},
+		{
+			name: "should map conflicts to conflict",
+			err:  errs.New(errs.ErrConflict, "duplicate id"),
+			want: http.StatusConflict,
+		},
+		{
+			name: "should map contents over the limit to request entity too large",
+			err:  errs.New(errs.ErrTooLarge, "file is larger than 10 bytes"),
+			want: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "should map unavailable dependencies to service unavailable",
+			err:  errs.Wrap(errs.ErrUnavailable, errors.New("connection refused")),
+			want: http.StatusServiceUnavailable,
+		},
+		{
+			name: "should map unknown errors to internal server error",
+			err:  errors.New("unexpected"),
+			want: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statusCode(tt.err); got != tt.want {
+				t.Errorf("statusCode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://localhost:8080/v1/api/tasks", nil)
+	tests := []struct {
+		name string
+		err  error
+		want Problem
+	}{
+		{
+			name: "should list all the violations of a validation error",
+			err: errs.Validation([]errs.Violation{
+				{Field: "title", Message: "field cannot be empty"},
+				{Field: "priority", Message: "invalid priority range"},
+			}),
+			want: Problem{
+				Type:   problemTypeValidation,
+				Title:  "Your request parameters didn't validate",
+				Status: http.StatusBadRequest,
+				Detail: "validation failed: invalid title: field cannot be empty; invalid priority: invalid priority range",
+				Violations: []errs.Violation{
+					{Field: "title", Message: "field cannot be empty"},
+					{Field: "priority", Message: "invalid priority range"},
+				},
+				Instance: "/v1/api/tasks",
+			},
+		},
+		{
+			name: "should describe not found errors",
+			err:  errs.New(errs.ErrNotFound, "task 1"),
+			want: Problem{
+				Type:     problemTypeNotFound,
+				Title:    "Not Found",
+				Status:   http.StatusNotFound,
+				Detail:   "not found: task 1",
+				Instance: "/v1/api/tasks",
+			},
+		},
+		{
+			name: "should not expose the details of internal errors",
+			err:  errors.New("pq: relation tasks does not exist"),
+			want: Problem{
+				Type:     problemTypeBlank,
+				Title:    "Internal Server Error",
+				Status:   http.StatusInternalServerError,
+				Instance: "/v1/api/tasks",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			writeError(response, req, tt.err, "test")
+
+			if response.Code != tt.want.Status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.want.Status, response.Code)
+			}
+			if got := response.Header().Get("Content-Type"); got != problemContentType {
+				t.Errorf("invalid content type, expected: %s, got: %s", problemContentType, got)
+			}
+			var got Problem
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("invalid problem, expected: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// overdueTag marks the entity tags of the overdue tasks
+const overdueTag = "-o"
+
+// taskETag returns the entity tag of the task, derived from its version since the version changes on every update.
+// The overdue flag of the representation is computed when the task is read, so it is added to the tag, which changes
+// when the task becomes overdue although its version does not.
+func taskETag(task *entity.Task) string {
+	if task.IsOverdue(time.Now()) {
+		return fmt.Sprintf(`"%d%s"`, task.Version, overdueTag)
+	}
+	return fmt.Sprintf(`"%d"`, task.Version)
+}
+
+// taskLastModified returns when the representation of the task last changed: its due time when it became overdue after its last update
+func taskLastModified(task *entity.Task) time.Time {
+	if task.IsOverdue(time.Now()) && task.DueAt.After(task.UpdatedAt) {
+		return *task.DueAt
+	}
+	return task.UpdatedAt
+}
+
+// parseIfMatch returns the version of the task the client expects from the If-Match header.
+// 0 is returned when the header is absent or "*", meaning the task is updated whatever its version.
+// The overdue mark is not compared, the task is updated if it still has the version whether it became overdue since or not.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	// If-Match uses the strong comparison, so a weak tag never matches
+	if strings.HasPrefix(header, "W/") {
+		return 0, errs.New(errs.ErrPreconditionFailed, "weak entity tags cannot be used with If-Match")
+	}
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, errs.Validation([]errs.Violation{{Field: "If-Match", Message: "should be a single quoted entity tag or *"}})
+	}
+	version, err := strconv.Atoi(strings.TrimSuffix(unquoted, overdueTag))
+	if err != nil || version < 1 {
+		// the tag was not issued by this service, so it cannot match the current version of the task
+		return 0, errs.New(errs.ErrPreconditionFailed, "entity tag %s does not match the task", header)
+	}
+	return version, nil
+}
+
+// notModified reports whether the representation the client already has is still the current one, following RFC 9110 section 13.2.2:
+// If-None-Match takes precedence and If-Modified-Since is only evaluated when it is absent.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matchesAny(header, etag)
+	}
+	if header := r.Header.Get("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil {
+			return false // an invalid date is ignored as if the header was not sent
+		}
+		// HTTP dates have a one second precision
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// matchesAny checks if the entity tag is in the comma separated list of the If-None-Match header using the weak comparison
+func matchesAny(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// setValidators sets the headers the clients send back to revalidate their copy, no-cache makes them revalidate it on every use
+func setValidators(w http.ResponseWriter, etag string, lastModified time.Time) {
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// writeNotModified tells the client that its copy is still valid, without sending the body again
+func writeNotModified(w http.ResponseWriter, etag string, lastModified time.Time) {
+	setValidators(w, etag, lastModified)
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// writeConditionalJSON writes the JSON representation of v with an entity tag computed from its content, or 304 if the client already has it.
+// Hashing the body makes the tag change whenever anything in the response changes, e.g. a task of the page or the total count.
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, v interface{}, lastModified time.Time) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, r, err, "failed to encode response")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := fmt.Sprintf(`W/"%x"`, sum[:16])
+	if notModified(r, etag, lastModified) {
+		writeNotModified(w, etag, lastModified)
+		return
+	}
+
+	setValidators(w, etag, lastModified)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append(body, '\n'))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write response")
+	}
+}
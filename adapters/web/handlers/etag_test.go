@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr error
+	}{
+		{name: "should accept any version without header", header: "", want: 0},
+		{name: "should accept any version with wildcard", header: "*", want: 0},
+		{name: "should return the version of the tag", header: `"42"`, want: 42},
+		{name: "should return the version of the tag of an overdue task", header: `"42-o"`, want: 42},
+		{name: "should fail because weak tags never match", header: `W/"42"`, wantErr: errs.ErrPreconditionFailed},
+		{name: "should fail because tag was not issued by the service", header: `"abc"`, wantErr: errs.ErrPreconditionFailed},
+		{name: "should fail because tag is not quoted", header: "42", wantErr: errs.ErrValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("parseIfMatch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseIfMatch() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	lastModified := time.Date(2022, 12, 1, 10, 30, 15, 500, time.UTC)
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    bool
+	}{
+		{name: "should be modified without conditional headers", headers: nil, want: false},
+		{name: "should not be modified when entity tag matches", headers: map[string]string{"If-None-Match": `"1", "3"`}, want: true},
+		{name: "should not be modified when weak entity tag matches", headers: map[string]string{"If-None-Match": `W/"3"`}, want: true},
+		{name: "should not be modified with wildcard", headers: map[string]string{"If-None-Match": "*"}, want: true},
+		{name: "should be modified when entity tag differs", headers: map[string]string{"If-None-Match": `"2"`}, want: false},
+		{
+			name:    "should not be modified when not updated since the date",
+			headers: map[string]string{"If-Modified-Since": "Thu, 01 Dec 2022 10:30:15 GMT"},
+			want:    true,
+		},
+		{
+			name:    "should be modified when updated since the date",
+			headers: map[string]string{"If-Modified-Since": "Thu, 01 Dec 2022 10:30:14 GMT"},
+			want:    false,
+		},
+		{
+			name:    "should ignore the date when an entity tag is given",
+			headers: map[string]string{"If-None-Match": `"2"`, "If-Modified-Since": "Thu, 01 Dec 2022 10:30:15 GMT"},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if got := notModified(req, `"3"`, lastModified); got != tt.want {
+				t.Errorf("notModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskETag(t *testing.T) {
+	updatedAt := time.Now().Add(-2 * time.Hour)
+	dueAt := time.Now().Add(-time.Hour)
+	task := entity.Task{Version: 3, UpdatedAt: updatedAt, TaskDescription: entity.TaskDescription{Status: entity.Active, DueAt: &dueAt}}
+
+	// the task became overdue after its last update, its representation changed although its version did not
+	if got := taskETag(&task); got != `"3-o"` {
+		t.Errorf("taskETag() = %s, want %s for an overdue task", got, `"3-o"`)
+	}
+	if got := taskLastModified(&task); !got.Equal(dueAt) {
+		t.Errorf("taskLastModified() = %v, want the due time %v", got, dueAt)
+	}
+
+	task.Status = entity.Closed
+	if got := taskETag(&task); got != `"3"` {
+		t.Errorf("taskETag() = %s, want %s for a closed task", got, `"3"`)
+	}
+	if got := taskLastModified(&task); !got.Equal(updatedAt) {
+		t.Errorf("taskLastModified() = %v, want the update time %v", got, updatedAt)
+	}
+}
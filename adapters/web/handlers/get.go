@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
 	"github.com/gorilla/mux"
@@ -19,29 +20,40 @@ type Get struct {
 // @Description  get a specific task by its ID
 // @Produce json
 // @Param id path string true "task ID"
+// @Param If-None-Match header string false "ETag of the copy of the client, 304 is returned if it is still current"
+// @Param If-Modified-Since header string false "Last-Modified of the copy of the client, only used without If-None-Match"
 // @Success 200 {object} entity.Task
-// @Failure 405,400,500
+// @Success 304 "the copy of the client is still current"
+// @Header 200 {string} ETag "version of the task, to be sent back in If-Match when updating it"
+// @Header 200 {string} Last-Modified "time of the last update of the task"
+// @Failure 405,400,404,500,503
 // @Router /tasks/{id} [get]
 //
 // ServeHTTP implements the handler interface to handle getting a task by ID
 func (g Get) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
 		return
 	}
 	id := mux.Vars(r)["id"]
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		log.Error().Msg("task ID not provided in path")
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
 		return
 	}
-	task, err := g.TaskService.GetByID(id)
+	task, err := g.TaskService.GetByID(r.Context(), id)
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		log.Error().Err(err).Msgf("failed to find task with id %s", id)
+		writeError(w, r, err, fmt.Sprintf("failed to find task with id %s", id))
+		return
+	}
+	// the dashboards polling a task only download it again when it changed
+	etag, modified := taskETag(task), taskLastModified(task)
+	if notModified(r, etag, modified) {
+		writeNotModified(w, etag, modified)
 		return
 	}
 	g.res = *task
+	setValidators(w, etag, modified)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	encoder := json.NewEncoder(w)
@@ -33,7 +33,9 @@ func TestGet_ServeHTTP(t *testing.T) {
 		taskService         = newMockTaskService(getTaskDB)
 		validReq            = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1", nil)
 		methodNotAllowedReq = httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks/1", nil)
+		notModifiedReq      = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1", nil)
 	)
+	notModifiedReq.Header.Set("If-None-Match", taskETag(&testGetTask))
 
 	type want struct {
 		body   entity.Task
@@ -56,6 +58,17 @@ func TestGet_ServeHTTP(t *testing.T) {
 				status: http.StatusOK,
 			},
 		},
+		{
+			name: "should not send item again because client copy is current",
+			fields: Get{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(notModifiedReq, map[string]string{"id": testGetTask.ID}),
+			want: want{
+				body:   entity.Task{},
+				status: http.StatusNotModified,
+			},
+		},
 		{
 			name: "should fail to get task with StatusMethodNotAllowed",
 			fields: Get{
@@ -67,6 +80,17 @@ func TestGet_ServeHTTP(t *testing.T) {
 				status: http.StatusMethodNotAllowed,
 			},
 		},
+		{
+			name: "should fail with StatusNotFound because task does not exist",
+			fields: Get{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(validReq, map[string]string{"id": "non-existing-id"}),
+			want: want{
+				body:   entity.Task{},
+				status: http.StatusNotFound,
+			},
+		},
 		{
 			name: "should fail because no id provided as path parameter",
 			fields: Get{
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// History represents the handler listing the changes made to a task
+type History struct {
+	TaskService interfaces.ITaskService
+}
+
+// HistoryResponse represents a page of the change events of a task, from the most recent one
+type HistoryResponse struct {
+	Events []*entity.TaskEvent `json:"events"`
+	Total  int64               `json:"total"`  // total number of events of the task
+	Limit  int                 `json:"limit"`  // maximum number of events in the page
+	Offset int                 `json:"offset"` // position of the first event of the page
+	Links  PageLinks           `json:"links"`
+}
+
+// @Summary get the history of a task
+// @Description  list who created, updated, deleted or restored the task and which fields were changed, from the most recent change.
+// @Description  The history is kept for the tasks in the trash and after they are purged.
+// @Produce json
+// @Param id path string true "task ID"
+// @Param limit query int false "maximum number of events to return (1-100)" default(20)
+// @Param offset query int false "number of events to skip" default(0)
+// @Success 200 {object} handlers.HistoryResponse
+// @Success 304 "the page held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/history [get]
+//
+// ServeHTTP implements the handler interface to handle listing the changes of a task
+func (h History) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	parser := queryParser{values: r.URL.Query()}
+	query := entity.HistoryQuery{Limit: parser.int("limit"), Offset: parser.int("offset")}
+	if err := errs.Validation(parser.violations); err != nil {
+		writeError(w, r, err, "failed to parse query parameters")
+		return
+	}
+
+	history, err := h.TaskService.History(r.Context(), id, &query)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to get history of task with id %s", id))
+		return
+	}
+
+	res := HistoryResponse{Events: history.Events, Total: history.Total, Limit: history.Limit, Offset: history.Offset,
+		Links: offsetLinks(r.URL, history.Offset, history.Limit, history.Total)}
+	if res.Events == nil {
+		res.Events = []*entity.TaskEvent{}
+	}
+	// events are never modified, so the first page last changed with its most recent event.
+	// Later pages shift when events are added, only their ETag can tell it.
+	var last time.Time
+	if len(history.Events) > 0 && history.Offset == 0 {
+		last = history.Events[0].At
+	}
+	writeConditionalJSON(w, r, res, last)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHistory_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name   string
+		method string
+		target string
+		id     string
+		status int
+	}{
+		{
+			name:   "should list the history of the task successfully",
+			method: "GET",
+			target: "http://localhost:8080/v1/api/tasks/1/history",
+			id:     "1",
+			status: http.StatusOK,
+		},
+		{
+			name:   "should fail with StatusNotFound because task does not exist",
+			method: "GET",
+			target: "http://localhost:8080/v1/api/tasks/non-existing-id/history",
+			id:     "non-existing-id",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should fail because offset is not an integer",
+			method: "GET",
+			target: "http://localhost:8080/v1/api/tasks/1/history?offset=first",
+			id:     "1",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "should fail to list history with StatusMethodNotAllowed",
+			method: "DELETE",
+			target: "http://localhost:8080/v1/api/tasks/1/history",
+			id:     "1",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(tt.method, tt.target, nil), map[string]string{"id": tt.id})
+
+			History{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got HistoryResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Total != 1 || len(got.Events) != 1 || got.Events[0].TaskID != tt.id {
+				t.Errorf("invalid response, expected the creation of task %s, got: %v", tt.id, got)
+			}
+		})
+	}
+}
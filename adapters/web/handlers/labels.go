@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ListLabels represents the handler listing the labels of the catalogue
+type ListLabels struct {
+	LabelService interfaces.ILabelService
+}
+
+// LabelsResponse represents the labels of the catalogue, ordered by name
+type LabelsResponse struct {
+	Labels []*entity.Label `json:"labels"`
+}
+
+// @Summary list the labels
+// @Description  list all the labels of the catalogue ordered by name
+// @Produce json
+// @Success 200 {object} handlers.LabelsResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,500,503
+// @Router /labels [get]
+//
+// ServeHTTP implements the handler interface to handle listing the labels
+func (l ListLabels) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	labels, err := l.LabelService.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list labels")
+		return
+	}
+	res := LabelsResponse{Labels: labels}
+	if res.Labels == nil {
+		res.Labels = []*entity.Label{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// CreateLabel represents the handler adding a label to the catalogue
+type CreateLabel struct {
+	LabelService interfaces.ILabelService
+}
+
+// @Summary create a label
+// @Description  add a new label to the catalogue, names are stored in lower case and must be unique
+// @Produce json
+// @Accept	json
+// @Param   label  body  entity.LabelDescription  true  "New label"
+// @Success 201 {object} entity.Label
+// @Failure 405,400,403,409,500,503
+// @Router /labels [post]
+//
+// ServeHTTP implements the handler interface to handle creating a label
+func (c CreateLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var req entity.LabelDescription
+	if !decodeBody(w, r, &req, "label description") {
+		return
+	}
+	label, err := c.LabelService.Create(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err, "failed to create label")
+		return
+	}
+	writeJSON(w, http.StatusCreated, label)
+}
+
+// GetLabel represents the handler getting a label of the catalogue
+type GetLabel struct {
+	LabelService interfaces.ILabelService
+}
+
+// @Summary get a label
+// @Description  get a label of the catalogue by its ID
+// @Produce json
+// @Param id path string true "label ID"
+// @Success 200 {object} entity.Label
+// @Success 304 "the copy of the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /labels/{id} [get]
+//
+// ServeHTTP implements the handler interface to handle getting a label by ID
+func (g GetLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("label ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "label ID not provided in path")
+		return
+	}
+	label, err := g.LabelService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to find label with id %s", id))
+		return
+	}
+	writeConditionalJSON(w, r, label, label.UpdatedAt)
+}
+
+// UpdateLabel represents the handler renaming or recolouring a label
+type UpdateLabel struct {
+	LabelService interfaces.ILabelService
+}
+
+// @Summary update a label
+// @Description  update a label by ID, the tasks it is attached to keep it
+// @Param id path string true "label ID"
+// @Param   label  body  entity.LabelDescription  true  "New label description"
+// @Produce json
+// @Accept	json
+// @Success 200 {object} entity.Label
+// @Failure 405,400,403,404,409,500,503
+// @Router /labels/{id} [put]
+// @Router /labels/{id} [patch]
+//
+// ServeHTTP implements the handler interface to handle updating the labels
+func (u UpdateLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("label ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "label ID not provided in path")
+		return
+	}
+	var req entity.LabelDescription
+	if !decodeBody(w, r, &req, "label description") {
+		return
+	}
+	var (
+		label *entity.Label
+		err   error
+	)
+	if r.Method == http.MethodPut {
+		label, err = u.LabelService.UpdateFully(r.Context(), &req, id)
+	} else {
+		label, err = u.LabelService.UpdatePartial(r.Context(), &req, id)
+	}
+	if err != nil {
+		writeError(w, r, err, "failed to update label")
+		return
+	}
+	writeJSON(w, http.StatusOK, label)
+}
+
+// DeleteLabel represents the handler removing a label from the catalogue
+type DeleteLabel struct {
+	LabelService interfaces.ILabelService
+}
+
+// @Summary delete a label
+// @Description  remove a label from the catalogue, it is detached from all the tasks
+// @Param id path string true "label ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /labels/{id} [delete]
+//
+// ServeHTTP implements the handler interface to handle deleting the labels
+func (d DeleteLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("label ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "label ID not provided in path")
+		return
+	}
+	if err := d.LabelService.DeleteByID(r.Context(), id); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to delete label with id %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeBody reads the JSON body of the request into v, writing a 400 response naming what was expected when it is not valid
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}, what string) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("failed close body")
+		}
+	}(r.Body)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to decode body")
+		writeStatus(w, r, http.StatusBadRequest, fmt.Sprintf("request body is not a valid JSON %s", what))
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("failed to write response")
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testLabel = &entity.Label{ID: "10", LabelDescription: entity.LabelDescription{Name: "bug", Color: "#ff0000"}}
+
+type mockLabelService struct{}
+
+func (m mockLabelService) Create(ctx context.Context, req *entity.LabelDescription) (*entity.Label, error) {
+	description, err := validation.ValidateLabel(req)
+	if err != nil {
+		return nil, err
+	}
+	if description.Name == testLabel.Name {
+		return nil, errs.New(errs.ErrConflict, "label '%s' already exists", description.Name)
+	}
+	return &entity.Label{ID: "11", LabelDescription: *description}, nil
+}
+
+func (m mockLabelService) List(ctx context.Context) ([]*entity.Label, error) {
+	return []*entity.Label{testLabel}, nil
+}
+
+func (m mockLabelService) GetByID(ctx context.Context, id string) (*entity.Label, error) {
+	if id != testLabel.ID {
+		return nil, errs.New(errs.ErrNotFound, "label with id '%s' not found", id)
+	}
+	return testLabel, nil
+}
+
+func (m mockLabelService) UpdateFully(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	if _, err := m.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	description, err := validation.ValidateLabel(req)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Label{ID: id, LabelDescription: *description}, nil
+}
+
+func (m mockLabelService) UpdatePartial(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	label, err := m.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = validation.ValidatePartialLabel(req); err != nil {
+		return nil, err
+	}
+	updated := *label
+	if req.Name != "" {
+		updated.Name = req.Name
+	}
+	if req.Color != "" {
+		updated.Color = req.Color
+	}
+	return &updated, nil
+}
+
+func (m mockLabelService) DeleteByID(ctx context.Context, id string) error {
+	_, err := m.GetByID(ctx, id)
+	return err
+}
+
+func TestListLabels_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	ListLabels{LabelService: mockLabelService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/labels", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	var got LabelsResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Labels) != 1 || got.Labels[0].Name != testLabel.Name {
+		t.Errorf("invalid response, expected label %s, got: %v", testLabel.Name, got.Labels)
+	}
+
+	response = httptest.NewRecorder()
+	ListLabels{LabelService: mockLabelService{}}.ServeHTTP(response, httptest.NewRequest("POST", "http://localhost:8080/v1/api/labels", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusMethodNotAllowed, response.Code)
+	}
+}
+
+func TestCreateLabel_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "should create the label", body: `{"name": "Urgent", "color": "#FFA500"}`, status: http.StatusCreated},
+		{name: "should fail with StatusConflict because the name is used", body: `{"name": "BUG"}`, status: http.StatusConflict},
+		{name: "should fail because the colour is invalid", body: `{"name": "urgent", "color": "orange"}`, status: http.StatusBadRequest},
+		{name: "should fail because body is not a JSON label", body: `"urgent"`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			CreateLabel{LabelService: mockLabelService{}}.ServeHTTP(response, httptest.NewRequest("POST", "http://localhost:8080/v1/api/labels", strings.NewReader(tt.body)))
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.Label
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "urgent" || got.Color != "#ffa500" {
+				t.Errorf("invalid response, expected the label normalized, got: %v", got)
+			}
+		})
+	}
+}
+
+func TestGetLabel_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should get the label", id: testLabel.ID, status: http.StatusOK},
+		{name: "should fail with StatusNotFound because label does not exist", id: "non-existing-id", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/labels/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			GetLabel{LabelService: mockLabelService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestUpdateLabel_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		body   string
+		status int
+		want   entity.LabelDescription
+	}{
+		{name: "should replace the label", method: "PUT", id: testLabel.ID, body: `{"name": "defect"}`, status: http.StatusOK,
+			want: entity.LabelDescription{Name: "defect"}},
+		{name: "should only change the colour of the label", method: "PATCH", id: testLabel.ID, body: `{"color": "#00ff00"}`, status: http.StatusOK,
+			want: entity.LabelDescription{Name: "bug", Color: "#00ff00"}},
+		{name: "should fail with StatusNotFound because label does not exist", method: "PUT", id: "non-existing-id", body: `{"name": "defect"}`, status: http.StatusNotFound},
+		{name: "should fail with StatusMethodNotAllowed", method: "POST", id: testLabel.ID, body: `{"name": "defect"}`, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/labels/"+tt.id, strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			UpdateLabel{LabelService: mockLabelService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got entity.Label
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.LabelDescription != tt.want {
+				t.Errorf("invalid response, expected: %v, got: %v", tt.want, got.LabelDescription)
+			}
+		})
+	}
+}
+
+func TestDeleteLabel_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should delete the label", id: testLabel.ID, status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because label does not exist", id: "non-existing-id", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/labels/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			DeleteLabel{LabelService: mockLabelService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
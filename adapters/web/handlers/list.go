@@ -1,43 +1,156 @@
 package handlers
 
 import (
-	"encoding/json"
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
-	"github.com/rs/zerolog/log"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/gorilla/mux"
 	"net/http"
+	"time"
 )
 
 // List In case some response type or sth similar is needed in the future
 type List struct {
-	res         []*entity.Task
+	res         ListResponse
 	TaskService interfaces.ITaskService
+	CursorKey   []byte // key used to sign and verify the cursors of the keyset pagination
+}
+
+// ListResponse represents a page of tasks, the links allow the clients to navigate through the pages without computing the offsets
+type ListResponse struct {
+	Tasks  []*entity.Task `json:"tasks"`
+	Total  int64          `json:"total"`  // total number of tasks matching the filters
+	Limit  int            `json:"limit"`  // maximum number of tasks in the page
+	Offset int            `json:"offset"` // position of the first task of the page
+	Links  PageLinks      `json:"links"`
+}
+
+// CursorListResponse represents a page of tasks in cursor mode, the total is not computed to keep walking the table cheap
+type CursorListResponse struct {
+	Tasks      []*entity.Task `json:"tasks"`
+	Limit      int            `json:"limit"`                // maximum number of tasks in the page
+	NextCursor string         `json:"nextCursor,omitempty"` // opaque cursor of the next page, omitted on the last page
+	Links      PageLinks      `json:"links"`
+}
+
+// PageLinks represents the links to the adjacent pages, they are omitted when there is no such page
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
 
 // @Summary list tasks
-// @Description  list the existing tasks
+// @Description  list the existing tasks page by page, optionally filtered and sorted.
+// @Description  In cursor mode the tasks are ordered by creation time and the pages stay consistent while tasks are added or removed.
 // @Produce json
-// @Success 201 {array} entity.Task
-// @Failure 405,400,500
+// @Param pid path string false "project ID, only on the nested route"
+// @Param limit query int false "maximum number of tasks to return (1-100)" default(20)
+// @Param offset query int false "number of tasks to skip" default(0)
+// @Param status query string false "comma separated list of statuses to keep, e.g. new,active"
+// @Param priorityMin query int false "minimum priority (inclusive)"
+// @Param priorityMax query int false "maximum priority (inclusive)"
+// @Param createdAfter query string false "RFC 3339 lower bound (inclusive) of the creation time"
+// @Param createdBefore query string false "RFC 3339 upper bound (exclusive) of the creation time"
+// @Param updatedAfter query string false "RFC 3339 lower bound (inclusive) of the last update time"
+// @Param updatedBefore query string false "RFC 3339 upper bound (exclusive) of the last update time"
+// @Param dueAfter query string false "RFC 3339 lower bound (inclusive) of the due time"
+// @Param dueBefore query string false "RFC 3339 upper bound (exclusive) of the due time"
+// @Param overdue query bool false "true to keep only the open tasks past their due time, false to exclude them"
+// @Param assignee query string false "subject of the assignee of the tasks, me for the caller"
+// @Param unassigned query bool false "true to keep only the tasks assigned to nobody, false to exclude them"
+// @Param labels query string false "comma separated names of labels, e.g. bug,urgent"
+// @Param labelMode query string false "any to keep the tasks having one of the labels, all for the ones having every label" Enums(any, all) default(any)
+// @Param sort query string false "comma separated fields to sort by, prefixed with '-' for descending order, e.g. priority,-createdAt"
+// @Param cursor query string false "switches to cursor mode, empty for the first page then the nextCursor of the previous page"
+// @Param If-None-Match header string false "ETag of the page held by the client, 304 is returned if it is still current"
+// @Param If-Modified-Since header string false "Last-Modified of the page held by the client, only used without If-None-Match"
+// @Success 200 {object} handlers.ListResponse "handlers.CursorListResponse in cursor mode"
+// @Success 304 "the page held by the client is still current"
+// @Header 200 {string} ETag "tag of the content of the page, it changes with the tasks and the total"
+// @Header 200 {string} Last-Modified "time of the most recent update among the tasks of the page"
+// @Failure 405,400,500,503
 // @Router /tasks [get]
+// @Router /projects/{pid}/tasks [get]
 //
-// ServeHTTP implements the handler interface to handle creating the tasks
+// ServeHTTP implements the handler interface to handle listing the tasks, of all the projects or of the one of the path
 func (l List) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.serve(w, r, false)
+}
+
+// serve lists either the live tasks or the ones in the trash, both listings support the same query parameters
+func (l List) serve(w http.ResponseWriter, r *http.Request, trashed bool) {
 	if r.Method != http.MethodGet {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+
+	query, err := parseTaskQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, err, "failed to parse query parameters")
 		return
 	}
+	query.Trashed = trashed
+	// the nested route lists the tasks of a single project
+	query.ProjectID = mux.Vars(r)["pid"]
+	// the presence of the cursor parameter selects the keyset pagination, an empty cursor starts from the first task
+	if r.URL.Query().Has("cursor") {
+		query.Keyset = true
+		if token := r.URL.Query().Get("cursor"); token != "" {
+			query.After, err = decodeCursor(l.CursorKey, token)
+			if err != nil {
+				writeError(w, r, errs.Validation([]errs.Violation{{Field: "cursor", Message: err.Error()}}), "failed to decode cursor")
+				return
+			}
+		}
+	}
 
-	tasks, err := l.TaskService.Get()
-	l.res = tasks
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	encoder := json.NewEncoder(w)
-	err = encoder.Encode(l.res)
+	page, err := l.TaskService.Get(r.Context(), query)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Error().Err(err).Msg("failed to write response")
+		writeError(w, r, err, "failed to list tasks")
 		return
 	}
-	return
+
+	if query.Keyset {
+		l.serveCursorPage(w, r, page)
+		return
+	}
+
+	l.res = ListResponse{Tasks: page.Tasks, Total: page.Total, Limit: page.Limit, Offset: page.Offset}
+	if l.res.Tasks == nil {
+		l.res.Tasks = []*entity.Task{} // an empty page is encoded as [] rather than null
+	}
+	l.res.Links = offsetLinks(r.URL, page.Offset, page.Limit, page.Total)
+
+	writeConditionalJSON(w, r, l.res, lastModified(page.Tasks))
+}
+
+// serveCursorPage writes the page of tasks listed in cursor mode along with the signed cursor of the next page
+func (l List) serveCursorPage(w http.ResponseWriter, r *http.Request, page *entity.TaskList) {
+	res := CursorListResponse{Tasks: page.Tasks, Limit: page.Limit}
+	if res.Tasks == nil {
+		res.Tasks = []*entity.Task{}
+	}
+	if page.Next != nil {
+		token, err := encodeCursor(l.CursorKey, page.Next)
+		if err != nil {
+			writeError(w, r, err, "failed to encode cursor")
+			return
+		}
+		res.NextCursor = token
+		res.Links.Next = cursorLink(r.URL, token)
+	}
+	writeConditionalJSON(w, r, res, lastModified(page.Tasks))
+}
+
+// lastModified returns the time of the most recent change among the tasks of the page.
+// A task leaving the page does not move it, such changes are only detected by the ETag, which is why If-None-Match takes precedence.
+func lastModified(tasks []*entity.Task) time.Time {
+	var last time.Time
+	for _, task := range tasks {
+		if modified := taskLastModified(task); modified.After(last) {
+			last = modified
+		}
+	}
+	return last
 }
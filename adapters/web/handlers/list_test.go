@@ -8,17 +8,22 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestList_ServeHTTP(t *testing.T) {
 	var (
 		taskService         = newMockTaskService(tasksDatabase)
 		validReq            = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks", nil)
+		firstPageReq        = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?limit=1&sort=-priority", nil)
+		lastPageReq         = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?limit=1&offset=1", nil)
+		invalidQueryReq     = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?limit=ten", nil)
 		methodNotAllowedReq = httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks", nil)
+		invalidCursorReq    = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?cursor=forged", nil)
 	)
 
 	type want struct {
-		body   []*entity.Task
+		body   ListResponse
 		status int
 	}
 	tests := []struct {
@@ -34,10 +39,57 @@ func TestList_ServeHTTP(t *testing.T) {
 			},
 			request: validReq,
 			want: want{
-				body:   taskService.tasks,
+				body:   ListResponse{Tasks: taskService.tasks, Total: 2, Limit: 20},
 				status: http.StatusOK,
 			},
 		},
+		{
+			name: "should list first page with link to the next one",
+			fields: List{
+				TaskService: taskService,
+			},
+			request: firstPageReq,
+			want: want{
+				body: ListResponse{Tasks: taskService.tasks[:1], Total: 2, Limit: 1,
+					Links: PageLinks{Next: "/v1/api/tasks?limit=1&offset=1&sort=-priority"}},
+				status: http.StatusOK,
+			},
+		},
+		{
+			name: "should list last page with link to the previous one",
+			fields: List{
+				TaskService: taskService,
+			},
+			request: lastPageReq,
+			want: want{
+				body: ListResponse{Tasks: taskService.tasks[1:], Total: 2, Limit: 1, Offset: 1,
+					Links: PageLinks{Prev: "/v1/api/tasks?limit=1&offset=0"}},
+				status: http.StatusOK,
+			},
+		},
+		{
+			name: "should fail because limit is not an integer",
+			fields: List{
+				TaskService: taskService,
+			},
+			request: invalidQueryReq,
+			want: want{
+				body:   ListResponse{},
+				status: http.StatusBadRequest,
+			},
+		},
+		{
+			name: "should fail because cursor was not issued by the service",
+			fields: List{
+				TaskService: taskService,
+				CursorKey:   []byte("test-key"),
+			},
+			request: invalidCursorReq,
+			want: want{
+				body:   ListResponse{},
+				status: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "should fail to list tasks with StatusMethodNotAllowed",
 			fields: List{
@@ -45,7 +97,7 @@ func TestList_ServeHTTP(t *testing.T) {
 			},
 			request: methodNotAllowedReq,
 			want: want{
-				body:   []*entity.Task{},
+				body:   ListResponse{},
 				status: http.StatusMethodNotAllowed,
 			},
 		},
@@ -58,6 +110,7 @@ func TestList_ServeHTTP(t *testing.T) {
 			l := List{
 				res:         tt.fields.res,
 				TaskService: tt.fields.TaskService,
+				CursorKey:   tt.fields.CursorKey,
 			}
 
 			l.ServeHTTP(response, tt.request)
@@ -89,20 +142,144 @@ func TestList_ServeHTTP(t *testing.T) {
 	}
 }
 
-func readListBody(response *httptest.ResponseRecorder) ([]*entity.Task, error) {
-	if response.Body.Len() == 0 { // this way when method not allowed and we got no body in response, this function would not return an error
-		return []*entity.Task{}, nil
+// cursorTaskDB is not shared with the other handler tests, since they modify their DB
+var cursorTaskDB = []*entity.Task{{
+	ID:              "1",
+	TaskDescription: entity.TaskDescription{Title: "test1"},
+}, {
+	ID:              "2",
+	TaskDescription: entity.TaskDescription{Title: "test2"},
+}, {
+	ID:              "3",
+	TaskDescription: entity.TaskDescription{Title: "test3"},
+}}
+
+func TestList_ServeHTTP_Cursor(t *testing.T) {
+	var (
+		taskService = newMockTaskService(cursorTaskDB)
+		l           = List{TaskService: taskService, CursorKey: []byte("test-key")}
+		seen        []*entity.Task
+		link        = "/v1/api/tasks?cursor=&limit=1"
+	)
+	// walk all the pages by following the next links until the last page is reached
+	for link != "" {
+		response := httptest.NewRecorder()
+		l.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080"+link, nil))
+		if response.Code != http.StatusOK {
+			t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+		}
+		var page CursorListResponse
+		if err := json.Unmarshal(response.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		if page.Limit != 1 || len(page.Tasks) > 1 {
+			t.Fatalf("invalid page size, expected at most 1 task, got: %d", len(page.Tasks))
+		}
+		seen = append(seen, page.Tasks...)
+		link = page.Links.Next
+	}
+	if !reflect.DeepEqual(seen, taskService.tasks) {
+		t.Errorf("invalid tasks walked through, expected: %v, got: %v", taskService.tasks, seen)
+	}
+}
+
+func TestList_ServeHTTP_NotModified(t *testing.T) {
+	l := List{TaskService: newMockTaskService(cursorTaskDB)}
+
+	first := httptest.NewRecorder()
+	l.ServeHTTP(first, httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?status=new", nil))
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status %d with an ETag, got: %d with '%s'", http.StatusOK, first.Code, etag)
+	}
+
+	// the same filtered query is not sent again while the page does not change
+	req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?status=new", nil)
+	req.Header.Set("If-None-Match", etag)
+	second := httptest.NewRecorder()
+	l.ServeHTTP(second, req)
+	if second.Code != http.StatusNotModified || second.Body.Len() != 0 {
+		t.Errorf("expected status %d without body, got: %d", http.StatusNotModified, second.Code)
+	}
+
+	// another page has another entity tag
+	req = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?status=new&limit=1", nil)
+	req.Header.Set("If-None-Match", etag)
+	third := httptest.NewRecorder()
+	l.ServeHTTP(third, req)
+	if third.Code != http.StatusOK {
+		t.Errorf("expected status %d, got: %d", http.StatusOK, third.Code)
+	}
+}
+
+func TestParseTaskQuery(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?status=new,active&status=on-hold&priorityMin=2&sort=priority,-createdAt", nil)
+	min := 2
+	want := &entity.TaskQuery{
+		Statuses:    []entity.Status{entity.New, entity.Active, entity.OnHold},
+		MinPriority: &min,
+		Sort:        []entity.SortField{{Field: "priority"}, {Field: "createdAt", Desc: true}},
+	}
+	got, err := parseTaskQuery(req.URL.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseTaskQuery() got = %v, want %v", got, want)
+	}
+
+	invalid := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?createdAfter=yesterday", nil)
+	if _, err := parseTaskQuery(invalid.URL.Query()); err == nil {
+		t.Errorf("parseTaskQuery() expected error for invalid timestamp")
+	}
+
+	due := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?overdue=true&dueBefore=2022-03-01T00:00:00%2B01:00", nil)
+	got, err = parseTaskQuery(due.URL.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Overdue == nil || !*got.Overdue || got.DueBefore == nil || !got.DueBefore.Equal(time.Date(2022, 2, 28, 23, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseTaskQuery() got = %v, want overdue tasks due before 2022-02-28T23:00:00Z", got)
+	}
+	invalid = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?overdue=maybe", nil)
+	if _, err := parseTaskQuery(invalid.URL.Query()); err == nil {
+		t.Errorf("parseTaskQuery() expected error for invalid boolean")
+	}
+
+	labelled := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?labels=bug,urgent&labelMode=all", nil)
+	got, err = parseTaskQuery(labelled.URL.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Labels, []string{"bug", "urgent"}) || got.LabelMode != entity.AllLabels {
+		t.Errorf("parseTaskQuery() got = %v, want all the labels bug and urgent", got)
+	}
+
+	mine := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks?assignee=me&unassigned=false", nil)
+	got, err = parseTaskQuery(mine.URL.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Assignee != entity.AssigneeMe || got.Unassigned == nil || *got.Unassigned {
+		t.Errorf("parseTaskQuery() got = %v, want the tasks assigned to the caller", got)
+	}
+}
+
+func readListBody(response *httptest.ResponseRecorder) (ListResponse, error) {
+	// this way when the request failed and we got a problem or no body in response, this function would not return an error
+	if response.Body.Len() == 0 || response.Header().Get("Content-Type") == problemContentType {
+		return ListResponse{}, nil
 	}
 
-	var got []*entity.Task
+	var got ListResponse
 
 	responseData, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, err
+		return ListResponse{}, err
 	}
 	err = json.Unmarshal(responseData, &got)
 	if err != nil {
-		return nil, err
+		return ListResponse{}, err
 	}
 	return got, nil
 }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+const (
+	// SessionCookie is the cookie holding the session of the browser clients logged in with the OpenID provider
+	SessionCookie = "tasks_session"
+	// loginStateCookie binds the login to the browser which started it, so that a callback with the code of another browser is refused
+	loginStateCookie = "tasks_login_state"
+	// loginStateTTL is how long the browser has to come back from the OpenID provider
+	loginStateTTL = 10 * time.Minute
+)
+
+// Login represents the handler starting the login of a browser client with the OpenID provider
+type Login struct {
+	SessionService interfaces.ISessionService
+}
+
+// @Summary log in with the OpenID provider
+// @Description  redirect the browser to the OpenID provider, which sends it back to the callback once the user is logged in. returnTo is the path of the service the browser is sent to after the login.
+// @Param returnTo query string false "Path to go back to after the login"
+// @Success 302
+// @Failure 405,500,503
+// @Router /auth/login [get]
+//
+// ServeHTTP implements the handler interface to handle starting a login
+func (l Login) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	state, authURL, err := l.SessionService.BeginLogin(r.Context(), r.URL.Query().Get("returnTo"))
+	if err != nil {
+		writeError(w, r, err, "failed to start login")
+		return
+	}
+	setCookie(w, loginStateCookie, state, time.Now().Add(loginStateTTL))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// LoginCallback represents the handler the OpenID provider sends the browser back to with the authorization code
+type LoginCallback struct {
+	SessionService interfaces.ISessionService
+}
+
+// @Summary complete the login with the OpenID provider
+// @Description  exchange the authorization code for the ID token of the user, open a session in the tasks_session cookie and redirect the browser to the path the login was started for
+// @Param code query string true "Authorization code"
+// @Param state query string true "State of the login"
+// @Success 303
+// @Failure 405,400,401,500,503
+// @Router /auth/callback [get]
+//
+// ServeHTTP implements the handler interface to handle completing a login
+func (c LoginCallback) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		log.Warn().Msgf("login refused by the OpenID provider: %s %s", reason, query.Get("error_description"))
+		writeStatus(w, r, http.StatusUnauthorized, fmt.Sprintf("login refused by the OpenID provider: %s", reason))
+		return
+	}
+	state, code := query.Get("state"), query.Get("code")
+	if state == "" || code == "" {
+		log.Warn().Msg("state or code not provided in query")
+		writeStatus(w, r, http.StatusBadRequest, "state or code not provided in query")
+		return
+	}
+	cookie, err := r.Cookie(loginStateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		writeStatus(w, r, http.StatusUnauthorized, "the login was not started by this browser")
+		return
+	}
+	session, err := c.SessionService.CompleteLogin(r.Context(), state, code)
+	if err != nil {
+		writeError(w, r, err, "failed to complete login")
+		return
+	}
+	clearCookie(w, loginStateCookie)
+	setCookie(w, SessionCookie, session.Token, session.ExpiresAt)
+	http.Redirect(w, r, session.ReturnTo, http.StatusSeeOther)
+}
+
+// Logout represents the handler closing the session of a browser client
+type Logout struct {
+	SessionService interfaces.ISessionService
+}
+
+// @Summary log out
+// @Description  close the session of the tasks_session cookie and redirect the browser to the OpenID provider to close the session there as well
+// @Success 303
+// @Failure 405,500,503
+// @Router /auth/logout [post]
+//
+// ServeHTTP implements the handler interface to handle logging out
+func (l Logout) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var token string
+	if cookie, err := r.Cookie(SessionCookie); err == nil {
+		token = cookie.Value
+	}
+	logoutURL, err := l.SessionService.Logout(r.Context(), token)
+	if err != nil {
+		writeError(w, r, err, "failed to log out")
+		return
+	}
+	clearCookie(w, SessionCookie)
+	http.Redirect(w, r, logoutURL, http.StatusSeeOther)
+}
+
+// setCookie sets a cookie the scripts cannot read and that is only sent over HTTPS. SameSite=Lax lets the browser send it
+// when it comes back from the OpenID provider, but not with the requests other sites make in the background.
+func setCookie(w http.ResponseWriter, name string, value string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearCookie removes a cookie set by setCookie
+func clearCookie(w http.ResponseWriter, name string) {
+	http.SetCookie(w, &http.Cookie{Name: name, Path: "/", MaxAge: -1, HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode})
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// mockSessionService starts the logins with the state "state" and accepts the code "code" for it
+type mockSessionService struct{}
+
+func (m mockSessionService) BeginLogin(ctx context.Context, returnTo string) (string, string, error) {
+	return "state", "https://sso.example.com/authorize?state=state", nil
+}
+
+func (m mockSessionService) CompleteLogin(ctx context.Context, state string, code string) (*entity.IssuedSession, error) {
+	if state != "state" || code != "code" {
+		return nil, errs.New(errs.ErrUnauthenticated, "unknown login")
+	}
+	return &entity.IssuedSession{Token: "session", ExpiresAt: time.Now().Add(time.Hour), ReturnTo: "/v1/api/tasks"}, nil
+}
+
+func (m mockSessionService) Authenticate(ctx context.Context, token string) (principal.Principal, error) {
+	return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "unknown session")
+}
+
+func (m mockSessionService) Logout(ctx context.Context, token string) (string, error) {
+	return "https://sso.example.com/logout", nil
+}
+
+// cookie returns the cookie of the response with the name, or nil
+func cookie(response *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range response.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func TestLogin_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	Login{SessionService: mockSessionService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/auth/login?returnTo=/v1/api/tasks", nil))
+	if response.Code != http.StatusFound {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusFound, response.Code)
+	}
+	if got := response.Header().Get("Location"); got != "https://sso.example.com/authorize?state=state" {
+		t.Errorf("invalid redirect, got: %s", got)
+	}
+	state := cookie(response, loginStateCookie)
+	if state == nil || state.Value != "state" || !state.HttpOnly || !state.Secure || state.SameSite != http.SameSiteLaxMode {
+		t.Errorf("invalid state cookie: %v", state)
+	}
+}
+
+func TestLoginCallback_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		state  string
+		status int
+	}{
+		{name: "should open a session", query: "?state=state&code=code", state: "state", status: http.StatusSeeOther},
+		{name: "should refuse a login started by another browser", query: "?state=state&code=code", state: "other", status: http.StatusUnauthorized},
+		{name: "should refuse a login without state cookie", query: "?state=state&code=code", status: http.StatusUnauthorized},
+		{name: "should refuse a code the service does not accept", query: "?state=state&code=forged", state: "state", status: http.StatusUnauthorized},
+		{name: "should refuse a login refused by the provider", query: "?state=state&error=access_denied", state: "state", status: http.StatusUnauthorized},
+		{name: "should refuse a callback without code", query: "?state=state", state: "state", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/auth/callback"+tt.query, nil)
+			if tt.state != "" {
+				req.AddCookie(&http.Cookie{Name: loginStateCookie, Value: tt.state})
+			}
+			response := httptest.NewRecorder()
+			LoginCallback{SessionService: mockSessionService{}}.ServeHTTP(response, req)
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			session := cookie(response, SessionCookie)
+			if tt.status != http.StatusSeeOther {
+				if session != nil {
+					t.Errorf("the session cookie is set for a refused login")
+				}
+				return
+			}
+			if session == nil || session.Value != "session" || !session.HttpOnly || !session.Secure || session.Path != "/" {
+				t.Errorf("invalid session cookie: %v", session)
+			}
+			if got := response.Header().Get("Location"); got != "/v1/api/tasks" {
+				t.Errorf("invalid redirect, expected: /v1/api/tasks, got: %s", got)
+			}
+		})
+	}
+}
+
+func TestLogout_ServeHTTP(t *testing.T) {
+	req := httptest.NewRequest("POST", "http://localhost:8080/v1/api/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: SessionCookie, Value: "session"})
+	response := httptest.NewRecorder()
+	Logout{SessionService: mockSessionService{}}.ServeHTTP(response, req)
+	if response.Code != http.StatusSeeOther {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusSeeOther, response.Code)
+	}
+	if got := response.Header().Get("Location"); got != "https://sso.example.com/logout" {
+		t.Errorf("invalid redirect, got: %s", got)
+	}
+	if session := cookie(response, SessionCookie); session == nil || session.MaxAge >= 0 {
+		t.Errorf("the session cookie is not cleared: %v", session)
+	}
+
+	response = httptest.NewRecorder()
+	Logout{SessionService: mockSessionService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/auth/logout", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusMethodNotAllowed, response.Code)
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/http"
+	"time"
+)
+
+// Next represents the handler planning the order in which the open tasks can be worked on
+type Next struct {
+	TaskService interfaces.ITaskService
+}
+
+// NextResponse represents the open tasks in the order they can be worked on
+type NextResponse struct {
+	Tasks []*entity.PlannedTask `json:"tasks"`
+}
+
+// @Summary what to work on next
+// @Description  list the open tasks in an order they can be worked on: every task comes after the tasks blocking it,
+// @Description  and otherwise the highest priority first. The tasks that can be worked on right away have no blockedBy.
+// @Produce json
+// @Param limit query int false "maximum number of tasks to return (1-100)" default(20)
+// @Success 200 {object} handlers.NextResponse
+// @Success 304 "the plan held by the client is still current"
+// @Failure 405,400,500,503
+// @Router /tasks/next [get]
+//
+// ServeHTTP implements the handler interface to handle planning the next tasks
+func (n Next) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	parser := queryParser{values: r.URL.Query()}
+	limit := parser.int("limit")
+	if err := errs.Validation(parser.violations); err != nil {
+		writeError(w, r, err, "failed to parse query parameters")
+		return
+	}
+	plan, err := n.TaskService.Next(r.Context(), limit)
+	if err != nil {
+		writeError(w, r, err, "failed to plan the next tasks")
+		return
+	}
+	writeConditionalJSON(w, r, NextResponse{Tasks: plan}, time.Time{})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNext_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name   string
+		target string
+		status int
+		want   int
+	}{
+		{name: "should plan the next tasks", target: "http://localhost:8080/v1/api/tasks/next", status: http.StatusOK, want: 3},
+		{name: "should plan only the requested number of tasks", target: "http://localhost:8080/v1/api/tasks/next?limit=2", status: http.StatusOK, want: 2},
+		{name: "should fail because limit is not an integer", target: "http://localhost:8080/v1/api/tasks/next?limit=all", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			Next{TaskService: taskService}.ServeHTTP(response, httptest.NewRequest("GET", tt.target, nil))
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got NextResponse
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Tasks) != tt.want || got.Tasks[0].Task.ID != "1" || got.Tasks[0].BlockedBy == nil {
+				t.Errorf("invalid response, expected %d tasks starting with task 1, got: %v", tt.want, got.Tasks)
+			}
+		})
+	}
+}
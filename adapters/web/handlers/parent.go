@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// RemoveParent represents the handler making a subtask a top-level task
+type RemoveParent struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary remove the parent of a task
+// @Description  make the subtask a top-level task, the completion of the parent it leaves is rolled up again.
+// @Description  A task is moved under another one by setting its parentId, which PATCH cannot empty. The change is recorded in the history of the task.
+// @Param id path string true "task ID"
+// @Param If-Match header string false "ETag of the task as last seen by the client, the change fails with 412 if it was modified since"
+// @Produce json
+// @Success 200 {object} entity.Task
+// @Header 200 {string} ETag "new version of the task"
+// @Failure 405,400,403,404,412,500,503
+// @Router /tasks/{id}/parent [delete]
+//
+// ServeHTTP implements the handler interface to handle removing the parent of a task
+func (p RemoveParent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	version, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		writeError(w, r, err, "failed to check If-Match header")
+		return
+	}
+	task, err := p.TaskService.RemoveParent(r.Context(), id, version)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to remove parent of task with id %s", id))
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	writeJSON(w, http.StatusOK, task)
+}
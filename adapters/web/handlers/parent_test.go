@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var subtaskDB = []*entity.Task{{ID: "2", Version: 3, TaskDescription: entity.TaskDescription{Title: "test2", ParentID: "1"}}}
+
+func TestRemoveParent_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		id      string
+		ifMatch string
+		status  int
+	}{
+		{name: "should make the subtask a top-level task", method: "DELETE", id: "2", status: http.StatusOK},
+		{name: "should make the subtask a top-level task at its version", method: "DELETE", id: "2", ifMatch: `"3"`, status: http.StatusOK},
+		{name: "should fail because the task moved on", method: "DELETE", id: "2", ifMatch: `"2"`, status: http.StatusPreconditionFailed},
+		{name: "should fail because the task does not exist", method: "DELETE", id: "missing", status: http.StatusNotFound},
+		{name: "should fail because the method is not allowed", method: "PUT", id: "2", status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subtaskDB[0].ParentID = "1"
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/parent", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			response := httptest.NewRecorder()
+			RemoveParent{TaskService: newMockTaskService(subtaskDB)}.ServeHTTP(response, req)
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got entity.Task
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.ParentID != "" {
+				t.Errorf("invalid parent, expected none, got: %s", got.ParentID)
+			}
+			if response.Header().Get("ETag") == "" {
+				t.Errorf("the ETag of the task is missing")
+			}
+		})
+	}
+}
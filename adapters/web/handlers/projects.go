@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// ListProjects represents the handler listing the projects
+type ListProjects struct {
+	ProjectService interfaces.IProjectService
+}
+
+// ProjectsResponse represents the projects, ordered by name
+type ProjectsResponse struct {
+	Projects []*entity.Project `json:"projects"`
+}
+
+// @Summary list the projects
+// @Description  list all the projects ordered by name
+// @Produce json
+// @Success 200 {object} handlers.ProjectsResponse
+// @Success 304 "the list held by the client is still current"
+// @Failure 405,500,503
+// @Router /projects [get]
+//
+// ServeHTTP implements the handler interface to handle listing the projects
+func (l ListProjects) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	projects, err := l.ProjectService.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list projects")
+		return
+	}
+	res := ProjectsResponse{Projects: projects}
+	if res.Projects == nil {
+		res.Projects = []*entity.Project{}
+	}
+	writeConditionalJSON(w, r, res, time.Time{})
+}
+
+// CreateProject represents the handler creating a project
+type CreateProject struct {
+	ProjectService interfaces.IProjectService
+}
+
+// @Summary create a project
+// @Description  create a new project, with the default priority and the allowed statuses of its tasks. Names must be unique.
+// @Produce json
+// @Accept	json
+// @Param   project  body  entity.ProjectDescription  true  "New project"
+// @Success 201 {object} entity.Project
+// @Failure 405,400,403,409,500,503
+// @Router /projects [post]
+//
+// ServeHTTP implements the handler interface to handle creating a project
+func (c CreateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var req entity.ProjectDescription
+	if !decodeBody(w, r, &req, "project description") {
+		return
+	}
+	project, err := c.ProjectService.Create(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err, "failed to create project")
+		return
+	}
+	writeJSON(w, http.StatusCreated, project)
+}
+
+// GetProject represents the handler getting a project
+type GetProject struct {
+	ProjectService interfaces.IProjectService
+}
+
+// @Summary get a project
+// @Description  get a project by its ID
+// @Produce json
+// @Param id path string true "project ID"
+// @Success 200 {object} entity.Project
+// @Success 304 "the copy of the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /projects/{id} [get]
+//
+// ServeHTTP implements the handler interface to handle getting a project by ID
+func (g GetProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("project ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "project ID not provided in path")
+		return
+	}
+	project, err := g.ProjectService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to find project with id %s", id))
+		return
+	}
+	writeConditionalJSON(w, r, project, project.UpdatedAt)
+}
+
+// UpdateProject represents the handler updating a project and its settings
+type UpdateProject struct {
+	ProjectService interfaces.IProjectService
+}
+
+// @Summary update a project
+// @Description  update a project by ID, the new settings apply to the tasks created or updated afterwards
+// @Param id path string true "project ID"
+// @Param   project  body  entity.ProjectDescription  true  "New project description"
+// @Produce json
+// @Accept	json
+// @Success 200 {object} entity.Project
+// @Failure 405,400,403,404,409,500,503
+// @Router /projects/{id} [put]
+// @Router /projects/{id} [patch]
+//
+// ServeHTTP implements the handler interface to handle updating the projects
+func (u UpdateProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("project ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "project ID not provided in path")
+		return
+	}
+	var req entity.ProjectDescription
+	if !decodeBody(w, r, &req, "project description") {
+		return
+	}
+	var (
+		project *entity.Project
+		err     error
+	)
+	if r.Method == http.MethodPut {
+		project, err = u.ProjectService.UpdateFully(r.Context(), &req, id)
+	} else {
+		project, err = u.ProjectService.UpdatePartial(r.Context(), &req, id)
+	}
+	if err != nil {
+		writeError(w, r, err, "failed to update project")
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+// DeleteProject represents the handler deleting a project
+type DeleteProject struct {
+	ProjectService interfaces.IProjectService
+}
+
+// @Summary delete a project
+// @Description  delete a project without tasks, including the ones in the trash. The default project cannot be deleted.
+// @Param id path string true "project ID"
+// @Success 204
+// @Failure 405,400,403,404,409,500,503
+// @Router /projects/{id} [delete]
+//
+// ServeHTTP implements the handler interface to handle deleting the projects
+func (d DeleteProject) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("project ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "project ID not provided in path")
+		return
+	}
+	if err := d.ProjectService.DeleteByID(r.Context(), id); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to delete project with id %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testProject = &entity.Project{ID: entity.DefaultProjectID, ProjectDescription: entity.ProjectDescription{Name: "Default"}}
+
+type mockProjectService struct{}
+
+func (m mockProjectService) Create(ctx context.Context, req *entity.ProjectDescription) (*entity.Project, error) {
+	description, err := validation.ValidateProject(req)
+	if err != nil {
+		return nil, err
+	}
+	if description.Name == testProject.Name {
+		return nil, errs.New(errs.ErrConflict, "project '%s' already exists", description.Name)
+	}
+	return &entity.Project{ID: "11", ProjectDescription: *description}, nil
+}
+
+func (m mockProjectService) List(ctx context.Context) ([]*entity.Project, error) {
+	return []*entity.Project{testProject}, nil
+}
+
+func (m mockProjectService) GetByID(ctx context.Context, id string) (*entity.Project, error) {
+	if id != testProject.ID {
+		return nil, errs.New(errs.ErrNotFound, "project with id '%s' not found", id)
+	}
+	return testProject, nil
+}
+
+func (m mockProjectService) UpdateFully(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	if _, err := m.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	description, err := validation.ValidateProject(req)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.Project{ID: id, ProjectDescription: *description}, nil
+}
+
+func (m mockProjectService) UpdatePartial(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	project, err := m.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = validation.ValidatePartialProject(req); err != nil {
+		return nil, err
+	}
+	updated := *project
+	if req.DefaultPriority != 0 {
+		updated.DefaultPriority = req.DefaultPriority
+	}
+	if len(req.AllowedStatuses) > 0 {
+		updated.AllowedStatuses = req.AllowedStatuses
+	}
+	return &updated, nil
+}
+
+func (m mockProjectService) DeleteByID(ctx context.Context, id string) error {
+	if id == entity.DefaultProjectID {
+		return errs.New(errs.ErrConflict, "the default project cannot be deleted")
+	}
+	return errs.New(errs.ErrNotFound, "project with id '%s' not found", id)
+}
+
+func TestListProjects_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	ListProjects{ProjectService: mockProjectService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/projects", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	var got ProjectsResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Projects) != 1 || got.Projects[0].ID != testProject.ID {
+		t.Errorf("invalid response, expected project %s, got: %v", testProject.ID, got.Projects)
+	}
+}
+
+func TestCreateProject_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   entity.ProjectDescription
+	}{
+		{name: "should create the project", body: `{"name": "Backend", "defaultPriority": 3, "allowedStatuses": ["New", "closed"]}`, status: http.StatusCreated,
+			want: entity.ProjectDescription{Name: "Backend", ProjectSettings: entity.ProjectSettings{DefaultPriority: 3, AllowedStatuses: entity.StatusList{entity.New, entity.Closed}}}},
+		{name: "should fail with StatusConflict because the name is used", body: `{"name": "Default"}`, status: http.StatusConflict},
+		{name: "should fail because a status is unknown", body: `{"name": "Backend", "allowedStatuses": ["done"]}`, status: http.StatusBadRequest},
+		{name: "should fail because body is not a JSON project", body: `"Backend"`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			CreateProject{ProjectService: mockProjectService{}}.ServeHTTP(response, httptest.NewRequest("POST", "http://localhost:8080/v1/api/projects", strings.NewReader(tt.body)))
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.Project
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.ProjectDescription, tt.want) {
+				t.Errorf("invalid response, expected: %v, got: %v", tt.want, got.ProjectDescription)
+			}
+		})
+	}
+}
+
+func TestGetProject_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should get the project", id: testProject.ID, status: http.StatusOK},
+		{name: "should fail with StatusNotFound because project does not exist", id: "non-existing-id", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/projects/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			GetProject{ProjectService: mockProjectService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestUpdateProject_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		body   string
+		status int
+	}{
+		{name: "should replace the project", method: "PUT", id: testProject.ID, body: `{"name": "Main"}`, status: http.StatusOK},
+		{name: "should only change the default priority", method: "PATCH", id: testProject.ID, body: `{"defaultPriority": 2}`, status: http.StatusOK},
+		{name: "should fail because default priority is out of range", method: "PATCH", id: testProject.ID, body: `{"defaultPriority": 20}`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because project does not exist", method: "PUT", id: "non-existing-id", body: `{"name": "Main"}`, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/projects/"+tt.id, strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			UpdateProject{ProjectService: mockProjectService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteProject_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should fail with StatusConflict because the default project cannot be deleted", id: entity.DefaultProjectID, status: http.StatusConflict},
+		{name: "should fail with StatusNotFound because project does not exist", id: "non-existing-id", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/projects/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			DeleteProject{ProjectService: mockProjectService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestList_ServeHTTP_Project(t *testing.T) {
+	l := List{TaskService: newMockTaskService(cursorTaskDB)}
+
+	response := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/projects/default/tasks?limit=1", nil), map[string]string{"pid": entity.DefaultProjectID})
+	l.ServeHTTP(response, req)
+	got, err := readListBody(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the links of the pages stay on the nested route
+	if response.Code != http.StatusOK || got.Links.Next != "/v1/api/projects/default/tasks?limit=1&offset=1" {
+		t.Errorf("expected status %d with a link to the next page of the project, got: %d with %v", http.StatusOK, response.Code, got.Links)
+	}
+
+	response = httptest.NewRecorder()
+	req = mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/projects/non-existing-id/tasks", nil), map[string]string{"pid": "non-existing-id"})
+	l.ServeHTTP(response, req)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusNotFound, response.Code)
+	}
+}
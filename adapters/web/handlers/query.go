@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseTaskQuery reads the listing options from the query parameters of the request.
+// Only the format of the parameters is checked here, their values are validated by the service.
+func parseTaskQuery(values url.Values) (*entity.TaskQuery, error) {
+	var (
+		query  entity.TaskQuery
+		parser = queryParser{values: values}
+	)
+	query.Limit = parser.int("limit")
+	query.Offset = parser.int("offset")
+	for _, status := range splitList(values["status"]) {
+		query.Statuses = append(query.Statuses, entity.Status(status))
+	}
+	query.MinPriority = parser.optionalInt("priorityMin")
+	query.MaxPriority = parser.optionalInt("priorityMax")
+	query.CreatedAfter = parser.time("createdAfter")
+	query.CreatedBefore = parser.time("createdBefore")
+	query.UpdatedAfter = parser.time("updatedAfter")
+	query.UpdatedBefore = parser.time("updatedBefore")
+	query.DueAfter = parser.time("dueAfter")
+	query.DueBefore = parser.time("dueBefore")
+	query.Overdue = parser.optionalBool("overdue")
+	// assignee=me lists the queue of the caller, unassigned=true the tasks nobody took
+	query.Assignee = values.Get("assignee")
+	query.Unassigned = parser.optionalBool("unassigned")
+	// labels=bug,urgent matches the tasks having any of the labels, or all of them with labelMode=all
+	query.Labels = splitList(values["labels"])
+	query.LabelMode = entity.LabelMode(values.Get("labelMode"))
+	// sort=priority,-createdAt orders by ascending priority then by descending creation time
+	for _, field := range splitList(values["sort"]) {
+		if strings.HasPrefix(field, "-") {
+			query.Sort = append(query.Sort, entity.SortField{Field: strings.TrimPrefix(field, "-"), Desc: true})
+		} else {
+			query.Sort = append(query.Sort, entity.SortField{Field: strings.TrimPrefix(field, "+")})
+		}
+	}
+	if err := errs.Validation(parser.violations); err != nil {
+		return nil, err
+	}
+	return &query, nil
+}
+
+// splitList supports both repeated parameters and comma separated values, e.g. status=new&status=active or status=new,active
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// queryParser converts the query parameters to their types, collecting a violation for each malformed one
+type queryParser struct {
+	values     url.Values
+	violations []errs.Violation
+}
+
+func (p *queryParser) int(key string) int {
+	i := p.optionalInt(key)
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func (p *queryParser) optionalInt(key string) *int {
+	value := p.values.Get(key)
+	if value == "" {
+		return nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		p.violations = append(p.violations, errs.Violation{Field: key, Message: "should be an integer"})
+		return nil
+	}
+	return &i
+}
+
+func (p *queryParser) optionalBool(key string) *bool {
+	value := p.values.Get(key)
+	if value == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.violations = append(p.violations, errs.Violation{Field: key, Message: "should be true or false"})
+		return nil
+	}
+	return &b
+}
+
+func (p *queryParser) time(key string) *time.Time {
+	value := p.values.Get(key)
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		p.violations = append(p.violations, errs.Violation{Field: key, Message: "should be an RFC 3339 timestamp"})
+		return nil
+	}
+	return &t
+}
+
+// offsetLinks returns the links to the pages next to the page of a list paginated by offset, when there are such pages
+func offsetLinks(u *url.URL, offset int, limit int, total int64) PageLinks {
+	var links PageLinks
+	if int64(offset+limit) < total {
+		links.Next = pageLink(u, offset+limit)
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links.Prev = pageLink(u, prev)
+	}
+	return links
+}
+
+// pageLink returns the link to the page starting at the given offset, keeping all the other query parameters of the request
+func pageLink(u *url.URL, offset int) string {
+	values := u.Query()
+	values.Set("offset", strconv.Itoa(offset))
+	link := url.URL{Path: u.Path, RawQuery: values.Encode()}
+	return link.String()
+}
+
+// cursorLink returns the link to the page following the given cursor, keeping all the other query parameters of the request
+func cursorLink(u *url.URL, cursor string) string {
+	values := u.Query()
+	values.Set("cursor", cursor)
+	link := url.URL{Path: u.Path, RawQuery: values.Encode()}
+	return link.String()
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// Restore represents the handler taking a deleted task out of the trash
+type Restore struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary restore a deleted task
+// @Description  take a task out of the trash, it is listed again with the values it had when it was deleted
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} entity.Task
+// @Header 200 {string} ETag "new version of the task"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/restore [post]
+//
+// ServeHTTP implements the handler interface to handle restoring the deleted tasks
+func (rs Restore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	task, err := rs.TaskService.Restore(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to restore task with id %s", id))
+		return
+	}
+	w.Header().Set("ETag", taskETag(task))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(task)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write response")
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var deletedAt = time.Date(2022, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+// trashTaskDB is not shared with the other handler tests, since they modify their DB
+var trashTaskDB = []*entity.Task{{
+	ID:              "1",
+	Version:         1,
+	TaskDescription: entity.TaskDescription{Title: "live"},
+}, {
+	ID:              "2",
+	Version:         2,
+	DeletedAt:       &deletedAt,
+	DeletedBy:       "admin",
+	TaskDescription: entity.TaskDescription{Title: "deleted"},
+}}
+
+func TestRestore_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(trashTaskDB)
+	tests := []struct {
+		name     string
+		method   string
+		id       string
+		status   int
+		wantETag string
+	}{
+		{
+			name:     "should restore deleted task successfully",
+			method:   "POST",
+			id:       "2",
+			status:   http.StatusOK,
+			wantETag: `"3"`,
+		},
+		{
+			name:   "should fail with StatusNotFound because task is not in the trash",
+			method: "POST",
+			id:     "1",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "should fail to restore task with StatusMethodNotAllowed",
+			method: "GET",
+			id:     "2",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/restore", nil)
+
+			Restore{TaskService: taskService}.ServeHTTP(response, mux.SetURLVars(req, map[string]string{"id": tt.id}))
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if got := response.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("invalid ETag, expected: %s, got: %s", tt.wantETag, got)
+			}
+			got, err := readTaskResponse(response)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.status == http.StatusOK && (got.ID != tt.id || got.DeletedAt != nil || got.DeletedBy != "") {
+				t.Errorf("invalid response, expected restored task %s, got: %v", tt.id, got)
+			}
+		})
+	}
+}
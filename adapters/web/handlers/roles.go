@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// ListRoles represents the handler listing the roles granted in the tenant, it is reserved to the admins
+type ListRoles struct {
+	RoleService interfaces.IRoleService
+}
+
+// RolesResponse represents the roles granted in the tenant, ordered by subject
+type RolesResponse struct {
+	Roles []*entity.RoleAssignment `json:"roles"`
+}
+
+// @Summary list the roles
+// @Description  list the roles granted in the tenant ordered by subject, the ones without projectId apply to all the projects
+// @Produce json
+// @Success 200 {object} handlers.RolesResponse
+// @Failure 405,403,500,503
+// @Router /roles [get]
+//
+// ServeHTTP implements the handler interface to handle listing the roles
+func (l ListRoles) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	roles, err := l.RoleService.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list roles")
+		return
+	}
+	res := RolesResponse{Roles: roles}
+	if res.Roles == nil {
+		res.Roles = []*entity.RoleAssignment{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// AssignRole represents the handler granting a role to a subject, on all the projects or on the project of the path
+type AssignRole struct {
+	RoleService interfaces.IRoleService
+}
+
+// @Summary grant a role
+// @Description  grant a role to a subject (a username or the subject of a bearer token) on all the projects, or on a single project which takes precedence, replacing its previous role there
+// @Produce json
+// @Accept	json
+// @Param subject path string true "subject"
+// @Param id path string false "project ID"
+// @Param   role  body  entity.RoleGrant  true  "viewer, editor or admin"
+// @Success 200 {object} entity.RoleAssignment
+// @Failure 405,400,403,404,500,503
+// @Router /roles/{subject} [put]
+// @Router /projects/{id}/roles/{subject} [put]
+//
+// ServeHTTP implements the handler interface to handle granting the roles
+func (a AssignRole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	subject := mux.Vars(r)["subject"]
+	if subject == "" {
+		log.Warn().Msg("subject not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "subject not provided in path")
+		return
+	}
+	var req entity.RoleGrant
+	if !decodeBody(w, r, &req, "role grant") {
+		return
+	}
+	assignment, err := a.RoleService.Assign(r.Context(), &entity.RoleAssignment{Subject: subject, ProjectID: mux.Vars(r)["id"], Role: req.Role})
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to grant role to %s", subject))
+		return
+	}
+	writeJSON(w, http.StatusOK, assignment)
+}
+
+// RevokeRole represents the handler removing the role of a subject, on all the projects or on the project of the path
+type RevokeRole struct {
+	RoleService interfaces.IRoleService
+}
+
+// @Summary revoke a role
+// @Description  remove the role of a subject on all the projects, or on a single project, the subject then gets its other role or the default one
+// @Param subject path string true "subject"
+// @Param id path string false "project ID"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /roles/{subject} [delete]
+// @Router /projects/{id}/roles/{subject} [delete]
+//
+// ServeHTTP implements the handler interface to handle revoking the roles
+func (d RevokeRole) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	subject := mux.Vars(r)["subject"]
+	if subject == "" {
+		log.Warn().Msg("subject not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "subject not provided in path")
+		return
+	}
+	if err := d.RoleService.Revoke(r.Context(), subject, mux.Vars(r)["id"]); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to revoke role of %s", subject))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
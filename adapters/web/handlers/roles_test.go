@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testRole = &entity.RoleAssignment{Subject: "alice", Role: entity.Editor}
+
+// mockRoleService only lets the requests carrying an administrator principal manage the roles, the project "p1" is the only one
+type mockRoleService struct{}
+
+func (m mockRoleService) RoleOf(ctx context.Context, projectID string) (entity.Role, error) {
+	return entity.Viewer, nil
+}
+
+func (m mockRoleService) List(ctx context.Context) ([]*entity.RoleAssignment, error) {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return []*entity.RoleAssignment{testRole}, nil
+}
+
+func (m mockRoleService) Assign(ctx context.Context, req *entity.RoleAssignment) (*entity.RoleAssignment, error) {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if req.ProjectID != "" && req.ProjectID != "p1" {
+		return nil, errs.New(errs.ErrNotFound, "project '%s' not found", req.ProjectID)
+	}
+	return validation.ValidateRoleAssignment(req)
+}
+
+func (m mockRoleService) Revoke(ctx context.Context, subject string, projectID string) error {
+	if err := (mockUserService{}).requireAdmin(ctx); err != nil {
+		return err
+	}
+	if subject != testRole.Subject || projectID != testRole.ProjectID {
+		return errs.New(errs.ErrNotFound, "no role")
+	}
+	return nil
+}
+
+func TestListRoles_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	ListRoles{RoleService: mockRoleService{}}.ServeHTTP(response, asAdmin(httptest.NewRequest("GET", "http://localhost:8080/v1/api/roles", nil)))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	var got RolesResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Roles) != 1 || *got.Roles[0] != *testRole {
+		t.Errorf("invalid response, expected %v, got: %v", testRole, got.Roles)
+	}
+
+	response = httptest.NewRecorder()
+	ListRoles{RoleService: mockRoleService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/roles", nil))
+	if response.Code != http.StatusForbidden {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusForbidden, response.Code)
+	}
+}
+
+func TestAssignRole_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		vars   map[string]string
+		body   string
+		status int
+		want   entity.RoleAssignment
+	}{
+		{name: "should grant the role on all the projects", vars: map[string]string{"subject": "bob"}, body: `{"role": "Admin"}`, status: http.StatusOK,
+			want: entity.RoleAssignment{Subject: "bob", Role: entity.Admin}},
+		{name: "should grant the role on the project", vars: map[string]string{"subject": "bob", "id": "p1"}, body: `{"role": "viewer"}`, status: http.StatusOK,
+			want: entity.RoleAssignment{Subject: "bob", ProjectID: "p1", Role: entity.Viewer}},
+		{name: "should fail with StatusNotFound because the project does not exist", vars: map[string]string{"subject": "bob", "id": "p2"}, body: `{"role": "viewer"}`, status: http.StatusNotFound},
+		{name: "should fail because the role is unknown", vars: map[string]string{"subject": "bob"}, body: `{"role": "owner"}`, status: http.StatusBadRequest},
+		{name: "should fail because body is not a JSON role grant", vars: map[string]string{"subject": "bob"}, body: `"admin"`, status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("PUT", "http://localhost:8080/v1/api/roles/"+tt.vars["subject"], strings.NewReader(tt.body)), tt.vars)
+
+			AssignRole{RoleService: mockRoleService{}}.ServeHTTP(response, asAdmin(req))
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var got entity.RoleAssignment
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("invalid response, expected: %v, got: %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRevokeRole_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		vars   map[string]string
+		status int
+	}{
+		{name: "should revoke the role", vars: map[string]string{"subject": "alice"}, status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because the subject has no role on the project", vars: map[string]string{"subject": "alice", "id": "p1"}, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/roles/"+tt.vars["subject"], nil), tt.vars)
+
+			RevokeRole{RoleService: mockRoleService{}}.ServeHTTP(response, asAdmin(req))
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
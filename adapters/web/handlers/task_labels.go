@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// AttachLabel represents the handler attaching a label of the catalogue to a task
+type AttachLabel struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary attach a label to a task
+// @Description  attach a label of the catalogue to a task, attaching a label that is already attached does nothing
+// @Param id path string true "task ID"
+// @Param labelId path string true "label ID"
+// @Success 204
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/labels/{labelId} [put]
+//
+// ServeHTTP implements the handler interface to handle attaching a label to a task
+func (a AttachLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, labelID := mux.Vars(r)["id"], mux.Vars(r)["labelId"]
+	if id == "" || labelID == "" {
+		log.Warn().Msg("task or label ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and label IDs not provided in path")
+		return
+	}
+	if err := a.TaskService.AddLabel(r.Context(), id, labelID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to attach label %s to task with id %s", labelID, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DetachLabel represents the handler detaching a label from a task
+type DetachLabel struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary detach a label from a task
+// @Description  detach a label from a task, the label stays in the catalogue
+// @Param id path string true "task ID"
+// @Param labelId path string true "label ID"
+// @Success 204
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/labels/{labelId} [delete]
+//
+// ServeHTTP implements the handler interface to handle detaching a label from a task
+func (d DetachLabel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id, labelID := mux.Vars(r)["id"], mux.Vars(r)["labelId"]
+	if id == "" || labelID == "" {
+		log.Warn().Msg("task or label ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task and label IDs not provided in path")
+		return
+	}
+	if err := d.TaskService.RemoveLabel(r.Context(), id, labelID); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to detach label %s from task with id %s", labelID, id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
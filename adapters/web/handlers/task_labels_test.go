@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAttachLabel_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name    string
+		method  string
+		id      string
+		labelID string
+		status  int
+	}{
+		{name: "should attach the label to the task", method: "PUT", id: "1", labelID: testLabel.ID, status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because label does not exist", method: "PUT", id: "1", labelID: "non-existing-id", status: http.StatusNotFound},
+		{name: "should fail with StatusNotFound because task does not exist", method: "PUT", id: "non-existing-id", labelID: testLabel.ID, status: http.StatusNotFound},
+		{name: "should fail with StatusMethodNotAllowed", method: "POST", id: "1", labelID: testLabel.ID, status: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest(tt.method, "http://localhost:8080/v1/api/tasks/"+tt.id+"/labels/"+tt.labelID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id, "labelId": tt.labelID})
+
+			AttachLabel{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestDetachLabel_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(cursorTaskDB)
+	tests := []struct {
+		name    string
+		id      string
+		labelID string
+		status  int
+	}{
+		{name: "should detach the label from the task", id: "1", labelID: testLabel.ID, status: http.StatusNoContent},
+		{name: "should fail with StatusNotFound because label is not attached", id: "1", labelID: "non-existing-id", status: http.StatusNotFound},
+		{name: "should fail with StatusBadRequest because label ID is missing", id: "1", labelID: "", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "http://localhost:8080/v1/api/tasks/"+tt.id+"/labels/"+tt.labelID, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id, "labelId": tt.labelID})
+
+			DetachLabel{TaskService: taskService}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
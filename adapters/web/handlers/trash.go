@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"net/http"
+)
+
+// Trash represents the handler listing the deleted tasks that have not been purged yet
+type Trash struct {
+	TaskService interfaces.ITaskService
+	CursorKey   []byte // key used to sign and verify the cursors of the keyset pagination
+}
+
+// @Summary list deleted tasks
+// @Description  list the tasks in the trash page by page, they can be restored until they are purged after the retention period.
+// @Description  The same filters, sorting and pagination as for listing the tasks are supported, sort=-deletedAt lists the latest deleted first.
+// @Produce json
+// @Param limit query int false "maximum number of tasks to return (1-100)" default(20)
+// @Param offset query int false "number of tasks to skip" default(0)
+// @Param status query string false "comma separated list of statuses to keep, e.g. new,active"
+// @Param sort query string false "comma separated fields to sort by, prefixed with '-' for descending order, e.g. -deletedAt"
+// @Param cursor query string false "switches to cursor mode, empty for the first page then the nextCursor of the previous page"
+// @Success 200 {object} handlers.ListResponse "handlers.CursorListResponse in cursor mode"
+// @Success 304 "the page held by the client is still current"
+// @Failure 405,400,500,503
+// @Router /tasks/trash [get]
+//
+// ServeHTTP implements the handler interface to handle listing the deleted tasks
+func (t Trash) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	List{TaskService: t.TaskService, CursorKey: t.CursorKey}.serve(w, r, true)
+}
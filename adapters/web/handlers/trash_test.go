@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrash_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(trashTaskDB)
+	response := httptest.NewRecorder()
+
+	Trash{TaskService: taskService}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/trash", nil))
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	got, err := readListBody(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Total != 1 || len(got.Tasks) != 1 || got.Tasks[0].ID != "2" || got.Tasks[0].DeletedBy != "admin" {
+		t.Errorf("invalid response, expected only the deleted task, got: %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"time"
+)
+
+// Tree represents the handler getting a task along with all its descendants
+type Tree struct {
+	TaskService interfaces.ITaskService
+}
+
+// @Summary get the tree of a task
+// @Description  get a task with its subtasks, their own subtasks and so on, each level ordered by creation. The ones in the trash are left out.
+// @Produce json
+// @Param id path string true "task ID"
+// @Success 200 {object} entity.TaskNode
+// @Success 304 "the tree held by the client is still current"
+// @Failure 405,400,404,500,503
+// @Router /tasks/{id}/tree [get]
+//
+// ServeHTTP implements the handler interface to handle getting the tree of a task
+func (t Tree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	tree, err := t.TaskService.Tree(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to get tree of task with id %s", id))
+		return
+	}
+	writeConditionalJSON(w, r, tree, time.Time{})
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTree_ServeHTTP(t *testing.T) {
+	taskService := newMockTaskService(hierarchyTaskDB)
+
+	response := httptest.NewRecorder()
+	req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/epic/tree", nil), map[string]string{"id": "epic"})
+	Tree{TaskService: taskService}.ServeHTTP(response, req)
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	var got entity.TaskNode
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Task.ID != "epic" || len(got.Children) != 2 || got.Children[0].Task.ID != "story" ||
+		len(got.Children[0].Children) != 1 || got.Children[0].Children[0].Task.ID != "subtask" || len(got.Children[1].Children) != 0 {
+		t.Errorf("invalid response, expected the tree of epic with story/subtask and bug, got: %v", got)
+	}
+
+	// the client already has the current tree
+	etag := response.Header().Get("ETag")
+	req = mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/epic/tree", nil), map[string]string{"id": "epic"})
+	req.Header.Set("If-None-Match", etag)
+	response = httptest.NewRecorder()
+	Tree{TaskService: taskService}.ServeHTTP(response, req)
+	if response.Code != http.StatusNotModified {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusNotModified, response.Code)
+	}
+
+	response = httptest.NewRecorder()
+	req = mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/non-existing-id/tree", nil), map[string]string{"id": "non-existing-id"})
+	Tree{TaskService: taskService}.ServeHTTP(response, req)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusNotFound, response.Code)
+	}
+}
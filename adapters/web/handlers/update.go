@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
 	"github.com/gorilla/mux"
@@ -21,10 +22,12 @@ type Update struct {
 // @Description  update a task by ID
 // @Param id path string true "task ID"
 // @Param   task  body  entity.TaskDescription  true  "New task description"
+// @Param If-Match header string false "ETag of the task as last seen by the client, the update fails with 412 if it was modified since"
 // @Produce json
 // @Accept	json
 // @Success 200 {object} entity.Task
-// @Failure 405,400,500
+// @Header 200 {string} ETag "new version of the task"
+// @Failure 405,400,404,409,412,500,503
 // @Router /tasks/{id} [put]
 // @Router /tasks/{id} [patch]
 //
@@ -32,7 +35,7 @@ type Update struct {
 func (u Update) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Both requests have the same path, so they should have the same handler depending on the request method
 	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
-		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
 		return
 	}
 	decoder := json.NewDecoder(r.Body)
@@ -44,29 +47,35 @@ func (u Update) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	}(r.Body)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		log.Error().Err(err).Msg("failed to decode body")
+		log.Warn().Err(err).Msg("failed to decode body")
+		writeStatus(w, r, http.StatusBadRequest, "request body is not a valid JSON task description")
 		return
 	}
 	id := mux.Vars(r)["id"]
 	if id == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		log.Error().Msg("task ID not provided in path")
+		log.Warn().Msg("task ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "task ID not provided in path")
+		return
+	}
+	// with If-Match the task is only updated if nobody else modified it since the client got it
+	version, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		writeError(w, r, err, "failed to check If-Match header")
 		return
 	}
 	var response *entity.Task
 	if r.Method == http.MethodPut { //PUT here
-		response, err = u.TaskService.UpdateFully(&u.req, id)
+		response, err = u.TaskService.UpdateFully(r.Context(), &u.req, id, version)
 	} else { //PATCH here
-		response, err = u.TaskService.UpdatePartial(&u.req, id)
+		response, err = u.TaskService.UpdatePartial(r.Context(), &u.req, id, version)
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Error().Err(err).Msg("failed to update task")
+		writeError(w, r, err, "failed to update task")
 		return
 	}
 	u.res = *response
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", taskETag(response))
 	w.WriteHeader(http.StatusOK)
 	encoder := json.NewEncoder(w)
 	err = encoder.Encode(u.res)
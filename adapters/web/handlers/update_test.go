@@ -28,6 +28,14 @@ var updateTaskDB = []*entity.Task{{
 		Description: "test2",
 		Priority:    0,
 	},
+}, {
+	ID:      "3",
+	Version: 3,
+	TaskDescription: entity.TaskDescription{
+		Title:       "test3",
+		Description: "test3",
+		Priority:    0,
+	},
 }}
 
 var testUpdateTask entity.Task = entity.Task{
@@ -48,11 +56,18 @@ func TestUpdate_ServeHTTP(t *testing.T) {
 		methodNotAllowedReq = httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks/1", bytes.NewReader(reqBodyToJson(testUpdateTask.TaskDescription, t)))
 		invalidBodyReq      = httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks/1", strings.NewReader("no-json"))
 		invalidPathParamReq = httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks", strings.NewReader("no-json"))
+		matchingReq         = httptest.NewRequest("PUT", "http://localhost:8080/v1/api/tasks/3", bytes.NewReader(reqBodyToJson(testUpdateTask.TaskDescription, t)))
+		outdatedReq         = httptest.NewRequest("PATCH", "http://localhost:8080/v1/api/tasks/3", bytes.NewReader(reqBodyToJson(testUpdateTask.TaskDescription, t)))
+		malformedIfMatchReq = httptest.NewRequest("PATCH", "http://localhost:8080/v1/api/tasks/3", bytes.NewReader(reqBodyToJson(testUpdateTask.TaskDescription, t)))
 	)
+	matchingReq.Header.Set("If-Match", `"3"`)
+	outdatedReq.Header.Set("If-Match", `"2"`)
+	malformedIfMatchReq.Header.Set("If-Match", "3")
 
 	type want struct {
 		body   entity.Task
 		status int
+		etag   string
 	}
 	tests := []struct {
 		name    string
@@ -82,6 +97,40 @@ func TestUpdate_ServeHTTP(t *testing.T) {
 				status: http.StatusOK,
 			},
 		},
+		{
+			name: "should update item because If-Match matches its version",
+			fields: Update{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(matchingReq, map[string]string{"id": "3"}),
+			want: want{
+				body:   entity.Task{ID: "3", Version: 3, TaskDescription: testUpdateTask.TaskDescription},
+				status: http.StatusOK,
+				etag:   `"3"`,
+			},
+		},
+		{
+			name: "should fail with StatusPreconditionFailed because item was modified since",
+			fields: Update{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(outdatedReq, map[string]string{"id": "3"}),
+			want: want{
+				body:   entity.Task{},
+				status: http.StatusPreconditionFailed,
+			},
+		},
+		{
+			name: "should fail because If-Match is not a quoted entity tag",
+			fields: Update{
+				TaskService: taskService,
+			},
+			request: mux.SetURLVars(malformedIfMatchReq, map[string]string{"id": "3"}),
+			want: want{
+				body:   entity.Task{},
+				status: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "should fail to create tasks with StatusMethodNotAllowed",
 			fields: Update{
@@ -145,6 +194,9 @@ func TestUpdate_ServeHTTP(t *testing.T) {
 			if res.StatusCode != tt.want.status {
 				t.Errorf("invalid status code, expected: %d, got: %d", tt.want.status, res.StatusCode)
 			}
+			if tt.want.etag != "" && res.Header.Get("ETag") != tt.want.etag {
+				t.Errorf("invalid ETag, expected: %s, got: %s", tt.want.etag, res.Header.Get("ETag"))
+			}
 
 			got, err := readTaskResponse(response)
 			if err != nil {
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// ListUsers represents the handler listing the users of the tenant, it is reserved to the administrators
+type ListUsers struct {
+	UserService interfaces.IUserService
+}
+
+// UsersResponse represents the users of the tenant, ordered by username
+type UsersResponse struct {
+	Users []*entity.User `json:"users"`
+}
+
+// @Summary list the users
+// @Description  list the users of the tenant of the administrator ordered by username, their passwords are never returned
+// @Produce json
+// @Success 200 {object} handlers.UsersResponse
+// @Failure 405,403,500,503
+// @Router /users [get]
+//
+// ServeHTTP implements the handler interface to handle listing the users
+func (l ListUsers) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	users, err := l.UserService.List(r.Context())
+	if err != nil {
+		writeError(w, r, err, "failed to list users")
+		return
+	}
+	res := UsersResponse{Users: users}
+	if res.Users == nil {
+		res.Users = []*entity.User{}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// CreateUser represents the handler creating a user in the tenant of the administrator
+type CreateUser struct {
+	UserService interfaces.IUserService
+}
+
+// @Summary create a user
+// @Description  create a user in the tenant of the administrator, usernames are stored in lower case and must be unique
+// @Produce json
+// @Accept	json
+// @Param   user  body  entity.NewUser  true  "New user"
+// @Success 201 {object} entity.User
+// @Failure 405,400,403,409,500,503
+// @Router /users [post]
+//
+// ServeHTTP implements the handler interface to handle creating a user
+func (c CreateUser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	var req entity.NewUser
+	if !decodeBody(w, r, &req, "user") {
+		return
+	}
+	user, err := c.UserService.Create(r.Context(), &req)
+	if err != nil {
+		writeError(w, r, err, "failed to create user")
+		return
+	}
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// GetUser represents the handler getting a user of the tenant
+type GetUser struct {
+	UserService interfaces.IUserService
+}
+
+// @Summary get a user
+// @Description  get a user of the tenant of the administrator by its ID
+// @Produce json
+// @Param id path string true "user ID"
+// @Success 200 {object} entity.User
+// @Failure 405,400,403,404,500,503
+// @Router /users/{id} [get]
+//
+// ServeHTTP implements the handler interface to handle getting a user by ID
+func (g GetUser) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("user ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "user ID not provided in path")
+		return
+	}
+	user, err := g.UserService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to find user with id %s", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// SetUserDisabled represents the handler disabling or enabling a user, it revokes the access of the user without changing the other ones
+type SetUserDisabled struct {
+	UserService interfaces.IUserService
+	Disabled    bool // whether the handler disables the user or enables it again
+}
+
+// @Summary disable or enable a user
+// @Description  disable a user of the tenant, it cannot authenticate anymore until it is enabled again. Administrators cannot disable themselves.
+// @Produce json
+// @Param id path string true "user ID"
+// @Success 200 {object} entity.User
+// @Failure 405,400,403,404,409,500,503
+// @Router /users/{id}/disable [post]
+// @Router /users/{id}/enable [post]
+//
+// ServeHTTP implements the handler interface to handle disabling and enabling the users
+func (s SetUserDisabled) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("user ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "user ID not provided in path")
+		return
+	}
+	user, err := s.UserService.SetDisabled(r.Context(), id, s.Disabled)
+	if err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to update user with id %s", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// ResetPassword represents the handler replacing the password of a user
+type ResetPassword struct {
+	UserService interfaces.IUserService
+}
+
+// @Summary reset the password of a user
+// @Description  replace the password of a user of the tenant, the previous one is not accepted anymore
+// @Accept	json
+// @Param id path string true "user ID"
+// @Param   password  body  entity.PasswordReset  true  "New password"
+// @Success 204
+// @Failure 405,400,403,404,500,503
+// @Router /users/{id}/password [put]
+//
+// ServeHTTP implements the handler interface to handle resetting the passwords
+func (p ResetPassword) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		log.Warn().Msg("user ID not provided in path")
+		writeStatus(w, r, http.StatusBadRequest, "user ID not provided in path")
+		return
+	}
+	var req entity.PasswordReset
+	if !decodeBody(w, r, &req, "password reset") {
+		return
+	}
+	if err := p.UserService.ResetPassword(r.Context(), id, &req); err != nil {
+		writeError(w, r, err, fmt.Sprintf("failed to reset the password of user with id %s", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
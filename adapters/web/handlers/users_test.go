@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testUser = &entity.User{ID: "20", PasswordHash: "hash", UserDescription: entity.UserDescription{Username: "alice"}}
+
+// mockUserService only lets the requests carrying an administrator principal manage the users
+type mockUserService struct{}
+
+func (m mockUserService) Authenticate(ctx context.Context, username string, password string) (principal.Principal, error) {
+	return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "wrong username or password")
+}
+
+func (m mockUserService) Create(ctx context.Context, req *entity.NewUser) (*entity.User, error) {
+	if err := m.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	req, err := validation.ValidateNewUser(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Username == testUser.Username {
+		return nil, errs.New(errs.ErrConflict, "username '%s' is already used", req.Username)
+	}
+	return &entity.User{ID: "21", PasswordHash: "hash", UserDescription: req.UserDescription}, nil
+}
+
+func (m mockUserService) List(ctx context.Context) ([]*entity.User, error) {
+	if err := m.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return []*entity.User{testUser}, nil
+}
+
+func (m mockUserService) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	if err := m.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if id != testUser.ID {
+		return nil, errs.New(errs.ErrNotFound, "user with id '%s' not found", id)
+	}
+	return testUser, nil
+}
+
+func (m mockUserService) SetDisabled(ctx context.Context, id string, disabled bool) (*entity.User, error) {
+	user, err := m.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	updated := *user
+	updated.Disabled = disabled
+	return &updated, nil
+}
+
+func (m mockUserService) ResetPassword(ctx context.Context, id string, req *entity.PasswordReset) error {
+	if _, err := m.GetByID(ctx, id); err != nil {
+		return err
+	}
+	if err := validation.ValidatePassword(req.Password); err != nil {
+		return errs.Validation([]errs.Violation{{Field: "password", Message: err.Error()}})
+	}
+	return nil
+}
+
+func (m mockUserService) requireAdmin(ctx context.Context) error {
+	if p, _ := principal.FromContext(ctx); !p.Admin {
+		return errs.New(errs.ErrForbidden, "only the administrators can manage the users")
+	}
+	return nil
+}
+
+// asAdmin returns the request made by an administrator
+func asAdmin(r *http.Request) *http.Request {
+	return r.WithContext(principal.NewContext(r.Context(), principal.Principal{Subject: "admin", Tenant: "acme", Admin: true}))
+}
+
+func TestListUsers_ServeHTTP(t *testing.T) {
+	response := httptest.NewRecorder()
+	ListUsers{UserService: mockUserService{}}.ServeHTTP(response, asAdmin(httptest.NewRequest("GET", "http://localhost:8080/v1/api/users", nil)))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	if strings.Contains(response.Body.String(), "hash") {
+		t.Errorf("invalid response, the password hashes must not be returned, got: %s", response.Body.String())
+	}
+	var got UsersResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Users) != 1 || got.Users[0].Username != testUser.Username {
+		t.Errorf("invalid response, expected user %s, got: %v", testUser.Username, got.Users)
+	}
+
+	response = httptest.NewRecorder()
+	ListUsers{UserService: mockUserService{}}.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/users", nil))
+	if response.Code != http.StatusForbidden {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusForbidden, response.Code)
+	}
+}
+
+func TestCreateUser_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		admin  bool
+		status int
+	}{
+		{name: "should create the user", body: `{"username": "Bob", "password": "bob-password"}`, admin: true, status: http.StatusCreated},
+		{name: "should fail with StatusConflict because the username is used", body: `{"username": "alice", "password": "alice-password"}`, admin: true, status: http.StatusConflict},
+		{name: "should fail because the password is too short", body: `{"username": "bob", "password": "bob"}`, admin: true, status: http.StatusBadRequest},
+		{name: "should fail with StatusForbidden because the caller is not an administrator", body: `{"username": "bob", "password": "bob-password"}`, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "http://localhost:8080/v1/api/users", strings.NewReader(tt.body))
+			if tt.admin {
+				req = asAdmin(req)
+			}
+			CreateUser{UserService: mockUserService{}}.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+			var got entity.User
+			if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Username != "bob" || strings.Contains(response.Body.String(), "password") {
+				t.Errorf("invalid response, expected bob without password, got: %s", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetUser_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		status int
+	}{
+		{name: "should get the user", id: testUser.ID, status: http.StatusOK},
+		{name: "should fail with StatusNotFound because user does not exist", id: "non-existing-id", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest("GET", "http://localhost:8080/v1/api/users/"+tt.id, nil), map[string]string{"id": tt.id})
+
+			GetUser{UserService: mockUserService{}}.ServeHTTP(response, asAdmin(req))
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
+
+func TestSetUserDisabled_ServeHTTP(t *testing.T) {
+	for _, disabled := range []bool{true, false} {
+		response := httptest.NewRecorder()
+		req := mux.SetURLVars(httptest.NewRequest("POST", "http://localhost:8080/v1/api/users/"+testUser.ID+"/disable", nil), map[string]string{"id": testUser.ID})
+
+		SetUserDisabled{UserService: mockUserService{}, Disabled: disabled}.ServeHTTP(response, asAdmin(req))
+
+		if response.Code != http.StatusOK {
+			t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+		}
+		var got entity.User
+		if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Disabled != disabled {
+			t.Errorf("invalid response, expected disabled %t, got: %t", disabled, got.Disabled)
+		}
+	}
+}
+
+func TestResetPassword_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		body   string
+		status int
+	}{
+		{name: "should reset the password", id: testUser.ID, body: `{"password": "new-password"}`, status: http.StatusNoContent},
+		{name: "should fail because the password is too short", id: testUser.ID, body: `{"password": "new"}`, status: http.StatusBadRequest},
+		{name: "should fail with StatusNotFound because user does not exist", id: "non-existing-id", body: `{"password": "new-password"}`, status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "http://localhost:8080/v1/api/users/"+tt.id+"/password", strings.NewReader(tt.body))
+			req = mux.SetURLVars(req, map[string]string{"id": tt.id})
+
+			ResetPassword{UserService: mockUserService{}}.ServeHTTP(response, asAdmin(req))
+
+			if response.Code != tt.status {
+				t.Errorf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
+	"net/http"
+	"time"
+)
+
+// Workflow represents the handler describing the transitions allowed between the statuses of a task
+type Workflow struct {
+	TaskService interfaces.ITaskService
+}
+
+// WorkflowResponse represents the status workflow, so that the clients only offer the statuses a task can move to
+type WorkflowResponse struct {
+	Statuses    []entity.Status                   `json:"statuses"`    // all the statuses a task can have
+	Initial     entity.Status                     `json:"initial"`     // status of the tasks created without one
+	Transitions map[entity.Status][]entity.Status `json:"transitions"` // statuses each status can move to, keeping the same status is always allowed
+}
+
+// @Summary get the status workflow
+// @Description  get the transitions allowed between the statuses, updating a task to a status that cannot be reached from its current one fails with 409
+// @Produce json
+// @Success 200 {object} handlers.WorkflowResponse
+// @Success 304 "the workflow held by the client is still current"
+// @Failure 405,500
+// @Router /workflow [get]
+//
+// ServeHTTP implements the handler interface to handle getting the status workflow
+func (wf Workflow) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeStatus(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("method %s is not allowed", r.Method))
+		return
+	}
+	res := WorkflowResponse{
+		Statuses:    workflow.Statuses,
+		Initial:     entity.New,
+		Transitions: wf.TaskService.GetWorkflow(r.Context()).Transitions(),
+	}
+	// the workflow only changes with the configuration, so clients can keep it as long as its ETag matches
+	writeConditionalJSON(w, r, res, time.Time{})
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWorkflow_ServeHTTP(t *testing.T) {
+	wf := Workflow{TaskService: newMockTaskService(tasksDatabase)}
+
+	response := httptest.NewRecorder()
+	wf.ServeHTTP(response, httptest.NewRequest("GET", "http://localhost:8080/v1/api/workflow", nil))
+	if response.Code != http.StatusOK {
+		t.Fatalf("invalid status code, expected: %d, got: %d", http.StatusOK, response.Code)
+	}
+	var got WorkflowResponse
+	if err := json.Unmarshal(response.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []entity.Status{entity.Active}; !reflect.DeepEqual(got.Transitions[entity.Closed], want) {
+		t.Errorf("invalid transitions from closed, expected: %v, got: %v", want, got.Transitions[entity.Closed])
+	}
+	if got.Initial != entity.New || len(got.Statuses) != 4 {
+		t.Errorf("invalid statuses, got: %v with initial %s", got.Statuses, got.Initial)
+	}
+
+	response = httptest.NewRecorder()
+	wf.ServeHTTP(response, httptest.NewRequest("POST", "http://localhost:8080/v1/api/workflow", nil))
+	if response.Code != http.StatusMethodNotAllowed {
+		t.Errorf("invalid status code, expected: %d, got: %d", http.StatusMethodNotAllowed, response.Code)
+	}
+}
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore stores the content of the files attached to the tasks, their metadata is kept by the IAttachmentRepository.
+// The keys are made of segments separated by slashes, e.g. "<tenant>/<task id>/<attachment id>".
+type BlobStore interface {
+	// Put stores the content under the key, size and checksum (the hexadecimal SHA-256 of the content) are known before the upload
+	// so that the stores can check the content they received. An existing blob with the same key is replaced.
+	Put(ctx context.Context, key string, content io.Reader, size int64, checksum string) error
+	// Get returns the content stored under the key, errs.ErrNotFound is returned if there is none
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under the key, removing a blob that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+}
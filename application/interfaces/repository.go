@@ -1,20 +1,173 @@
 package interfaces
 
-import "github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"time"
+)
 
 // ITaskRepository defines the CRUD operations that are done to the database
 type ITaskRepository interface {
 	WriterRepository
 	ReaderRepository
+	HistoryRepository
+	SeriesRepository
+	HierarchyRepository
+	DependencyRepository
+	TaskLabelRepository
+	// FindProject finds the project of the tasks, errs.ErrNotFound is returned if there is no such project
+	FindProject(id string) (*entity.Project, error)
+	// FindProjectOf returns the ID of the project of the task, which may be in the trash, errs.ErrNotFound is returned if there is no such task
+	FindProjectOf(taskID string) (string, error)
+	// Transaction runs fn with a repository bound to a single database transaction, which is committed if fn returns nil and rolled back otherwise
+	Transaction(fn func(repo ITaskRepository) error) error
+	// WithTenant returns a repository only seeing the data of the tenant, the statements of a repository bound to no tenant fail
+	WithTenant(tenant string) ITaskRepository
+	// AllTenants returns a repository seeing the data of all the tenants, for the background jobs which do not act for a caller
+	AllTenants() ITaskRepository
 }
 
 type WriterRepository interface {
 	Create(task *entity.Task) error
-	DeleteByID(id string) error
-	Update(fields map[string]interface{}, id string) error
+	DeleteByID(id string, deletedBy string) error
+	Restore(id string) error
+	Purge(deletedBefore time.Time) (purged int64, blobKeys []string, err error)
+	Update(fields map[string]interface{}, id string, version int) error
 }
 
 type ReaderRepository interface {
-	FindAll() ([]*entity.Task, error)
+	FindAll(query *entity.TaskQuery) ([]*entity.Task, error)
+	Count(query *entity.TaskQuery) (int64, error)
 	FindByID(id string) (*entity.Task, error)
 }
+
+// HistoryRepository stores the change events of the tasks, events can only be appended, never updated or removed
+type HistoryRepository interface {
+	AppendEvent(event *entity.TaskEvent) error
+	FindEvents(taskID string, query *entity.HistoryQuery) ([]*entity.TaskEvent, error)
+	CountEvents(taskID string) (int64, error)
+}
+
+// SeriesRepository stores the occurrences of the recurring tasks
+type SeriesRepository interface {
+	CreateOccurrence(task *entity.Task) (created bool, err error)
+	FindDueOccurrences(now time.Time, limit int) ([]*entity.Task, error)
+	EndSeries(id string) error
+	ResumeSeries(templateID string) error
+}
+
+// HierarchyRepository navigates the subtasks of the tasks and keeps their completion rolled up on their parent
+type HierarchyRepository interface {
+	FindChildren(parentIDs []string) ([]*entity.Task, error)
+	RefreshCompletion(id string) error
+}
+
+// DependencyRepository stores which tasks block which other ones
+type DependencyRepository interface {
+	AddDependency(dependency *entity.TaskDependency) error
+	RemoveDependency(taskID string, blockerID string) error
+	FindBlockers(taskID string) ([]*entity.Task, error)
+	FindDependencies(taskIDs []string) ([]*entity.TaskDependency, error)
+	FindOpenDependencies() ([]*entity.TaskDependency, error)
+	CountOpenBlockers(taskID string) (int64, error)
+	FindOpenTasks(limit int) ([]*entity.Task, error)
+}
+
+// TaskLabelRepository attaches the labels of the catalogue to the tasks
+type TaskLabelRepository interface {
+	AttachLabel(taskID string, labelID string) (attached bool, err error)
+	DetachLabel(taskID string, labelID string) error
+	FindLabels(taskIDs []string) (map[string][]*entity.Label, error)
+}
+
+// ILabelRepository stores the catalogue of the labels
+type ILabelRepository interface {
+	Create(label *entity.Label) error
+	FindAll() ([]*entity.Label, error)
+	FindByID(id string) (*entity.Label, error)
+	Update(fields map[string]interface{}, id string) error
+	DeleteByID(id string) error
+	WithTenant(tenant string) ILabelRepository
+}
+
+// ICommentRepository stores the comments of the tasks along with the previous bodies of the edited ones, and keeps their number on the tasks
+type ICommentRepository interface {
+	Create(comment *entity.Comment) error
+	FindByTask(taskID string, query *entity.CommentQuery) ([]*entity.Comment, error)
+	CountByTask(taskID string) (int64, error)
+	FindByID(taskID string, id string) (*entity.Comment, error)
+	Update(comment *entity.Comment, revision *entity.CommentRevision) error
+	DeleteByID(taskID string, id string) error
+	FindRevisions(commentID string) ([]*entity.CommentRevision, error)
+	WithTenant(tenant string) ICommentRepository
+}
+
+// IChecklistRepository stores the checklist items of the tasks in their order, and keeps the number of items and of checked ones on the tasks
+type IChecklistRepository interface {
+	Create(item *entity.ChecklistItem) error
+	FindByTask(taskID string) ([]*entity.ChecklistItem, error)
+	FindByID(taskID string, id string) (*entity.ChecklistItem, error)
+	Move(taskID string, id string, position int) error
+	SetDone(taskID string, id string, done bool) error
+	DeleteByID(taskID string, id string) error
+	WithTenant(tenant string) IChecklistRepository
+}
+
+// IAttachmentRepository stores the metadata of the files attached to the tasks, their content is kept in a BlobStore
+type IAttachmentRepository interface {
+	Create(attachment *entity.Attachment) error
+	FindByTask(taskID string) ([]*entity.Attachment, error)
+	FindByID(taskID string, id string) (*entity.Attachment, error)
+	DeleteByID(taskID string, id string) error
+	WithTenant(tenant string) IAttachmentRepository
+}
+
+// IRoleRepository stores the roles granted to the subjects, an empty project ID stands for all the projects of the tenant
+type IRoleRepository interface {
+	Assign(assignment *entity.RoleAssignment) error
+	FindAll() ([]*entity.RoleAssignment, error)
+	FindBySubject(subject string, projectID string) ([]*entity.RoleAssignment, error)
+	Revoke(subject string, projectID string) error
+	WithTenant(tenant string) IRoleRepository
+}
+
+// IProjectRepository stores the projects the tasks belong to
+type IProjectRepository interface {
+	Create(project *entity.Project) error
+	FindAll() ([]*entity.Project, error)
+	FindByID(id string) (*entity.Project, error)
+	Update(fields map[string]interface{}, id string) error
+	DeleteByID(id string) error
+	WithTenant(tenant string) IProjectRepository
+}
+
+// IUserRepository stores the user accounts
+type IUserRepository interface {
+	Create(user *entity.User) error
+	FindAll() ([]*entity.User, error)
+	FindByID(id string) (*entity.User, error)
+	FindByUsername(username string) (*entity.User, error)
+	Update(fields map[string]interface{}, id string) error
+	WithTenant(tenant string) IUserRepository
+	AllTenants() IUserRepository
+}
+
+// IAPIKeyRepository stores the API keys of the machine clients
+type IAPIKeyRepository interface {
+	Create(key *entity.APIKey) error
+	FindAll() ([]*entity.APIKey, error)
+	FindByHash(hash string) (*entity.APIKey, error)
+	Touch(id string, usedAt time.Time) error
+	DeleteByID(id string) error
+	WithTenant(tenant string) IAPIKeyRepository
+	AllTenants() IAPIKeyRepository
+}
+
+// ISessionRepository stores the sessions of the browser clients and the logins they started, for all the tenants
+type ISessionRepository interface {
+	Create(session *entity.Session) error
+	FindByID(id string) (*entity.Session, error)
+	DeleteByID(id string) error
+	CreateLogin(attempt *entity.LoginAttempt) error
+	TakeLogin(id string) (*entity.LoginAttempt, error)
+	PurgeExpired(now time.Time) (int64, error)
+}
@@ -1,13 +1,127 @@
 package interfaces
 
-import "github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
+	"io"
+	"time"
+)
 
 // ITaskService defines the functions needed for the use-cases, they should contain all the business logic needed to fulfill the services required from the user.
+// The context carries the principal calling the service, see the principal package.
 type ITaskService interface {
-	Create(task *entity.TaskDescription) (*entity.Task, error)
-	Get() ([]*entity.Task, error)
-	GetByID(id string) (*entity.Task, error)
-	DeleteByID(id string) error
-	UpdatePartial(task *entity.TaskDescription, id string) (*entity.Task, error)
-	UpdateFully(task *entity.TaskDescription, id string) (*entity.Task, error)
+	Create(ctx context.Context, task *entity.TaskDescription) (*entity.Task, error)
+	Get(ctx context.Context, query *entity.TaskQuery) (*entity.TaskList, error)
+	GetByID(ctx context.Context, id string) (*entity.Task, error)
+	DeleteByID(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*entity.Task, error)
+	ProjectOf(ctx context.Context, id string) (string, error)
+	PurgeTrash(ctx context.Context, retention time.Duration) (int64, error)
+	SpawnOccurrences(ctx context.Context) (int, error)
+	Children(ctx context.Context, id string) ([]*entity.Task, error)
+	Tree(ctx context.Context, id string) (*entity.TaskNode, error)
+	RemoveParent(ctx context.Context, id string, version int) (*entity.Task, error)
+	Blockers(ctx context.Context, id string) ([]*entity.Task, error)
+	AddBlocker(ctx context.Context, id string, blockerID string) (*entity.TaskDependency, error)
+	RemoveBlocker(ctx context.Context, id string, blockerID string) error
+	Next(ctx context.Context, limit int) ([]*entity.PlannedTask, error)
+	AddLabel(ctx context.Context, id string, labelID string) error
+	RemoveLabel(ctx context.Context, id string, labelID string) error
+	History(ctx context.Context, id string, query *entity.HistoryQuery) (*entity.TaskHistory, error)
+	UpdatePartial(ctx context.Context, task *entity.TaskDescription, id string, version int) (*entity.Task, error)
+	UpdateFully(ctx context.Context, task *entity.TaskDescription, id string, version int) (*entity.Task, error)
+	Assign(ctx context.Context, id string, assigneeID string, version int) (*entity.Task, error)
+	GetWorkflow(ctx context.Context) *workflow.Workflow
+	GetDeletePolicy(ctx context.Context) hierarchy.DeletePolicy
+}
+
+// ILabelService manages the catalogue of the labels that can be attached to the tasks
+type ILabelService interface {
+	Create(ctx context.Context, label *entity.LabelDescription) (*entity.Label, error)
+	List(ctx context.Context) ([]*entity.Label, error)
+	GetByID(ctx context.Context, id string) (*entity.Label, error)
+	UpdateFully(ctx context.Context, label *entity.LabelDescription, id string) (*entity.Label, error)
+	UpdatePartial(ctx context.Context, label *entity.LabelDescription, id string) (*entity.Label, error)
+	DeleteByID(ctx context.Context, id string) error
+}
+
+// ICommentService manages the comments of the tasks, the callers see the comments of the tasks they can read
+type ICommentService interface {
+	List(ctx context.Context, taskID string, query *entity.CommentQuery) (*entity.CommentList, error)
+	Create(ctx context.Context, taskID string, comment *entity.CommentBody) (*entity.Comment, error)
+	Update(ctx context.Context, taskID string, id string, comment *entity.CommentBody) (*entity.Comment, error)
+	Delete(ctx context.Context, taskID string, id string) error
+	Revisions(ctx context.Context, taskID string, id string) ([]*entity.CommentRevision, error)
+}
+
+// IChecklistService manages the checklists of the tasks, the callers see the checklists of the tasks they can read
+type IChecklistService interface {
+	List(ctx context.Context, taskID string) ([]*entity.ChecklistItem, error)
+	Add(ctx context.Context, taskID string, item *entity.NewChecklistItem) (*entity.ChecklistItem, error)
+	Move(ctx context.Context, taskID string, id string, move *entity.ChecklistMove) (*entity.ChecklistItem, error)
+	SetDone(ctx context.Context, taskID string, id string, done bool) (*entity.ChecklistItem, error)
+	Remove(ctx context.Context, taskID string, id string) error
+}
+
+// IAttachmentService manages the files attached to the tasks, the callers see the files of the tasks they can read
+type IAttachmentService interface {
+	List(ctx context.Context, taskID string) ([]*entity.Attachment, error)
+	Upload(ctx context.Context, taskID string, name string, content io.Reader) (*entity.Attachment, error)
+	Download(ctx context.Context, taskID string, id string) (*entity.Attachment, io.ReadCloser, error)
+	Delete(ctx context.Context, taskID string, id string) error
+}
+
+// IProjectService manages the projects the tasks belong to, along with their settings
+type IProjectService interface {
+	Create(ctx context.Context, project *entity.ProjectDescription) (*entity.Project, error)
+	List(ctx context.Context) ([]*entity.Project, error)
+	GetByID(ctx context.Context, id string) (*entity.Project, error)
+	UpdateFully(ctx context.Context, project *entity.ProjectDescription, id string) (*entity.Project, error)
+	UpdatePartial(ctx context.Context, project *entity.ProjectDescription, id string) (*entity.Project, error)
+	DeleteByID(ctx context.Context, id string) error
+}
+
+// IUserService manages the user accounts and authenticates the callers against them, only the administrators can manage the users of their tenant
+type IUserService interface {
+	Authenticate(ctx context.Context, username string, password string) (principal.Principal, error)
+	Create(ctx context.Context, user *entity.NewUser) (*entity.User, error)
+	List(ctx context.Context) ([]*entity.User, error)
+	GetByID(ctx context.Context, id string) (*entity.User, error)
+	SetDisabled(ctx context.Context, id string, disabled bool) (*entity.User, error)
+	ResetPassword(ctx context.Context, id string, req *entity.PasswordReset) error
+}
+
+// IAPIKeyService issues the API keys of the machine clients and authenticates the callers with them, only the administrators can manage the keys of their tenant
+type IAPIKeyService interface {
+	Authenticate(ctx context.Context, key string) (principal.Principal, error)
+	Issue(ctx context.Context, key *entity.APIKeyDescription) (*entity.IssuedAPIKey, error)
+	List(ctx context.Context) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, id string) error
+}
+
+// IIdentityProvider is the OpenID provider the browser clients log in with, using the authorization code flow with PKCE
+type IIdentityProvider interface {
+	AuthCodeURL(state string, nonce string, codeChallenge string) string
+	Exchange(ctx context.Context, code string, codeVerifier string) (idToken string, err error)
+	VerifyIDToken(ctx context.Context, idToken string, nonce string) (principal.Claims, error)
+	LogoutURL(idToken string) string
+}
+
+// ISessionService logs the browser clients in with the identity provider and authenticates the callers with their session
+type ISessionService interface {
+	BeginLogin(ctx context.Context, returnTo string) (state string, authURL string, err error)
+	CompleteLogin(ctx context.Context, state string, code string) (*entity.IssuedSession, error)
+	Authenticate(ctx context.Context, token string) (principal.Principal, error)
+	Logout(ctx context.Context, token string) (logoutURL string, err error)
+}
+
+// IRoleService manages the roles granted in the tenant and resolves the role of the callers, an empty project ID stands for all the projects
+type IRoleService interface {
+	RoleOf(ctx context.Context, projectID string) (entity.Role, error)
+	List(ctx context.Context) ([]*entity.RoleAssignment, error)
+	Assign(ctx context.Context, assignment *entity.RoleAssignment) (*entity.RoleAssignment, error)
+	Revoke(ctx context.Context, subject string, projectID string) error
 }
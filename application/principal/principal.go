@@ -0,0 +1,52 @@
+// Package principal carries the identity of the authenticated caller through the request context,
+// from the auth middleware down to the service which records who did what.
+package principal
+
+import "context"
+
+// Principal represents the authenticated caller of the service
+type Principal struct {
+	Subject string   // unique name of the caller, e.g. the basic auth username
+	Tenant  string   // tenant the caller belongs to, the caller only sees the data of this tenant
+	Admin   bool     // whether the caller can manage the user accounts of its tenant
+	Claims  Claims   // claims of the bearer token the caller authenticated with, nil for the other authentications
+	Scopes  []string // scopes of the API key the caller authenticated with, which replace its role, nil for the other authentications
+	Role    string   // role on all the projects given by the identity provider the caller logged in with, empty when it gives none
+}
+
+// Claims represents the claims of a verified token, as decoded from its JSON payload
+type Claims map[string]interface{}
+
+// contextKey is unexported so that no other package can overwrite the principal in the context
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, ok is false when the caller is not authenticated
+func FromContext(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// Subject returns the subject of the principal carried by ctx, or an empty string when the caller is not authenticated
+func Subject(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.Subject
+}
+
+// Tenant returns the tenant of the principal carried by ctx, or an empty string when the caller is not authenticated.
+// The repositories refuse to run statements for an empty tenant, so an unauthenticated caller cannot see any data.
+func Tenant(ctx context.Context) string {
+	p, _ := FromContext(ctx)
+	return p.Tenant
+}
+
+// Claim returns the claim of the token the caller authenticated with, ok is false when there is no such claim
+func Claim(ctx context.Context, name string) (v interface{}, ok bool) {
+	p, _ := FromContext(ctx)
+	v, ok = p.Claims[name]
+	return v, ok
+}
@@ -0,0 +1,39 @@
+package principal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()); ok {
+		t.Errorf("FromContext() should not find a principal in an empty context")
+	}
+	if got := Subject(context.Background()); got != "" {
+		t.Errorf("Subject() = %v, want empty subject", got)
+	}
+
+	if got := Tenant(context.Background()); got != "" {
+		t.Errorf("Tenant() = %v, want empty tenant", got)
+	}
+
+	ctx := NewContext(context.Background(), Principal{Subject: "admin", Tenant: "acme"})
+	p, ok := FromContext(ctx)
+	if !ok || p.Subject != "admin" {
+		t.Errorf("FromContext() = %v, %v, want admin", p, ok)
+	}
+	if got := Subject(ctx); got != "admin" {
+		t.Errorf("Subject() = %v, want admin", got)
+	}
+	if got := Tenant(ctx); got != "acme" {
+		t.Errorf("Tenant() = %v, want acme", got)
+	}
+	if _, ok := Claim(ctx, "scope"); ok {
+		t.Errorf("Claim() should not find a claim without token")
+	}
+
+	ctx = NewContext(context.Background(), Principal{Subject: "alice", Tenant: "acme", Claims: Claims{"scope": "tasks:read"}})
+	if got, ok := Claim(ctx, "scope"); !ok || got != "tasks:read" {
+		t.Errorf("Claim() = %v, %v, want tasks:read", got, ok)
+	}
+}
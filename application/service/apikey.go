@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+	"strings"
+	"time"
+)
+
+const (
+	// apiKeyPrefix starts all the API keys, so that they are recognised by secret scanners and cannot be mistaken for another credential
+	apiKeyPrefix = "tsk_"
+	// lastUsedPrecision is how often the last use of an API key is recorded, so that a busy client does not write on every request
+	lastUsedPrecision = time.Minute
+)
+
+// APIKeyService issues the API keys of the machine clients and authenticates the requests made with them.
+// Only the SHA-256 of the keys is stored: they are random so a slow hash such as bcrypt would not make them harder to guess,
+// and a hash that can be looked up lets the keys be found without knowing their tenant.
+type APIKeyService struct {
+	APIKeyRepository interfaces.IAPIKeyRepository
+}
+
+// NewAPIKeyService is the constructor of an APIKeyService with the repository dependency injected
+func NewAPIKeyService(repo interfaces.IAPIKeyRepository) *APIKeyService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	return &APIKeyService{APIKeyRepository: repo}
+}
+
+// Authenticate checks the API key and returns the principal it acts as, with the scopes of the key. The same errs.ErrUnauthenticated
+// is returned whether the key is unknown, revoked or expired, so that the callers cannot tell which is the case.
+func (a *APIKeyService) Authenticate(ctx context.Context, key string) (principal.Principal, error) {
+	denied := errs.New(errs.ErrUnauthenticated, "invalid API key")
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return principal.Principal{}, denied
+	}
+	repo := a.APIKeyRepository.AllTenants()
+	apiKey, err := repo.FindByHash(hashSecret(key))
+	if errors.Is(err, errs.ErrNotFound) {
+		return principal.Principal{}, denied
+	}
+	if err != nil {
+		return principal.Principal{}, err
+	}
+	now := time.Now().UTC()
+	if apiKey.Expired(now) {
+		return principal.Principal{}, denied
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedPrecision {
+		// the request is still served when the last use cannot be recorded, it is only informative
+		if err = repo.Touch(apiKey.ID, now); err != nil {
+			log.Printf("failed to record the last use of API key with ID '%s': %v", apiKey.ID, err)
+		}
+	}
+	scopes := make([]string, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = string(scope)
+	}
+	return principal.Principal{Subject: apiKey.Subject(), Tenant: apiKey.TenantID, Scopes: scopes}, nil
+}
+
+// Issue creates an API key in the tenant of the administrator, the returned key is not stored and cannot be retrieved afterwards.
+// errs.ErrConflict is returned if the name is already used by another key of the tenant.
+func (a *APIKeyService) Issue(ctx context.Context, req *entity.APIKeyDescription) (*entity.IssuedAPIKey, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	req, err := validation.ValidateAPIKey(req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+	apiKey := entity.APIKey{
+		ID:                uuid.NewString(),
+		Hash:              hashSecret(key),
+		Prefix:            key[:len(apiKeyPrefix)+6],
+		CreatedBy:         principal.Subject(ctx),
+		APIKeyDescription: *req,
+	}
+	log.Printf("issuing API key with ID '%s' ...", apiKey.ID)
+	if err = a.repo(ctx).Create(&apiKey); err != nil {
+		return nil, err
+	}
+	return &entity.IssuedAPIKey{APIKey: &apiKey, Key: key}, nil
+}
+
+// List returns the API keys of the tenant of the administrator ordered by name, without the keys themselves
+func (a *APIKeyService) List(ctx context.Context) ([]*entity.APIKey, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	log.Printf("listing API keys ...")
+	return a.repo(ctx).FindAll()
+}
+
+// Revoke deletes the API key, the requests made with it are refused from then on
+func (a *APIKeyService) Revoke(ctx context.Context, id string) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	log.Printf("revoking API key with ID '%s' ...", id)
+	return a.repo(ctx).DeleteByID(id)
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the API keys of this tenant
+func (a *APIKeyService) repo(ctx context.Context) interfaces.IAPIKeyRepository {
+	return a.APIKeyRepository.WithTenant(principal.Tenant(ctx))
+}
+
+// randomSecret returns 32 random bytes encoded in base64url, for the keys, cookies and states that must not be guessed
+func randomSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// hashSecret returns the hexadecimal SHA-256 of the secret, as it is stored
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
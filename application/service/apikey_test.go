@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockAPIKeyRepository keeps the API keys of all the tenants, tenant is empty when the repository sees all of them
+type mockAPIKeyRepository struct {
+	keys    map[string]*entity.APIKey
+	tenant  string
+	touched *int // number of times the last use of a key was recorded
+}
+
+func (m mockAPIKeyRepository) visible(key *entity.APIKey) bool {
+	return m.tenant == "" || key.TenantID == m.tenant
+}
+
+func (m mockAPIKeyRepository) Create(key *entity.APIKey) error {
+	for _, existing := range m.keys {
+		if existing.Name == key.Name && m.visible(existing) {
+			return errs.New(errs.ErrConflict, "name already used")
+		}
+	}
+	if m.tenant != "" {
+		key.TenantID = m.tenant
+	}
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m mockAPIKeyRepository) FindAll() ([]*entity.APIKey, error) {
+	var keys []*entity.APIKey
+	for _, key := range m.keys {
+		if m.visible(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m mockAPIKeyRepository) FindByHash(hash string) (*entity.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Hash == hash && m.visible(key) {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "API key not found")
+}
+
+func (m mockAPIKeyRepository) Touch(id string, usedAt time.Time) error {
+	*m.touched++
+	m.keys[id].LastUsedAt = &usedAt
+	return nil
+}
+
+func (m mockAPIKeyRepository) DeleteByID(id string) error {
+	if key, ok := m.keys[id]; !ok || !m.visible(key) {
+		return errs.New(errs.ErrNotFound, "API key not found")
+	}
+	delete(m.keys, id)
+	return nil
+}
+
+func (m mockAPIKeyRepository) WithTenant(tenant string) interfaces.IAPIKeyRepository {
+	return mockAPIKeyRepository{keys: m.keys, tenant: tenant, touched: m.touched}
+}
+
+func (m mockAPIKeyRepository) AllTenants() interfaces.IAPIKeyRepository {
+	return mockAPIKeyRepository{keys: m.keys, touched: m.touched}
+}
+
+func TestAPIKeyService(t1 *testing.T) {
+	repo := mockAPIKeyRepository{keys: make(map[string]*entity.APIKey), touched: new(int)}
+	a := NewAPIKeyService(repo)
+	adminCtx := principal.NewContext(context.Background(), principal.Principal{Subject: "root", Tenant: testTenant, Admin: true})
+
+	if _, err := a.Issue(callerCtx("alice"), &entity.APIKeyDescription{Name: "ci", Scopes: entity.ScopeList{entity.ScopeTasksWrite}}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Issue() error = %v, want %v for a caller who is not admin", err, errs.ErrForbidden)
+	}
+	issued, err := a.Issue(adminCtx, &entity.APIKeyDescription{Name: "CI", Scopes: entity.ScopeList{entity.ScopeTasksWrite}})
+	if err != nil {
+		t1.Fatalf("Issue() error = %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, apiKeyPrefix) || !strings.HasPrefix(issued.Key, issued.Prefix) || issued.Hash == issued.Key || issued.CreatedBy != "root" {
+		t1.Errorf("Issue() got = %v, want a key stored as a hash", issued)
+	}
+	if _, err = a.Issue(adminCtx, &entity.APIKeyDescription{Name: "ci", Scopes: entity.ScopeList{entity.ScopeTasksRead}}); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("Issue() error = %v, want %v for a name already used", err, errs.ErrConflict)
+	}
+
+	// the key acts in the tenant of the administrator with its scopes, its last use is recorded once a minute at most
+	for i := 0; i < 2; i++ {
+		got, err := a.Authenticate(context.Background(), issued.Key)
+		if err != nil {
+			t1.Fatalf("Authenticate() error = %v", err)
+		}
+		if want := (principal.Principal{Subject: "apikey:ci", Tenant: testTenant, Scopes: []string{"tasks:write"}}); !reflect.DeepEqual(got, want) {
+			t1.Errorf("Authenticate() got = %v, want %v", got, want)
+		}
+	}
+	if *repo.touched != 1 || repo.keys[issued.ID].LastUsedAt == nil {
+		t1.Errorf("Authenticate() recorded the last use %d times, want 1", *repo.touched)
+	}
+
+	// an expired key, a revoked key and an unknown key are refused the same way
+	expired, err := a.Issue(adminCtx, &entity.APIKeyDescription{Name: "bot", Scopes: entity.ScopeList{entity.ScopeTasksRead}})
+	if err != nil {
+		t1.Fatalf("Issue() error = %v", err)
+	}
+	past := time.Now().Add(-time.Second)
+	repo.keys[expired.ID].ExpiresAt = &past
+	if err = a.Revoke(adminCtx, issued.ID); err != nil {
+		t1.Fatalf("Revoke() error = %v", err)
+	}
+	for _, key := range []string{issued.Key, expired.Key, apiKeyPrefix + "unknown", "not-a-key"} {
+		if _, err = a.Authenticate(context.Background(), key); !errors.Is(err, errs.ErrUnauthenticated) {
+			t1.Errorf("Authenticate(%q) error = %v, want %v", key, err, errs.ErrUnauthenticated)
+		}
+	}
+	if err = a.Revoke(adminCtx, issued.ID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Revoke() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if keys, err := a.List(adminCtx); err != nil || len(keys) != 1 || keys[0].Name != "bot" {
+		t1.Errorf("List() = %v, %v, want the key bot", keys, err)
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"log"
+)
+
+// Assign makes the subject responsible for the task, replacing its previous assignee, or unassigns it when assigneeID is empty.
+// If version is not 0, the task is only assigned if it is still at this version. The change is recorded in the history of the task.
+// The assignee is not looked up among the users, since the callers authenticated by a token or the identity provider have no account.
+func (t *TaskService) Assign(ctx context.Context, id string, assigneeID string, version int) (*entity.Task, error) {
+	if assigneeID != "" {
+		if err := validation.ValidateSubject(assigneeID); err != nil {
+			return nil, errs.Validation([]errs.Violation{{Field: "assigneeId", Message: err.Error()}})
+		}
+		log.Printf("assigning task with id '%s' to '%s' ...", id, assigneeID)
+	} else {
+		log.Printf("unassigning task with id '%s' ...", id)
+	}
+	return t.update(ctx, map[string]interface{}{"assignee_id": assigneeID}, id, version)
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"io"
+	"log"
+	"net/http"
+	"os"
+)
+
+// sniffLength is the number of bytes the content type of a file is sniffed from
+const sniffLength = 512
+
+// AttachmentService manages the files attached to the tasks, their metadata goes to the repository and their content to the blob store.
+// As for the comments, the files of a task are read by its viewers and attached by its editors, and a file is deleted by the one who
+// uploaded it or an admin of the project.
+type AttachmentService struct {
+	AttachmentRepository interfaces.IAttachmentRepository
+	BlobStore            interfaces.BlobStore
+	TaskService          interfaces.ITaskService
+	RoleService          interfaces.IRoleService
+	MaxSize              int64 // maximum size of a file in bytes
+}
+
+// NewAttachmentService is the constructor of an AttachmentService, tasks should be the task service checking the roles of the callers
+func NewAttachmentService(repo interfaces.IAttachmentRepository, store interfaces.BlobStore, tasks interfaces.ITaskService, roles interfaces.IRoleService, maxSize int64) *AttachmentService {
+	if repo == nil || store == nil {
+		log.Fatalf("nil repo provided")
+	}
+	if tasks == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &AttachmentService{AttachmentRepository: repo, BlobStore: store, TaskService: tasks, RoleService: roles, MaxSize: maxSize}
+}
+
+// List returns the files attached to the task, from the oldest one
+func (a *AttachmentService) List(ctx context.Context, taskID string) ([]*entity.Attachment, error) {
+	if _, err := a.TaskService.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	log.Printf("listing attachments of task with id '%s' ...", taskID)
+	return a.repo(ctx).FindByTask(taskID)
+}
+
+// Upload attaches the file to the task. The content is spooled to a temporary file while its size and checksum are computed,
+// so that nothing is stored when it is too large, and its content type is sniffed from its first bytes.
+// errs.ErrTooLarge is returned if the content exceeds MaxSize.
+func (a *AttachmentService) Upload(ctx context.Context, taskID string, name string, content io.Reader) (*entity.Attachment, error) {
+	name, err := validation.ValidateFileName(name)
+	if err != nil {
+		return nil, errs.Validation([]errs.Violation{{Field: "name", Message: err.Error()}})
+	}
+	task, err := a.TaskService.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireRole(ctx, a.RoleService, task.ProjectID, entity.Editor, "attach files"); err != nil {
+		return nil, err
+	}
+
+	spool, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), io.LimitReader(content, a.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if size > a.MaxSize {
+		return nil, errs.New(errs.ErrTooLarge, "file is larger than %d bytes", a.MaxSize)
+	}
+	if size == 0 {
+		return nil, errs.Validation([]errs.Violation{{Field: "file", Message: validation.ErrEmptyField.Error()}})
+	}
+	head := make([]byte, sniffLength)
+	n, err := spool.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	attachment := entity.Attachment{
+		ID:          uuid.NewString(),
+		TaskID:      taskID,
+		Name:        name,
+		Size:        size,
+		ContentType: http.DetectContentType(head[:n]),
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+		UploadedBy:  principal.Subject(ctx),
+	}
+	attachment.BlobKey = principal.Tenant(ctx) + "/" + taskID + "/" + attachment.ID
+	log.Printf("attaching file with ID '%s' to task with id '%s' ...", attachment.ID, taskID)
+	if _, err = spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err = a.BlobStore.Put(ctx, attachment.BlobKey, spool, size, attachment.Checksum); err != nil {
+		return nil, err
+	}
+	if err = a.repo(ctx).Create(&attachment); err != nil {
+		// without metadata the content cannot be reached anymore
+		if deleteErr := a.BlobStore.Delete(ctx, attachment.BlobKey); deleteErr != nil {
+			log.Printf("failed to delete blob '%s' of a failed upload: %v", attachment.BlobKey, deleteErr)
+		}
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// Download returns the metadata of a file attached to the task along with its content, which the caller has to close
+func (a *AttachmentService) Download(ctx context.Context, taskID string, id string) (*entity.Attachment, io.ReadCloser, error) {
+	_, attachment, err := a.find(ctx, taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("downloading attachment with id '%s' ...", id)
+	content, err := a.BlobStore.Get(ctx, attachment.BlobKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// Delete removes a file attached to the task, the admins of the project of the task can remove the files of others.
+// The content is removed after the metadata, a failure leaving an unreachable blob rather than metadata without content.
+func (a *AttachmentService) Delete(ctx context.Context, taskID string, id string) error {
+	task, attachment, err := a.find(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if attachment.UploadedBy == principal.Subject(ctx) {
+		err = requireRole(ctx, a.RoleService, task.ProjectID, entity.Editor, "delete attachments")
+	} else {
+		err = requireRole(ctx, a.RoleService, task.ProjectID, entity.Admin, "delete the attachments of others")
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("deleting attachment with id '%s' of task with id '%s' ...", id, taskID)
+	if err = a.repo(ctx).DeleteByID(taskID, id); err != nil {
+		return err
+	}
+	if err = a.BlobStore.Delete(ctx, attachment.BlobKey); err != nil {
+		log.Printf("failed to delete blob '%s' of attachment with id '%s': %v", attachment.BlobKey, id, err)
+	}
+	return nil
+}
+
+// find returns a task the caller can read along with one of its files, errs.ErrNotFound is returned if the task has no such file
+func (a *AttachmentService) find(ctx context.Context, taskID string, id string) (*entity.Task, *entity.Attachment, error) {
+	task, err := a.TaskService.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := a.repo(ctx).FindByID(taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, attachment, nil
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the attachments of this tenant
+func (a *AttachmentService) repo(ctx context.Context) interfaces.IAttachmentRepository {
+	return a.AttachmentRepository.WithTenant(principal.Tenant(ctx))
+}
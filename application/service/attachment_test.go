@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"io"
+	"strings"
+	"testing"
+)
+
+// mockAttachmentRepository keeps the attachments in memory in the order they are created, the tenant is ignored
+type mockAttachmentRepository struct {
+	attachments *[]*entity.Attachment
+}
+
+func (m mockAttachmentRepository) Create(attachment *entity.Attachment) error {
+	*m.attachments = append(*m.attachments, attachment)
+	return nil
+}
+
+func (m mockAttachmentRepository) FindByTask(taskID string) ([]*entity.Attachment, error) {
+	var attachments []*entity.Attachment
+	for _, attachment := range *m.attachments {
+		if attachment.TaskID == taskID {
+			attachments = append(attachments, attachment)
+		}
+	}
+	return attachments, nil
+}
+
+func (m mockAttachmentRepository) FindByID(taskID string, id string) (*entity.Attachment, error) {
+	for _, attachment := range *m.attachments {
+		if attachment.TaskID == taskID && attachment.ID == id {
+			return attachment, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "attachment not found")
+}
+
+func (m mockAttachmentRepository) DeleteByID(taskID string, id string) error {
+	for i, attachment := range *m.attachments {
+		if attachment.TaskID == taskID && attachment.ID == id {
+			*m.attachments = append((*m.attachments)[:i], (*m.attachments)[i+1:]...)
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "attachment not found")
+}
+
+func (m mockAttachmentRepository) WithTenant(tenant string) interfaces.IAttachmentRepository {
+	return m
+}
+
+// mockBlobStore keeps the blobs in memory by key
+type mockBlobStore struct {
+	blobs map[string][]byte
+}
+
+func (m mockBlobStore) Put(ctx context.Context, key string, content io.Reader, size int64, checksum string) error {
+	b, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	if int64(len(b)) != size {
+		return errs.New(errs.ErrValidation, "size mismatch")
+	}
+	m.blobs[key] = b
+	return nil
+}
+
+func (m mockBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	b, ok := m.blobs[key]
+	if !ok {
+		return nil, errs.New(errs.ErrNotFound, "blob not found")
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m mockBlobStore) Delete(ctx context.Context, key string) error {
+	delete(m.blobs, key)
+	return nil
+}
+
+func TestAttachmentService(t1 *testing.T) {
+	roles := newTestRoleService()
+	// alice and bob edit all the projects, alice administers p1, carol has the default role of viewer
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "alice", Role: entity.Editor},
+		{Subject: "alice", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "bob", Role: entity.Editor},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	store := mockBlobStore{blobs: make(map[string][]byte)}
+	a := NewAttachmentService(mockAttachmentRepository{attachments: &[]*entity.Attachment{}}, store, NewAuthorizedTaskService(stubTaskService{}, roles), roles, 16)
+
+	if _, err := a.Upload(callerCtx("carol"), "t1", "notes.txt", strings.NewReader("hello")); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Upload() error = %v, want %v for a viewer", err, errs.ErrForbidden)
+	}
+	if _, err := a.Upload(callerCtx("bob"), "t1", "..", strings.NewReader("hello")); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Upload() error = %v, want %v for an invalid name", err, errs.ErrValidation)
+	}
+	if _, err := a.Upload(callerCtx("bob"), "t1", "empty.txt", strings.NewReader("")); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Upload() error = %v, want %v for an empty file", err, errs.ErrValidation)
+	}
+	if _, err := a.Upload(callerCtx("bob"), "t1", "big.txt", strings.NewReader(strings.Repeat("a", 17))); !errors.Is(err, errs.ErrTooLarge) {
+		t1.Errorf("Upload() error = %v, want %v for a file over the limit", err, errs.ErrTooLarge)
+	}
+	if len(store.blobs) != 0 {
+		t1.Errorf("Upload() stored %d blobs for the refused files, want none", len(store.blobs))
+	}
+
+	first, err := a.Upload(callerCtx("bob"), "t1", `C:\Users\bob\notes.txt`, strings.NewReader("hello, world"))
+	if err != nil {
+		t1.Fatalf("Upload() error = %v", err)
+	}
+	want := entity.Attachment{ID: first.ID, TaskID: "t1", Name: "notes.txt", Size: 12, ContentType: "text/plain; charset=utf-8",
+		Checksum: "09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b", UploadedBy: "bob", BlobKey: testTenant + "/t1/" + first.ID}
+	if *first != want {
+		t1.Errorf("Upload() got = %+v, want %+v", *first, want)
+	}
+	second, err := a.Upload(callerCtx("alice"), "t1", "logo.png", bytes.NewReader([]byte("\x89PNG\r\n\x1a\nrest")))
+	if err != nil {
+		t1.Fatalf("Upload() error = %v", err)
+	}
+	if second.ContentType != "image/png" {
+		t1.Errorf("Upload() got content type %s, want the sniffed image/png", second.ContentType)
+	}
+
+	// the viewers list and download the files
+	list, err := a.List(callerCtx("carol"), "t1")
+	if err != nil {
+		t1.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != first.ID {
+		t1.Errorf("List() got = %v, want the 2 files from the oldest one", list)
+	}
+	got, content, err := a.Download(callerCtx("carol"), "t1", first.ID)
+	if err != nil {
+		t1.Fatalf("Download() error = %v", err)
+	}
+	b, _ := io.ReadAll(content)
+	_ = content.Close()
+	if got.ID != first.ID || string(b) != "hello, world" {
+		t1.Errorf("Download() got = %+v with %q, want the content of the first file", got, b)
+	}
+	if _, _, err = a.Download(callerCtx("carol"), "t2", first.ID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Download() error = %v, want %v for a file of another task", err, errs.ErrNotFound)
+	}
+
+	// the uploaders delete their files, the admins of the project the ones of the others
+	if err = a.Delete(callerCtx("bob"), "t1", second.ID); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Delete() error = %v, want %v for another caller than the uploader", err, errs.ErrForbidden)
+	}
+	if err = a.Delete(callerCtx("alice"), "t1", first.ID); err != nil {
+		t1.Errorf("Delete() error = %v for an admin of the project", err)
+	}
+	if err = a.Delete(callerCtx("alice"), "t1", second.ID); err != nil {
+		t1.Errorf("Delete() error = %v for the uploader", err)
+	}
+	if len(store.blobs) != 0 {
+		t1.Errorf("Delete() left %d blobs, want none", len(store.blobs))
+	}
+}
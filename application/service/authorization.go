@@ -0,0 +1,437 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
+	"log"
+	"time"
+)
+
+// AuthorizedTaskService checks the role of the caller on the project of the tasks before passing the calls to the task service,
+// errs.ErrForbidden is returned for the operations the role does not allow:
+//   - the viewers read the tasks, their history, subtasks and blockers
+//   - the editors also create the tasks, update some of their values, assign them, and attach labels and blockers
+//   - the admins also replace, delete and restore the tasks
+//
+// Linking a task to a task of another project also requires the viewer role on the project of the blocker,
+// and the editor role on the project of the parent, whose completion is rolled up from its subtasks.
+type AuthorizedTaskService struct {
+	TaskService interfaces.ITaskService
+	RoleService interfaces.IRoleService
+}
+
+// NewAuthorizedTaskService is the constructor of an AuthorizedTaskService wrapping the task service
+func NewAuthorizedTaskService(tasks interfaces.ITaskService, roles interfaces.IRoleService) *AuthorizedTaskService {
+	if tasks == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &AuthorizedTaskService{TaskService: tasks, RoleService: roles}
+}
+
+// require returns errs.ErrForbidden unless the role of the caller on the project, or on all the projects when projectID is empty, includes the role
+func (a *AuthorizedTaskService) require(ctx context.Context, projectID string, role entity.Role, operation string) error {
+	return requireRole(ctx, a.RoleService, projectID, role, operation)
+}
+
+// requireRole is require for the services whose operations are checked against the roles on the projects of the tasks
+func requireRole(ctx context.Context, roles interfaces.IRoleService, projectID string, role entity.Role, operation string) error {
+	granted, err := roles.RoleOf(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	if !granted.Includes(role) {
+		if projectID == "" {
+			return errs.New(errs.ErrForbidden, "role '%s' of '%s' does not allow to %s", granted, principal.Subject(ctx), operation)
+		}
+		return errs.New(errs.ErrForbidden, "role '%s' of '%s' does not allow to %s in project '%s'", granted, principal.Subject(ctx), operation, projectID)
+	}
+	return nil
+}
+
+// requireOnTask checks the role of the caller on the project of the task and returns the task, the task is not found
+// for the caller as for the task service, so that the check does not tell whether the task exists
+func (a *AuthorizedTaskService) requireOnTask(ctx context.Context, id string, role entity.Role, operation string) (*entity.Task, error) {
+	task, err := a.TaskService.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err = a.require(ctx, task.ProjectID, role, operation); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// requireOnLinked checks the role of the caller on the project of a task linked to the one of the operation, its blocker or parent.
+// A linked task that is not found is left to the task service, which reports it as a violation of the request.
+func (a *AuthorizedTaskService) requireOnLinked(ctx context.Context, id string, role entity.Role, operation string) error {
+	if id == "" {
+		return nil
+	}
+	task, err := a.TaskService.GetByID(ctx, id)
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return a.require(ctx, task.ProjectID, role, operation)
+}
+
+// requireOnParents requires the editor role on the projects of the parents a task is moved between,
+// since their completion is rolled up from their subtasks
+func (a *AuthorizedTaskService) requireOnParents(ctx context.Context, oldParentID string, parentID string) error {
+	if parentID == oldParentID {
+		return nil
+	}
+	for _, id := range []string{oldParentID, parentID} {
+		if err := a.requireOnLinked(ctx, id, entity.Editor, "change subtasks"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requireOnProjectOf checks the role of the caller on the project of the task like requireOnTask, for the operations
+// on the tasks that may be in the trash
+func (a *AuthorizedTaskService) requireOnProjectOf(ctx context.Context, id string, role entity.Role, operation string) error {
+	projectID, err := a.TaskService.ProjectOf(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.require(ctx, projectID, role, operation)
+}
+
+func (a *AuthorizedTaskService) Create(ctx context.Context, req *entity.TaskDescription) (*entity.Task, error) {
+	projectID := req.ProjectID
+	if projectID == "" {
+		projectID = entity.DefaultProjectID
+	}
+	if err := a.require(ctx, projectID, entity.Editor, "create tasks"); err != nil {
+		return nil, err
+	}
+	if err := a.requireOnParents(ctx, "", req.ParentID); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Create(ctx, req)
+}
+
+func (a *AuthorizedTaskService) Get(ctx context.Context, query *entity.TaskQuery) (*entity.TaskList, error) {
+	if err := a.require(ctx, query.ProjectID, entity.Viewer, "list tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Get(ctx, query)
+}
+
+func (a *AuthorizedTaskService) GetByID(ctx context.Context, id string) (*entity.Task, error) {
+	return a.requireOnTask(ctx, id, entity.Viewer, "read tasks")
+}
+
+// DeleteByID requires the admin role on the project of the task, and on the projects of its subtasks the delete policy
+// applies to, which may be other projects: the admin role when they are moved to the trash with it, the editor role when they are detached
+func (a *AuthorizedTaskService) DeleteByID(ctx context.Context, id string) error {
+	task, err := a.requireOnTask(ctx, id, entity.Admin, "delete tasks")
+	if err != nil {
+		return err
+	}
+	checked := map[string]bool{task.ProjectID: true}
+	requireOnce := func(projectID string, role entity.Role, operation string) error {
+		if checked[projectID] {
+			return nil
+		}
+		checked[projectID] = true
+		return a.require(ctx, projectID, role, operation)
+	}
+	switch a.TaskService.GetDeletePolicy(ctx) {
+	case hierarchy.Cascade:
+		tree, err := a.TaskService.Tree(ctx, id)
+		if err != nil {
+			return err
+		}
+		for nodes := append([]*entity.TaskNode(nil), tree.Children...); len(nodes) > 0; {
+			node := nodes[0]
+			nodes = append(nodes[1:], node.Children...)
+			if err = requireOnce(node.Task.ProjectID, entity.Admin, "delete tasks"); err != nil {
+				return err
+			}
+		}
+	case hierarchy.Orphan:
+		children, err := a.TaskService.Children(ctx, id)
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			if err = requireOnce(child.ProjectID, entity.Editor, "update tasks"); err != nil {
+				return err
+			}
+		}
+	}
+	return a.TaskService.DeleteByID(ctx, id)
+}
+
+func (a *AuthorizedTaskService) Restore(ctx context.Context, id string) (*entity.Task, error) {
+	if err := a.requireOnProjectOf(ctx, id, entity.Admin, "restore tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Restore(ctx, id)
+}
+
+func (a *AuthorizedTaskService) ProjectOf(ctx context.Context, id string) (string, error) {
+	if err := a.requireOnProjectOf(ctx, id, entity.Viewer, "read tasks"); err != nil {
+		return "", err
+	}
+	return a.TaskService.ProjectOf(ctx, id)
+}
+
+// PurgeTrash is only run by the background jobs, which do not act for a caller
+func (a *AuthorizedTaskService) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	return a.TaskService.PurgeTrash(ctx, retention)
+}
+
+// SpawnOccurrences is only run by the background jobs, which do not act for a caller
+func (a *AuthorizedTaskService) SpawnOccurrences(ctx context.Context) (int, error) {
+	return a.TaskService.SpawnOccurrences(ctx)
+}
+
+func (a *AuthorizedTaskService) Children(ctx context.Context, id string) ([]*entity.Task, error) {
+	if _, err := a.requireOnTask(ctx, id, entity.Viewer, "read tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Children(ctx, id)
+}
+
+func (a *AuthorizedTaskService) Tree(ctx context.Context, id string) (*entity.TaskNode, error) {
+	if _, err := a.requireOnTask(ctx, id, entity.Viewer, "read tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Tree(ctx, id)
+}
+
+func (a *AuthorizedTaskService) Blockers(ctx context.Context, id string) ([]*entity.Task, error) {
+	if _, err := a.requireOnTask(ctx, id, entity.Viewer, "read tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Blockers(ctx, id)
+}
+
+// AddBlocker also requires the viewer role on the project of the blocker, whose status then shows on the blocked task
+func (a *AuthorizedTaskService) AddBlocker(ctx context.Context, id string, blockerID string) (*entity.TaskDependency, error) {
+	if _, err := a.requireOnTask(ctx, id, entity.Editor, "add blockers"); err != nil {
+		return nil, err
+	}
+	if err := a.requireOnLinked(ctx, blockerID, entity.Viewer, "read tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.AddBlocker(ctx, id, blockerID)
+}
+
+func (a *AuthorizedTaskService) RemoveBlocker(ctx context.Context, id string, blockerID string) error {
+	if _, err := a.requireOnTask(ctx, id, entity.Editor, "remove blockers"); err != nil {
+		return err
+	}
+	return a.TaskService.RemoveBlocker(ctx, id, blockerID)
+}
+
+func (a *AuthorizedTaskService) Next(ctx context.Context, limit int) ([]*entity.PlannedTask, error) {
+	if err := a.require(ctx, "", entity.Viewer, "plan tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Next(ctx, limit)
+}
+
+func (a *AuthorizedTaskService) AddLabel(ctx context.Context, id string, labelID string) error {
+	if _, err := a.requireOnTask(ctx, id, entity.Editor, "attach labels"); err != nil {
+		return err
+	}
+	return a.TaskService.AddLabel(ctx, id, labelID)
+}
+
+func (a *AuthorizedTaskService) RemoveLabel(ctx context.Context, id string, labelID string) error {
+	if _, err := a.requireOnTask(ctx, id, entity.Editor, "detach labels"); err != nil {
+		return err
+	}
+	return a.TaskService.RemoveLabel(ctx, id, labelID)
+}
+
+// History is also read for the tasks in the trash
+func (a *AuthorizedTaskService) History(ctx context.Context, id string, query *entity.HistoryQuery) (*entity.TaskHistory, error) {
+	if err := a.requireOnProjectOf(ctx, id, entity.Viewer, "read tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.History(ctx, id, query)
+}
+
+// UpdatePartial requires the editor role on the project of the task, and on the project it is moved to if any,
+// as well as on the projects of its old and new parent when it is moved under another task
+func (a *AuthorizedTaskService) UpdatePartial(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
+	task, err := a.requireOnTask(ctx, id, entity.Editor, "update tasks")
+	if err != nil {
+		return nil, err
+	}
+	if req.ProjectID != "" && req.ProjectID != task.ProjectID {
+		if err = a.require(ctx, req.ProjectID, entity.Editor, "move tasks"); err != nil {
+			return nil, err
+		}
+	}
+	if req.ParentID != "" {
+		if err = a.requireOnParents(ctx, task.ParentID, req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	return a.TaskService.UpdatePartial(ctx, req, id, version)
+}
+
+// RemoveParent requires the editor role on the project of the task and on the project of the parent it leaves
+func (a *AuthorizedTaskService) RemoveParent(ctx context.Context, id string, version int) (*entity.Task, error) {
+	task, err := a.requireOnTask(ctx, id, entity.Editor, "update tasks")
+	if err != nil {
+		return nil, err
+	}
+	if err = a.requireOnParents(ctx, task.ParentID, ""); err != nil {
+		return nil, err
+	}
+	return a.TaskService.RemoveParent(ctx, id, version)
+}
+
+// UpdateFully requires the admin role on the project of the task, and on the project it is moved to if any,
+// since replacing a task discards the values that are not given. The editor role is required on the projects
+// of its old and new parent when it changes.
+func (a *AuthorizedTaskService) UpdateFully(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
+	task, err := a.requireOnTask(ctx, id, entity.Admin, "replace tasks")
+	if err != nil {
+		return nil, err
+	}
+	if req.ProjectID != "" && req.ProjectID != task.ProjectID {
+		if err = a.require(ctx, req.ProjectID, entity.Admin, "move tasks"); err != nil {
+			return nil, err
+		}
+	}
+	if err = a.requireOnParents(ctx, task.ParentID, req.ParentID); err != nil {
+		return nil, err
+	}
+	return a.TaskService.UpdateFully(ctx, req, id, version)
+}
+
+func (a *AuthorizedTaskService) Assign(ctx context.Context, id string, assigneeID string, version int) (*entity.Task, error) {
+	if _, err := a.requireOnTask(ctx, id, entity.Editor, "assign tasks"); err != nil {
+		return nil, err
+	}
+	return a.TaskService.Assign(ctx, id, assigneeID, version)
+}
+
+func (a *AuthorizedTaskService) GetWorkflow(ctx context.Context) *workflow.Workflow {
+	return a.TaskService.GetWorkflow(ctx)
+}
+
+func (a *AuthorizedTaskService) GetDeletePolicy(ctx context.Context) hierarchy.DeletePolicy {
+	return a.TaskService.GetDeletePolicy(ctx)
+}
+
+// AuthorizedLabelService checks the role of the caller before passing the calls to the label service. The labels are shared
+// by all the projects of the tenant, so everyone reads the catalogue and only the admins of all the projects change it.
+type AuthorizedLabelService struct {
+	LabelService interfaces.ILabelService
+	RoleService  interfaces.IRoleService
+}
+
+// NewAuthorizedLabelService is the constructor of an AuthorizedLabelService wrapping the label service
+func NewAuthorizedLabelService(labels interfaces.ILabelService, roles interfaces.IRoleService) *AuthorizedLabelService {
+	if labels == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &AuthorizedLabelService{LabelService: labels, RoleService: roles}
+}
+
+func (a *AuthorizedLabelService) Create(ctx context.Context, req *entity.LabelDescription) (*entity.Label, error) {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "create labels"); err != nil {
+		return nil, err
+	}
+	return a.LabelService.Create(ctx, req)
+}
+
+func (a *AuthorizedLabelService) List(ctx context.Context) ([]*entity.Label, error) {
+	return a.LabelService.List(ctx)
+}
+
+func (a *AuthorizedLabelService) GetByID(ctx context.Context, id string) (*entity.Label, error) {
+	return a.LabelService.GetByID(ctx, id)
+}
+
+func (a *AuthorizedLabelService) UpdateFully(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "update labels"); err != nil {
+		return nil, err
+	}
+	return a.LabelService.UpdateFully(ctx, req, id)
+}
+
+func (a *AuthorizedLabelService) UpdatePartial(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "update labels"); err != nil {
+		return nil, err
+	}
+	return a.LabelService.UpdatePartial(ctx, req, id)
+}
+
+func (a *AuthorizedLabelService) DeleteByID(ctx context.Context, id string) error {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "delete labels"); err != nil {
+		return err
+	}
+	return a.LabelService.DeleteByID(ctx, id)
+}
+
+// AuthorizedProjectService checks the role of the caller before passing the calls to the project service:
+//   - everyone reads the projects
+//   - the admins of a project change its settings
+//   - the admins of all the projects create and delete them
+type AuthorizedProjectService struct {
+	ProjectService interfaces.IProjectService
+	RoleService    interfaces.IRoleService
+}
+
+// NewAuthorizedProjectService is the constructor of an AuthorizedProjectService wrapping the project service
+func NewAuthorizedProjectService(projects interfaces.IProjectService, roles interfaces.IRoleService) *AuthorizedProjectService {
+	if projects == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &AuthorizedProjectService{ProjectService: projects, RoleService: roles}
+}
+
+func (a *AuthorizedProjectService) Create(ctx context.Context, req *entity.ProjectDescription) (*entity.Project, error) {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "create projects"); err != nil {
+		return nil, err
+	}
+	return a.ProjectService.Create(ctx, req)
+}
+
+func (a *AuthorizedProjectService) List(ctx context.Context) ([]*entity.Project, error) {
+	return a.ProjectService.List(ctx)
+}
+
+func (a *AuthorizedProjectService) GetByID(ctx context.Context, id string) (*entity.Project, error) {
+	return a.ProjectService.GetByID(ctx, id)
+}
+
+func (a *AuthorizedProjectService) UpdateFully(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	if err := requireRole(ctx, a.RoleService, id, entity.Admin, "update projects"); err != nil {
+		return nil, err
+	}
+	return a.ProjectService.UpdateFully(ctx, req, id)
+}
+
+func (a *AuthorizedProjectService) UpdatePartial(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	if err := requireRole(ctx, a.RoleService, id, entity.Admin, "update projects"); err != nil {
+		return nil, err
+	}
+	return a.ProjectService.UpdatePartial(ctx, req, id)
+}
+
+func (a *AuthorizedProjectService) DeleteByID(ctx context.Context, id string) error {
+	if err := requireRole(ctx, a.RoleService, "", entity.Admin, "delete projects"); err != nil {
+		return err
+	}
+	return a.ProjectService.DeleteByID(ctx, id)
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"testing"
+)
+
+// stubTaskService knows the task "t1" of the project p1, the task "t2" of the project p2 and the task "t4" of p1 in the trash,
+// and accepts every operation on them. The task "t1" has the subtask "t5" of the project p2, which has the subtask "t6" of p1,
+// and the task "t7" of p1 is a subtask of "t2".
+// The methods the tests do not call are left to the embedded nil interface.
+type stubTaskService struct {
+	interfaces.ITaskService
+	policy hierarchy.DeletePolicy // what deleting a task does to its subtasks, Refuse when empty
+}
+
+func (s stubTaskService) GetDeletePolicy(ctx context.Context) hierarchy.DeletePolicy {
+	if s.policy == "" {
+		return hierarchy.Refuse
+	}
+	return s.policy
+}
+
+func (s stubTaskService) Children(ctx context.Context, id string) ([]*entity.Task, error) {
+	tree, err := s.Tree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	children := make([]*entity.Task, len(tree.Children))
+	for i, child := range tree.Children {
+		children[i] = child.Task
+	}
+	return children, nil
+}
+
+func (s stubTaskService) Tree(ctx context.Context, id string) (*entity.TaskNode, error) {
+	task, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	node := &entity.TaskNode{Task: task}
+	if id == "t1" {
+		grandchild := &entity.TaskNode{Task: &entity.Task{ID: "t6", TaskDescription: entity.TaskDescription{ProjectID: "p1", ParentID: "t5"}}}
+		node.Children = []*entity.TaskNode{{Task: &entity.Task{ID: "t5", TaskDescription: entity.TaskDescription{ProjectID: "p2", ParentID: "t1"}},
+			Children: []*entity.TaskNode{grandchild}}}
+	}
+	return node, nil
+}
+
+func (s stubTaskService) GetByID(ctx context.Context, id string) (*entity.Task, error) {
+	switch id {
+	case "t1":
+		return &entity.Task{ID: id, TaskDescription: entity.TaskDescription{ProjectID: "p1"}}, nil
+	case "t2":
+		return &entity.Task{ID: id, TaskDescription: entity.TaskDescription{ProjectID: "p2"}}, nil
+	case "t7":
+		return &entity.Task{ID: id, TaskDescription: entity.TaskDescription{ProjectID: "p1", ParentID: "t2"}}, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "task not found")
+}
+
+func (s stubTaskService) ProjectOf(ctx context.Context, id string) (string, error) {
+	if id == "t4" {
+		return "p1", nil
+	}
+	task, err := s.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return task.ProjectID, nil
+}
+
+func (s stubTaskService) History(ctx context.Context, id string, query *entity.HistoryQuery) (*entity.TaskHistory, error) {
+	return &entity.TaskHistory{}, nil
+}
+
+func (s stubTaskService) Create(ctx context.Context, req *entity.TaskDescription) (*entity.Task, error) {
+	return &entity.Task{ID: "t3", TaskDescription: *req}, nil
+}
+
+func (s stubTaskService) Get(ctx context.Context, query *entity.TaskQuery) (*entity.TaskList, error) {
+	return &entity.TaskList{}, nil
+}
+
+func (s stubTaskService) AddBlocker(ctx context.Context, id string, blockerID string) (*entity.TaskDependency, error) {
+	return &entity.TaskDependency{TaskID: id, BlockerID: blockerID}, nil
+}
+
+func (s stubTaskService) DeleteByID(ctx context.Context, id string) error {
+	return nil
+}
+
+func (s stubTaskService) Restore(ctx context.Context, id string) (*entity.Task, error) {
+	return &entity.Task{ID: id, TaskDescription: entity.TaskDescription{ProjectID: "p1"}}, nil
+}
+
+func (s stubTaskService) UpdatePartial(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
+	return s.GetByID(ctx, id)
+}
+
+func (s stubTaskService) UpdateFully(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
+	return s.GetByID(ctx, id)
+}
+
+func (s stubTaskService) RemoveParent(ctx context.Context, id string, version int) (*entity.Task, error) {
+	return s.GetByID(ctx, id)
+}
+
+func (s stubTaskService) Assign(ctx context.Context, id string, assigneeID string, version int) (*entity.Task, error) {
+	return s.GetByID(ctx, id)
+}
+
+func TestAuthorizedTaskService(t1 *testing.T) {
+	roles := newTestRoleService()
+	// alice edits all the projects and administers p1, bob only views p2, carol has the default role
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "alice", Role: entity.Editor},
+		{Subject: "alice", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "bob", Role: entity.Editor},
+		{Subject: "bob", ProjectID: "p2", Role: entity.Viewer},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	a := NewAuthorizedTaskService(stubTaskService{}, roles)
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context) error
+		allowed []string
+	}{
+		{name: "should let everyone read", call: func(ctx context.Context) error {
+			_, err := a.GetByID(ctx, "t2")
+			return err
+		}, allowed: []string{"alice", "bob", "carol"}},
+		{name: "should let everyone list", call: func(ctx context.Context) error {
+			_, err := a.Get(ctx, &entity.TaskQuery{ProjectID: "p2"})
+			return err
+		}, allowed: []string{"alice", "bob", "carol"}},
+		{name: "should let the editors create in the default project", call: func(ctx context.Context) error {
+			_, err := a.Create(ctx, &entity.TaskDescription{Title: "new"})
+			return err
+		}, allowed: []string{"alice", "bob"}},
+		{name: "should let the editors of the project create", call: func(ctx context.Context) error {
+			_, err := a.Create(ctx, &entity.TaskDescription{Title: "new", ProjectID: "p2"})
+			return err
+		}, allowed: []string{"alice"}},
+		{name: "should let the editors update partially", call: func(ctx context.Context) error {
+			_, err := a.UpdatePartial(ctx, &entity.TaskDescription{Title: "renamed"}, "t1", 0)
+			return err
+		}, allowed: []string{"alice", "bob"}},
+		{name: "should let the editors of both projects move a task", call: func(ctx context.Context) error {
+			_, err := a.UpdatePartial(ctx, &entity.TaskDescription{ProjectID: "p2"}, "t1", 0)
+			return err
+		}, allowed: []string{"alice"}},
+		{name: "should let the editors assign", call: func(ctx context.Context) error {
+			_, err := a.Assign(ctx, "t1", "carol", 0)
+			return err
+		}, allowed: []string{"alice", "bob"}},
+		{name: "should let the admins of the project replace", call: func(ctx context.Context) error {
+			_, err := a.UpdateFully(ctx, &entity.TaskDescription{Title: "replaced"}, "t1", 0)
+			return err
+		}, allowed: []string{"alice"}},
+		{name: "should let the admins of the project delete", call: func(ctx context.Context) error {
+			return a.DeleteByID(ctx, "t1")
+		}, allowed: []string{"alice"}},
+		{name: "should not let an admin of a single project delete elsewhere", call: func(ctx context.Context) error {
+			return a.DeleteByID(ctx, "t2")
+		}},
+		{name: "should let everyone read the history of a task in the trash", call: func(ctx context.Context) error {
+			_, err := a.History(ctx, "t4", &entity.HistoryQuery{})
+			return err
+		}, allowed: []string{"alice", "bob", "carol"}},
+		{name: "should let the admins of the project restore", call: func(ctx context.Context) error {
+			_, err := a.Restore(ctx, "t4")
+			return err
+		}, allowed: []string{"alice"}},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			for _, subject := range []string{"alice", "bob", "carol"} {
+				allowed := false
+				for _, s := range tt.allowed {
+					allowed = allowed || s == subject
+				}
+				err := tt.call(callerCtx(subject))
+				if allowed && err != nil {
+					t1.Errorf("%s: error = %v, want allowed", subject, err)
+				}
+				if !allowed && !errors.Is(err, errs.ErrForbidden) {
+					t1.Errorf("%s: error = %v, want %v", subject, err, errs.ErrForbidden)
+				}
+			}
+		})
+	}
+
+	// a task that does not exist is not found, whatever the role
+	if _, err := a.UpdateFully(callerCtx("carol"), &entity.TaskDescription{}, "missing", 0); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("UpdateFully() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
+
+func TestAuthorizedTaskService_Links(t1 *testing.T) {
+	roles := newTestRoleService()
+	// nobody can read a project without a role on it: dave only edits p1, erin also views p2, frank edits both
+	roles.DefaultRole = ""
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "dave", ProjectID: "p1", Role: entity.Editor},
+		{Subject: "erin", ProjectID: "p1", Role: entity.Editor},
+		{Subject: "erin", ProjectID: "p2", Role: entity.Viewer},
+		{Subject: "frank", ProjectID: "p1", Role: entity.Editor},
+		{Subject: "frank", ProjectID: "p2", Role: entity.Editor},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	a := NewAuthorizedTaskService(stubTaskService{}, roles)
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context) error
+		allowed []string
+	}{
+		{name: "should let the viewers of the project of the blocker add it", call: func(ctx context.Context) error {
+			_, err := a.AddBlocker(ctx, "t1", "t2")
+			return err
+		}, allowed: []string{"erin", "frank"}},
+		{name: "should leave a blocker not found to the task service", call: func(ctx context.Context) error {
+			_, err := a.AddBlocker(ctx, "t1", "missing")
+			return err
+		}, allowed: []string{"dave", "erin", "frank"}},
+		{name: "should let the editors of the project of the parent create a subtask", call: func(ctx context.Context) error {
+			_, err := a.Create(ctx, &entity.TaskDescription{Title: "new", ProjectID: "p1", ParentID: "t2"})
+			return err
+		}, allowed: []string{"frank"}},
+		{name: "should let the editors of the project of the parent move a task under it", call: func(ctx context.Context) error {
+			_, err := a.UpdatePartial(ctx, &entity.TaskDescription{ParentID: "t2"}, "t1", 0)
+			return err
+		}, allowed: []string{"frank"}},
+		{name: "should let the editors of the project of the parent make its subtask a top-level task", call: func(ctx context.Context) error {
+			_, err := a.RemoveParent(ctx, "t7", 0)
+			return err
+		}, allowed: []string{"frank"}},
+		{name: "should let the editors of the project of a top-level task remove its missing parent", call: func(ctx context.Context) error {
+			_, err := a.RemoveParent(ctx, "t1", 0)
+			return err
+		}, allowed: []string{"dave", "erin", "frank"}},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			for _, subject := range []string{"dave", "erin", "frank"} {
+				allowed := false
+				for _, s := range tt.allowed {
+					allowed = allowed || s == subject
+				}
+				err := tt.call(callerCtx(subject))
+				if allowed && err != nil {
+					t1.Errorf("%s: error = %v, want allowed", subject, err)
+				}
+				if !allowed && !errors.Is(err, errs.ErrForbidden) {
+					t1.Errorf("%s: error = %v, want %v", subject, err, errs.ErrForbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizedTaskService_DeleteSubtasks(t1 *testing.T) {
+	roles := newTestRoleService()
+	// all of them administer p1, gina edits p2, hank administers it, ivy has the default role on it
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "gina", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "gina", ProjectID: "p2", Role: entity.Editor},
+		{Subject: "hank", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "hank", ProjectID: "p2", Role: entity.Admin},
+		{Subject: "ivy", ProjectID: "p1", Role: entity.Admin},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		policy  hierarchy.DeletePolicy
+		allowed []string
+	}{
+		// the subtask of p2 is moved to the trash with its parent
+		{policy: hierarchy.Cascade, allowed: []string{"hank"}},
+		// the subtask of p2 is detached from its parent
+		{policy: hierarchy.Orphan, allowed: []string{"gina", "hank"}},
+		// the subtasks are left as they are, the task service refuses to delete a parent
+		{policy: hierarchy.Refuse, allowed: []string{"gina", "hank", "ivy"}},
+	}
+	for _, tt := range tests {
+		t1.Run(string(tt.policy), func(t1 *testing.T) {
+			a := NewAuthorizedTaskService(stubTaskService{policy: tt.policy}, roles)
+			for _, subject := range []string{"gina", "hank", "ivy"} {
+				allowed := false
+				for _, s := range tt.allowed {
+					allowed = allowed || s == subject
+				}
+				err := a.DeleteByID(callerCtx(subject), "t1")
+				if allowed && err != nil {
+					t1.Errorf("%s: error = %v, want allowed", subject, err)
+				}
+				if !allowed && !errors.Is(err, errs.ErrForbidden) {
+					t1.Errorf("%s: error = %v, want %v", subject, err, errs.ErrForbidden)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizedLabelAndProjectServices(t1 *testing.T) {
+	roles := newTestRoleService()
+	// dave administers all the projects, erin only p1, bob edits all the projects, carol has the default role
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "dave", Role: entity.Admin},
+		{Subject: "erin", Role: entity.Viewer},
+		{Subject: "erin", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "bob", Role: entity.Editor},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	labels := NewAuthorizedLabelService(NewLabelService(mockLabelRepository{labels: map[string]*entity.Label{
+		"l1": {ID: "l1", LabelDescription: entity.LabelDescription{Name: "bug"}},
+	}}), roles)
+	projects := NewAuthorizedProjectService(NewProjectService(mockProjectRepository{projects: map[string]*entity.Project{
+		"p1": {ID: "p1", ProjectDescription: entity.ProjectDescription{Name: "one"}},
+		"p2": {ID: "p2", ProjectDescription: entity.ProjectDescription{Name: "two"}},
+	}}), roles)
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context) error
+		allowed []string
+	}{
+		{name: "should let everyone read the labels", call: func(ctx context.Context) error {
+			_, err := labels.GetByID(ctx, "l1")
+			return err
+		}, allowed: []string{"bob", "carol", "dave", "erin"}},
+		{name: "should only let the admins of all the projects create labels", call: func(ctx context.Context) error {
+			_, err := labels.Create(ctx, &entity.LabelDescription{Name: "feature"})
+			return err
+		}, allowed: []string{"dave"}},
+		{name: "should only let the admins of all the projects update labels", call: func(ctx context.Context) error {
+			_, err := labels.UpdatePartial(ctx, &entity.LabelDescription{Color: "#00ff00"}, "l1")
+			return err
+		}, allowed: []string{"dave"}},
+		{name: "should only let the admins of all the projects delete labels", call: func(ctx context.Context) error {
+			return labels.DeleteByID(ctx, "l1")
+		}, allowed: []string{"dave"}},
+		{name: "should let everyone read the projects", call: func(ctx context.Context) error {
+			_, err := projects.GetByID(ctx, "p1")
+			return err
+		}, allowed: []string{"bob", "carol", "dave", "erin"}},
+		{name: "should only let the admins of all the projects create projects", call: func(ctx context.Context) error {
+			_, err := projects.Create(ctx, &entity.ProjectDescription{Name: "three"})
+			return err
+		}, allowed: []string{"dave"}},
+		{name: "should let the admins of the project update its settings", call: func(ctx context.Context) error {
+			_, err := projects.UpdatePartial(ctx, &entity.ProjectDescription{Description: "first"}, "p1")
+			return err
+		}, allowed: []string{"dave", "erin"}},
+		{name: "should not let an admin of a single project update another one", call: func(ctx context.Context) error {
+			_, err := projects.UpdateFully(ctx, &entity.ProjectDescription{Name: "two"}, "p2")
+			return err
+		}, allowed: []string{"dave"}},
+		{name: "should only let the admins of all the projects delete projects", call: func(ctx context.Context) error {
+			return projects.DeleteByID(ctx, "p2")
+		}, allowed: []string{"dave"}},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			// dave comes last so that the calls denied to the others still find what dave changes or deletes
+			for _, subject := range []string{"bob", "carol", "erin", "dave"} {
+				allowed := false
+				for _, s := range tt.allowed {
+					allowed = allowed || s == subject
+				}
+				err := tt.call(callerCtx(subject))
+				if allowed && err != nil {
+					t1.Errorf("%s: error = %v, want allowed", subject, err)
+				}
+				if !allowed && !errors.Is(err, errs.ErrForbidden) {
+					t1.Errorf("%s: error = %v, want %v", subject, err, errs.ErrForbidden)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+)
+
+// ChecklistService manages the checklists of the tasks. The task service it is given checks that the caller can read the task,
+// so the checklist of a task is read by its viewers, and changed by the editors of its project.
+type ChecklistService struct {
+	ChecklistRepository interfaces.IChecklistRepository
+	TaskService         interfaces.ITaskService
+	RoleService         interfaces.IRoleService
+}
+
+// NewChecklistService is the constructor of a ChecklistService, tasks should be the task service checking the roles of the callers
+func NewChecklistService(repo interfaces.IChecklistRepository, tasks interfaces.ITaskService, roles interfaces.IRoleService) *ChecklistService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	if tasks == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &ChecklistService{ChecklistRepository: repo, TaskService: tasks, RoleService: roles}
+}
+
+// List returns the items of the checklist of the task in their order
+func (c *ChecklistService) List(ctx context.Context, taskID string) ([]*entity.ChecklistItem, error) {
+	if _, err := c.TaskService.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	log.Printf("listing checklist of task with id '%s' ...", taskID)
+	return c.repo(ctx).FindByTask(taskID)
+}
+
+// Add adds an unchecked item to the checklist of the task, at the end unless a position is given
+func (c *ChecklistService) Add(ctx context.Context, taskID string, req *entity.NewChecklistItem) (*entity.ChecklistItem, error) {
+	item, err := validation.ValidateChecklistItem(req)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.requireEditor(ctx, taskID, "change checklists"); err != nil {
+		return nil, err
+	}
+	created := entity.ChecklistItem{ID: uuid.NewString(), TaskID: taskID, Position: item.Position, ChecklistItemBody: item.ChecklistItemBody}
+	log.Printf("adding checklist item with ID '%s' to task with id '%s' ...", created.ID, taskID)
+	if err = c.repo(ctx).Create(&created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// Move puts an item of the checklist at a new position, the last one when the position is after the last item
+func (c *ChecklistService) Move(ctx context.Context, taskID string, id string, req *entity.ChecklistMove) (*entity.ChecklistItem, error) {
+	if err := validation.ValidateChecklistMove(req); err != nil {
+		return nil, err
+	}
+	if err := c.requireEditor(ctx, taskID, "change checklists"); err != nil {
+		return nil, err
+	}
+	log.Printf("moving checklist item with id '%s' to position %d ...", id, req.Position)
+	if err := c.repo(ctx).Move(taskID, id, req.Position); err != nil {
+		return nil, err
+	}
+	return c.repo(ctx).FindByID(taskID, id)
+}
+
+// SetDone checks or unchecks an item of the checklist, the progress of the checklist on the task follows
+func (c *ChecklistService) SetDone(ctx context.Context, taskID string, id string, done bool) (*entity.ChecklistItem, error) {
+	if err := c.requireEditor(ctx, taskID, "check checklist items"); err != nil {
+		return nil, err
+	}
+	log.Printf("setting done to %t on checklist item with id '%s' ...", done, id)
+	if err := c.repo(ctx).SetDone(taskID, id, done); err != nil {
+		return nil, err
+	}
+	return c.repo(ctx).FindByID(taskID, id)
+}
+
+// Remove removes an item from the checklist of the task, the following items moving up
+func (c *ChecklistService) Remove(ctx context.Context, taskID string, id string) error {
+	if err := c.requireEditor(ctx, taskID, "change checklists"); err != nil {
+		return err
+	}
+	log.Printf("removing checklist item with id '%s' of task with id '%s' ...", id, taskID)
+	return c.repo(ctx).DeleteByID(taskID, id)
+}
+
+// requireEditor returns errs.ErrForbidden unless the caller edits the project of the task, the task is not found if the caller cannot read it
+func (c *ChecklistService) requireEditor(ctx context.Context, taskID string, operation string) error {
+	task, err := c.TaskService.GetByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return requireRole(ctx, c.RoleService, task.ProjectID, entity.Editor, operation)
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the checklist items of this tenant
+func (c *ChecklistService) repo(ctx context.Context) interfaces.IChecklistRepository {
+	return c.ChecklistRepository.WithTenant(principal.Tenant(ctx))
+}
+
+// checkChecklist returns an errs.ErrConflict error if the values close the task while items of its checklist are unchecked,
+// and the project the task ends up in requires them to be checked
+func checkChecklist(repo interfaces.ITaskRepository, old *entity.Task, values map[string]interface{}) error {
+	status, ok := values["status"].(entity.Status)
+	if !ok || status != entity.Closed || old.Status == entity.Closed {
+		return nil
+	}
+	unchecked := old.ChecklistItems - old.ChecklistDone
+	if unchecked == 0 {
+		return nil
+	}
+	projectID := old.ProjectID
+	if id, ok := values["project_id"].(string); ok && id != "" {
+		projectID = id
+	}
+	project, err := repo.FindProject(projectID)
+	if err != nil {
+		return err
+	}
+	if project.RequireChecklist {
+		return errs.New(errs.ErrConflict, "task cannot be closed while %d items of its checklist are unchecked", unchecked)
+	}
+	return nil
+}
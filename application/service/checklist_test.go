@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"sort"
+	"testing"
+)
+
+// mockChecklistRepository keeps the checklist items in memory and renumbers them like the database does, the tenant is ignored
+type mockChecklistRepository struct {
+	items *[]*entity.ChecklistItem
+}
+
+// ordered returns the items of the task in their order
+func (m mockChecklistRepository) ordered(taskID string) []*entity.ChecklistItem {
+	var items []*entity.ChecklistItem
+	for _, item := range *m.items {
+		if item.TaskID == taskID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Position < items[j].Position })
+	return items
+}
+
+// renumber sets the positions of the items of the task from their order in items
+func renumber(items []*entity.ChecklistItem) {
+	for i, item := range items {
+		item.Position = i + 1
+	}
+}
+
+func (m mockChecklistRepository) Create(item *entity.ChecklistItem) error {
+	if _, err := (stubTaskService{}).GetByID(context.Background(), item.TaskID); err != nil {
+		return err
+	}
+	items := m.ordered(item.TaskID)
+	if item.Position < 1 || item.Position > len(items) {
+		item.Position = len(items) + 1
+	}
+	items = append(items[:item.Position-1], append([]*entity.ChecklistItem{item}, items[item.Position-1:]...)...)
+	renumber(items)
+	*m.items = append(*m.items, item)
+	return nil
+}
+
+func (m mockChecklistRepository) FindByTask(taskID string) ([]*entity.ChecklistItem, error) {
+	return m.ordered(taskID), nil
+}
+
+func (m mockChecklistRepository) FindByID(taskID string, id string) (*entity.ChecklistItem, error) {
+	for _, item := range *m.items {
+		if item.TaskID == taskID && item.ID == id {
+			copied := *item
+			return &copied, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "checklist item not found")
+}
+
+func (m mockChecklistRepository) Move(taskID string, id string, position int) error {
+	items := m.ordered(taskID)
+	for i, item := range items {
+		if item.ID == id {
+			items = append(items[:i], items[i+1:]...)
+			if position > len(items)+1 {
+				position = len(items) + 1
+			}
+			renumber(append(items[:position-1], append([]*entity.ChecklistItem{item}, items[position-1:]...)...))
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "checklist item not found")
+}
+
+func (m mockChecklistRepository) SetDone(taskID string, id string, done bool) error {
+	for _, item := range *m.items {
+		if item.TaskID == taskID && item.ID == id {
+			item.Done = done
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "checklist item not found")
+}
+
+func (m mockChecklistRepository) DeleteByID(taskID string, id string) error {
+	for i, item := range *m.items {
+		if item.TaskID == taskID && item.ID == id {
+			*m.items = append((*m.items)[:i], (*m.items)[i+1:]...)
+			renumber(m.ordered(taskID))
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "checklist item not found")
+}
+
+func (m mockChecklistRepository) WithTenant(tenant string) interfaces.IChecklistRepository {
+	return m
+}
+
+func TestChecklistService(t1 *testing.T) {
+	roles := newTestRoleService()
+	// bob edits all the projects, carol has the default role of viewer
+	if err := roles.RoleRepository.Assign(&entity.RoleAssignment{Subject: "bob", Role: entity.Editor}); err != nil {
+		t1.Fatal(err)
+	}
+	c := NewChecklistService(mockChecklistRepository{items: &[]*entity.ChecklistItem{}}, NewAuthorizedTaskService(stubTaskService{}, roles), roles)
+	add := func(text string, position int) *entity.ChecklistItem {
+		item, err := c.Add(callerCtx("bob"), "t1", &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: text}, Position: position})
+		if err != nil {
+			t1.Fatalf("Add() error = %v", err)
+		}
+		return item
+	}
+	// texts returns the texts of the checklist of t1 in their order, with the checked items marked
+	texts := func() []string {
+		items, err := c.List(callerCtx("carol"), "t1")
+		if err != nil {
+			t1.Fatalf("List() error = %v", err)
+		}
+		var texts []string
+		for i, item := range items {
+			if item.Position != i+1 {
+				t1.Errorf("List() item %s at position %d, want %d", item.Text, item.Position, i+1)
+			}
+			if item.Done {
+				texts = append(texts, "[x] "+item.Text)
+			} else {
+				texts = append(texts, item.Text)
+			}
+		}
+		return texts
+	}
+
+	if _, err := c.Add(callerCtx("carol"), "t1", &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "test"}}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Add() error = %v, want %v for a viewer", err, errs.ErrForbidden)
+	}
+	if _, err := c.Add(callerCtx("bob"), "t1", &entity.NewChecklistItem{}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Add() error = %v, want %v for an item without text", err, errs.ErrValidation)
+	}
+	if _, err := c.Add(callerCtx("bob"), "missing", &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "test"}}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Add() error = %v, want %v for a task that does not exist", err, errs.ErrNotFound)
+	}
+	test := add(" test ", 0)
+	deploy := add("deploy", 0)
+	review := add("review", 2)
+	if test.Text != "test" || test.Done || review.Position != 2 {
+		t1.Errorf("Add() got = %+v and %+v, want the trimmed unchecked item and the item inserted second", test, review)
+	}
+	if got := texts(); len(got) != 3 || got[0] != "test" || got[1] != "review" || got[2] != "deploy" {
+		t1.Errorf("List() got = %v, want [test review deploy]", got)
+	}
+
+	moved, err := c.Move(callerCtx("bob"), "t1", deploy.ID, &entity.ChecklistMove{Position: 1})
+	if err != nil {
+		t1.Fatalf("Move() error = %v", err)
+	}
+	if moved.Position != 1 {
+		t1.Errorf("Move() got = %+v, want the item at position 1", moved)
+	}
+	if _, err = c.Move(callerCtx("bob"), "t1", deploy.ID, &entity.ChecklistMove{}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Move() error = %v, want %v for position 0", err, errs.ErrValidation)
+	}
+	if _, err = c.Move(callerCtx("carol"), "t1", deploy.ID, &entity.ChecklistMove{Position: 2}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Move() error = %v, want %v for a viewer", err, errs.ErrForbidden)
+	}
+
+	checked, err := c.SetDone(callerCtx("bob"), "t1", review.ID, true)
+	if err != nil {
+		t1.Fatalf("SetDone() error = %v", err)
+	}
+	if !checked.Done {
+		t1.Errorf("SetDone() got = %+v, want the item checked", checked)
+	}
+	if _, err = c.SetDone(callerCtx("bob"), "t2", review.ID, true); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("SetDone() error = %v, want %v for an item of another task", err, errs.ErrNotFound)
+	}
+	if got := texts(); len(got) != 3 || got[0] != "deploy" || got[1] != "test" || got[2] != "[x] review" {
+		t1.Errorf("List() got = %v, want [deploy test [x] review]", got)
+	}
+
+	if err = c.Remove(callerCtx("bob"), "t1", test.ID); err != nil {
+		t1.Fatalf("Remove() error = %v", err)
+	}
+	if got := texts(); len(got) != 2 || got[0] != "deploy" || got[1] != "[x] review" {
+		t1.Errorf("List() got = %v after the removal, want [deploy [x] review]", got)
+	}
+}
+
+// checklistTasks finds the project "strict", whose tasks can only be closed once their checklist is done, and the project "lax"
+type checklistTasks struct {
+	interfaces.ITaskRepository
+}
+
+func (c checklistTasks) FindProject(id string) (*entity.Project, error) {
+	return &entity.Project{ID: id, ProjectDescription: entity.ProjectDescription{ProjectSettings: entity.ProjectSettings{RequireChecklist: id == "strict"}}}, nil
+}
+
+func TestCheckChecklist(t1 *testing.T) {
+	inProgress := func(projectID string, status entity.Status) *entity.Task {
+		return &entity.Task{ChecklistItems: 5, ChecklistDone: 3, TaskDescription: entity.TaskDescription{ProjectID: projectID, Status: status}}
+	}
+	tests := []struct {
+		name    string
+		old     *entity.Task
+		values  map[string]interface{}
+		wantErr bool
+	}{
+		{name: "should not close while items are unchecked", old: inProgress("strict", entity.Active), values: map[string]interface{}{"status": entity.Closed}, wantErr: true},
+		{name: "should close once all the items are checked", old: &entity.Task{ChecklistItems: 5, ChecklistDone: 5, TaskDescription: entity.TaskDescription{ProjectID: "strict"}}, values: map[string]interface{}{"status": entity.Closed}},
+		{name: "should close when the project does not require the checklist", old: inProgress("lax", entity.Active), values: map[string]interface{}{"status": entity.Closed}},
+		{name: "should not close in a project requiring the checklist", old: inProgress("lax", entity.Active), values: map[string]interface{}{"status": entity.Closed, "project_id": "strict"}, wantErr: true},
+		{name: "should let the other statuses be set", old: inProgress("strict", entity.Active), values: map[string]interface{}{"status": entity.OnHold}},
+		{name: "should let a closed task be updated", old: inProgress("strict", entity.Closed), values: map[string]interface{}{"status": entity.Closed, "title": "renamed"}},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			err := checkChecklist(checklistTasks{}, tt.old, tt.values)
+			if tt.wantErr && !errors.Is(err, errs.ErrConflict) {
+				t1.Errorf("checkChecklist() error = %v, want %v", err, errs.ErrConflict)
+			}
+			if !tt.wantErr && err != nil {
+				t1.Errorf("checkChecklist() error = %v", err)
+			}
+		})
+	}
+}
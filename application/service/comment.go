@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+	"time"
+)
+
+// CommentService manages the comments of the tasks. The task service it is given checks that the caller can read the task, so the
+// comments of a task are read by its viewers. Posting needs the editor role on the project of the task, a comment is only edited
+// by its author, and deleted by its author or an admin of the project.
+type CommentService struct {
+	CommentRepository interfaces.ICommentRepository
+	TaskService       interfaces.ITaskService
+	RoleService       interfaces.IRoleService
+}
+
+// NewCommentService is the constructor of a CommentService, tasks should be the task service checking the roles of the callers
+func NewCommentService(repo interfaces.ICommentRepository, tasks interfaces.ITaskService, roles interfaces.IRoleService) *CommentService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	if tasks == nil || roles == nil {
+		log.Fatalf("nil service provided")
+	}
+	return &CommentService{CommentRepository: repo, TaskService: tasks, RoleService: roles}
+}
+
+// List returns the page of the comments of the task, from the oldest one
+func (c *CommentService) List(ctx context.Context, taskID string, req *entity.CommentQuery) (*entity.CommentList, error) {
+	query, err := validation.ValidateCommentQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.TaskService.GetByID(ctx, taskID); err != nil {
+		return nil, err
+	}
+	log.Printf("listing comments of task with id '%s' ...", taskID)
+	total, err := c.repo(ctx).CountByTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := c.repo(ctx).FindByTask(taskID, query)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.CommentList{Comments: comments, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+// Create posts a comment on the task as the caller
+func (c *CommentService) Create(ctx context.Context, taskID string, req *entity.CommentBody) (*entity.Comment, error) {
+	body, err := validation.ValidateComment(req)
+	if err != nil {
+		return nil, err
+	}
+	task, err := c.TaskService.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err = requireRole(ctx, c.RoleService, task.ProjectID, entity.Editor, "post comments"); err != nil {
+		return nil, err
+	}
+	comment := entity.Comment{ID: uuid.NewString(), TaskID: taskID, Author: principal.Subject(ctx), CommentBody: *body}
+	log.Printf("posting comment with ID '%s' on task with id '%s' ...", comment.ID, taskID)
+	if err = c.repo(ctx).Create(&comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// Update replaces the body of a comment of the caller, the previous body is kept as a revision.
+// errs.ErrForbidden is returned if the comment was posted by someone else.
+func (c *CommentService) Update(ctx context.Context, taskID string, id string, req *entity.CommentBody) (*entity.Comment, error) {
+	body, err := validation.ValidateComment(req)
+	if err != nil {
+		return nil, err
+	}
+	task, comment, err := c.find(ctx, taskID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Author != principal.Subject(ctx) {
+		return nil, errs.New(errs.ErrForbidden, "only the author of comment '%s' can edit it", id)
+	}
+	if err = requireRole(ctx, c.RoleService, task.ProjectID, entity.Editor, "edit comments"); err != nil {
+		return nil, err
+	}
+	if comment.Body == body.Body {
+		return comment, nil
+	}
+	log.Printf("editing comment with id '%s' of task with id '%s' ...", id, taskID)
+	now := time.Now().UTC()
+	revision := entity.CommentRevision{ID: uuid.NewString(), CommentID: id, Body: comment.Body, EditedBy: principal.Subject(ctx), EditedAt: now}
+	comment.Body, comment.EditedAt = body.Body, &now
+	if err = c.repo(ctx).Update(comment, &revision); err != nil {
+		return nil, err
+	}
+	return c.repo(ctx).FindByID(taskID, id)
+}
+
+// Delete removes a comment along with its revisions, the admins of the project of the task can remove the comments of the others
+func (c *CommentService) Delete(ctx context.Context, taskID string, id string) error {
+	task, comment, err := c.find(ctx, taskID, id)
+	if err != nil {
+		return err
+	}
+	if comment.Author == principal.Subject(ctx) {
+		err = requireRole(ctx, c.RoleService, task.ProjectID, entity.Editor, "delete comments")
+	} else {
+		err = requireRole(ctx, c.RoleService, task.ProjectID, entity.Admin, "delete the comments of others")
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("deleting comment with id '%s' of task with id '%s' ...", id, taskID)
+	return c.repo(ctx).DeleteByID(taskID, id)
+}
+
+// Revisions returns the previous bodies of a comment, from the most recent edit
+func (c *CommentService) Revisions(ctx context.Context, taskID string, id string) ([]*entity.CommentRevision, error) {
+	if _, _, err := c.find(ctx, taskID, id); err != nil {
+		return nil, err
+	}
+	log.Printf("getting revisions of comment with id '%s' ...", id)
+	return c.repo(ctx).FindRevisions(id)
+}
+
+// find returns a task the caller can read along with one of its comments, errs.ErrNotFound is returned if the task has no such comment
+func (c *CommentService) find(ctx context.Context, taskID string, id string) (*entity.Task, *entity.Comment, error) {
+	task, err := c.TaskService.GetByID(ctx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	comment, err := c.repo(ctx).FindByID(taskID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return task, comment, nil
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the comments of this tenant
+func (c *CommentService) repo(ctx context.Context) interfaces.ICommentRepository {
+	return c.CommentRepository.WithTenant(principal.Tenant(ctx))
+}
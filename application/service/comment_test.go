@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"testing"
+)
+
+// mockCommentRepository keeps the comments and their revisions in memory in the order they are created, the tenant is ignored
+type mockCommentRepository struct {
+	comments  *[]*entity.Comment
+	revisions *[]*entity.CommentRevision
+}
+
+func newMockCommentRepository() mockCommentRepository {
+	return mockCommentRepository{comments: &[]*entity.Comment{}, revisions: &[]*entity.CommentRevision{}}
+}
+
+func (m mockCommentRepository) Create(comment *entity.Comment) error {
+	*m.comments = append(*m.comments, comment)
+	return nil
+}
+
+func (m mockCommentRepository) FindByTask(taskID string, query *entity.CommentQuery) ([]*entity.Comment, error) {
+	var comments []*entity.Comment
+	for _, comment := range *m.comments {
+		if comment.TaskID == taskID {
+			comments = append(comments, comment)
+		}
+	}
+	if query.Offset >= len(comments) {
+		return nil, nil
+	}
+	comments = comments[query.Offset:]
+	if len(comments) > query.Limit {
+		comments = comments[:query.Limit]
+	}
+	return comments, nil
+}
+
+func (m mockCommentRepository) CountByTask(taskID string) (int64, error) {
+	comments, _ := m.FindByTask(taskID, &entity.CommentQuery{Limit: len(*m.comments)})
+	return int64(len(comments)), nil
+}
+
+func (m mockCommentRepository) FindByID(taskID string, id string) (*entity.Comment, error) {
+	for _, comment := range *m.comments {
+		if comment.TaskID == taskID && comment.ID == id {
+			copied := *comment
+			return &copied, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "comment not found")
+}
+
+func (m mockCommentRepository) Update(comment *entity.Comment, revision *entity.CommentRevision) error {
+	for i, c := range *m.comments {
+		if c.ID == comment.ID {
+			(*m.comments)[i] = comment
+			*m.revisions = append([]*entity.CommentRevision{revision}, *m.revisions...)
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "comment not found")
+}
+
+func (m mockCommentRepository) DeleteByID(taskID string, id string) error {
+	for i, comment := range *m.comments {
+		if comment.TaskID == taskID && comment.ID == id {
+			*m.comments = append((*m.comments)[:i], (*m.comments)[i+1:]...)
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "comment not found")
+}
+
+func (m mockCommentRepository) FindRevisions(commentID string) ([]*entity.CommentRevision, error) {
+	var revisions []*entity.CommentRevision
+	for _, revision := range *m.revisions {
+		if revision.CommentID == commentID {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions, nil
+}
+
+func (m mockCommentRepository) WithTenant(tenant string) interfaces.ICommentRepository {
+	return m
+}
+
+func TestCommentService(t1 *testing.T) {
+	roles := newTestRoleService()
+	// alice and bob edit all the projects, alice administers p1, carol has the default role of viewer
+	for _, assignment := range []*entity.RoleAssignment{
+		{Subject: "alice", Role: entity.Editor},
+		{Subject: "alice", ProjectID: "p1", Role: entity.Admin},
+		{Subject: "bob", Role: entity.Editor},
+	} {
+		if err := roles.RoleRepository.Assign(assignment); err != nil {
+			t1.Fatal(err)
+		}
+	}
+	c := NewCommentService(newMockCommentRepository(), NewAuthorizedTaskService(stubTaskService{}, roles), roles)
+
+	if _, err := c.Create(callerCtx("carol"), "t1", &entity.CommentBody{Body: "me too"}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Create() error = %v, want %v for a viewer", err, errs.ErrForbidden)
+	}
+	if _, err := c.Create(callerCtx("bob"), "t1", &entity.CommentBody{Body: " "}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Create() error = %v, want %v for a blank body", err, errs.ErrValidation)
+	}
+	if _, err := c.Create(callerCtx("bob"), "missing", &entity.CommentBody{Body: "hello"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Create() error = %v, want %v for a task that does not exist", err, errs.ErrNotFound)
+	}
+	first, err := c.Create(callerCtx("bob"), "t1", &entity.CommentBody{Body: " Fixed in **#42** "})
+	if err != nil {
+		t1.Fatalf("Create() error = %v", err)
+	}
+	if first.Author != "bob" || first.TaskID != "t1" || first.Body != "Fixed in **#42**" {
+		t1.Errorf("Create() got = %+v, want the trimmed body posted by bob on t1", first)
+	}
+	second, err := c.Create(callerCtx("alice"), "t1", &entity.CommentBody{Body: "Thanks"})
+	if err != nil {
+		t1.Fatalf("Create() error = %v", err)
+	}
+
+	// the viewers read the comments, from the oldest one
+	list, err := c.List(callerCtx("carol"), "t1", &entity.CommentQuery{Limit: 1, Offset: 1})
+	if err != nil {
+		t1.Fatalf("List() error = %v", err)
+	}
+	if list.Total != 2 || len(list.Comments) != 1 || list.Comments[0].ID != second.ID {
+		t1.Errorf("List() got = %+v, want the second of 2 comments", list)
+	}
+
+	// only the author edits, and the previous body is kept
+	if _, err = c.Update(callerCtx("alice"), "t1", first.ID, &entity.CommentBody{Body: "hijacked"}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Update() error = %v, want %v for another caller than the author", err, errs.ErrForbidden)
+	}
+	edited, err := c.Update(callerCtx("bob"), "t1", first.ID, &entity.CommentBody{Body: "Fixed in #43"})
+	if err != nil {
+		t1.Fatalf("Update() error = %v", err)
+	}
+	if edited.Body != "Fixed in #43" || edited.EditedAt == nil {
+		t1.Errorf("Update() got = %+v, want the new body with its edit time", edited)
+	}
+	if _, err = c.Update(callerCtx("bob"), "t1", first.ID, &entity.CommentBody{Body: "Fixed in #43"}); err != nil {
+		t1.Fatalf("Update() error = %v", err)
+	}
+	revisions, err := c.Revisions(callerCtx("carol"), "t1", first.ID)
+	if err != nil {
+		t1.Fatalf("Revisions() error = %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Body != "Fixed in **#42**" || revisions[0].EditedBy != "bob" {
+		t1.Errorf("Revisions() got = %v, want the single previous body", revisions)
+	}
+	if _, err = c.Update(callerCtx("bob"), "t2", first.ID, &entity.CommentBody{Body: "moved"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Update() error = %v, want %v for a comment of another task", err, errs.ErrNotFound)
+	}
+
+	// the authors delete their comments, the admins of the project the ones of the others
+	if err = c.Delete(callerCtx("bob"), "t1", second.ID); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Delete() error = %v, want %v for another caller than the author", err, errs.ErrForbidden)
+	}
+	if err = c.Delete(callerCtx("alice"), "t1", first.ID); err != nil {
+		t1.Errorf("Delete() error = %v for an admin of the project", err)
+	}
+	if err = c.Delete(callerCtx("alice"), "t1", second.ID); err != nil {
+		t1.Errorf("Delete() error = %v for the author", err)
+	}
+	if list, _ = c.List(callerCtx("carol"), "t1", &entity.CommentQuery{}); list.Total != 0 {
+		t1.Errorf("List() got = %+v after the deletions, want no comment", list)
+	}
+}
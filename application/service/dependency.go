@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/dependency"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"log"
+)
+
+// planSize is the maximum number of open tasks ordered by Next, the most important ones.
+// A task blocked by a less important task than those is left out of the plan.
+const planSize = 1000
+
+// Blockers returns the tasks blocking the task, the most important first, whether they are open or not
+func (t *TaskService) Blockers(ctx context.Context, id string) ([]*entity.Task, error) {
+	log.Printf("listing blockers of task with id '%s' ...", id)
+	if _, err := t.repo(ctx).FindByID(id); err != nil {
+		return nil, err
+	}
+	return t.repo(ctx).FindBlockers(id)
+}
+
+// AddBlocker records on behalf of the principal of the context that the task is blocked by another one.
+// errs.ErrValidation is returned if the dependency would make a cycle and errs.ErrConflict if it already exists.
+func (t *TaskService) AddBlocker(ctx context.Context, id string, blockerID string) (*entity.TaskDependency, error) {
+	log.Printf("blocking task with id '%s' by task with id '%s' ...", id, blockerID)
+	dependency := &entity.TaskDependency{TaskID: id, BlockerID: blockerID, CreatedBy: principal.Subject(ctx)}
+	err := t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if _, err := repo.FindByID(id); err != nil {
+			return err
+		}
+		if blockerID == "" {
+			return blockerViolation("blocker ID cannot be empty")
+		}
+		if _, err := repo.FindByID(blockerID); errors.Is(err, errs.ErrNotFound) {
+			return blockerViolation("blocker task '%s' not found", blockerID)
+		} else if err != nil {
+			return err
+		}
+		if err := checkCycle(repo, id, blockerID); err != nil {
+			return err
+		}
+		if err := repo.AddDependency(dependency); err != nil {
+			return err
+		}
+		changes := []entity.FieldChange{{Field: "blockedBy", New: blockerID}}
+		return repo.AppendEvent(newEvent(ctx, id, entity.BlockerAdded, changes))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dependency, nil
+}
+
+// RemoveBlocker removes the dependency of the task on its blocker
+func (t *TaskService) RemoveBlocker(ctx context.Context, id string, blockerID string) error {
+	log.Printf("unblocking task with id '%s' from task with id '%s' ...", id, blockerID)
+	return t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if err := repo.RemoveDependency(id, blockerID); err != nil {
+			return err
+		}
+		changes := []entity.FieldChange{{Field: "blockedBy", Old: blockerID}}
+		return repo.AppendEvent(newEvent(ctx, id, entity.BlockerRemoved, changes))
+	})
+}
+
+// Next returns the open tasks in the order they can be worked on: after their blockers, and the most important first among the others
+func (t *TaskService) Next(ctx context.Context, limit int) ([]*entity.PlannedTask, error) {
+	limit, err := validation.ValidateLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("planning the next %d tasks ...", limit)
+	tasks, err := t.repo(ctx).FindOpenTasks(planSize)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := t.repo(ctx).FindOpenDependencies()
+	if err != nil {
+		return nil, err
+	}
+	plan := dependency.Plan(tasks, dependencies)
+	if len(plan) > limit {
+		plan = plan[:limit]
+	}
+	return plan, nil
+}
+
+// checkCycle checks that the task does not already block the blocker, directly or through other tasks, since the dependency would make a cycle
+func checkCycle(repo interfaces.ITaskRepository, id string, blockerID string) error {
+	if id == blockerID {
+		return blockerViolation("a task cannot block itself")
+	}
+	seen := map[string]bool{blockerID: true}
+	for level := []string{blockerID}; len(level) > 0; {
+		dependencies, err := repo.FindDependencies(level)
+		if err != nil {
+			return err
+		}
+		level = nil
+		for _, dependency := range dependencies {
+			if dependency.BlockerID == id {
+				return blockerViolation("task '%s' is already blocked by task '%s', directly or not", blockerID, id)
+			}
+			if !seen[dependency.BlockerID] {
+				seen[dependency.BlockerID] = true
+				level = append(level, dependency.BlockerID)
+			}
+		}
+	}
+	return nil
+}
+
+func blockerViolation(format string, args ...interface{}) error {
+	return errs.Validation([]errs.Violation{{Field: "blockerId", Message: fmt.Sprintf(format, args...)}})
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"log"
+)
+
+// Children returns the direct subtasks of the task, ordered by creation
+func (t *TaskService) Children(ctx context.Context, id string) ([]*entity.Task, error) {
+	log.Printf("listing subtasks of task with id '%s' ...", id)
+	if _, err := t.repo(ctx).FindByID(id); err != nil {
+		return nil, err
+	}
+	return t.repo(ctx).FindChildren([]string{id})
+}
+
+// RemoveParent makes the task a top-level one, and rolls up again the completion of the parent it leaves.
+// If version is not 0, the task is only updated if it is still at this version.
+func (t *TaskService) RemoveParent(ctx context.Context, id string, version int) (*entity.Task, error) {
+	log.Printf("removing parent of task with id '%s' ...", id)
+	return t.update(ctx, map[string]interface{}{"parent_id": ""}, id, version)
+}
+
+// Tree returns the task along with all its descendants.
+// The tree is read level by level, so that the number of queries depends on its depth rather than on its number of tasks.
+func (t *TaskService) Tree(ctx context.Context, id string) (*entity.TaskNode, error) {
+	log.Printf("getting tree of task with id '%s' ...", id)
+	task, err := t.repo(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	root := &entity.TaskNode{Task: task, Children: []*entity.TaskNode{}}
+	level := map[string]*entity.TaskNode{id: root}
+	for depth := 1; depth < hierarchy.MaxDepth && len(level) > 0; depth++ {
+		ids := make([]string, 0, len(level))
+		for parentID := range level {
+			ids = append(ids, parentID)
+		}
+		children, err := t.repo(ctx).FindChildren(ids)
+		if err != nil {
+			return nil, err
+		}
+		next := make(map[string]*entity.TaskNode, len(children))
+		for _, child := range children {
+			node := &entity.TaskNode{Task: child, Children: []*entity.TaskNode{}}
+			level[child.ParentID].Children = append(level[child.ParentID].Children, node)
+			next[child.ID] = node
+		}
+		level = next
+	}
+	return root, nil
+}
+
+// checkParent checks that the task identified by id can become a subtask of the parent, id being empty for a task not created yet.
+// The parent must exist and must not be the task or one of its descendants, which would make a cycle,
+// and the hierarchy must not become deeper than hierarchy.MaxDepth.
+func checkParent(repo interfaces.ITaskRepository, id string, parentID string) error {
+	levels := 0 // levels of the hierarchy from the parent up to its top-level ancestor
+	for ancestorID := parentID; ancestorID != ""; levels++ {
+		if ancestorID == id {
+			return parentViolation("a task cannot be a subtask of itself or of one of its subtasks")
+		}
+		if levels == hierarchy.MaxDepth {
+			return parentViolation("the hierarchy cannot be deeper than %d levels", hierarchy.MaxDepth)
+		}
+		ancestor, err := repo.FindByID(ancestorID)
+		if errors.Is(err, errs.ErrNotFound) && ancestorID == parentID {
+			return parentViolation("parent task '%s' not found", parentID)
+		}
+		if err != nil {
+			return err
+		}
+		ancestorID = ancestor.ParentID
+	}
+	height, err := subtreeHeight(repo, id)
+	if err != nil {
+		return err
+	}
+	if levels+height > hierarchy.MaxDepth {
+		return parentViolation("the hierarchy cannot be deeper than %d levels", hierarchy.MaxDepth)
+	}
+	return nil
+}
+
+// subtreeHeight returns the number of levels of the tree of the task, 1 for a task without subtasks or not created yet
+func subtreeHeight(repo interfaces.ITaskRepository, id string) (int, error) {
+	height := 1
+	if id == "" {
+		return height, nil
+	}
+	for level := []string{id}; height <= hierarchy.MaxDepth; height++ {
+		children, err := repo.FindChildren(level)
+		if err != nil {
+			return 0, err
+		}
+		if len(children) == 0 {
+			break
+		}
+		level = level[:0]
+		for _, child := range children {
+			level = append(level, child.ID)
+		}
+	}
+	return height, nil
+}
+
+func parentViolation(format string, args ...interface{}) error {
+	return errs.Validation([]errs.Violation{{Field: "parentId", Message: fmt.Sprintf(format, args...)}})
+}
+
+// rollUp refreshes the completion of the task and then of its ancestors, up to the top-level one
+func rollUp(repo interfaces.ITaskRepository, id string) error {
+	for depth := 0; id != "" && depth < hierarchy.MaxDepth; depth++ {
+		if err := repo.RefreshCompletion(id); err != nil {
+			return err
+		}
+		task, err := repo.FindByID(id)
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil // the ancestors of a task in the trash are not affected by its subtasks
+		}
+		if err != nil {
+			return err
+		}
+		id = task.ParentID
+	}
+	return nil
+}
+
+// deleteChildren applies the delete policy to the subtasks of a task about to be moved to the trash
+func (t *TaskService) deleteChildren(ctx context.Context, repo interfaces.ITaskRepository, task *entity.Task) error {
+	children, err := repo.FindChildren([]string{task.ID})
+	if err != nil || len(children) == 0 {
+		return err
+	}
+	switch t.DeletePolicy {
+	case hierarchy.Cascade:
+		for _, child := range children {
+			if err = t.deleteChildren(ctx, repo, child); err != nil {
+				return err
+			}
+			if err = repo.DeleteByID(child.ID, principal.Subject(ctx)); err != nil {
+				return err
+			}
+			if err = repo.AppendEvent(newEvent(ctx, child.ID, entity.Deleted, nil)); err != nil {
+				return err
+			}
+		}
+	case hierarchy.Orphan:
+		for _, child := range children {
+			if err = t.detach(ctx, repo, child); err != nil {
+				return err
+			}
+		}
+	default:
+		return errs.New(errs.ErrConflict, "task with id '%s' has %d subtasks, they must be deleted or moved first", task.ID, len(children))
+	}
+	return nil
+}
+
+// detach makes the task a top-level one
+func (t *TaskService) detach(ctx context.Context, repo interfaces.ITaskRepository, task *entity.Task) error {
+	values := map[string]interface{}{"parent_id": ""}
+	if err := repo.Update(values, task.ID, 0); err != nil {
+		return err
+	}
+	return repo.AppendEvent(newEvent(ctx, task.ID, entity.Updated, updateChanges(task, values)))
+}
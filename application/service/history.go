@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// History returns the page of the change events of the task, from the most recent one.
+// The history of a task in the trash can still be read, since its last event tells who deleted it.
+func (t *TaskService) History(ctx context.Context, id string, req *entity.HistoryQuery) (*entity.TaskHistory, error) {
+	query, err := validation.ValidateHistoryQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("getting history of task with id '%s' ...", id)
+
+	total, err := t.repo(ctx).CountEvents(id)
+	if err != nil {
+		return nil, err
+	}
+	// tasks created before their history was recorded have no event, the ones that do not exist at all are not found
+	if total == 0 {
+		if _, err = t.repo(ctx).FindByID(id); err != nil {
+			return nil, err
+		}
+	}
+	events, err := t.repo(ctx).FindEvents(id, query)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.TaskHistory{Events: events, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+// newEvent returns the event of a change made to the task by the principal of the context
+func newEvent(ctx context.Context, taskID string, eventType entity.EventType, changes []entity.FieldChange) *entity.TaskEvent {
+	return &entity.TaskEvent{
+		ID:      uuid.NewString(),
+		TaskID:  taskID,
+		Type:    eventType,
+		Actor:   principal.Subject(ctx),
+		At:      time.Now().UTC(),
+		Changes: changes,
+	}
+}
+
+// fieldNames maps the keys of the values map, which are column names, to the names of the fields in the JSON of a task
+var fieldNames = map[string]string{
+	"title":       "title",
+	"description": "description",
+	"priority":    "priority",
+	"status":      "status",
+	"start_at":    "startAt",
+	"due_at":      "dueAt",
+	"recurrence":  "recurrence",
+	"timezone":    "timezone",
+	"template_id": "templateId",
+	"occurrence":  "occurrence",
+	"parent_id":   "parentId",
+	"project_id":  "projectId",
+	"assignee_id": "assigneeId",
+}
+
+// creationChanges lists the initial values of the fields of a new task, the optional ones are omitted when they are not set
+func creationChanges(task *entity.Task) []entity.FieldChange {
+	changes := []entity.FieldChange{
+		{Field: "title", New: task.Title},
+		{Field: "description", New: task.Description},
+		{Field: "priority", New: task.Priority},
+		{Field: "status", New: task.Status},
+		{Field: "projectId", New: task.ProjectID},
+	}
+	if task.StartAt != nil {
+		changes = append(changes, entity.FieldChange{Field: "startAt", New: task.StartAt})
+	}
+	if task.DueAt != nil {
+		changes = append(changes, entity.FieldChange{Field: "dueAt", New: task.DueAt})
+	}
+	if task.Recurrence != "" {
+		changes = append(changes, entity.FieldChange{Field: "recurrence", New: task.Recurrence})
+	}
+	if task.Timezone != "" {
+		changes = append(changes, entity.FieldChange{Field: "timezone", New: task.Timezone})
+	}
+	if task.ParentID != "" {
+		changes = append(changes, entity.FieldChange{Field: "parentId", New: task.ParentID})
+	}
+	if task.AssigneeID != "" {
+		changes = append(changes, entity.FieldChange{Field: "assigneeId", New: task.AssigneeID})
+	}
+	if task.TemplateID != "" {
+		changes = append(changes,
+			entity.FieldChange{Field: "templateId", New: task.TemplateID},
+			entity.FieldChange{Field: "occurrence", New: task.Occurrence})
+	}
+	return changes
+}
+
+// updateChanges lists the fields of the values map whose value differs from the one of the task before the update, ordered by field name
+func updateChanges(old *entity.Task, values map[string]interface{}) []entity.FieldChange {
+	var changes []entity.FieldChange
+	for column, value := range values {
+		previous := fieldValue(old, column)
+		if !sameValue(previous, value) {
+			field, ok := fieldNames[column]
+			if !ok {
+				field = column
+			}
+			changes = append(changes, entity.FieldChange{Field: field, Old: previous, New: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// fieldValue returns the value of the task for a key of the values map, nil for the keys that are not task fields
+func fieldValue(task *entity.Task, column string) interface{} {
+	switch column {
+	case "title":
+		return task.Title
+	case "description":
+		return task.Description
+	case "priority":
+		return task.Priority
+	case "status":
+		return task.Status
+	case "start_at":
+		return task.StartAt
+	case "due_at":
+		return task.DueAt
+	case "recurrence":
+		return task.Recurrence
+	case "timezone":
+		return task.Timezone
+	case "template_id":
+		return task.TemplateID
+	case "occurrence":
+		return task.Occurrence
+	case "parent_id":
+		return task.ParentID
+	case "project_id":
+		return task.ProjectID
+	case "assignee_id":
+		return task.AssigneeID
+	}
+	return nil
+}
+
+// sameValue compares the values of a field, times are compared as instants since the stored ones may not be in the time zone of the request
+func sameValue(a, b interface{}) bool {
+	if ta, ok := a.(*time.Time); ok {
+		if tb, ok := b.(*time.Time); ok {
+			return ta == tb || (ta != nil && tb != nil && ta.Equal(*tb))
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+)
+
+// LabelService manages the catalogue of the labels that can be attached to the tasks
+type LabelService struct {
+	LabelRepository interfaces.ILabelRepository
+}
+
+// NewLabelService is the constructor of a LabelService with the repository dependency injected
+func NewLabelService(repo interfaces.ILabelRepository) *LabelService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	return &LabelService{LabelRepository: repo}
+}
+
+// Create adds a label to the catalogue, errs.ErrConflict is returned if its name is already used
+func (l *LabelService) Create(ctx context.Context, req *entity.LabelDescription) (*entity.Label, error) {
+	description, err := validation.ValidateLabel(req)
+	if err != nil {
+		return nil, err
+	}
+	label := entity.Label{ID: uuid.NewString(), LabelDescription: *description}
+	log.Printf("creating label with ID '%s' ...", label.ID)
+	if err = l.repo(ctx).Create(&label); err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+// List returns all the labels of the catalogue ordered by name
+func (l *LabelService) List(ctx context.Context) ([]*entity.Label, error) {
+	log.Printf("listing labels ...")
+	return l.repo(ctx).FindAll()
+}
+
+func (l *LabelService) GetByID(ctx context.Context, id string) (*entity.Label, error) {
+	log.Printf("getting label with id '%s' ...", id)
+	return l.repo(ctx).FindByID(id)
+}
+
+// UpdateFully replaces the name and the colour of the label
+func (l *LabelService) UpdateFully(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	log.Printf("updating label with id '%s' ...", id)
+	description, err := validation.ValidateLabel(req)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{"name": description.Name, "color": description.Color}
+	return l.update(ctx, values, id)
+}
+
+// UpdatePartial updates only the values set in the request
+func (l *LabelService) UpdatePartial(ctx context.Context, req *entity.LabelDescription, id string) (*entity.Label, error) {
+	log.Printf("updating label with id '%s' ...", id)
+	if err := validation.ValidatePartialLabel(req); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if req.Name != "" {
+		values["name"] = req.Name
+	}
+	if req.Color != "" {
+		values["color"] = req.Color
+	}
+	return l.update(ctx, values, id)
+}
+
+func (l *LabelService) update(ctx context.Context, values map[string]interface{}, id string) (*entity.Label, error) {
+	if len(values) == 0 {
+		return l.repo(ctx).FindByID(id)
+	}
+	if err := l.repo(ctx).Update(values, id); err != nil {
+		return nil, err
+	}
+	return l.repo(ctx).FindByID(id)
+}
+
+// DeleteByID removes the label from the catalogue, it is detached from the tasks it was attached to
+func (l *LabelService) DeleteByID(ctx context.Context, id string) error {
+	log.Printf("deleting label with id '%s' ...", id)
+	return l.repo(ctx).DeleteByID(id)
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the labels of this tenant
+func (l *LabelService) repo(ctx context.Context) interfaces.ILabelRepository {
+	return l.LabelRepository.WithTenant(principal.Tenant(ctx))
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+)
+
+type mockLabelRepository struct {
+	labels map[string]*entity.Label
+}
+
+func (m mockLabelRepository) Create(label *entity.Label) error {
+	for _, existing := range m.labels {
+		if existing.Name == label.Name {
+			return errs.New(errs.ErrConflict, "label already exists")
+		}
+	}
+	m.labels[label.ID] = label
+	return nil
+}
+
+func (m mockLabelRepository) FindAll() ([]*entity.Label, error) {
+	var labels []*entity.Label
+	for _, label := range m.labels {
+		labels = append(labels, label)
+	}
+	return labels, nil
+}
+
+func (m mockLabelRepository) FindByID(id string) (*entity.Label, error) {
+	if label, ok := m.labels[id]; ok {
+		copied := *label
+		return &copied, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "label not found")
+}
+
+func (m mockLabelRepository) Update(fields map[string]interface{}, id string) error {
+	label, ok := m.labels[id]
+	if !ok {
+		return errs.New(errs.ErrNotFound, "label not found")
+	}
+	if name, ok := fields["name"].(string); ok {
+		label.Name = name
+	}
+	if color, ok := fields["color"].(string); ok {
+		label.Color = color
+	}
+	return nil
+}
+
+func (m mockLabelRepository) DeleteByID(id string) error {
+	if _, ok := m.labels[id]; !ok {
+		return errs.New(errs.ErrNotFound, "label not found")
+	}
+	delete(m.labels, id)
+	return nil
+}
+
+func (m mockLabelRepository) WithTenant(tenant string) interfaces.ILabelRepository {
+	return m
+}
+
+func TestLabelService(t1 *testing.T) {
+	l := NewLabelService(mockLabelRepository{labels: make(map[string]*entity.Label)})
+
+	label, err := l.Create(testCtx, &entity.LabelDescription{Name: " Bug ", Color: "#FF0000"})
+	if err != nil {
+		t1.Fatalf("Create() error = %v", err)
+	}
+	if want := (entity.LabelDescription{Name: "bug", Color: "#ff0000"}); label.ID == "" || !reflect.DeepEqual(label.LabelDescription, want) {
+		t1.Errorf("Create() got = %v, want %v normalized", label, want)
+	}
+	if _, err = l.Create(testCtx, &entity.LabelDescription{Name: "BUG"}); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("Create() error = %v, want %v for a name already used", err, errs.ErrConflict)
+	}
+	if _, err = l.Create(testCtx, &entity.LabelDescription{Name: "urgent", Color: "red"}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Create() error = %v, want %v for an invalid colour", err, errs.ErrValidation)
+	}
+
+	got, err := l.UpdatePartial(testCtx, &entity.LabelDescription{Color: "#00FF00"}, label.ID)
+	if want := (entity.LabelDescription{Name: "bug", Color: "#00ff00"}); err != nil || !reflect.DeepEqual(got.LabelDescription, want) {
+		t1.Errorf("UpdatePartial() = %v, %v, want %v", got, err, want)
+	}
+	got, err = l.UpdateFully(testCtx, &entity.LabelDescription{Name: "defect"}, label.ID)
+	if want := (entity.LabelDescription{Name: "defect"}); err != nil || !reflect.DeepEqual(got.LabelDescription, want) {
+		t1.Errorf("UpdateFully() = %v, %v, want %v", got, err, want)
+	}
+	if _, err = l.UpdateFully(testCtx, &entity.LabelDescription{Name: "defect"}, "non-existing-ID"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("UpdateFully() error = %v, want %v", err, errs.ErrNotFound)
+	}
+
+	labels, err := l.List(testCtx)
+	if err != nil || len(labels) != 1 {
+		t1.Errorf("List() = %v, %v, want a single label", labels, err)
+	}
+	if err = l.DeleteByID(testCtx, label.ID); err != nil {
+		t1.Fatalf("DeleteByID() error = %v", err)
+	}
+	if _, err = l.GetByID(testCtx, label.ID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("GetByID() error = %v, want %v after deletion", err, errs.ErrNotFound)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+)
+
+// ProjectService manages the projects the tasks belong to, along with their settings
+type ProjectService struct {
+	ProjectRepository interfaces.IProjectRepository
+}
+
+// NewProjectService is the constructor of a ProjectService with the repository dependency injected
+func NewProjectService(repo interfaces.IProjectRepository) *ProjectService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	return &ProjectService{ProjectRepository: repo}
+}
+
+// Create creates a project, errs.ErrConflict is returned if its name is already used
+func (p *ProjectService) Create(ctx context.Context, req *entity.ProjectDescription) (*entity.Project, error) {
+	description, err := validation.ValidateProject(req)
+	if err != nil {
+		return nil, err
+	}
+	project := entity.Project{ID: uuid.NewString(), ProjectDescription: *description}
+	log.Printf("creating project with ID '%s' ...", project.ID)
+	if err = p.repo(ctx).Create(&project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// List returns all the projects ordered by name
+func (p *ProjectService) List(ctx context.Context) ([]*entity.Project, error) {
+	log.Printf("listing projects ...")
+	return p.repo(ctx).FindAll()
+}
+
+func (p *ProjectService) GetByID(ctx context.Context, id string) (*entity.Project, error) {
+	log.Printf("getting project with id '%s' ...", id)
+	return p.repo(ctx).FindByID(id)
+}
+
+// UpdateFully replaces all the values of the project. The new settings apply to the tasks created or updated afterwards,
+// the tasks already in the project are left as they are.
+func (p *ProjectService) UpdateFully(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	log.Printf("updating project with id '%s' ...", id)
+	description, err := validation.ValidateProject(req)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{"name": description.Name, "description": description.Description,
+		"default_priority": description.DefaultPriority, "allowed_statuses": description.AllowedStatuses, "require_checklist": description.RequireChecklist}
+	return p.update(ctx, values, id)
+}
+
+// UpdatePartial updates only the values set in the request, so RequireChecklist can only be turned off by UpdateFully
+func (p *ProjectService) UpdatePartial(ctx context.Context, req *entity.ProjectDescription, id string) (*entity.Project, error) {
+	log.Printf("updating project with id '%s' ...", id)
+	if err := validation.ValidatePartialProject(req); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if req.Name != "" {
+		values["name"] = req.Name
+	}
+	if req.Description != "" {
+		values["description"] = req.Description
+	}
+	if req.DefaultPriority != 0 {
+		values["default_priority"] = req.DefaultPriority
+	}
+	if len(req.AllowedStatuses) > 0 {
+		values["allowed_statuses"] = req.AllowedStatuses
+	}
+	if req.RequireChecklist {
+		values["require_checklist"] = true
+	}
+	return p.update(ctx, values, id)
+}
+
+func (p *ProjectService) update(ctx context.Context, values map[string]interface{}, id string) (*entity.Project, error) {
+	if len(values) == 0 {
+		return p.repo(ctx).FindByID(id)
+	}
+	if err := p.repo(ctx).Update(values, id); err != nil {
+		return nil, err
+	}
+	return p.repo(ctx).FindByID(id)
+}
+
+// DeleteByID deletes the project, errs.ErrConflict is returned if it still has tasks or if it is the default project
+func (p *ProjectService) DeleteByID(ctx context.Context, id string) error {
+	log.Printf("deleting project with id '%s' ...", id)
+	if id == entity.DefaultProjectID {
+		return errs.New(errs.ErrConflict, "the default project cannot be deleted")
+	}
+	return p.repo(ctx).DeleteByID(id)
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the projects of this tenant
+func (p *ProjectService) repo(ctx context.Context) interfaces.IProjectRepository {
+	return p.ProjectRepository.WithTenant(principal.Tenant(ctx))
+}
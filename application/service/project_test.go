@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+)
+
+type mockProjectRepository struct {
+	projects map[string]*entity.Project
+}
+
+func (m mockProjectRepository) Create(project *entity.Project) error {
+	for _, existing := range m.projects {
+		if existing.Name == project.Name {
+			return errs.New(errs.ErrConflict, "project already exists")
+		}
+	}
+	m.projects[project.ID] = project
+	return nil
+}
+
+func (m mockProjectRepository) FindAll() ([]*entity.Project, error) {
+	var projects []*entity.Project
+	for _, project := range m.projects {
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (m mockProjectRepository) FindByID(id string) (*entity.Project, error) {
+	if project, ok := m.projects[id]; ok {
+		copied := *project
+		return &copied, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "project not found")
+}
+
+func (m mockProjectRepository) Update(fields map[string]interface{}, id string) error {
+	project, ok := m.projects[id]
+	if !ok {
+		return errs.New(errs.ErrNotFound, "project not found")
+	}
+	if name, ok := fields["name"].(string); ok {
+		project.Name = name
+	}
+	if description, ok := fields["description"].(string); ok {
+		project.Description = description
+	}
+	if priority, ok := fields["default_priority"].(int); ok {
+		project.DefaultPriority = priority
+	}
+	if statuses, ok := fields["allowed_statuses"].(entity.StatusList); ok {
+		project.AllowedStatuses = statuses
+	}
+	if requireChecklist, ok := fields["require_checklist"].(bool); ok {
+		project.RequireChecklist = requireChecklist
+	}
+	return nil
+}
+
+func (m mockProjectRepository) DeleteByID(id string) error {
+	if _, ok := m.projects[id]; !ok {
+		return errs.New(errs.ErrNotFound, "project not found")
+	}
+	delete(m.projects, id)
+	return nil
+}
+
+func (m mockProjectRepository) WithTenant(tenant string) interfaces.IProjectRepository {
+	return m
+}
+
+func TestProjectService(t1 *testing.T) {
+	p := NewProjectService(mockProjectRepository{projects: map[string]*entity.Project{
+		entity.DefaultProjectID: {ID: entity.DefaultProjectID, ProjectDescription: entity.ProjectDescription{Name: "Default"}},
+	}})
+
+	project, err := p.Create(testCtx, &entity.ProjectDescription{Name: " Backend ", ProjectSettings: entity.ProjectSettings{AllowedStatuses: entity.StatusList{"Active"}}})
+	if err != nil {
+		t1.Fatalf("Create() error = %v", err)
+	}
+	want := entity.ProjectDescription{Name: "Backend", ProjectSettings: entity.ProjectSettings{AllowedStatuses: entity.StatusList{entity.Active}}}
+	if project.ID == "" || !reflect.DeepEqual(project.ProjectDescription, want) {
+		t1.Errorf("Create() got = %v, want %v normalized", project, want)
+	}
+	if _, err = p.Create(testCtx, &entity.ProjectDescription{Name: "Backend"}); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("Create() error = %v, want %v for a name already used", err, errs.ErrConflict)
+	}
+
+	got, err := p.UpdatePartial(testCtx, &entity.ProjectDescription{ProjectSettings: entity.ProjectSettings{DefaultPriority: 3, RequireChecklist: true}}, project.ID)
+	want.DefaultPriority, want.RequireChecklist = 3, true
+	if err != nil || !reflect.DeepEqual(got.ProjectDescription, want) {
+		t1.Errorf("UpdatePartial() = %v, %v, want %v", got, err, want)
+	}
+	got, err = p.UpdateFully(testCtx, &entity.ProjectDescription{Name: "API"}, project.ID)
+	if want := (entity.ProjectDescription{Name: "API"}); err != nil || !reflect.DeepEqual(got.ProjectDescription, want) {
+		t1.Errorf("UpdateFully() = %v, %v, want %v", got, err, want)
+	}
+	if _, err = p.UpdateFully(testCtx, &entity.ProjectDescription{Name: "API", ProjectSettings: entity.ProjectSettings{DefaultPriority: 20}}, project.ID); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdateFully() error = %v, want %v", err, errs.ErrValidation)
+	}
+
+	if err = p.DeleteByID(testCtx, entity.DefaultProjectID); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("DeleteByID() error = %v, want %v for the default project", err, errs.ErrConflict)
+	}
+	if err = p.DeleteByID(testCtx, project.ID); err != nil {
+		t1.Fatalf("DeleteByID() error = %v", err)
+	}
+	if _, err = p.GetByID(testCtx, project.ID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("GetByID() error = %v, want %v after deletion", err, errs.ErrNotFound)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/recurrence"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"log"
+	"time"
+)
+
+// spawnBatchSize is the maximum number of series whose next occurrence is spawned by one call of SpawnOccurrences
+const spawnBatchSize = 100
+
+// SpawnOccurrences creates the next occurrence of the series whose last occurrence is closed or due, and returns how many were created.
+// Each occurrence is spawned in its own transaction, so that a series that cannot be continued does not block the others.
+// The series of all the tenants are looked up at once, then each occurrence is spawned in the tenant of its series.
+func (t *TaskService) SpawnOccurrences(ctx context.Context) (int, error) {
+	due, err := t.TaskRepository.AllTenants().FindDueOccurrences(time.Now().UTC(), spawnBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	spawned := 0
+	for _, current := range due {
+		err = t.TaskRepository.WithTenant(current.TenantID).Transaction(func(repo interfaces.ITaskRepository) error {
+			created, err := t.spawnNext(ctx, repo, current)
+			if created {
+				spawned++
+			}
+			return err
+		})
+		if err != nil {
+			log.Printf("failed to spawn the occurrence following task '%s': %v", current.ID, err)
+		}
+	}
+	return spawned, nil
+}
+
+// spawnNext creates the occurrence following current in its series, unless the series ended or the occurrence already exists.
+// The rule is read from the template task, so changing or removing it there applies to the rest of the series.
+// When the series ended, current is marked as its end so that the scheduler does not pick it again.
+func (t *TaskService) spawnNext(ctx context.Context, repo interfaces.ITaskRepository, current *entity.Task) (created bool, err error) {
+	if current.DueAt == nil {
+		return false, repo.EndSeries(current.ID)
+	}
+	template, err := repo.FindByID(current.TemplateID)
+	if errors.Is(err, errs.ErrNotFound) {
+		return false, repo.EndSeries(current.ID) // the template was deleted, which ends the series
+	}
+	if err != nil {
+		return false, err
+	}
+	if template.Recurrence == "" {
+		return false, repo.EndSeries(current.ID)
+	}
+	rule, err := recurrence.Parse(template.Recurrence)
+	if err != nil {
+		return false, err
+	}
+	// the days and the time of the day of the occurrences are the ones of the client that set the series
+	if rule.Location, err = time.LoadLocation(template.Timezone); err != nil {
+		return false, err
+	}
+	if rule.Count > 0 && current.Occurrence >= rule.Count {
+		return false, repo.EndSeries(current.ID)
+	}
+	dueAt, ok := rule.Next(*current.DueAt)
+	if !ok {
+		return false, repo.EndSeries(current.ID)
+	}
+
+	next := entity.Task{
+		ID:         uuid.NewString(),
+		Version:    1,
+		TemplateID: current.TemplateID,
+		Occurrence: current.Occurrence + 1,
+		// the occurrence is spawned by the caller closing the previous one, or by the scheduler, for the people of the template
+		CreatedBy:  principal.Subject(ctx),
+		ReporterID: template.ReporterID,
+		AssigneeID: template.AssigneeID,
+		TaskDescription: entity.TaskDescription{
+			Title:       template.Title,
+			Description: template.Description,
+			Priority:    template.Priority,
+			Status:      entity.New,
+			DueAt:       &dueAt,
+			ParentID:    template.ParentID,
+			ProjectID:   template.ProjectID,
+		},
+	}
+	// the occurrence starts as long before it is due as the previous one did
+	if current.StartAt != nil {
+		startAt := dueAt.Add(current.StartAt.Sub(*current.DueAt))
+		next.StartAt = &startAt
+	}
+	if created, err = repo.CreateOccurrence(&next); err != nil || !created {
+		return false, err
+	}
+	log.Printf("spawned occurrence %d of task '%s' with ID '%s'", next.Occurrence, next.TemplateID, next.ID)
+	if err = repo.AppendEvent(newEvent(ctx, next.ID, entity.Created, creationChanges(&next))); err != nil {
+		return true, err
+	}
+	return true, rollUp(repo, next.ParentID)
+}
+
+// checkRecurrence checks the recurrence set by the values, and links the task to its series when it becomes recurring.
+// Only the template of a series holds the rule, its occurrences cannot have their own.
+func checkRecurrence(old *entity.Task, values map[string]interface{}) error {
+	rule, ok := values["recurrence"].(string)
+	if !ok {
+		rule = old.Recurrence
+	} else if rule != "" && old.TemplateID != "" && old.TemplateID != old.ID {
+		return errs.Validation([]errs.Violation{{Field: "recurrence", Message: "only the template of a series can have a recurrence rule"}})
+	}
+	if rule == "" {
+		return nil
+	}
+	dueAt := old.DueAt
+	if value, ok := values["due_at"].(*time.Time); ok {
+		dueAt = value
+	}
+	if dueAt == nil {
+		return errs.Validation([]errs.Violation{{Field: "recurrence", Message: validation.ErrRecurrenceWithoutDue.Error()}})
+	}
+	if old.TemplateID == "" {
+		values["template_id"] = old.ID
+		values["occurrence"] = 1
+	}
+	return nil
+}
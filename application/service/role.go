@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"log"
+)
+
+// RoleService manages the roles granted in the tenant of the caller, and resolves the role of the callers on the projects
+type RoleService struct {
+	RoleRepository    interfaces.IRoleRepository
+	ProjectRepository interfaces.IProjectRepository
+	DefaultRole       entity.Role // role of the callers who were granted no role, on the projects they have no role on
+}
+
+// NewRoleService is the constructor of a RoleService with the repositories injected, the callers without role get the default one
+func NewRoleService(repo interfaces.IRoleRepository, projects interfaces.IProjectRepository, defaultRole entity.Role) *RoleService {
+	if repo == nil || projects == nil {
+		log.Fatalf("nil repo provided")
+	}
+	return &RoleService{RoleRepository: repo, ProjectRepository: projects, DefaultRole: defaultRole}
+}
+
+// RoleOf returns the role of the caller on the project, or on all the projects when projectID is empty.
+// The administrators of the users are admin everywhere, the API keys have the role of their scopes on all the projects, and the others
+// have the role granted on the project, or else the role granted on all the projects, or else the role given by their identity provider,
+// or else the default role.
+func (r *RoleService) RoleOf(ctx context.Context, projectID string) (entity.Role, error) {
+	p, ok := principal.FromContext(ctx)
+	if !ok {
+		return "", errs.New(errs.ErrUnauthenticated, "the caller is not authenticated")
+	}
+	if p.Admin {
+		return entity.Admin, nil
+	}
+	if p.Scopes != nil {
+		scopes := make(entity.ScopeList, len(p.Scopes))
+		for i, scope := range p.Scopes {
+			scopes[i] = entity.Scope(scope)
+		}
+		return scopes.Role(), nil
+	}
+	assignments, err := r.repo(ctx).FindBySubject(p.Subject, projectID)
+	if err != nil {
+		return "", err
+	}
+	role := r.DefaultRole
+	if p.Role != "" {
+		role = entity.Role(p.Role)
+	}
+	for _, assignment := range assignments {
+		if assignment.ProjectID == projectID {
+			return assignment.Role, nil
+		}
+		role = assignment.Role
+	}
+	return role, nil
+}
+
+// List returns the roles granted in the tenant ordered by subject
+func (r *RoleService) List(ctx context.Context) ([]*entity.RoleAssignment, error) {
+	if err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	log.Printf("listing role assignments ...")
+	return r.repo(ctx).FindAll()
+}
+
+// Assign grants the role to the subject on the project, or on all the projects when the project ID is empty, replacing its previous role there
+func (r *RoleService) Assign(ctx context.Context, req *entity.RoleAssignment) (*entity.RoleAssignment, error) {
+	if err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	assignment, err := validation.ValidateRoleAssignment(req)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.ProjectID != "" {
+		if _, err = r.ProjectRepository.WithTenant(principal.Tenant(ctx)).FindByID(assignment.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("granting role %s to '%s' on project '%s' ...", assignment.Role, assignment.Subject, assignment.ProjectID)
+	if err = r.repo(ctx).Assign(assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// Revoke removes the role of the subject on the project, or on all the projects when projectID is empty
+func (r *RoleService) Revoke(ctx context.Context, subject string, projectID string) error {
+	if err := r.requireAdmin(ctx); err != nil {
+		return err
+	}
+	log.Printf("revoking role of '%s' on project '%s' ...", subject, projectID)
+	return r.repo(ctx).Revoke(subject, projectID)
+}
+
+// requireAdmin returns errs.ErrForbidden unless the caller is admin on all the projects
+func (r *RoleService) requireAdmin(ctx context.Context) error {
+	role, err := r.RoleOf(ctx, "")
+	if err != nil {
+		return err
+	}
+	if !role.Includes(entity.Admin) {
+		return errs.New(errs.ErrForbidden, "only the admins of all the projects can manage the roles")
+	}
+	return nil
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the roles granted in this tenant
+func (r *RoleService) repo(ctx context.Context) interfaces.IRoleRepository {
+	return r.RoleRepository.WithTenant(principal.Tenant(ctx))
+}
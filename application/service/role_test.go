@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"testing"
+)
+
+// mockRoleRepository keeps the assignments by subject and project, the tenant is ignored
+type mockRoleRepository struct {
+	assignments map[[2]string]*entity.RoleAssignment
+}
+
+func (m mockRoleRepository) Assign(assignment *entity.RoleAssignment) error {
+	m.assignments[[2]string{assignment.Subject, assignment.ProjectID}] = assignment
+	return nil
+}
+
+func (m mockRoleRepository) FindAll() ([]*entity.RoleAssignment, error) {
+	var assignments []*entity.RoleAssignment
+	for _, assignment := range m.assignments {
+		assignments = append(assignments, assignment)
+	}
+	return assignments, nil
+}
+
+func (m mockRoleRepository) FindBySubject(subject string, projectID string) ([]*entity.RoleAssignment, error) {
+	var assignments []*entity.RoleAssignment
+	for _, id := range []string{projectID, ""} {
+		if assignment, ok := m.assignments[[2]string{subject, id}]; ok {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+func (m mockRoleRepository) Revoke(subject string, projectID string) error {
+	if _, ok := m.assignments[[2]string{subject, projectID}]; !ok {
+		return errs.New(errs.ErrNotFound, "assignment not found")
+	}
+	delete(m.assignments, [2]string{subject, projectID})
+	return nil
+}
+
+func (m mockRoleRepository) WithTenant(tenant string) interfaces.IRoleRepository {
+	return m
+}
+
+// callerCtx returns the context of a caller of the test tenant who is not an administrator of the users
+func callerCtx(subject string) context.Context {
+	return principal.NewContext(context.Background(), principal.Principal{Subject: subject, Tenant: testTenant})
+}
+
+func newTestRoleService() *RoleService {
+	projects := mockProjectRepository{projects: map[string]*entity.Project{"p1": {ID: "p1"}, "p2": {ID: "p2"}}}
+	return NewRoleService(mockRoleRepository{assignments: make(map[[2]string]*entity.RoleAssignment)}, projects, entity.Viewer)
+}
+
+func TestRoleService(t1 *testing.T) {
+	r := newTestRoleService()
+	adminCtx := principal.NewContext(context.Background(), principal.Principal{Subject: "root", Tenant: testTenant, Admin: true})
+
+	if _, err := r.Assign(callerCtx("alice"), &entity.RoleAssignment{Subject: "alice", Role: entity.Admin}); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("Assign() error = %v, want %v for a caller who is not admin", err, errs.ErrForbidden)
+	}
+	if _, err := r.Assign(adminCtx, &entity.RoleAssignment{Subject: "alice", Role: " Editor "}); err != nil {
+		t1.Fatalf("Assign() error = %v", err)
+	}
+	if _, err := r.Assign(adminCtx, &entity.RoleAssignment{Subject: "alice", ProjectID: "p1", Role: entity.Viewer}); err != nil {
+		t1.Fatalf("Assign() error = %v", err)
+	}
+	if _, err := r.Assign(adminCtx, &entity.RoleAssignment{Subject: "alice", ProjectID: "missing", Role: entity.Viewer}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Assign() error = %v, want %v for a project that does not exist", err, errs.ErrNotFound)
+	}
+	if _, err := r.Assign(adminCtx, &entity.RoleAssignment{Subject: "bob", Role: "owner"}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Assign() error = %v, want %v for an unknown role", err, errs.ErrValidation)
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		projectID string
+		want      entity.Role
+	}{
+		{name: "should return the role on all the projects", ctx: callerCtx("alice"), want: entity.Editor},
+		{name: "should prefer the role on the project", ctx: callerCtx("alice"), projectID: "p1", want: entity.Viewer},
+		{name: "should fall back to the role on all the projects", ctx: callerCtx("alice"), projectID: "p2", want: entity.Editor},
+		{name: "should return the default role without assignment", ctx: callerCtx("bob"), projectID: "p1", want: entity.Viewer},
+		{name: "should return admin for the administrators of the users", ctx: adminCtx, projectID: "p1", want: entity.Admin},
+		{name: "should return the role given by the identity provider without assignment", ctx: principal.NewContext(context.Background(), principal.Principal{Subject: "bob", Tenant: testTenant, Role: "editor"}), projectID: "p1", want: entity.Editor},
+		{name: "should prefer the role on the project to the role given by the identity provider", ctx: principal.NewContext(context.Background(), principal.Principal{Subject: "alice", Tenant: testTenant, Role: "admin"}), projectID: "p1", want: entity.Viewer},
+		{name: "should return the role of the scopes for the API keys", ctx: principal.NewContext(context.Background(), principal.Principal{Subject: "apikey:ci", Tenant: testTenant, Scopes: []string{"tasks:read", "tasks:write"}}), projectID: "p1", want: entity.Editor},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			got, err := r.RoleOf(tt.ctx, tt.projectID)
+			if err != nil {
+				t1.Fatalf("RoleOf() error = %v", err)
+			}
+			if got != tt.want {
+				t1.Errorf("RoleOf() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if _, err := r.RoleOf(context.Background(), ""); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("RoleOf() error = %v, want %v without caller", err, errs.ErrUnauthenticated)
+	}
+
+	if err := r.Revoke(adminCtx, "alice", "p1"); err != nil {
+		t1.Fatalf("Revoke() error = %v", err)
+	}
+	if got, _ := r.RoleOf(callerCtx("alice"), "p1"); got != entity.Editor {
+		t1.Errorf("RoleOf() got = %v after the revocation, want %v", got, entity.Editor)
+	}
+	if err := r.Revoke(adminCtx, "alice", "p1"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Revoke() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
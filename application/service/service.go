@@ -2,129 +2,432 @@ package service
 
 // INFO Important you can see it does not depend on the repository but on the interface that the repo implements
 import (
+	"context"
+	"errors"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
 	"github.com/google/uuid"
 	"log"
+	"time"
 )
 
 // TaskService The attributes should be the dependencies needed from the outer layer's stuff, those will be injected
 // DIP happens here,
 type TaskService struct {
 	TaskRepository interfaces.ITaskRepository
+	Workflow       *workflow.Workflow     // transitions allowed between the statuses when updating a task
+	DeletePolicy   hierarchy.DeletePolicy // what deleting a task does to its subtasks
+	BlobStore      interfaces.BlobStore   // holds the content of the attachments, removed when their task is purged
 }
 
 // NewTaskService Dependency Inversion Principle. DIP suggests that we should depend on abstractions (interfaces), not concrete classes.
 // => also that way we respect the Dependency Rule. This rule says that source code dependencies can only point inwards.
 // Inner circles never mention a name in an outer circle. Repository impl is in outer circle, but the interfaces are in the app layer.
-func NewTaskService(repo interfaces.ITaskRepository) *TaskService {
+func NewTaskService(repo interfaces.ITaskRepository, workflow *workflow.Workflow, deletePolicy hierarchy.DeletePolicy, store interfaces.BlobStore) *TaskService {
 	if repo == nil {
 		log.Fatalf("nil repo provided")
 	}
-	return &TaskService{TaskRepository: repo}
+	if workflow == nil {
+		log.Fatalf("nil workflow provided")
+	}
+	if store == nil {
+		log.Fatalf("nil blob store provided")
+	}
+	return &TaskService{TaskRepository: repo, Workflow: workflow, DeletePolicy: deletePolicy, BlobStore: store}
+}
+
+// repo returns the repository bound to the tenant of the caller. Every method acting for a caller goes through it,
+// the repository refusing the statements of a caller without tenant rather than showing it the tasks of all the tenants.
+func (t *TaskService) repo(ctx context.Context) interfaces.ITaskRepository {
+	return t.TaskRepository.WithTenant(principal.Tenant(ctx))
 }
 
-func (t *TaskService) Create(req *entity.TaskDescription) (*entity.Task, error) {
-	description, err := validation.ValidateParams(req)
+// Create creates the task in its project, the default one when none is given, following the settings of the project
+func (t *TaskService) Create(ctx context.Context, req *entity.TaskDescription) (*entity.Task, error) {
+	if req.ProjectID == "" {
+		req.ProjectID = entity.DefaultProjectID
+	}
+	project, err := t.repo(ctx).FindProject(req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	description, err := validation.ValidateParams(req, &project.ProjectSettings)
 	if err != nil {
 		return nil, err
 	}
 
-	task := entity.Task{ID: uuid.NewString(), TaskDescription: *description}
+	// the creator reports the task, it is assigned to nobody until someone takes it or is given it
+	task := entity.Task{ID: uuid.NewString(), Version: 1, CreatedBy: principal.Subject(ctx), ReporterID: principal.Subject(ctx), TaskDescription: *description}
+	// a recurring task is the template of its series and its first occurrence
+	if task.Recurrence != "" {
+		task.TemplateID, task.Occurrence = task.ID, 1
+	}
 	log.Printf("creating task with ID '%s' ...", task.ID)
 
-	err = t.TaskRepository.Create(&task)
+	err = t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if task.ParentID != "" {
+			if err := checkParent(repo, "", task.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := repo.Create(&task); err != nil {
+			return err
+		}
+		if err := repo.AppendEvent(newEvent(ctx, task.ID, entity.Created, creationChanges(&task))); err != nil {
+			return err
+		}
+		return rollUp(repo, task.ParentID)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &task, err
 }
 
-func (t *TaskService) Get() ([]*entity.Task, error) {
-	log.Printf("listing all tasks ...")
-	return t.TaskRepository.FindAll()
+// Get returns the page of the tasks matching the query, assignee=me selecting the tasks assigned to the caller
+func (t *TaskService) Get(ctx context.Context, req *entity.TaskQuery) (*entity.TaskList, error) {
+	if req.Assignee == entity.AssigneeMe {
+		if req.Assignee = principal.Subject(ctx); req.Assignee == "" {
+			return nil, errs.New(errs.ErrUnauthenticated, "the tasks of the caller cannot be listed without caller")
+		}
+	}
+	query, err := validation.ValidateQuery(req)
+	if err != nil {
+		return nil, err
+	}
+	if query.ProjectID != "" {
+		if _, err = t.repo(ctx).FindProject(query.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+	if query.Keyset {
+		return t.getAfter(ctx, query)
+	}
+	log.Printf("listing tasks with limit %d and offset %d (trash: %t) ...", query.Limit, query.Offset, query.Trashed)
+
+	tasks, err := t.repo(ctx).FindAll(query)
+	if err != nil {
+		return nil, err
+	}
+	total, err := t.repo(ctx).Count(query)
+	if err != nil {
+		return nil, err
+	}
+	if err = t.withLabels(ctx, tasks...); err != nil {
+		return nil, err
+	}
+	return &entity.TaskList{Tasks: tasks, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
 }
 
-func (t *TaskService) DeleteByID(id string) error {
-	log.Printf("deleting task with id '%s' ...", id)
-	return t.TaskRepository.DeleteByID(id)
+// getAfter returns the page of tasks following the cursor of the query, along with the cursor of the next page if there is one
+func (t *TaskService) getAfter(ctx context.Context, query *entity.TaskQuery) (*entity.TaskList, error) {
+	log.Printf("listing tasks with limit %d after cursor ...", query.Limit)
+	// one more task than requested is fetched to know whether another page follows without counting
+	lookahead := *query
+	lookahead.Limit++
+	tasks, err := t.repo(ctx).FindAll(&lookahead)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &entity.TaskList{Limit: query.Limit}
+	if len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+		last := tasks[len(tasks)-1]
+		page.Next = &entity.TaskCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	if err = t.withLabels(ctx, tasks...); err != nil {
+		return nil, err
+	}
+	page.Tasks = tasks
+	return page, nil
 }
 
-func (t *TaskService) GetByID(id string) (*entity.Task, error) {
-	log.Printf("getting task with id '%s' ...", id)
-	return t.TaskRepository.FindByID(id)
+// DeleteByID moves the task to the trash on behalf of the principal of the context, it can be restored until it is purged.
+// Its subtasks are handled according to the delete policy, errs.ErrConflict is returned if the policy refuses to delete it.
+func (t *TaskService) DeleteByID(ctx context.Context, id string) error {
+	log.Printf("moving task with id '%s' to the trash ...", id)
+	return t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		task, err := repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		if err = t.deleteChildren(ctx, repo, task); err != nil {
+			return err
+		}
+		if err = repo.DeleteByID(id, principal.Subject(ctx)); err != nil {
+			return err
+		}
+		if err = repo.AppendEvent(newEvent(ctx, id, entity.Deleted, nil)); err != nil {
+			return err
+		}
+		return rollUp(repo, task.ParentID)
+	})
 }
 
-func (t *TaskService) UpdateFully(req *entity.TaskDescription, id string) (*entity.Task, error) {
-	log.Printf("updating task with id '%s' ...", id)
-	_, err := t.TaskRepository.FindByID(id)
+// Restore takes the task out of the trash and returns it as it was before being deleted.
+// Its subtasks deleted along with it stay in the trash, and it becomes a top-level task if its parent is not there anymore.
+func (t *TaskService) Restore(ctx context.Context, id string) (*entity.Task, error) {
+	log.Printf("restoring task with id '%s' ...", id)
+	err := t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if err := repo.Restore(id); err != nil {
+			return err
+		}
+		if err := repo.AppendEvent(newEvent(ctx, id, entity.Restored, nil)); err != nil {
+			return err
+		}
+		task, err := repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		// the series ended when its template was deleted, restoring the template continues it
+		if task.TemplateID == id {
+			if err = repo.ResumeSeries(id); err != nil {
+				return err
+			}
+		}
+		if task.ParentID != "" {
+			if _, err = repo.FindByID(task.ParentID); errors.Is(err, errs.ErrNotFound) {
+				if err = t.detach(ctx, repo, task); err != nil {
+					return err
+				}
+			} else if err != nil {
+				return err
+			}
+		}
+		return rollUp(repo, id)
+	})
 	if err != nil {
 		return nil, err
 	}
-	request, err := validation.ValidateParams(req)
+	return t.GetByID(ctx, id)
+}
+
+// ProjectOf returns the ID of the project of the task, unlike GetByID the tasks in the trash are found too
+func (t *TaskService) ProjectOf(ctx context.Context, id string) (string, error) {
+	return t.repo(ctx).FindProjectOf(id)
+}
+
+// PurgeTrash permanently removes the tasks that have been in the trash for longer than the retention period, whatever their tenant,
+// along with the content of their attachments
+func (t *TaskService) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	log.Printf("purging tasks deleted more than %s ago ...", retention)
+	purged, blobKeys, err := t.TaskRepository.AllTenants().Purge(time.Now().Add(-retention))
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	// the tasks are gone, a blob that cannot be deleted is left behind rather than failing the purge
+	for _, key := range blobKeys {
+		if err = t.BlobStore.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete blob '%s' of an attachment of a purged task: %v", key, err)
+		}
 	}
+	return purged, nil
+}
 
-	values := map[string]interface{}{"title": request.Title, "description": request.Description, "priority": request.Priority, "status": request.Status}
-	err = t.TaskRepository.Update(values, id)
+// taskProject returns the project the task belongs to once updated, the one of the request when it moves the task to another project
+func (t *TaskService) taskProject(ctx context.Context, task *entity.Task, projectID string) (*entity.Project, error) {
+	if projectID == "" {
+		projectID = task.ProjectID
+	}
+	return t.repo(ctx).FindProject(projectID)
+}
+
+// checkSchedule checks that the task is not due before it starts once the values are set, a partial update may change only one of the dates
+func checkSchedule(old *entity.Task, values map[string]interface{}) error {
+	startAt, dueAt := old.StartAt, old.DueAt
+	if value, ok := values["start_at"].(*time.Time); ok {
+		startAt = value
+	}
+	if value, ok := values["due_at"].(*time.Time); ok {
+		dueAt = value
+	}
+	if err := validation.ValidateSchedule(startAt, dueAt); err != nil {
+		return errs.Validation([]errs.Violation{{Field: "dueAt", Message: err.Error()}})
+	}
+	return nil
+}
+
+// GetWorkflow returns the transitions allowed between the statuses of the tasks
+func (t *TaskService) GetWorkflow(ctx context.Context) *workflow.Workflow {
+	return t.Workflow
+}
+
+// GetDeletePolicy returns what deleting a task does to its subtasks
+func (t *TaskService) GetDeletePolicy(ctx context.Context) hierarchy.DeletePolicy {
+	return t.DeletePolicy
+}
+
+func (t *TaskService) GetByID(ctx context.Context, id string) (*entity.Task, error) {
+	log.Printf("getting task with id '%s' ...", id)
+	task, err := t.repo(ctx).FindByID(id)
 	if err != nil {
 		return nil, err
 	}
+	return task, t.withLabels(ctx, task)
+}
 
-	task, err := t.TaskRepository.FindByID(id)
+// UpdateFully replaces all the values of the task. If version is not 0, the task is only updated if it is still at this version.
+// errs.ErrConflict is returned if the workflow, the open blockers of the task, or the unchecked items of its checklist when its project
+// requires them to be checked, do not allow it to move to the new status. The task stays in its project when the request does not give one.
+func (t *TaskService) UpdateFully(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
+	log.Printf("updating task with id '%s' ...", id)
+	old, err := t.repo(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := t.taskProject(ctx, old, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	request, err := validation.ValidateParams(req, &project.ProjectSettings)
 	if err != nil {
 		return nil, err
 	}
 
-	return task, nil
+	values := map[string]interface{}{"title": request.Title, "description": request.Description, "priority": request.Priority, "status": request.Status,
+		"start_at": request.StartAt, "due_at": request.DueAt, "recurrence": request.Recurrence, "timezone": request.Timezone,
+		"parent_id": request.ParentID, "project_id": project.ID}
+	return t.update(ctx, values, id, version)
 }
 
-func (t *TaskService) UpdatePartial(req *entity.TaskDescription, id string) (*entity.Task, error) {
+// UpdatePartial updates only the values set in the request. If version is not 0, the task is only updated if it is still at this version.
+// errs.ErrConflict is returned if the workflow, the open blockers of the task, or the unchecked items of its checklist when its project
+// requires them to be checked, do not allow it to move to the new status.
+func (t *TaskService) UpdatePartial(ctx context.Context, req *entity.TaskDescription, id string, version int) (*entity.Task, error) {
 	log.Printf("updating task with id '%s' ...", id)
-	_, err := t.TaskRepository.FindByID(id)
+	old, err := t.repo(ctx).FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := t.taskProject(ctx, old, req.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	err = validation.ValidatePartialParams(req, &project.ProjectSettings)
 	if err != nil {
 		return nil, err
 	}
 	values := make(map[string]interface{})
-	if req.Title != "" {
-		err := validation.ValidateTitle(req.Title)
-		if err != nil {
-			return nil, err
+	if project.ID != old.ProjectID {
+		// the status the task keeps has to be allowed in the project it moves to
+		if req.Status == "" {
+			if err = validation.ValidateAllowedStatus(old.Status, &project.ProjectSettings); err != nil {
+				return nil, errs.Validation([]errs.Violation{{Field: "status", Message: err.Error()}})
+			}
 		}
+		values["project_id"] = project.ID
+	}
+	if req.Title != "" {
 		values["title"] = req.Title
 	}
 	if req.Description != "" {
-		err := validation.ValidateDescription(req.Description)
-		if err != nil {
-			return nil, err
-		}
 		values["description"] = req.Description
 	}
 	if req.Priority != 0 {
-		err := validation.ValidatePriority(req.Priority)
-		if err != nil {
-			return nil, err
-		}
-		values["Priority"] = req.Priority
+		values["priority"] = req.Priority
 	}
 
 	if req.Status != "" {
 		values["status"] = req.Status
 	}
-	err = t.TaskRepository.Update(values, id)
-	if err != nil {
-		return nil, err
+	if req.StartAt != nil {
+		values["start_at"] = req.StartAt
 	}
-
-	task, err := t.TaskRepository.FindByID(id)
-	if err != nil {
-		return nil, err
+	if req.DueAt != nil {
+		values["due_at"] = req.DueAt
+	}
+	if req.Recurrence != "" {
+		values["recurrence"] = req.Recurrence
 	}
+	if req.Timezone != "" {
+		values["timezone"] = req.Timezone
+	}
+	if req.ParentID != "" {
+		values["parent_id"] = req.ParentID
+	}
+	return t.update(ctx, values, id, version)
+}
 
+// update sets the values of the task and records the change in its history, within the same transaction.
+// The task is read again in the transaction so that the recorded old values are the ones actually overwritten,
+// and so that a new status is checked against the workflow, and the blockers of the task, from the status it really replaces.
+// Closing an occurrence of a recurring task spawns the next one in the same transaction,
+// and the completion of the parents is rolled up again when the status or the parent of the task changes.
+func (t *TaskService) update(ctx context.Context, values map[string]interface{}, id string, version int) (*entity.Task, error) {
+	var task *entity.Task
+	err := t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		old, err := repo.FindByID(id)
+		if err != nil {
+			return err
+		}
+		status, statusSet := values["status"].(entity.Status)
+		if statusSet && status != old.Status {
+			if err = t.Workflow.Check(old.Status, status); err != nil {
+				return err
+			}
+			openBlockers, err := repo.CountOpenBlockers(id)
+			if err != nil {
+				return err
+			}
+			if err = t.Workflow.CheckBlockers(status, openBlockers); err != nil {
+				return err
+			}
+		}
+		parentID, parentSet := values["parent_id"].(string)
+		parentChanged := parentSet && parentID != old.ParentID
+		if parentChanged && parentID != "" {
+			if err = checkParent(repo, id, parentID); err != nil {
+				return err
+			}
+		}
+		if err = checkSchedule(old, values); err != nil {
+			return err
+		}
+		if err = checkRecurrence(old, values); err != nil {
+			return err
+		}
+		if err = checkChecklist(repo, old, values); err != nil {
+			return err
+		}
+		if err = repo.Update(values, id, version); err != nil {
+			return err
+		}
+		// the new rule of the template may continue the series the previous one ended
+		if _, ok := values["recurrence"]; ok && old.TemplateID == id {
+			if err = repo.ResumeSeries(id); err != nil {
+				return err
+			}
+		}
+		if task, err = repo.FindByID(id); err != nil {
+			return err
+		}
+		if task.TemplateID != "" && status == entity.Closed && old.Status != entity.Closed {
+			if _, err = t.spawnNext(ctx, repo, task); err != nil {
+				return err
+			}
+		}
+		if err = repo.AppendEvent(newEvent(ctx, id, entity.Updated, updateChanges(old, values))); err != nil {
+			return err
+		}
+		if parentChanged {
+			if err = rollUp(repo, old.ParentID); err != nil {
+				return err
+			}
+			return rollUp(repo, parentID)
+		}
+		if statusSet && status != old.Status {
+			return rollUp(repo, task.ParentID)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return task, nil
+	return task, t.withLabels(ctx, task)
 }
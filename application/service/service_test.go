@@ -1,21 +1,41 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 )
 
 const (
+	testSubject         = "tester"
+	testTenant          = "acme"
 	testID              = "testID"
 	testFullUpdateID    = "testFullUpdateID"
 	testPartialUpdateID = "testPartialUpdateID"
+	testClosedID        = "testClosedID"
+	testTemplateID      = "testTemplateID"
+	testOccurrenceID    = "testOccurrenceID"
+	testParentID        = "testParentID"
+	testChildID         = "testChildID"
+	testLabelID         = "testLabelID"
+	testProjectID       = "testProjectID"
 )
 
 var (
+	testStartAt         = time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC)
+	testDueAt           = time.Date(2022, time.January, 10, 17, 0, 0, 0, time.UTC)
+	testCtx             = principal.NewContext(context.Background(), principal.Principal{Subject: testSubject, Tenant: testTenant})
 	TaskRequestInstance = entity.TaskDescription{
 		Title:       "test",
 		Description: "test",
@@ -30,11 +50,59 @@ var (
 	}
 	PartialUpdateRequest = entity.TaskDescription{
 		Title:  "testPartialUpdate",
-		Status: "Active",
+		Status: entity.Active,
 	}
 )
 
 type mockTaskRepository struct {
+	events       *[]*entity.TaskEvent      // recorded events, not kept when nil
+	occurrences  *[]*entity.Task           // spawned occurrences, not kept when nil
+	refreshed    *[]string                 // IDs of the tasks whose completion was refreshed, not kept when nil
+	dependencies *[]*entity.TaskDependency // dependencies between the tasks, none when nil
+	labels       *[]*entity.TaskLabel      // labels attached to the tasks, none when nil
+	tenants      *[]string                 // tenants the repository was bound to, allTenants when not bound to one, not kept when nil
+}
+
+// allTenants is recorded by the mock when the repository is not bound to a tenant
+const allTenants = "*"
+
+func (m mockTaskRepository) Transaction(fn func(repo interfaces.ITaskRepository) error) error {
+	return fn(m)
+}
+
+func (m mockTaskRepository) WithTenant(tenant string) interfaces.ITaskRepository {
+	if m.tenants != nil {
+		*m.tenants = append(*m.tenants, tenant)
+	}
+	return m
+}
+
+func (m mockTaskRepository) AllTenants() interfaces.ITaskRepository {
+	return m.WithTenant(allTenants)
+}
+
+func (m mockTaskRepository) AppendEvent(event *entity.TaskEvent) error {
+	if m.events != nil {
+		*m.events = append(*m.events, event)
+	}
+	return nil
+}
+
+func (m mockTaskRepository) FindEvents(taskID string, query *entity.HistoryQuery) ([]*entity.TaskEvent, error) {
+	var events []*entity.TaskEvent
+	if m.events != nil {
+		for _, event := range *m.events {
+			if event.TaskID == taskID {
+				events = append(events, event)
+			}
+		}
+	}
+	return events, nil
+}
+
+func (m mockTaskRepository) CountEvents(taskID string) (int64, error) {
+	events, err := m.FindEvents(taskID, nil)
+	return int64(len(events)), err
 }
 
 func (m mockTaskRepository) Create(task *entity.Task) error {
@@ -44,14 +112,208 @@ func (m mockTaskRepository) Create(task *entity.Task) error {
 	return errors.New("unexpected values passed to the repository")
 }
 
-func (m mockTaskRepository) DeleteByID(id string) error {
+func (m mockTaskRepository) CreateOccurrence(task *entity.Task) (bool, error) {
+	if m.occurrences == nil {
+		return true, nil
+	}
+	for _, occurrence := range *m.occurrences {
+		if occurrence.TemplateID == task.TemplateID && occurrence.Occurrence == task.Occurrence {
+			return false, nil
+		}
+	}
+	*m.occurrences = append(*m.occurrences, task)
+	return true, nil
+}
+
+func (m mockTaskRepository) FindDueOccurrences(now time.Time, limit int) ([]*entity.Task, error) {
+	occurrence, err := m.FindByID(testOccurrenceID)
+	return []*entity.Task{occurrence}, err
+}
+
+func (m mockTaskRepository) EndSeries(id string) error {
+	return nil
+}
+
+func (m mockTaskRepository) ResumeSeries(templateID string) error {
+	return nil
+}
+
+func (m mockTaskRepository) FindChildren(parentIDs []string) ([]*entity.Task, error) {
+	for _, id := range parentIDs {
+		if id == testParentID {
+			child, err := m.FindByID(testChildID)
+			return []*entity.Task{child}, err
+		}
+	}
+	return nil, nil
+}
+
+func (m mockTaskRepository) RefreshCompletion(id string) error {
+	if m.refreshed != nil {
+		*m.refreshed = append(*m.refreshed, id)
+	}
+	return nil
+}
+
+func (m mockTaskRepository) AddDependency(dependency *entity.TaskDependency) error {
+	for _, existing := range *m.dependencies {
+		if existing.TaskID == dependency.TaskID && existing.BlockerID == dependency.BlockerID {
+			return errs.New(errs.ErrConflict, "dependency already exists")
+		}
+	}
+	*m.dependencies = append(*m.dependencies, dependency)
+	return nil
+}
+
+func (m mockTaskRepository) RemoveDependency(taskID string, blockerID string) error {
+	for i, dependency := range *m.dependencies {
+		if dependency.TaskID == taskID && dependency.BlockerID == blockerID {
+			*m.dependencies = append((*m.dependencies)[:i], (*m.dependencies)[i+1:]...)
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "dependency not found")
+}
+
+func (m mockTaskRepository) FindBlockers(taskID string) ([]*entity.Task, error) {
+	dependencies, _ := m.FindDependencies([]string{taskID})
+	var blockers []*entity.Task
+	for _, dependency := range dependencies {
+		blocker, err := m.FindByID(dependency.BlockerID)
+		if err != nil {
+			return nil, err
+		}
+		blockers = append(blockers, blocker)
+	}
+	return blockers, nil
+}
+
+func (m mockTaskRepository) FindDependencies(taskIDs []string) ([]*entity.TaskDependency, error) {
+	var dependencies []*entity.TaskDependency
+	if m.dependencies == nil {
+		return nil, nil
+	}
+	for _, dependency := range *m.dependencies {
+		for _, id := range taskIDs {
+			if dependency.TaskID == id {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+	return dependencies, nil
+}
+
+func (m mockTaskRepository) FindOpenDependencies() ([]*entity.TaskDependency, error) {
+	if m.dependencies == nil {
+		return nil, nil
+	}
+	return *m.dependencies, nil
+}
+
+func (m mockTaskRepository) CountOpenBlockers(taskID string) (int64, error) {
+	blockers, err := m.FindBlockers(taskID)
+	var open int64
+	for _, blocker := range blockers {
+		if blocker.Status != entity.Closed {
+			open++
+		}
+	}
+	return open, err
+}
+
+func (m mockTaskRepository) FindOpenTasks(limit int) ([]*entity.Task, error) {
+	var tasks []*entity.Task
+	for _, id := range []string{testID, testParentID, testChildID} {
+		task, err := m.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (m mockTaskRepository) AttachLabel(taskID string, labelID string) (bool, error) {
+	if labelID != testLabelID {
+		return false, errs.New(errs.ErrNotFound, "label not found")
+	}
+	for _, label := range *m.labels {
+		if label.TaskID == taskID && label.LabelID == labelID {
+			return false, nil
+		}
+	}
+	*m.labels = append(*m.labels, &entity.TaskLabel{TaskID: taskID, LabelID: labelID})
+	return true, nil
+}
+
+func (m mockTaskRepository) DetachLabel(taskID string, labelID string) error {
+	for i, label := range *m.labels {
+		if label.TaskID == taskID && label.LabelID == labelID {
+			*m.labels = append((*m.labels)[:i], (*m.labels)[i+1:]...)
+			return nil
+		}
+	}
+	return errs.New(errs.ErrNotFound, "label not attached")
+}
+
+func (m mockTaskRepository) FindLabels(taskIDs []string) (map[string][]*entity.Label, error) {
+	labels := make(map[string][]*entity.Label)
+	if m.labels == nil {
+		return labels, nil
+	}
+	for _, label := range *m.labels {
+		for _, id := range taskIDs {
+			if label.TaskID == id {
+				labels[id] = append(labels[id], &entity.Label{ID: label.LabelID, LabelDescription: entity.LabelDescription{Name: "bug"}})
+			}
+		}
+	}
+	return labels, nil
+}
+
+func (m mockTaskRepository) FindProject(id string) (*entity.Project, error) {
+	switch id {
+	case "non-existing-ID":
+		return nil, errs.New(errs.ErrNotFound, "project not found")
+	case testProjectID:
+		return &entity.Project{ID: id, ProjectDescription: entity.ProjectDescription{Name: "restricted", ProjectSettings: entity.ProjectSettings{
+			DefaultPriority: 4, AllowedStatuses: entity.StatusList{entity.Active, entity.Closed}}}}, nil
+	}
+	return &entity.Project{ID: id}, nil
+}
+
+func (m mockTaskRepository) FindProjectOf(taskID string) (string, error) {
+	task, err := m.FindByID(taskID)
+	if err != nil {
+		return "", err
+	}
+	return task.ProjectID, nil
+}
+
+func (m mockTaskRepository) DeleteByID(id string, deletedBy string) error {
+	if deletedBy != testSubject {
+		return errors.New("task is not deleted on behalf of the principal")
+	}
 	_, err := m.FindByID(id)
 	return err
 }
 
-func (m mockTaskRepository) Update(fields map[string]interface{}, id string) error {
+func (m mockTaskRepository) Restore(id string) error {
+	_, err := m.FindByID(id)
+	return err
+}
+
+func (m mockTaskRepository) Purge(deletedBefore time.Time) (int64, []string, error) {
+	if time.Since(deletedBefore) < time.Hour {
+		return 0, nil, errors.New("tasks deleted within the retention period would be purged")
+	}
+	return 2, []string{testTenant + "/" + testClosedID + "/a1", testTenant + "/" + testClosedID + "/a2"}, nil
+}
+
+func (m mockTaskRepository) Update(fields map[string]interface{}, id string, version int) error {
 	fullUpdateValues := map[string]interface{}{"title": FullUpdateRequest.Title, "description": FullUpdateRequest.Description,
-		"priority": FullUpdateRequest.Priority, "status": FullUpdateRequest.Status}
+		"priority": FullUpdateRequest.Priority, "status": FullUpdateRequest.Status, "start_at": FullUpdateRequest.StartAt, "due_at": FullUpdateRequest.DueAt,
+		"recurrence": FullUpdateRequest.Recurrence, "timezone": FullUpdateRequest.Timezone, "parent_id": FullUpdateRequest.ParentID, "project_id": FullUpdateRequest.ProjectID}
 
 	partialUpdateValues := map[string]interface{}{"title": PartialUpdateRequest.Title, "status": PartialUpdateRequest.Status}
 	//Task should be found
@@ -59,6 +321,9 @@ func (m mockTaskRepository) Update(fields map[string]interface{}, id string) err
 	if err != nil {
 		return err
 	}
+	if version != 0 && version != 1 {
+		return errs.New(errs.ErrPreconditionFailed, "instance is not at version %d", version)
+	}
 	//checking with id to test full-update functionality
 	if id == testFullUpdateID && !reflect.DeepEqual(fields, fullUpdateValues) {
 		return errors.New("not all fields are being updated correctly")
@@ -70,7 +335,11 @@ func (m mockTaskRepository) Update(fields map[string]interface{}, id string) err
 	return nil
 }
 
-func (m mockTaskRepository) FindAll() ([]*entity.Task, error) {
+func (m mockTaskRepository) Count(query *entity.TaskQuery) (int64, error) {
+	return 2, nil
+}
+
+func (m mockTaskRepository) FindAll(query *entity.TaskQuery) ([]*entity.Task, error) {
 	return []*entity.Task{{
 		ID:              "test1",
 		CreatedAt:       time.Time{},
@@ -95,13 +364,46 @@ func (m mockTaskRepository) FindByID(id string) (*entity.Task, error) {
 			ID:              testFullUpdateID,
 			TaskDescription: FullUpdateRequest,
 		}, nil
+	} else if id == testClosedID {
+		return &entity.Task{
+			ID:              testClosedID,
+			TaskDescription: entity.TaskDescription{Title: "closed", Priority: 1, Status: entity.Closed, StartAt: &testStartAt},
+		}, nil
+	} else if id == testTemplateID {
+		return &entity.Task{
+			ID:              testTemplateID,
+			TemplateID:      testTemplateID,
+			Occurrence:      1,
+			TaskDescription: entity.TaskDescription{Title: "weekly", Priority: 2, Status: entity.Closed, DueAt: &testDueAt, Recurrence: "FREQ=WEEKLY;COUNT=3"},
+		}, nil
+	} else if id == testOccurrenceID {
+		dueAt := testDueAt.AddDate(0, 0, 7)
+		startAt := dueAt.Add(-time.Hour)
+		return &entity.Task{
+			ID:              testOccurrenceID,
+			TenantID:        testTenant,
+			TemplateID:      testTemplateID,
+			Occurrence:      2,
+			TaskDescription: entity.TaskDescription{Title: "weekly", Priority: 2, Status: entity.Active, StartAt: &startAt, DueAt: &dueAt},
+		}, nil
+	} else if id == testParentID {
+		return &entity.Task{
+			ID:              testParentID,
+			Subtasks:        1,
+			TaskDescription: entity.TaskDescription{Title: "epic", Priority: 1, Status: entity.Active},
+		}, nil
+	} else if id == testChildID {
+		return &entity.Task{
+			ID:              testChildID,
+			TaskDescription: entity.TaskDescription{Title: "subtask", Priority: 1, Status: entity.Active, ParentID: testParentID},
+		}, nil
 	} else if id == testPartialUpdateID {
 		return &entity.Task{
 			ID:              testPartialUpdateID,
 			TaskDescription: PartialUpdateRequest,
 		}, nil
 	}
-	return nil, errors.New("instance with specified ID not found")
+	return nil, errs.New(errs.ErrNotFound, "instance with specified ID not found")
 }
 
 func TestNewTaskService(t *testing.T) {
@@ -116,12 +418,12 @@ func TestNewTaskService(t *testing.T) {
 		{
 			name: "should pass",
 			args: args{mockTaskRepository{}},
-			want: &TaskService{TaskRepository: mockTaskRepository{}},
+			want: &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default(), DeletePolicy: hierarchy.Refuse, BlobStore: mockBlobStore{}},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewTaskService(tt.args.repo); !reflect.DeepEqual(got, tt.want) {
+			if got := NewTaskService(tt.args.repo, workflow.Default(), hierarchy.Refuse, mockBlobStore{}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewTaskService() = %v, want %v", got, tt.want)
 			}
 		})
@@ -140,30 +442,44 @@ func TestTaskService_Create(t1 *testing.T) {
 		fields  fields
 		args    args
 		want    *entity.TaskDescription
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name:    "should pass and create task",
 			fields:  fields{TaskRepository: mockTaskRepository{}},
 			args:    args{req: &TaskRequestInstance},
 			want:    &TaskRequestInstance,
-			wantErr: false,
+			wantErr: nil,
+		},
+		{
+			name:    "should fail with a validation error because title is empty",
+			fields:  fields{TaskRepository: mockTaskRepository{}},
+			args:    args{req: &entity.TaskDescription{Description: "no title"}},
+			want:    nil,
+			wantErr: errs.ErrValidation,
 		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			got, err := t.Create(tt.args.req)
-			if (err != nil) != tt.wantErr {
+			got, err := t.Create(testCtx, tt.args.req)
+			if !errors.Is(err, tt.wantErr) {
 				t1.Errorf("Create() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr != nil {
+				return
+			}
 			//the stuff that is added by gorm is already tested on repo side, here we only need to check if the request is propagated
 			if !reflect.DeepEqual(got.TaskDescription, *tt.want) {
 				t1.Errorf("Create() got = %v, want %v", got.TaskDescription, tt.want)
 			}
+			if got.CreatedBy != testSubject || got.ReporterID != testSubject || got.AssigneeID != "" {
+				t1.Errorf("Create() got created by %s, reported by %s and assigned to %s, want created and reported by %s", got.CreatedBy, got.ReporterID, got.AssigneeID, testSubject)
+			}
 		})
 	}
 }
@@ -198,47 +514,237 @@ func TestTaskService_DeleteByID(t1 *testing.T) {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			if err := t.DeleteByID(tt.args.id); (err != nil) != tt.wantErr {
+			if err := t.DeleteByID(testCtx, tt.args.id); (err != nil) != tt.wantErr {
 				t1.Errorf("DeleteByID() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
 
+func TestTaskService_Restore(t1 *testing.T) {
+	t := &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default()}
+	got, err := t.Restore(testCtx, testID)
+	if err != nil {
+		t1.Fatalf("Restore() error = %v", err)
+	}
+	if got.ID != testID {
+		t1.Errorf("Restore() got = %v, want task %s", got, testID)
+	}
+	if _, err = t.Restore(testCtx, "non-existing-ID"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Restore() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
+
+func TestTaskService_PurgeTrash(t1 *testing.T) {
+	store := mockBlobStore{blobs: map[string][]byte{
+		testTenant + "/" + testClosedID + "/a1": []byte("purged"),
+		testTenant + "/" + testClosedID + "/a2": []byte("purged"),
+		testTenant + "/" + testID + "/a3":       []byte("kept"),
+	}}
+	t := &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default(), BlobStore: store}
+	got, err := t.PurgeTrash(testCtx, 24*time.Hour)
+	if err != nil {
+		t1.Fatalf("PurgeTrash() error = %v", err)
+	}
+	if got != 2 {
+		t1.Errorf("PurgeTrash() got = %d, want 2", got)
+	}
+	// the content of the attachments of the purged tasks is deleted with them
+	if _, ok := store.blobs[testTenant+"/"+testID+"/a3"]; len(store.blobs) != 1 || !ok {
+		t1.Errorf("PurgeTrash() left the blobs %v, want only the one of %s", store.blobs, testID)
+	}
+}
+
+func TestTaskService_Tenants(t1 *testing.T) {
+	var tenants []string
+	t := &TaskService{TaskRepository: mockTaskRepository{tenants: &tenants}, Workflow: workflow.Default(), BlobStore: mockBlobStore{blobs: make(map[string][]byte)}}
+
+	if _, err := t.GetByID(testCtx, testID); err != nil {
+		t1.Fatalf("GetByID() error = %v", err)
+	}
+	if len(tenants) == 0 || tenants[0] != testTenant {
+		t1.Errorf("GetByID() bound the repository to %v, want %s", tenants, testTenant)
+	}
+
+	// without principal the repository is bound to the empty tenant, for which it refuses all the statements
+	tenants = nil
+	if _, err := t.GetByID(context.Background(), testID); err != nil {
+		t1.Fatalf("GetByID() error = %v", err)
+	}
+	if len(tenants) == 0 || tenants[0] != "" {
+		t1.Errorf("GetByID() bound the repository to %v, want the empty tenant", tenants)
+	}
+
+	// the background jobs look at all the tenants, then spawn each occurrence in the tenant of its series
+	tenants = nil
+	if _, err := t.PurgeTrash(context.Background(), time.Hour); err != nil {
+		t1.Fatalf("PurgeTrash() error = %v", err)
+	}
+	if _, err := t.SpawnOccurrences(context.Background()); err != nil {
+		t1.Fatalf("SpawnOccurrences() error = %v", err)
+	}
+	if want := []string{allTenants, allTenants, testTenant}; !reflect.DeepEqual(tenants, want) {
+		t1.Errorf("background jobs bound the repository to %v, want %v", tenants, want)
+	}
+}
+
+func TestTaskService_History(t1 *testing.T) {
+	var events []*entity.TaskEvent
+	t := &TaskService{TaskRepository: mockTaskRepository{events: &events}, Workflow: workflow.Default()}
+
+	update := TaskRequestInstance
+	update.Title = "renamed"
+	if _, err := t.UpdateFully(testCtx, &update, testID, 0); err != nil {
+		t1.Fatalf("UpdateFully() error = %v", err)
+	}
+	if err := t.DeleteByID(testCtx, testID); err != nil {
+		t1.Fatalf("DeleteByID() error = %v", err)
+	}
+
+	got, err := t.History(testCtx, testID, &entity.HistoryQuery{})
+	if err != nil {
+		t1.Fatalf("History() error = %v", err)
+	}
+	if got.Total != 2 || got.Limit != validation.DefaultLimit || len(got.Events) != 2 {
+		t1.Fatalf("History() got = %v, want 2 events", got)
+	}
+	updated, deletion := got.Events[0], got.Events[1]
+	wantChanges := []entity.FieldChange{{Field: "title", Old: TaskRequestInstance.Title, New: "renamed"}}
+	if updated.Type != entity.Updated || updated.Actor != testSubject || !reflect.DeepEqual(updated.Changes, wantChanges) {
+		t1.Errorf("History() got update event = %v, want changes %v by %s", updated, wantChanges, testSubject)
+	}
+	if deletion.Type != entity.Deleted || deletion.Actor != testSubject {
+		t1.Errorf("History() got deletion event = %v, want deletion by %s", deletion, testSubject)
+	}
+
+	if _, err = t.History(testCtx, "non-existing-ID", &entity.HistoryQuery{}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("History() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if _, err = t.History(testCtx, testID, &entity.HistoryQuery{Limit: -1}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("History() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
+
+func TestTaskService_Assign(t1 *testing.T) {
+	var events []*entity.TaskEvent
+	t := &TaskService{TaskRepository: mockTaskRepository{events: &events}, Workflow: workflow.Default()}
+
+	if _, err := t.Assign(testCtx, testID, "bob", 0); err != nil {
+		t1.Fatalf("Assign() error = %v", err)
+	}
+	wantChanges := []entity.FieldChange{{Field: "assigneeId", Old: "", New: "bob"}}
+	if len(events) != 1 || events[0].Type != entity.Updated || !reflect.DeepEqual(events[0].Changes, wantChanges) {
+		t1.Errorf("Assign() recorded events = %v, want changes %v", events, wantChanges)
+	}
+	if _, err := t.Assign(testCtx, testID, " bob", 0); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Assign() error = %v, want %v for a subject with spaces", err, errs.ErrValidation)
+	}
+	if _, err := t.Assign(testCtx, "non-existing-ID", "", 0); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Assign() error = %v, want %v", err, errs.ErrNotFound)
+	}
+	if _, err := t.Assign(testCtx, testID, "", 3); !errors.Is(err, errs.ErrPreconditionFailed) {
+		t1.Errorf("Assign() error = %v, want %v for an outdated version", err, errs.ErrPreconditionFailed)
+	}
+
+	// the caller lists its own queue with assignee=me
+	query := &entity.TaskQuery{Assignee: entity.AssigneeMe}
+	if _, err := t.Get(testCtx, query); err != nil || query.Assignee != testSubject {
+		t1.Errorf("Get() listed the tasks of %s, %v, want the tasks of %s", query.Assignee, err, testSubject)
+	}
+	if _, err := t.Get(context.Background(), &entity.TaskQuery{Assignee: entity.AssigneeMe}); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("Get() error = %v, want %v without caller", err, errs.ErrUnauthenticated)
+	}
+}
+
 func TestTaskService_Get(t1 *testing.T) {
 	type fields struct {
 		TaskRepository interfaces.ITaskRepository
 	}
+	type args struct {
+		query *entity.TaskQuery
+	}
 	tests := []struct {
 		name    string
 		fields  fields
-		want    []*entity.Task
+		args    args
+		want    *entity.TaskList
 		wantErr bool
 	}{
 		{
 			name:   "should pass and return the instances fetched by the repository",
 			fields: fields{TaskRepository: mockTaskRepository{}},
-			want: []*entity.Task{{
-				ID:              "test1",
-				CreatedAt:       time.Time{},
-				UpdatedAt:       time.Time{},
-				TaskDescription: entity.TaskDescription{},
-			}, {
-				ID:              "test2",
-				CreatedAt:       time.Time{},
-				UpdatedAt:       time.Time{},
-				TaskDescription: entity.TaskDescription{},
-			}},
+			args:   args{query: &entity.TaskQuery{Offset: 10}},
+			want: &entity.TaskList{
+				Tasks: []*entity.Task{{
+					ID:              "test1",
+					CreatedAt:       time.Time{},
+					UpdatedAt:       time.Time{},
+					TaskDescription: entity.TaskDescription{},
+				}, {
+					ID:              "test2",
+					CreatedAt:       time.Time{},
+					UpdatedAt:       time.Time{},
+					TaskDescription: entity.TaskDescription{},
+				}},
+				Total:  2,
+				Limit:  validation.DefaultLimit,
+				Offset: 10,
+			},
 			wantErr: false,
 		},
+		{
+			name:   "should return the first page with the cursor of the next one",
+			fields: fields{TaskRepository: mockTaskRepository{}},
+			args:   args{query: &entity.TaskQuery{Limit: 1, Keyset: true}},
+			want: &entity.TaskList{
+				Tasks: []*entity.Task{{
+					ID:              "test1",
+					CreatedAt:       time.Time{},
+					UpdatedAt:       time.Time{},
+					TaskDescription: entity.TaskDescription{},
+				}},
+				Limit: 1,
+				Next:  &entity.TaskCursor{CreatedAt: time.Time{}, ID: "test1"},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "should return the last page without cursor",
+			fields: fields{TaskRepository: mockTaskRepository{}},
+			args:   args{query: &entity.TaskQuery{Limit: 2, Keyset: true}},
+			want: &entity.TaskList{
+				Tasks: []*entity.Task{{
+					ID:              "test1",
+					CreatedAt:       time.Time{},
+					UpdatedAt:       time.Time{},
+					TaskDescription: entity.TaskDescription{},
+				}, {
+					ID:              "test2",
+					CreatedAt:       time.Time{},
+					UpdatedAt:       time.Time{},
+					TaskDescription: entity.TaskDescription{},
+				}},
+				Limit: 2,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "should fail because of invalid query",
+			fields:  fields{TaskRepository: mockTaskRepository{}},
+			args:    args{query: &entity.TaskQuery{Sort: []entity.SortField{{Field: "unknown"}}}},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			got, err := t.Get()
+			got, err := t.Get(testCtx, tt.args.query)
 			if (err != nil) != tt.wantErr {
 				t1.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -288,8 +794,9 @@ func TestTaskService_GetByID(t1 *testing.T) {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			got, err := t.GetByID(tt.args.id)
+			got, err := t.GetByID(testCtx, tt.args.id)
 			if (err != nil) != tt.wantErr {
 				t1.Errorf("GetByID() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -306,8 +813,9 @@ func TestTaskService_UpdateFully(t1 *testing.T) {
 		TaskRepository interfaces.ITaskRepository
 	}
 	type args struct {
-		req *entity.TaskDescription
-		id  string
+		req     *entity.TaskDescription
+		id      string
+		version int
 	}
 	tests := []struct {
 		name    string
@@ -339,13 +847,25 @@ func TestTaskService_UpdateFully(t1 *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name:   "should fail to update instance since its version has moved on",
+			fields: fields{TaskRepository: mockTaskRepository{}},
+			args: args{
+				req:     &FullUpdateRequest,
+				id:      testFullUpdateID,
+				version: 2,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			got, err := t.UpdateFully(tt.args.req, tt.args.id)
+			got, err := t.UpdateFully(testCtx, tt.args.req, tt.args.id, tt.args.version)
 			if (err != nil) != tt.wantErr {
 				t1.Errorf("UpdateFully() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -362,8 +882,9 @@ func TestTaskService_UpdatePartial(t1 *testing.T) {
 		TaskRepository interfaces.ITaskRepository
 	}
 	type args struct {
-		req *entity.TaskDescription
-		id  string
+		req     *entity.TaskDescription
+		id      string
+		version int
 	}
 	tests := []struct {
 		name    string
@@ -400,8 +921,9 @@ func TestTaskService_UpdatePartial(t1 *testing.T) {
 		t1.Run(tt.name, func(t1 *testing.T) {
 			t := &TaskService{
 				TaskRepository: tt.fields.TaskRepository,
+				Workflow:       workflow.Default(),
 			}
-			got, err := t.UpdatePartial(tt.args.req, tt.args.id)
+			got, err := t.UpdatePartial(testCtx, tt.args.req, tt.args.id, tt.args.version)
 			if (err != nil) != tt.wantErr {
 				t1.Errorf("UpdatePartial() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -412,3 +934,425 @@ func TestTaskService_UpdatePartial(t1 *testing.T) {
 		})
 	}
 }
+
+func TestTaskService_UpdateWorkflow(t1 *testing.T) {
+	t := &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default()}
+
+	_, err := t.UpdatePartial(testCtx, &entity.TaskDescription{Status: "New"}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a closed task moved back to new", err, errs.ErrConflict)
+	}
+	_, err = t.UpdateFully(testCtx, &entity.TaskDescription{Title: "closed", Priority: 1, Status: entity.New}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("UpdateFully() error = %v, want %v for a closed task moved back to new", err, errs.ErrConflict)
+	}
+	_, err = t.UpdatePartial(testCtx, &entity.TaskDescription{Status: "done"}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for an unknown status", err, errs.ErrValidation)
+	}
+	got, err := t.UpdatePartial(testCtx, &entity.TaskDescription{Status: "Active"}, testClosedID, 0)
+	if err != nil {
+		t1.Errorf("UpdatePartial() error = %v, want a closed task to be reopened", err)
+	}
+	if got == nil || got.ID != testClosedID {
+		t1.Errorf("UpdatePartial() got = %v, want task %s", got, testClosedID)
+	}
+}
+
+func TestTaskService_UpdateSchedule(t1 *testing.T) {
+	var events []*entity.TaskEvent
+	t := &TaskService{TaskRepository: mockTaskRepository{events: &events}, Workflow: workflow.Default()}
+
+	// only the due time is sent, it is checked against the start time of the stored task
+	dueAt := testStartAt.Add(-time.Hour)
+	_, err := t.UpdatePartial(testCtx, &entity.TaskDescription{DueAt: &dueAt}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a task due before it starts", err, errs.ErrValidation)
+	}
+
+	dueAt = testStartAt.Add(24 * time.Hour)
+	if _, err = t.UpdatePartial(testCtx, &entity.TaskDescription{DueAt: &dueAt}, testClosedID, 0); err != nil {
+		t1.Fatalf("UpdatePartial() error = %v", err)
+	}
+	want := []entity.FieldChange{{Field: "dueAt", Old: (*time.Time)(nil), New: &dueAt}}
+	if len(events) != 1 || !reflect.DeepEqual(events[0].Changes, want) {
+		t1.Errorf("UpdatePartial() recorded %v, want changes %v", events, want)
+	}
+}
+
+func TestTaskService_SpawnOccurrences(t1 *testing.T) {
+	var events []*entity.TaskEvent
+	var occurrences []*entity.Task
+	t := &TaskService{TaskRepository: mockTaskRepository{events: &events, occurrences: &occurrences}, Workflow: workflow.Default()}
+
+	spawned, err := t.SpawnOccurrences(testCtx)
+	if err != nil || spawned != 1 {
+		t1.Fatalf("SpawnOccurrences() = %d, %v, want 1 occurrence spawned", spawned, err)
+	}
+	next := occurrences[0]
+	dueAt, startAt := testDueAt.AddDate(0, 0, 14), testDueAt.AddDate(0, 0, 14).Add(-time.Hour)
+	if next.TemplateID != testTemplateID || next.Occurrence != 3 || next.Status != entity.New || next.Title != "weekly" ||
+		!next.DueAt.Equal(dueAt) || !next.StartAt.Equal(startAt) {
+		t1.Errorf("SpawnOccurrences() spawned %v, want occurrence 3 of %s due at %s", next, testTemplateID, dueAt)
+	}
+	if len(events) != 1 || events[0].TaskID != next.ID || events[0].Type != entity.Created {
+		t1.Errorf("SpawnOccurrences() recorded %v, want the creation of %s", events, next.ID)
+	}
+
+	// the occurrence already exists
+	if spawned, err = t.SpawnOccurrences(testCtx); err != nil || spawned != 0 {
+		t1.Errorf("SpawnOccurrences() = %d, %v, want no occurrence spawned twice", spawned, err)
+	}
+}
+
+// seriesRepository keeps the tasks of the series in memory, and finds the due occurrences as the task repository does
+type seriesRepository struct {
+	mockTaskRepository
+	tasks map[string]*entity.Task
+}
+
+func (s seriesRepository) Transaction(fn func(repo interfaces.ITaskRepository) error) error {
+	return fn(s)
+}
+
+func (s seriesRepository) WithTenant(tenant string) interfaces.ITaskRepository {
+	return s
+}
+
+func (s seriesRepository) AllTenants() interfaces.ITaskRepository {
+	return s
+}
+
+func (s seriesRepository) FindByID(id string) (*entity.Task, error) {
+	if task, ok := s.tasks[id]; ok {
+		return task, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "task not found")
+}
+
+func (s seriesRepository) CreateOccurrence(task *entity.Task) (bool, error) {
+	s.tasks[task.ID] = task
+	return true, nil
+}
+
+func (s seriesRepository) FindDueOccurrences(now time.Time, limit int) ([]*entity.Task, error) {
+	last := make(map[string]*entity.Task)
+	for _, task := range s.tasks {
+		if previous, ok := last[task.TemplateID]; task.TemplateID != "" && (!ok || previous.Occurrence < task.Occurrence) {
+			last[task.TemplateID] = task
+		}
+	}
+	var due []*entity.Task
+	for _, task := range last {
+		if !task.SeriesEnded && (task.Status == entity.Closed || !task.DueAt.After(now)) {
+			due = append(due, task)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].DueAt.Before(*due[j].DueAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s seriesRepository) EndSeries(id string) error {
+	s.tasks[id].SeriesEnded = true
+	return nil
+}
+
+func TestTaskService_SpawnOccurrencesAfterEndedSeries(t1 *testing.T) {
+	tasks := make(map[string]*entity.Task)
+	// more series whose count is reached than spawned at once, all due before the series that goes on
+	for i := 0; i < spawnBatchSize+spawnBatchSize/2; i++ {
+		id, dueAt := fmt.Sprintf("ended%d", i), testDueAt.Add(time.Duration(i)*time.Minute)
+		tasks[id] = &entity.Task{ID: id, TemplateID: id, Occurrence: 1,
+			TaskDescription: entity.TaskDescription{Status: entity.Closed, DueAt: &dueAt, Recurrence: "FREQ=DAILY;COUNT=1"}}
+	}
+	dueAt := testDueAt.AddDate(0, 0, 1)
+	tasks["live"] = &entity.Task{ID: "live", TemplateID: "live", Occurrence: 1,
+		TaskDescription: entity.TaskDescription{Status: entity.Closed, DueAt: &dueAt, Recurrence: "FREQ=DAILY"}}
+	t := &TaskService{TaskRepository: seriesRepository{tasks: tasks}, Workflow: workflow.Default()}
+
+	spawned := 0
+	for i := 0; i < 2; i++ {
+		n, err := t.SpawnOccurrences(testCtx)
+		if err != nil {
+			t1.Fatalf("SpawnOccurrences() error = %v", err)
+		}
+		spawned += n
+	}
+	if spawned != 1 {
+		t1.Errorf("SpawnOccurrences() spawned %d occurrences, want the one following the live series", spawned)
+	}
+	for id, task := range tasks {
+		if ended := task.TemplateID != "live"; task.SeriesEnded != ended {
+			t1.Errorf("SpawnOccurrences() left the end of the series of %s to %v, want %v", id, task.SeriesEnded, ended)
+		}
+	}
+}
+
+func TestTaskService_SpawnOccurrencesInTimezone(t1 *testing.T) {
+	// every Monday at 9:00 in Paris, the last occurrence before the summer time is due at 8:00 UTC
+	dueAt := time.Date(2022, time.March, 21, 8, 0, 0, 0, time.UTC)
+	tasks := map[string]*entity.Task{"paris": {ID: "paris", TemplateID: "paris", Occurrence: 1,
+		TaskDescription: entity.TaskDescription{Status: entity.Closed, DueAt: &dueAt, Recurrence: "FREQ=WEEKLY;BYDAY=MO", Timezone: "Europe/Paris"}}}
+	t := &TaskService{TaskRepository: seriesRepository{tasks: tasks}, Workflow: workflow.Default()}
+
+	if spawned, err := t.SpawnOccurrences(testCtx); err != nil || spawned != 1 {
+		t1.Fatalf("SpawnOccurrences() = %d, %v, want 1 occurrence spawned", spawned, err)
+	}
+	var next *entity.Task
+	for _, task := range tasks {
+		if task.Occurrence == 2 {
+			next = task
+		}
+	}
+	if want := time.Date(2022, time.March, 28, 7, 0, 0, 0, time.UTC); next == nil || !next.DueAt.Equal(want) {
+		t1.Errorf("SpawnOccurrences() spawned %v, want the occurrence due at %s", next, want)
+	}
+}
+
+func TestTaskService_UpdateRecurrence(t1 *testing.T) {
+	var occurrences []*entity.Task
+	t := &TaskService{TaskRepository: mockTaskRepository{occurrences: &occurrences}, Workflow: workflow.Default()}
+
+	// closing an occurrence spawns the next one of the series
+	if _, err := t.UpdatePartial(testCtx, &entity.TaskDescription{Status: entity.Closed}, testOccurrenceID, 0); err != nil {
+		t1.Fatalf("UpdatePartial() error = %v", err)
+	}
+	if len(occurrences) != 1 || occurrences[0].Occurrence != 3 {
+		t1.Errorf("UpdatePartial() spawned %v, want occurrence 3", occurrences)
+	}
+
+	_, err := t.UpdatePartial(testCtx, &entity.TaskDescription{Recurrence: "FREQ=DAILY"}, testOccurrenceID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a rule set on an occurrence", err, errs.ErrValidation)
+	}
+	_, err = t.UpdatePartial(testCtx, &entity.TaskDescription{Recurrence: "FREQ=DAILY"}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a recurring task without due time", err, errs.ErrValidation)
+	}
+}
+
+func TestTaskService_Tree(t1 *testing.T) {
+	t := &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default()}
+
+	children, err := t.Children(testCtx, testParentID)
+	if err != nil || len(children) != 1 || children[0].ID != testChildID {
+		t1.Errorf("Children() = %v, %v, want task %s", children, err, testChildID)
+	}
+	if _, err = t.Children(testCtx, "non-existing-ID"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Children() error = %v, want %v", err, errs.ErrNotFound)
+	}
+
+	got, err := t.Tree(testCtx, testParentID)
+	if err != nil {
+		t1.Fatalf("Tree() error = %v", err)
+	}
+	if got.Task.ID != testParentID || len(got.Children) != 1 || got.Children[0].Task.ID != testChildID || len(got.Children[0].Children) != 0 {
+		t1.Errorf("Tree() got = %v, want %s with the single subtask %s", got, testParentID, testChildID)
+	}
+}
+
+func TestTaskService_UpdateParent(t1 *testing.T) {
+	var refreshed []string
+	t := &TaskService{TaskRepository: mockTaskRepository{refreshed: &refreshed}, Workflow: workflow.Default()}
+
+	_, err := t.UpdatePartial(testCtx, &entity.TaskDescription{ParentID: testChildID}, testParentID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a task moved under its own subtask", err, errs.ErrValidation)
+	}
+	_, err = t.UpdatePartial(testCtx, &entity.TaskDescription{ParentID: "non-existing-ID"}, testClosedID, 0)
+	if !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for an unknown parent", err, errs.ErrValidation)
+	}
+
+	if _, err = t.UpdatePartial(testCtx, &entity.TaskDescription{ParentID: testParentID}, testClosedID, 0); err != nil {
+		t1.Fatalf("UpdatePartial() error = %v", err)
+	}
+	if !reflect.DeepEqual(refreshed, []string{testParentID}) {
+		t1.Errorf("UpdatePartial() refreshed %v, want the completion of %s", refreshed, testParentID)
+	}
+
+	// a subtask made a top-level task changes the completion of the parent it leaves
+	refreshed = nil
+	if _, err = t.RemoveParent(testCtx, testChildID, 0); err != nil {
+		t1.Fatalf("RemoveParent() error = %v", err)
+	}
+	if !reflect.DeepEqual(refreshed, []string{testParentID}) {
+		t1.Errorf("RemoveParent() refreshed %v, want the completion of %s", refreshed, testParentID)
+	}
+
+	// closing a subtask changes the completion of its parent
+	refreshed = nil
+	if _, err = t.UpdatePartial(testCtx, &entity.TaskDescription{Status: entity.Closed}, testChildID, 0); err != nil {
+		t1.Fatalf("UpdatePartial() error = %v", err)
+	}
+	if !reflect.DeepEqual(refreshed, []string{testParentID}) {
+		t1.Errorf("UpdatePartial() refreshed %v, want the completion of %s", refreshed, testParentID)
+	}
+}
+
+func TestTaskService_DeletePolicy(t1 *testing.T) {
+	tests := []struct {
+		policy  hierarchy.DeletePolicy
+		want    []entity.EventType // events recorded for the subtask and then its parent
+		wantErr error
+	}{
+		{policy: hierarchy.Refuse, wantErr: errs.ErrConflict},
+		{policy: hierarchy.Orphan, want: []entity.EventType{entity.Updated, entity.Deleted}},
+		{policy: hierarchy.Cascade, want: []entity.EventType{entity.Deleted, entity.Deleted}},
+	}
+	for _, tt := range tests {
+		t1.Run(string(tt.policy), func(t1 *testing.T) {
+			var events []*entity.TaskEvent
+			t := &TaskService{TaskRepository: mockTaskRepository{events: &events}, Workflow: workflow.Default(), DeletePolicy: tt.policy}
+
+			err := t.DeleteByID(testCtx, testParentID)
+			if !errors.Is(err, tt.wantErr) {
+				t1.Fatalf("DeleteByID() error = %v, want %v", err, tt.wantErr)
+			}
+			var got []entity.EventType
+			for _, event := range events {
+				got = append(got, event.Type)
+			}
+			if !reflect.DeepEqual(got, tt.want) || (len(events) > 0 && events[0].TaskID != testChildID) {
+				t1.Errorf("DeleteByID() recorded %v, want %v starting with the subtask", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskService_Blockers(t1 *testing.T) {
+	var dependencies []*entity.TaskDependency
+	var events []*entity.TaskEvent
+	t := &TaskService{TaskRepository: mockTaskRepository{dependencies: &dependencies, events: &events}, Workflow: workflow.Default()}
+
+	got, err := t.AddBlocker(testCtx, testID, testChildID)
+	if err != nil {
+		t1.Fatalf("AddBlocker() error = %v", err)
+	}
+	if want := (&entity.TaskDependency{TaskID: testID, BlockerID: testChildID, CreatedBy: testSubject}); !reflect.DeepEqual(got, want) {
+		t1.Errorf("AddBlocker() got = %v, want %v", got, want)
+	}
+	if _, err = t.AddBlocker(testCtx, testChildID, testParentID); err != nil {
+		t1.Fatalf("AddBlocker() error = %v", err)
+	}
+	if len(events) != 2 || events[0].Type != entity.BlockerAdded || events[0].TaskID != testID {
+		t1.Errorf("AddBlocker() recorded %v, want the blockers added to %s and %s", events, testID, testChildID)
+	}
+
+	// testParentID blocks testChildID which blocks testID, so testID cannot block testParentID
+	if _, err = t.AddBlocker(testCtx, testParentID, testID); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("AddBlocker() error = %v, want %v for a cycle", err, errs.ErrValidation)
+	}
+	if _, err = t.AddBlocker(testCtx, testID, testID); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("AddBlocker() error = %v, want %v for a task blocking itself", err, errs.ErrValidation)
+	}
+	if _, err = t.AddBlocker(testCtx, testID, "non-existing-ID"); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("AddBlocker() error = %v, want %v for an unknown blocker", err, errs.ErrValidation)
+	}
+	if _, err = t.AddBlocker(testCtx, testID, testChildID); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("AddBlocker() error = %v, want %v for an existing dependency", err, errs.ErrConflict)
+	}
+
+	blockers, err := t.Blockers(testCtx, testID)
+	if err != nil || len(blockers) != 1 || blockers[0].ID != testChildID {
+		t1.Errorf("Blockers() = %v, %v, want task %s", blockers, err, testChildID)
+	}
+
+	// the open blocker prevents closing the task
+	_, err = t.UpdatePartial(testCtx, &entity.TaskDescription{Status: entity.Closed}, testID, 0)
+	if !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a blocked task", err, errs.ErrConflict)
+	}
+	if err = t.RemoveBlocker(testCtx, testID, testChildID); err != nil {
+		t1.Fatalf("RemoveBlocker() error = %v", err)
+	}
+	if _, err = t.UpdatePartial(testCtx, &entity.TaskDescription{Status: entity.Closed}, testID, 0); err != nil {
+		t1.Errorf("UpdatePartial() error = %v, want an unblocked task to be closed", err)
+	}
+	if err = t.RemoveBlocker(testCtx, testID, testChildID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("RemoveBlocker() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
+
+func TestTaskService_Next(t1 *testing.T) {
+	dependencies := []*entity.TaskDependency{{TaskID: testID, BlockerID: testChildID}}
+	t := &TaskService{TaskRepository: mockTaskRepository{dependencies: &dependencies}, Workflow: workflow.Default()}
+
+	got, err := t.Next(testCtx, 2)
+	if err != nil {
+		t1.Fatalf("Next() error = %v", err)
+	}
+	// testID has the highest priority, but it is blocked by testChildID
+	var ids []string
+	for _, planned := range got {
+		ids = append(ids, planned.Task.ID)
+	}
+	if want := []string{testChildID, testID}; !reflect.DeepEqual(ids, want) {
+		t1.Errorf("Next() got = %v, want %v", ids, want)
+	}
+	if _, err = t.Next(testCtx, -1); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Next() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
+
+func TestTaskService_Labels(t1 *testing.T) {
+	var labels []*entity.TaskLabel
+	var events []*entity.TaskEvent
+	t := &TaskService{TaskRepository: mockTaskRepository{labels: &labels, events: &events}, Workflow: workflow.Default()}
+
+	if err := t.AddLabel(testCtx, testID, testLabelID); err != nil {
+		t1.Fatalf("AddLabel() error = %v", err)
+	}
+	// attaching the label again does nothing
+	if err := t.AddLabel(testCtx, testID, testLabelID); err != nil {
+		t1.Fatalf("AddLabel() error = %v", err)
+	}
+	if len(events) != 1 || events[0].Type != entity.LabelAdded || events[0].TaskID != testID {
+		t1.Errorf("AddLabel() recorded %v, want a single label added to %s", events, testID)
+	}
+	if err := t.AddLabel(testCtx, testID, "non-existing-ID"); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("AddLabel() error = %v, want %v for an unknown label", err, errs.ErrNotFound)
+	}
+	if err := t.AddLabel(testCtx, "non-existing-ID", testLabelID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("AddLabel() error = %v, want %v for an unknown task", err, errs.ErrNotFound)
+	}
+
+	task, err := t.GetByID(testCtx, testID)
+	if err != nil || len(task.Labels) != 1 || task.Labels[0].ID != testLabelID {
+		t1.Errorf("GetByID() = %v, %v, want the task with label %s", task, err, testLabelID)
+	}
+
+	if err = t.RemoveLabel(testCtx, testID, testLabelID); err != nil {
+		t1.Fatalf("RemoveLabel() error = %v", err)
+	}
+	if len(events) != 2 || events[1].Type != entity.LabelRemoved {
+		t1.Errorf("RemoveLabel() recorded %v, want the label removed", events)
+	}
+	if err = t.RemoveLabel(testCtx, testID, testLabelID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("RemoveLabel() error = %v, want %v", err, errs.ErrNotFound)
+	}
+}
+
+func TestTaskService_Projects(t1 *testing.T) {
+	t := &TaskService{TaskRepository: mockTaskRepository{}, Workflow: workflow.Default()}
+
+	if _, err := t.Create(testCtx, &entity.TaskDescription{Title: "test", ProjectID: "non-existing-ID"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Create() error = %v, want %v for an unknown project", err, errs.ErrNotFound)
+	}
+	if _, err := t.Get(testCtx, &entity.TaskQuery{ProjectID: "non-existing-ID"}); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("Get() error = %v, want %v for an unknown project", err, errs.ErrNotFound)
+	}
+	// testID is new, a status the project does not allow
+	if _, err := t.UpdatePartial(testCtx, &entity.TaskDescription{ProjectID: testProjectID}, testID, 0); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdatePartial() error = %v, want %v for a status not allowed in the project", err, errs.ErrValidation)
+	}
+	if _, err := t.UpdatePartial(testCtx, &entity.TaskDescription{ProjectID: testProjectID, Status: entity.Active}, testID, 0); err != nil {
+		t1.Errorf("UpdatePartial() error = %v, want the task moved along with an allowed status", err)
+	}
+	if _, err := t.UpdateFully(testCtx, &entity.TaskDescription{Title: "test", Status: entity.OnHold, ProjectID: testProjectID}, testID, 0); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("UpdateFully() error = %v, want %v for a status not allowed in the project", err, errs.ErrValidation)
+	}
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// loginTTL is how long the browser has to come back from the identity provider once the login is started
+const loginTTL = 10 * time.Minute
+
+// oidcSubjectPrefix prefixes the subjects of the callers logged in with the identity provider, so that they cannot be mistaken for
+// the subjects of the API keys or the usernames of the other authentication methods
+const oidcSubjectPrefix = "oidc:"
+
+// ClaimMapping tells which claims of the ID tokens give the principal of the callers logged in with the identity provider
+type ClaimMapping struct {
+	UsernameClaim string // claim naming the caller, it must be unique and never reassigned by the provider, as sub is
+	TenantClaim   string // claim holding the tenant of the caller
+	RolesClaim    string // claim listing the roles of the caller on all the projects, as an array or a string separated by spaces
+	DefaultTenant string // tenant of the callers whose ID token has no tenant claim
+}
+
+// SessionService logs the browser clients in with the identity provider, using the authorization code flow with PKCE, and keeps their
+// sessions on the server. Only the SHA-256 of the session cookies and of the login states are stored, as for the API keys.
+type SessionService struct {
+	SessionRepository interfaces.ISessionRepository
+	Provider          interfaces.IIdentityProvider
+	Claims            ClaimMapping
+	TTL               time.Duration // how long a session lasts after the login
+}
+
+// NewSessionService is the constructor of a SessionService with the repository and the identity provider injected
+func NewSessionService(repo interfaces.ISessionRepository, provider interfaces.IIdentityProvider, claims ClaimMapping, ttl time.Duration) *SessionService {
+	if repo == nil || provider == nil {
+		log.Fatalf("nil repo or provider provided")
+	}
+	return &SessionService{SessionRepository: repo, Provider: provider, Claims: claims, TTL: ttl}
+}
+
+// BeginLogin starts a login and returns its state along with the URL of the identity provider the browser is redirected to.
+// The nonce and the PKCE verifier of the login stay on the server, only the challenge derived from the verifier is sent to the provider.
+func (s *SessionService) BeginLogin(ctx context.Context, returnTo string) (string, string, error) {
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := randomSecret()
+		if err != nil {
+			return "", "", err
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+	attempt := entity.LoginAttempt{
+		ID:           hashSecret(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     localPath(returnTo),
+		ExpiresAt:    time.Now().UTC().Add(loginTTL),
+	}
+	if err := s.SessionRepository.CreateLogin(&attempt); err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	return state, s.Provider.AuthCodeURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:])), nil
+}
+
+// CompleteLogin ends the login of the state with the authorization code given by the identity provider, and opens a session for the caller
+// of the ID token. errs.ErrUnauthenticated is returned if the state is unknown, already used or expired, or if the provider refuses the code.
+func (s *SessionService) CompleteLogin(ctx context.Context, state string, code string) (*entity.IssuedSession, error) {
+	attempt, err := s.SessionRepository.TakeLogin(hashSecret(state))
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil, errs.New(errs.ErrUnauthenticated, "unknown login state, the login may have been completed already")
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if !now.Before(attempt.ExpiresAt) {
+		return nil, errs.New(errs.ErrUnauthenticated, "the login expired, it has to be started again")
+	}
+	idToken, err := s.Provider.Exchange(ctx, code, attempt.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.Provider.VerifyIDToken(ctx, idToken, attempt.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	p, err := s.principalOf(claims)
+	if err != nil {
+		return nil, err
+	}
+	token, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+	session := entity.Session{
+		ID:        hashSecret(token),
+		TenantID:  p.Tenant,
+		Subject:   p.Subject,
+		Admin:     p.Admin,
+		Role:      entity.Role(p.Role),
+		IDToken:   idToken,
+		ExpiresAt: now.Add(s.TTL),
+	}
+	log.Printf("opening session of '%s' in tenant '%s' ...", session.Subject, session.TenantID)
+	if err = s.SessionRepository.Create(&session); err != nil {
+		return nil, err
+	}
+	return &entity.IssuedSession{Token: token, ExpiresAt: session.ExpiresAt, ReturnTo: attempt.ReturnTo}, nil
+}
+
+// Authenticate returns the principal of the session of the cookie, errs.ErrUnauthenticated is returned if the session is unknown or expired
+func (s *SessionService) Authenticate(ctx context.Context, token string) (principal.Principal, error) {
+	session, err := s.SessionRepository.FindByID(hashSecret(token))
+	if errors.Is(err, errs.ErrNotFound) {
+		return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "unknown session")
+	}
+	if err != nil {
+		return principal.Principal{}, err
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "expired session")
+	}
+	return principal.Principal{Subject: session.Subject, Tenant: session.TenantID, Admin: session.Admin, Role: string(session.Role)}, nil
+}
+
+// Logout closes the session of the cookie and returns the URL ending the session at the identity provider as well.
+// An unknown session is not an error, the caller is logged out either way.
+func (s *SessionService) Logout(ctx context.Context, token string) (string, error) {
+	id := hashSecret(token)
+	session, err := s.SessionRepository.FindByID(id)
+	if errors.Is(err, errs.ErrNotFound) {
+		return s.Provider.LogoutURL(""), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	log.Printf("closing session of '%s' ...", session.Subject)
+	if err = s.SessionRepository.DeleteByID(id); err != nil && !errors.Is(err, errs.ErrNotFound) {
+		return "", err
+	}
+	return s.Provider.LogoutURL(session.IDToken), nil
+}
+
+// PurgeExpired removes the expired sessions and the logins that were never completed
+func (s *SessionService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.SessionRepository.PurgeExpired(time.Now().UTC())
+}
+
+// principalOf maps the claims of the ID token to the principal of the caller. The highest of the roles listed by the roles claim
+// is its role on all the projects, the other values are ignored; the admin role also makes it an administrator of the users, as the
+// administrators of the users are admin on all the projects. The roles are assigned to the subject, so the username claim must name
+// a single caller for ever: errs.ErrUnauthenticated is returned when the ID token lacks it, rather than naming the caller by another claim.
+func (s *SessionService) principalOf(claims principal.Claims) (principal.Principal, error) {
+	subject, _ := claims[s.Claims.UsernameClaim].(string)
+	if subject == "" {
+		return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "the ID token has no '%s' claim naming the caller", s.Claims.UsernameClaim)
+	}
+	tenant, _ := claims[s.Claims.TenantClaim].(string)
+	if tenant == "" {
+		tenant = s.Claims.DefaultTenant
+	}
+	var values []string
+	switch roles := claims[s.Claims.RolesClaim].(type) {
+	case string:
+		values = strings.Fields(roles)
+	case []interface{}:
+		for _, role := range roles {
+			if value, ok := role.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	var role entity.Role
+	for _, value := range values {
+		if r := entity.Role(strings.ToLower(value)); r.Includes(entity.Viewer) && r.Includes(role) {
+			role = r
+		}
+	}
+	return principal.Principal{Subject: oidcSubjectPrefix + subject, Tenant: tenant, Admin: role == entity.Admin, Role: string(role)}, nil
+}
+
+// localPath returns the path if it stays on the service, or "/" otherwise, so that the login cannot redirect the browser to another site.
+// The browsers strip the tabs and line breaks of the URLs and read backslashes as slashes, so "/\t/evil.example.com" would lead to
+// another site: the values with control characters or backslashes are refused, and the path is rebuilt from its parsed form.
+func localPath(path string) string {
+	if strings.ContainsRune(path, '\\') || strings.IndexFunc(path, unicode.IsControl) >= 0 {
+		return "/"
+	}
+	u, err := url.Parse(path)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil || !strings.HasPrefix(u.Path, "/") || strings.HasPrefix(u.Path, "//") ||
+		strings.ContainsRune(u.Path, '\\') || strings.IndexFunc(u.Path, unicode.IsControl) >= 0 {
+		return "/"
+	}
+	if u.RawQuery == "" {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + u.RawQuery
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// mockSessionRepository keeps the sessions and the login attempts by ID
+type mockSessionRepository struct {
+	sessions map[string]*entity.Session
+	logins   map[string]*entity.LoginAttempt
+}
+
+func (m mockSessionRepository) Create(session *entity.Session) error {
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m mockSessionRepository) FindByID(id string) (*entity.Session, error) {
+	if session, ok := m.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "session not found")
+}
+
+func (m mockSessionRepository) DeleteByID(id string) error {
+	if _, ok := m.sessions[id]; !ok {
+		return errs.New(errs.ErrNotFound, "session not found")
+	}
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m mockSessionRepository) CreateLogin(attempt *entity.LoginAttempt) error {
+	m.logins[attempt.ID] = attempt
+	return nil
+}
+
+func (m mockSessionRepository) TakeLogin(id string) (*entity.LoginAttempt, error) {
+	attempt, ok := m.logins[id]
+	if !ok {
+		return nil, errs.New(errs.ErrNotFound, "login attempt not found")
+	}
+	delete(m.logins, id)
+	return attempt, nil
+}
+
+func (m mockSessionRepository) PurgeExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// mockProvider hands out the code "code" for the challenge of the last login, and the ID token "id-token" carrying its nonce and the claims
+type mockProvider struct {
+	claims principal.Claims
+	login  *url.Values
+}
+
+func (m mockProvider) AuthCodeURL(state string, nonce string, codeChallenge string) string {
+	*m.login = url.Values{"state": {state}, "nonce": {nonce}, "code_challenge": {codeChallenge}}
+	return "https://idp.example.com/authorize?" + m.login.Encode()
+}
+
+func (m mockProvider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	challenge := sha256.Sum256([]byte(codeVerifier))
+	if code != "code" || base64.RawURLEncoding.EncodeToString(challenge[:]) != m.login.Get("code_challenge") {
+		return "", errs.New(errs.ErrUnauthenticated, "invalid_grant")
+	}
+	return "id-token", nil
+}
+
+func (m mockProvider) VerifyIDToken(ctx context.Context, idToken string, nonce string) (principal.Claims, error) {
+	if idToken != "id-token" || nonce != m.login.Get("nonce") {
+		return nil, errs.New(errs.ErrUnauthenticated, "invalid ID token")
+	}
+	return m.claims, nil
+}
+
+func (m mockProvider) LogoutURL(idToken string) string {
+	return "https://idp.example.com/logout?id_token_hint=" + idToken
+}
+
+func TestSessionService(t1 *testing.T) {
+	repo := mockSessionRepository{sessions: make(map[string]*entity.Session), logins: make(map[string]*entity.LoginAttempt)}
+	provider := mockProvider{login: &url.Values{}}
+	s := NewSessionService(repo, provider, ClaimMapping{UsernameClaim: "sub", TenantClaim: "tenant", RolesClaim: "roles", DefaultTenant: "default"}, time.Hour)
+
+	tests := []struct {
+		name     string
+		claims   principal.Claims
+		returnTo string
+		want     principal.Principal
+		wantPath string
+	}{
+		{name: "should map the claims to the principal", claims: principal.Claims{"sub": "42", "preferred_username": "alice", "tenant": testTenant, "roles": []interface{}{"offline_access", "editor", "viewer"}},
+			returnTo: "/tasks?status=new", want: principal.Principal{Subject: "oidc:42", Tenant: testTenant, Role: "editor"}, wantPath: "/tasks?status=new"},
+		{name: "should make the admins administrators of the users", claims: principal.Claims{"sub": "42", "roles": "Admin"},
+			returnTo: "/", want: principal.Principal{Subject: "oidc:42", Tenant: "default", Admin: true, Role: "admin"}, wantPath: "/"},
+		{name: "should not redirect to another site", claims: principal.Claims{"sub": "42"},
+			returnTo: "//evil.example.com", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+		{name: "should not redirect to another site after a backslash", claims: principal.Claims{"sub": "42"},
+			returnTo: "/\\evil.example.com", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+		{name: "should not redirect to another site after a tab stripped by the browser", claims: principal.Claims{"sub": "42"},
+			returnTo: "/\t/evil.example.com", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+		{name: "should not redirect to another site after a line break stripped by the browser", claims: principal.Claims{"sub": "42"},
+			returnTo: "/\n/evil.example.com", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+		{name: "should not redirect to an absolute URL", claims: principal.Claims{"sub": "42"},
+			returnTo: "https://evil.example.com/", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+		{name: "should not redirect to an encoded tab", claims: principal.Claims{"sub": "42"},
+			returnTo: "/%09/evil.example.com", want: principal.Principal{Subject: "oidc:42", Tenant: "default"}, wantPath: "/"},
+	}
+	for _, tt := range tests {
+		t1.Run(tt.name, func(t1 *testing.T) {
+			provider.claims = tt.claims
+			s.Provider = provider
+			state, authURL, err := s.BeginLogin(context.Background(), tt.returnTo)
+			if err != nil {
+				t1.Fatalf("BeginLogin() error = %v", err)
+			}
+			if provider.login.Get("state") != state || authURL == "" {
+				t1.Fatalf("BeginLogin() got = %s, want the URL of the provider with the state", authURL)
+			}
+			issued, err := s.CompleteLogin(context.Background(), state, "code")
+			if err != nil {
+				t1.Fatalf("CompleteLogin() error = %v", err)
+			}
+			if issued.ReturnTo != tt.wantPath {
+				t1.Errorf("CompleteLogin() return to = %s, want %s", issued.ReturnTo, tt.wantPath)
+			}
+			got, err := s.Authenticate(context.Background(), issued.Token)
+			if err != nil {
+				t1.Fatalf("Authenticate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t1.Errorf("Authenticate() got = %v, want %v", got, tt.want)
+			}
+			// the state is used once
+			if _, err = s.CompleteLogin(context.Background(), state, "code"); !errors.Is(err, errs.ErrUnauthenticated) {
+				t1.Errorf("CompleteLogin() error = %v, want %v when replayed", err, errs.ErrUnauthenticated)
+			}
+		})
+	}
+
+	// a code which does not match the PKCE challenge of the login is refused, and the login cannot be retried
+	state, _, err := s.BeginLogin(context.Background(), "/")
+	if err != nil {
+		t1.Fatal(err)
+	}
+	if _, err = s.CompleteLogin(context.Background(), state, "stolen"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("CompleteLogin() error = %v, want %v", err, errs.ErrUnauthenticated)
+	}
+
+	// an expired login is refused
+	state, _, err = s.BeginLogin(context.Background(), "/")
+	if err != nil {
+		t1.Fatal(err)
+	}
+	repo.logins[hashSecret(state)].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err = s.CompleteLogin(context.Background(), state, "code"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("CompleteLogin() error = %v, want %v for an expired login", err, errs.ErrUnauthenticated)
+	}
+
+	// the session is closed by the logout, and is refused once expired
+	state, _, _ = s.BeginLogin(context.Background(), "/")
+	issued, err := s.CompleteLogin(context.Background(), state, "code")
+	if err != nil {
+		t1.Fatal(err)
+	}
+	logoutURL, err := s.Logout(context.Background(), issued.Token)
+	if err != nil || logoutURL != "https://idp.example.com/logout?id_token_hint=id-token" {
+		t1.Errorf("Logout() = %s, %v, want the logout URL of the provider", logoutURL, err)
+	}
+	if _, err = s.Authenticate(context.Background(), issued.Token); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("Authenticate() error = %v, want %v after the logout", err, errs.ErrUnauthenticated)
+	}
+	state, _, _ = s.BeginLogin(context.Background(), "/")
+	if issued, err = s.CompleteLogin(context.Background(), state, "code"); err != nil {
+		t1.Fatal(err)
+	}
+	repo.sessions[hashSecret(issued.Token)].ExpiresAt = time.Now().Add(-time.Second)
+	if _, err = s.Authenticate(context.Background(), issued.Token); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("Authenticate() error = %v, want %v for an expired session", err, errs.ErrUnauthenticated)
+	}
+
+	// the ID tokens without the username claim are refused, rather than naming the caller by another claim
+	provider.claims = principal.Claims{"preferred_username": "alice"}
+	s.Provider = provider
+	state, _, _ = s.BeginLogin(context.Background(), "/")
+	if _, err = s.CompleteLogin(context.Background(), state, "code"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("CompleteLogin() error = %v, want %v without the username claim", err, errs.ErrUnauthenticated)
+	}
+}
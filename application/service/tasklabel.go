@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"log"
+)
+
+// AddLabel attaches the label of the catalogue to the task, attaching a label that is already attached does nothing.
+// errs.ErrNotFound is returned if there is no such task or label.
+func (t *TaskService) AddLabel(ctx context.Context, id string, labelID string) error {
+	log.Printf("attaching label with id '%s' to task with id '%s' ...", labelID, id)
+	return t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if _, err := repo.FindByID(id); err != nil {
+			return err
+		}
+		attached, err := repo.AttachLabel(id, labelID)
+		if err != nil || !attached {
+			return err
+		}
+		changes := []entity.FieldChange{{Field: "labels", New: labelID}}
+		return repo.AppendEvent(newEvent(ctx, id, entity.LabelAdded, changes))
+	})
+}
+
+// RemoveLabel detaches the label from the task, errs.ErrNotFound is returned if it is not attached to it
+func (t *TaskService) RemoveLabel(ctx context.Context, id string, labelID string) error {
+	log.Printf("detaching label with id '%s' from task with id '%s' ...", labelID, id)
+	return t.repo(ctx).Transaction(func(repo interfaces.ITaskRepository) error {
+		if err := repo.DetachLabel(id, labelID); err != nil {
+			return err
+		}
+		changes := []entity.FieldChange{{Field: "labels", Old: labelID}}
+		return repo.AppendEvent(newEvent(ctx, id, entity.LabelRemoved, changes))
+	})
+}
+
+// withLabels sets the labels attached to the tasks, they are read in a single query whatever the number of tasks
+func (t *TaskService) withLabels(ctx context.Context, tasks ...*entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	labels, err := t.repo(ctx).FindLabels(ids)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		task.Labels = labels[task.ID]
+	}
+	return nil
+}
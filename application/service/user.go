@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"log"
+)
+
+// UserService manages the user accounts, it authenticates the callers and lets the administrators manage the users of their tenant
+type UserService struct {
+	UserRepository interfaces.IUserRepository
+	Cost           int    // bcrypt cost of the password hashes, the hashes already stored keep the cost they were created with
+	dummyHash      []byte // compared when the username is unknown, so that the response time does not tell which usernames exist
+}
+
+// NewUserService is the constructor of a UserService with the repository dependency injected, the passwords are hashed with the given bcrypt cost
+func NewUserService(repo interfaces.IUserRepository, cost int) *UserService {
+	if repo == nil {
+		log.Fatalf("nil repo provided")
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("not a password"), cost)
+	if err != nil {
+		log.Fatalf("invalid bcrypt cost %d: %v", cost, err)
+	}
+	return &UserService{UserRepository: repo, Cost: cost, dummyHash: dummyHash}
+}
+
+// Authenticate checks the password of the user and returns the principal it acts as. The same errs.ErrUnauthenticated is returned
+// whether the username is unknown, the password is wrong or the user is disabled, so that the callers cannot tell which is the case.
+func (u *UserService) Authenticate(ctx context.Context, username string, password string) (principal.Principal, error) {
+	denied := errs.New(errs.ErrUnauthenticated, "wrong username or password")
+	normalized, err := validation.ValidateUsername(username)
+	if err != nil {
+		_ = bcrypt.CompareHashAndPassword(u.dummyHash, []byte(password))
+		return principal.Principal{}, denied
+	}
+	user, err := u.UserRepository.AllTenants().FindByUsername(normalized)
+	if errors.Is(err, errs.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(u.dummyHash, []byte(password))
+		return principal.Principal{}, denied
+	}
+	if err != nil {
+		return principal.Principal{}, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil || user.Disabled {
+		return principal.Principal{}, denied
+	}
+	return principal.Principal{Subject: user.Username, Tenant: user.TenantID, Admin: user.Admin}, nil
+}
+
+// Create creates a user in the tenant of the administrator, errs.ErrConflict is returned if its username is already used
+func (u *UserService) Create(ctx context.Context, req *entity.NewUser) (*entity.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	req, err := validation.ValidateNewUser(req)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), u.Cost)
+	if err != nil {
+		return nil, err
+	}
+	user := entity.User{ID: uuid.NewString(), PasswordHash: string(hash), UserDescription: req.UserDescription}
+	log.Printf("creating user with ID '%s' ...", user.ID)
+	if err = u.repo(ctx).Create(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// List returns the users of the tenant of the administrator ordered by username
+func (u *UserService) List(ctx context.Context) ([]*entity.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	log.Printf("listing users ...")
+	return u.repo(ctx).FindAll()
+}
+
+func (u *UserService) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	log.Printf("getting user with id '%s' ...", id)
+	return u.repo(ctx).FindByID(id)
+}
+
+// SetDisabled disables or enables the user, a disabled user cannot authenticate anymore.
+// Administrators cannot disable themselves, which prevents a tenant from losing its last administrator by mistake.
+func (u *UserService) SetDisabled(ctx context.Context, id string, disabled bool) (*entity.User, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	log.Printf("setting disabled of user with id '%s' to %t ...", id, disabled)
+	repo := u.repo(ctx)
+	user, err := repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if disabled && user.Username == principal.Subject(ctx) {
+		return nil, errs.New(errs.ErrConflict, "administrators cannot disable their own account")
+	}
+	if err = repo.Update(map[string]interface{}{"disabled": disabled}, id); err != nil {
+		return nil, err
+	}
+	return repo.FindByID(id)
+}
+
+// ResetPassword replaces the password of the user, the previous one is not accepted anymore
+func (u *UserService) ResetPassword(ctx context.Context, id string, req *entity.PasswordReset) error {
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	log.Printf("resetting password of user with id '%s' ...", id)
+	if err := validation.ValidatePassword(req.Password); err != nil {
+		return errs.Validation([]errs.Violation{{Field: "password", Message: err.Error()}})
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), u.Cost)
+	if err != nil {
+		return err
+	}
+	return u.repo(ctx).Update(map[string]interface{}{"password_hash": string(hash)}, id)
+}
+
+// Bootstrap creates an administrator in the tenant if there is no user with this username yet, so that a new deployment has a first
+// administrator to create the other users. An existing user is left as it is, its password is not reset.
+func (u *UserService) Bootstrap(ctx context.Context, username string, password string, tenant string) error {
+	req, err := validation.ValidateNewUser(&entity.NewUser{UserDescription: entity.UserDescription{Username: username, Admin: true}, Password: password})
+	if err != nil {
+		return err
+	}
+	if _, err = u.UserRepository.AllTenants().FindByUsername(req.Username); err == nil || !errors.Is(err, errs.ErrNotFound) {
+		return err
+	}
+	log.Printf("creating administrator '%s' of tenant '%s' ...", req.Username, tenant)
+	_, err = u.Create(principal.NewContext(ctx, principal.Principal{Subject: req.Username, Tenant: tenant, Admin: true}), req)
+	return err
+}
+
+// repo returns the repository bound to the tenant of the caller, it only sees the users of this tenant
+func (u *UserService) repo(ctx context.Context) interfaces.IUserRepository {
+	return u.UserRepository.WithTenant(principal.Tenant(ctx))
+}
+
+// requireAdmin returns errs.ErrForbidden unless the caller is an administrator
+func requireAdmin(ctx context.Context) error {
+	if p, ok := principal.FromContext(ctx); !ok || !p.Admin {
+		return errs.New(errs.ErrForbidden, "only the administrators can manage the users")
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"golang.org/x/crypto/bcrypt"
+	"reflect"
+	"testing"
+)
+
+// mockUserRepository keeps the users of all the tenants, tenant is empty when the repository sees all of them
+type mockUserRepository struct {
+	users  map[string]*entity.User
+	tenant string
+}
+
+func (m mockUserRepository) visible(user *entity.User) bool {
+	return m.tenant == "" || user.TenantID == m.tenant
+}
+
+func (m mockUserRepository) Create(user *entity.User) error {
+	for _, existing := range m.users {
+		if existing.Username == user.Username {
+			return errs.New(errs.ErrConflict, "username already used")
+		}
+	}
+	if m.tenant != "" {
+		user.TenantID = m.tenant
+	}
+	m.users[user.ID] = user
+	return nil
+}
+
+func (m mockUserRepository) FindAll() ([]*entity.User, error) {
+	var users []*entity.User
+	for _, user := range m.users {
+		if m.visible(user) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m mockUserRepository) FindByID(id string) (*entity.User, error) {
+	if user, ok := m.users[id]; ok && m.visible(user) {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "user not found")
+}
+
+func (m mockUserRepository) FindByUsername(username string) (*entity.User, error) {
+	for _, user := range m.users {
+		if user.Username == username && m.visible(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errs.New(errs.ErrNotFound, "user not found")
+}
+
+func (m mockUserRepository) Update(fields map[string]interface{}, id string) error {
+	user, ok := m.users[id]
+	if !ok || !m.visible(user) {
+		return errs.New(errs.ErrNotFound, "user not found")
+	}
+	if disabled, ok := fields["disabled"].(bool); ok {
+		user.Disabled = disabled
+	}
+	if hash, ok := fields["password_hash"].(string); ok {
+		user.PasswordHash = hash
+	}
+	return nil
+}
+
+func (m mockUserRepository) WithTenant(tenant string) interfaces.IUserRepository {
+	return mockUserRepository{users: m.users, tenant: tenant}
+}
+
+func (m mockUserRepository) AllTenants() interfaces.IUserRepository {
+	return mockUserRepository{users: m.users}
+}
+
+func TestUserService(t1 *testing.T) {
+	u := NewUserService(mockUserRepository{users: make(map[string]*entity.User)}, bcrypt.MinCost)
+	if err := u.Bootstrap(context.Background(), "Admin", "bootstrap-password", testTenant); err != nil {
+		t1.Fatalf("Bootstrap() error = %v", err)
+	}
+	// bootstrapping again keeps the existing administrator and its password
+	if err := u.Bootstrap(context.Background(), "admin", "another-password", testTenant); err != nil {
+		t1.Fatalf("Bootstrap() error = %v", err)
+	}
+	admin, err := u.Authenticate(context.Background(), "admin", "bootstrap-password")
+	if err != nil {
+		t1.Fatalf("Authenticate() error = %v", err)
+	}
+	if want := (principal.Principal{Subject: "admin", Tenant: testTenant, Admin: true}); !reflect.DeepEqual(admin, want) {
+		t1.Errorf("Authenticate() got = %v, want %v", admin, want)
+	}
+	adminCtx := principal.NewContext(context.Background(), admin)
+
+	user, err := u.Create(adminCtx, &entity.NewUser{UserDescription: entity.UserDescription{Username: " Alice "}, Password: "alice-password"})
+	if err != nil {
+		t1.Fatalf("Create() error = %v", err)
+	}
+	if user.ID == "" || user.Username != "alice" || user.PasswordHash == "alice-password" {
+		t1.Errorf("Create() got = %v, want alice with a hashed password", user)
+	}
+	if _, err = u.Create(adminCtx, &entity.NewUser{UserDescription: entity.UserDescription{Username: "alice"}, Password: "alice-password"}); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("Create() error = %v, want %v for a username already used", err, errs.ErrConflict)
+	}
+	if _, err = u.Create(adminCtx, &entity.NewUser{UserDescription: entity.UserDescription{Username: "bob"}, Password: "short"}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("Create() error = %v, want %v for a short password", err, errs.ErrValidation)
+	}
+
+	// the users are not allowed to manage the users, nor the administrators of other tenants
+	alice, err := u.Authenticate(context.Background(), "ALICE", "alice-password")
+	if err != nil {
+		t1.Fatalf("Authenticate() error = %v", err)
+	}
+	if _, err = u.List(principal.NewContext(context.Background(), alice)); !errors.Is(err, errs.ErrForbidden) {
+		t1.Errorf("List() error = %v, want %v for a user", err, errs.ErrForbidden)
+	}
+	otherAdminCtx := principal.NewContext(context.Background(), principal.Principal{Subject: "root", Tenant: "other", Admin: true})
+	if _, err = u.GetByID(otherAdminCtx, user.ID); !errors.Is(err, errs.ErrNotFound) {
+		t1.Errorf("GetByID() error = %v, want %v in another tenant", err, errs.ErrNotFound)
+	}
+	if users, err := u.List(adminCtx); err != nil || len(users) != 2 {
+		t1.Errorf("List() = %v, %v, want the administrator and alice", users, err)
+	}
+
+	// a wrong password and an unknown username are refused the same way
+	for _, credentials := range [][2]string{{"alice", "wrong-password"}, {"nobody", "alice-password"}, {"", ""}} {
+		if _, err = u.Authenticate(context.Background(), credentials[0], credentials[1]); !errors.Is(err, errs.ErrUnauthenticated) {
+			t1.Errorf("Authenticate(%q) error = %v, want %v", credentials[0], err, errs.ErrUnauthenticated)
+		}
+	}
+
+	// a disabled user cannot authenticate until it is enabled again
+	if user, err = u.SetDisabled(adminCtx, user.ID, true); err != nil || !user.Disabled {
+		t1.Fatalf("SetDisabled() = %v, %v, want alice disabled", user, err)
+	}
+	if _, err = u.Authenticate(context.Background(), "alice", "alice-password"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("Authenticate() error = %v, want %v for a disabled user", err, errs.ErrUnauthenticated)
+	}
+	if _, err = u.SetDisabled(adminCtx, user.ID, false); err != nil {
+		t1.Fatalf("SetDisabled() error = %v", err)
+	}
+	adminUser, err := u.repo(adminCtx).FindByUsername("admin")
+	if err != nil {
+		t1.Fatal(err)
+	}
+	if _, err = u.SetDisabled(adminCtx, adminUser.ID, true); !errors.Is(err, errs.ErrConflict) {
+		t1.Errorf("SetDisabled() error = %v, want %v for the own account", err, errs.ErrConflict)
+	}
+
+	// the previous password is not accepted anymore once reset
+	if err = u.ResetPassword(adminCtx, user.ID, &entity.PasswordReset{Password: "new-alice-password"}); err != nil {
+		t1.Fatalf("ResetPassword() error = %v", err)
+	}
+	if _, err = u.Authenticate(context.Background(), "alice", "alice-password"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t1.Errorf("Authenticate() error = %v, want %v with the previous password", err, errs.ErrUnauthenticated)
+	}
+	if _, err = u.Authenticate(context.Background(), "alice", "new-alice-password"); err != nil {
+		t1.Errorf("Authenticate() error = %v with the new password", err)
+	}
+	if err = u.ResetPassword(adminCtx, user.ID, &entity.PasswordReset{Password: "short"}); !errors.Is(err, errs.ErrValidation) {
+		t1.Errorf("ResetPassword() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
@@ -1,18 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/adapters/persistence/repository"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
 	"github.com/FirasYousfi/tasks-web-servcie/application/service"
 	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/hierarchy"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/validation"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/workflow"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/blob"
 	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/database"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/oidc"
 	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/router"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/scheduler"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/token"
 	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"time"
+	_ "time/tzdata" // the time zones of the recurring tasks are known even where the system has no time zone database
 )
 
+// loginPurgeInterval is how often the expired sessions and logins are removed
+const loginPurgeInterval = time.Hour
+
 // @title           Tasks Service API
 // @version         1.0
 // @description     This is the documentation for the tasks-service-api.
@@ -35,9 +52,158 @@ func main() {
 }
 
 // SetupHandlers here is where all the dependency injection stuff happens.
+// The background jobs are started here too, since they share the service with the handlers.
 func SetupHandlers(db *gorm.DB) *mux.Router {
+	// registered before any repository is used, so that no statement escapes the isolation of the tenants
+	if err := repository.RegisterTenantScope(db); err != nil {
+		log.Fatalf("failed to register the tenant scope: %v", err)
+	}
 	repo := repository.NewTaskRepository(db)
-	taskService := service.NewTaskService(repo)
-	r := router.SetupRoutes(taskService)
+	statusWorkflow, err := workflow.Parse(config.Config.Workflow.Transitions)
+	if err != nil {
+		log.Fatalf("invalid WORKFLOW_TRANSITIONS: %v", err)
+	}
+	deletePolicy, err := hierarchy.ParsePolicy(config.Config.Hierarchy.DeletePolicy)
+	if err != nil {
+		log.Fatalf("invalid SUBTASK_DELETE_POLICY: %v", err)
+	}
+	blobStore := newBlobStore(config.Config.Attachment)
+	taskService := service.NewTaskService(repo, statusWorkflow, deletePolicy, blobStore)
+	startPurge(taskService, config.Config.Trash.Retention, config.Config.Trash.PurgeInterval)
+	startRecurrence(taskService, config.Config.Recurrence.Interval)
+	userService := service.NewUserService(repository.NewUserRepository(db), bcrypt.DefaultCost)
+	bootstrapAdmin(userService, config.Config.Auth)
+	defaultRole, err := validation.ValidateRole(entity.Role(config.Config.RBAC.DefaultRole))
+	if err != nil {
+		log.Fatalf("invalid RBAC_DEFAULT_ROLE: %v", err)
+	}
+	roleService := service.NewRoleService(repository.NewRoleRepository(db), repository.NewProjectRepository(db), defaultRole)
+	// the handlers go through the role checks, the background jobs use the task service directly since they do not act for a caller
+	authorizedTasks := service.NewAuthorizedTaskService(taskService, roleService)
+	labelService := service.NewAuthorizedLabelService(service.NewLabelService(repository.NewLabelRepository(db)), roleService)
+	projectService := service.NewAuthorizedProjectService(service.NewProjectService(repository.NewProjectRepository(db)), roleService)
+	commentService := service.NewCommentService(repository.NewCommentRepository(db), authorizedTasks, roleService)
+	checklistService := service.NewChecklistService(repository.NewChecklistRepository(db), authorizedTasks, roleService)
+	attachmentService := service.NewAttachmentService(repository.NewAttachmentRepository(db), blobStore, authorizedTasks, roleService, config.Config.Attachment.MaxSize)
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(db))
+	var sessions interfaces.ISessionService
+	if sessionService := newSessionService(config.Config.OIDC, config.Config.Auth.Tenant, db); sessionService != nil {
+		startSessionPurge(sessionService, loginPurgeInterval)
+		sessions = sessionService
+	}
+	r := router.SetupRoutes(authorizedTasks, labelService, commentService, attachmentService, checklistService, projectService, userService, roleService, apiKeyService, sessions, tokenVerifier(config.Config.JWT, config.Config.Auth.Tenant))
 	return r
 }
+
+// bootstrapAdmin creates the first administrator from APP_USERNAME and APP_PASSWORD, so that a new deployment can create its users.
+// It is skipped when they are not set, and an existing user keeps its password, which is then changed through the API.
+func bootstrapAdmin(userService *service.UserService, auth config.AuthConfig) {
+	if auth.Username == "" || auth.Password == "" {
+		log.Printf("APP_USERNAME or APP_PASSWORD is not set, no administrator is bootstrapped")
+		return
+	}
+	if err := userService.Bootstrap(context.Background(), auth.Username, auth.Password, auth.Tenant); err != nil {
+		log.Fatalf("failed to bootstrap the administrator: %v", err)
+	}
+}
+
+// newBlobStore returns the store of the content of the attachments selected by ATTACHMENT_STORE
+func newBlobStore(conf config.AttachmentConfig) interfaces.BlobStore {
+	switch conf.Store {
+	case "local":
+		store, err := blob.NewLocalStore(conf.Dir)
+		if err != nil {
+			log.Fatalf("invalid ATTACHMENT_DIR: %v", err)
+		}
+		return store
+	case "s3":
+		store, err := blob.NewS3Store(conf, &http.Client{Timeout: 5 * time.Minute})
+		if err != nil {
+			log.Fatalf("invalid S3 config of the attachments: %v", err)
+		}
+		return store
+	}
+	log.Fatalf("invalid ATTACHMENT_STORE '%s', should be local or s3", conf.Store)
+	return nil
+}
+
+// tokenVerifier returns the verifier of the bearer tokens, or nil when no JWT key is configured and the bearer tokens are refused.
+// The tokens without tenant claim belong to the tenant of the first administrator.
+func tokenVerifier(conf config.JWTConfig, defaultTenant string) router.TokenVerifier {
+	if !token.Enabled(conf) {
+		log.Printf("no JWT key is set, bearer tokens are refused")
+		return nil
+	}
+	verifier, err := token.NewVerifier(conf, defaultTenant)
+	if err != nil {
+		log.Fatalf("invalid JWT config: %v", err)
+	}
+	return verifier
+}
+
+// newSessionService returns the service logging the browser clients in with the OpenID provider, or nil when no issuer is configured.
+// The callers without tenant claim belong to the tenant of the first administrator.
+func newSessionService(conf config.OIDCConfig, defaultTenant string, db *gorm.DB) *service.SessionService {
+	if !oidc.Enabled(conf) {
+		log.Printf("OIDC_ISSUER is not set, the login with an OpenID provider is disabled")
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	provider, err := oidc.Discover(ctx, conf, &http.Client{Timeout: 10 * time.Second})
+	if err != nil {
+		log.Fatalf("invalid OIDC config: %v", err)
+	}
+	claims := service.ClaimMapping{UsernameClaim: conf.UsernameClaim, TenantClaim: conf.TenantClaim, RolesClaim: conf.RolesClaim, DefaultTenant: defaultTenant}
+	return service.NewSessionService(repository.NewSessionRepository(db), provider, claims, conf.SessionTTL)
+}
+
+// startSessionPurge periodically removes the sessions and the logins that expired
+func startSessionPurge(sessionService *service.SessionService, interval time.Duration) {
+	go scheduler.Every(context.Background(), "session purge", interval, func(ctx context.Context) error {
+		purged, err := sessionService.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if purged > 0 {
+			log.Printf("purged %d expired sessions and logins", purged)
+		}
+		return nil
+	})
+}
+
+// startPurge periodically removes the tasks that have been in the trash for longer than the retention, a retention of 0 disables it
+func startPurge(taskService *service.TaskService, retention, interval time.Duration) {
+	if retention == 0 || interval == 0 {
+		log.Printf("trash purge is disabled, deleted tasks are kept until they are restored")
+		return
+	}
+	go scheduler.Every(context.Background(), "trash purge", interval, func(ctx context.Context) error {
+		purged, err := taskService.PurgeTrash(ctx, retention)
+		if err != nil {
+			return err
+		}
+		log.Printf("purged %d tasks from the trash", purged)
+		return nil
+	})
+}
+
+// startRecurrence periodically spawns the next occurrences of the recurring tasks that are due, an interval of 0 disables it.
+// Closing an occurrence spawns the next one anyway, the scheduler only catches up with the ones left open past their due time.
+func startRecurrence(taskService *service.TaskService, interval time.Duration) {
+	if interval == 0 {
+		log.Printf("recurrence scheduler is disabled, occurrences are only spawned when closed")
+		return
+	}
+	ctx := principal.NewContext(context.Background(), principal.Principal{Subject: "scheduler"})
+	go scheduler.Every(ctx, "recurrence", interval, func(ctx context.Context) error {
+		spawned, err := taskService.SpawnOccurrences(ctx)
+		if err != nil {
+			return err
+		}
+		if spawned > 0 {
+			log.Printf("spawned %d occurrences of recurring tasks", spawned)
+		}
+		return nil
+	})
+}
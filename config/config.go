@@ -4,14 +4,25 @@ package config
 import (
 	"github.com/rs/zerolog/log"
 	"os"
+	"strconv"
+	"time"
 )
 
 var Config Configuration
 
 type Configuration struct {
-	Server ServerConfig
-	DB     DbConfig
-	Auth   AuthConfig
+	Server     ServerConfig
+	DB         DbConfig
+	Auth       AuthConfig
+	JWT        JWTConfig
+	OIDC       OIDCConfig
+	RBAC       RBACConfig
+	Pagination PaginationConfig
+	Trash      TrashConfig
+	Workflow   WorkflowConfig
+	Recurrence RecurrenceConfig
+	Hierarchy  HierarchyConfig
+	Attachment AttachmentConfig
 }
 
 type ServerConfig struct {
@@ -26,8 +37,73 @@ type DbConfig struct {
 }
 
 type AuthConfig struct {
-	Username string
-	Password string
+	Username string // username of the first administrator, created at startup if it does not exist yet
+	Password string // initial password of the first administrator, it is not applied to an existing user
+	Tenant   string // tenant of the first administrator and of the users it creates
+}
+
+// JWTConfig configures the bearer token authentication, it is disabled when no key is set
+type JWTConfig struct {
+	Secret        string        // key shared with the issuer verifying the HS256 tokens
+	PublicKeyFile string        // PEM file of the RSA or P-256 public key verifying the RS256 or ES256 tokens
+	JWKSFile      string        // local JWKS file of the keys verifying the tokens, selected by the kid of the tokens
+	Issuer        string        // expected iss claim of the tokens
+	Audience      string        // expected aud claim of the tokens, i.e. the identifier of this service at the issuer
+	TenantClaim   string        // claim holding the tenant of the caller, the tokens without it belong to the tenant of the administrator
+	Leeway        time.Duration // clock skew tolerated when checking the expiry of the tokens
+}
+
+// OIDCConfig configures the login of the browser clients with an OpenID Connect provider, it is disabled when no issuer is set
+type OIDCConfig struct {
+	Issuer                string        // URL of the provider, its endpoints are discovered from <issuer>/.well-known/openid-configuration
+	ClientID              string        // ID of the service at the provider
+	ClientSecret          string        // secret of the service at the provider, empty for a public client which only relies on PKCE
+	RedirectURL           string        // URL of the callback endpoint of the service, as registered at the provider
+	PostLogoutRedirectURL string        // where the browser goes after logging out
+	Scopes                string        // scopes requested at the login, separated by spaces, openid is always requested
+	UsernameClaim         string        // claim of the ID token naming the caller, it must be unique and immutable (default sub)
+	TenantClaim           string        // claim of the ID token holding the tenant of the caller, the callers without it belong to the tenant of the administrator
+	RolesClaim            string        // claim of the ID token listing the roles of the caller on all the projects
+	SessionTTL            time.Duration // how long a session lasts after the login
+}
+
+type RBACConfig struct {
+	DefaultRole string // role of the callers without assignment: viewer, editor or admin
+}
+
+type PaginationConfig struct {
+	CursorSecret string // key signing the list cursors, it must be shared by all the replicas
+}
+
+type TrashConfig struct {
+	Retention     time.Duration // how long deleted tasks stay in the trash before being purged, 0 keeps them forever
+	PurgeInterval time.Duration // how often the trash is checked for tasks to purge
+}
+
+type WorkflowConfig struct {
+	Transitions string // allowed status transitions, e.g. "new:active,closed;active:closed", the default workflow is used if empty
+}
+
+type RecurrenceConfig struct {
+	Interval time.Duration // how often the recurring tasks are checked for occurrences to spawn, 0 only spawns them when closed
+}
+
+type HierarchyConfig struct {
+	DeletePolicy string // what deleting a task does to its subtasks: cascade, orphan or refuse, refuse if empty
+}
+
+// AttachmentConfig configures the storage of the files attached to the tasks, their content goes to the local directory or to the
+// bucket of an S3-compatible service
+type AttachmentConfig struct {
+	MaxSize     int64  // maximum size of an attached file in bytes
+	Store       string // where the content of the files is stored: local or s3
+	Dir         string // directory of the local store, created if it does not exist
+	S3Endpoint  string // URL of the S3-compatible service, e.g. https://s3.eu-west-1.amazonaws.com or http://minio:9000
+	S3Region    string // region the requests are signed for
+	S3Bucket    string // bucket holding the files, it must exist
+	S3AccessKey string // access key ID of the service account
+	S3SecretKey string // secret access key of the service account
+	S3PathStyle bool   // whether the bucket is named in the path rather than in the host name, as most S3-compatible services expect
 }
 
 func BuildConfig() {
@@ -43,6 +119,58 @@ func BuildConfig() {
 		Auth: AuthConfig{
 			Username: os.Getenv("APP_USERNAME"),
 			Password: os.Getenv("APP_PASSWORD"),
+			Tenant:   GetEnv("APP_TENANT", "default"),
+		},
+		JWT: JWTConfig{
+			Secret:        os.Getenv("JWT_SECRET"),
+			PublicKeyFile: os.Getenv("JWT_PUBLIC_KEY_FILE"),
+			JWKSFile:      os.Getenv("JWT_JWKS_FILE"),
+			Issuer:        os.Getenv("JWT_ISSUER"),
+			Audience:      os.Getenv("JWT_AUDIENCE"),
+			TenantClaim:   GetEnv("JWT_TENANT_CLAIM", "tenant"),
+			Leeway:        GetDurationEnv("JWT_LEEWAY", time.Minute),
+		},
+		OIDC: OIDCConfig{
+			Issuer:                os.Getenv("OIDC_ISSUER"),
+			ClientID:              os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret:          os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:           os.Getenv("OIDC_REDIRECT_URL"),
+			PostLogoutRedirectURL: GetEnv("OIDC_POST_LOGOUT_REDIRECT_URL", "/"),
+			Scopes:                GetEnv("OIDC_SCOPES", "openid profile email"),
+			UsernameClaim:         GetEnv("OIDC_USERNAME_CLAIM", "sub"),
+			TenantClaim:           GetEnv("OIDC_TENANT_CLAIM", "tenant"),
+			RolesClaim:            GetEnv("OIDC_ROLES_CLAIM", "roles"),
+			SessionTTL:            GetDurationEnv("OIDC_SESSION_TTL", 8*time.Hour),
+		},
+		RBAC: RBACConfig{
+			DefaultRole: GetEnv("RBAC_DEFAULT_ROLE", "viewer"),
+		},
+		Pagination: PaginationConfig{
+			CursorSecret: os.Getenv("CURSOR_SECRET"),
+		},
+		Trash: TrashConfig{
+			Retention:     GetDurationEnv("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: GetDurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Workflow: WorkflowConfig{
+			Transitions: os.Getenv("WORKFLOW_TRANSITIONS"),
+		},
+		Recurrence: RecurrenceConfig{
+			Interval: GetDurationEnv("RECURRENCE_INTERVAL", time.Minute),
+		},
+		Hierarchy: HierarchyConfig{
+			DeletePolicy: os.Getenv("SUBTASK_DELETE_POLICY"),
+		},
+		Attachment: AttachmentConfig{
+			MaxSize:     GetInt64Env("ATTACHMENT_MAX_SIZE", 10<<20),
+			Store:       GetEnv("ATTACHMENT_STORE", "local"),
+			Dir:         GetEnv("ATTACHMENT_DIR", "data/attachments"),
+			S3Endpoint:  os.Getenv("ATTACHMENT_S3_ENDPOINT"),
+			S3Region:    GetEnv("ATTACHMENT_S3_REGION", "us-east-1"),
+			S3Bucket:    os.Getenv("ATTACHMENT_S3_BUCKET"),
+			S3AccessKey: os.Getenv("ATTACHMENT_S3_ACCESS_KEY"),
+			S3SecretKey: os.Getenv("ATTACHMENT_S3_SECRET_KEY"),
+			S3PathStyle: GetBoolEnv("ATTACHMENT_S3_PATH_STYLE", true),
 		},
 	}
 	Config = conf
@@ -56,3 +184,48 @@ func GetEnv(key, fallback string) string {
 	log.Warn().Msgf("error occurred while trying to read %s env variable, it will be set to default value %s", key, fallback)
 	return fallback
 }
+
+// GetDurationEnv returns default value if the env variable is not found or is not a valid duration, e.g. 720h.
+func GetDurationEnv(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		log.Warn().Msgf("error occurred while trying to read %s env variable, it will be set to default value %s", key, fallback)
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		log.Warn().Msgf("%s env variable '%s' is not a valid duration, it will be set to default value %s", key, value, fallback)
+		return fallback
+	}
+	return duration
+}
+
+// GetInt64Env returns default value if the env variable is not found or is not a positive integer.
+func GetInt64Env(key string, fallback int64) int64 {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		log.Warn().Msgf("error occurred while trying to read %s env variable, it will be set to default value %d", key, fallback)
+		return fallback
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number <= 0 {
+		log.Warn().Msgf("%s env variable '%s' is not a positive integer, it will be set to default value %d", key, value, fallback)
+		return fallback
+	}
+	return number
+}
+
+// GetBoolEnv returns default value if the env variable is not found or is not a boolean, e.g. true or false.
+func GetBoolEnv(key string, fallback bool) bool {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		log.Warn().Msgf("error occurred while trying to read %s env variable, it will be set to default value %t", key, fallback)
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Warn().Msgf("%s env variable '%s' is not a boolean, it will be set to default value %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
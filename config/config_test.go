@@ -1,6 +1,9 @@
 package config
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestGetEnv(t *testing.T) {
 	t.Setenv("TEST", "testValue")
@@ -38,3 +41,78 @@ func TestGetEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDurationEnv(t *testing.T) {
+	t.Setenv("TEST_DURATION", "36h")
+	t.Setenv("TEST_INVALID_DURATION", "a month")
+	tests := []struct {
+		name string
+		key  string
+		want time.Duration
+	}{
+		{
+			name: "should return the parsed duration because env variable is set",
+			key:  "TEST_DURATION",
+			want: 36 * time.Hour,
+		},
+		{
+			name: "should return fallback because env variable is not a duration",
+			key:  "TEST_INVALID_DURATION",
+			want: time.Minute,
+		},
+		{
+			name: "should return fallback because env variable is not set",
+			key:  "NonSetVariable",
+			want: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetDurationEnv(tt.key, time.Minute); got != tt.want {
+				t.Errorf("GetDurationEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetInt64Env(t *testing.T) {
+	t.Setenv("TEST_SIZE", "1048576")
+	t.Setenv("TEST_NEGATIVE_SIZE", "-1")
+	tests := []struct {
+		name string
+		key  string
+		want int64
+	}{
+		{name: "should return the parsed integer because env variable is set", key: "TEST_SIZE", want: 1 << 20},
+		{name: "should return fallback because env variable is not positive", key: "TEST_NEGATIVE_SIZE", want: 10},
+		{name: "should return fallback because env variable is not set", key: "NonSetVariable", want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetInt64Env(tt.key, 10); got != tt.want {
+				t.Errorf("GetInt64Env() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetBoolEnv(t *testing.T) {
+	t.Setenv("TEST_BOOL", "false")
+	t.Setenv("TEST_INVALID_BOOL", "maybe")
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{name: "should return the parsed boolean because env variable is set", key: "TEST_BOOL", want: false},
+		{name: "should return fallback because env variable is not a boolean", key: "TEST_INVALID_BOOL", want: true},
+		{name: "should return fallback because env variable is not set", key: "NonSetVariable", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetBoolEnv(tt.key, true); got != tt.want {
+				t.Errorf("GetBoolEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package dependency orders the tasks according to the tasks blocking them
+package dependency
+
+import (
+	"container/heap"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+)
+
+// Plan returns the tasks in an order they can be worked on: every task comes after its blockers, and among the tasks
+// whose blockers are all done the one with the highest priority comes first, then the oldest one.
+// Only the dependencies between the given tasks are considered, a task blocked by a task that is not given is left out,
+// like the tasks of a cycle, since they cannot be reached by working on the given tasks.
+func Plan(tasks []*entity.Task, dependencies []*entity.TaskDependency) []*entity.PlannedTask {
+	byID := make(map[string]*entity.Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+	blockers := make(map[string][]string)   // open blockers of each task
+	dependents := make(map[string][]string) // tasks blocked by each task
+	for _, dependency := range dependencies {
+		blockers[dependency.TaskID] = append(blockers[dependency.TaskID], dependency.BlockerID)
+		dependents[dependency.BlockerID] = append(dependents[dependency.BlockerID], dependency.TaskID)
+	}
+
+	// Kahn's algorithm, taking the most important of the unblocked tasks first
+	remaining := make(map[string]int, len(blockers))
+	ready := &queue{}
+	for _, task := range tasks {
+		remaining[task.ID] = len(blockers[task.ID])
+		if remaining[task.ID] == 0 {
+			heap.Push(ready, task)
+		}
+	}
+	plan := make([]*entity.PlannedTask, 0, len(tasks))
+	for ready.Len() > 0 {
+		task := heap.Pop(ready).(*entity.Task)
+		blockedBy := blockers[task.ID]
+		if blockedBy == nil {
+			blockedBy = []string{}
+		}
+		plan = append(plan, &entity.PlannedTask{Task: task, BlockedBy: blockedBy})
+		for _, id := range dependents[task.ID] {
+			if _, ok := byID[id]; !ok {
+				continue
+			}
+			remaining[id]--
+			if remaining[id] == 0 {
+				heap.Push(ready, byID[id])
+			}
+		}
+	}
+	return plan
+}
+
+// queue is a priority queue of tasks implementing heap.Interface
+type queue []*entity.Task
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	if q[i].Priority != q[j].Priority {
+		return q[i].Priority > q[j].Priority
+	}
+	if !q[i].CreatedAt.Equal(q[j].CreatedAt) {
+		return q[i].CreatedAt.Before(q[j].CreatedAt)
+	}
+	return q[i].ID < q[j].ID
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*entity.Task)) }
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	task := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return task
+}
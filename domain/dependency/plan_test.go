@@ -0,0 +1,48 @@
+package dependency
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPlan(t *testing.T) {
+	created := time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC)
+	task := func(id string, priority int, age time.Duration) *entity.Task {
+		return &entity.Task{ID: id, CreatedAt: created.Add(-age), TaskDescription: entity.TaskDescription{Priority: priority}}
+	}
+	tasks := []*entity.Task{
+		task("design", 3, 0),
+		task("build", 9, 0),
+		task("docs", 5, time.Hour),
+		task("hotfix", 5, 2*time.Hour),
+		task("release", 10, 0),
+		task("cycle-a", 10, 0),
+		task("cycle-b", 10, 0),
+		task("external", 10, 0),
+	}
+	dependencies := []*entity.TaskDependency{
+		{TaskID: "build", BlockerID: "design"},
+		{TaskID: "release", BlockerID: "build"},
+		{TaskID: "release", BlockerID: "docs"},
+		{TaskID: "cycle-a", BlockerID: "cycle-b"},
+		{TaskID: "cycle-b", BlockerID: "cycle-a"},
+		{TaskID: "external", BlockerID: "not-given"},
+	}
+
+	var got []string
+	blockedBy := make(map[string][]string)
+	for _, planned := range Plan(tasks, dependencies) {
+		got = append(got, planned.Task.ID)
+		blockedBy[planned.Task.ID] = planned.BlockedBy
+	}
+	// the older of the tasks with the same priority comes first, and the blocked ones only after all their blockers
+	want := []string{"hotfix", "docs", "design", "build", "release"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() got = %v, want %v", got, want)
+	}
+	if len(blockedBy["hotfix"]) != 0 || !reflect.DeepEqual(blockedBy["release"], []string{"build", "docs"}) {
+		t.Errorf("Plan() got blockers %v, want none for hotfix and build, docs for release", blockedBy)
+	}
+}
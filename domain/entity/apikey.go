@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// the scopes an API key can carry, they replace the role of the keys on all the projects of their tenant
+const (
+	ScopeTasksRead  Scope = "tasks:read"  // reads the tasks, as a viewer
+	ScopeTasksWrite Scope = "tasks:write" // also creates and updates the tasks, as an editor
+)
+
+// Scope represents an operation an API key is allowed to do
+type Scope string
+
+// role returns the role the scope grants, an empty role for an unknown scope
+func (s Scope) role() Role {
+	switch s {
+	case ScopeTasksRead:
+		return Viewer
+	case ScopeTasksWrite:
+		return Editor
+	}
+	return ""
+}
+
+// APIKey represents a key authenticating a machine client, such as a CI pipeline or a bot, in the tenant of the administrator who issued it
+type APIKey struct {
+	ID         string     `gorm:"primary_key" json:"id"`
+	TenantID   string     `gorm:"not null;default:default;uniqueIndex:idx_api_keys_tenant_name,priority:1" json:"-"` // tenant the key acts in
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	Hash       string     `gorm:"not null;uniqueIndex" json:"-"` // SHA-256 of the key, the key itself is only returned when it is issued
+	Prefix     string     `gorm:"not null" json:"prefix"`        // first characters of the key, to recognise it without storing it
+	CreatedBy  string     `gorm:"not null" json:"createdBy"`     // subject of the administrator who issued the key
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`          // last time the key authenticated a request, updated at most once a minute
+	APIKeyDescription
+}
+
+// APIKeyDescription represents the values of an API key that an administrator sets when issuing it
+type APIKeyDescription struct {
+	Name      string     `gorm:"not null;uniqueIndex:idx_api_keys_tenant_name,priority:2" json:"name"` // unique name of the key in its tenant, the subject of its requests
+	Scopes    ScopeList  `gorm:"type:text;not null" json:"scopes"`                                     // operations the key is allowed to do
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`                                                  // the key is refused after this time, it never expires when empty
+}
+
+// Subject returns the subject of the requests authenticated with the key, prefixed so that it cannot be mistaken for a username
+func (k *APIKey) Subject() string {
+	return "apikey:" + k.Name
+}
+
+// Expired reports whether the key is refused at the given time
+func (k *APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// IssuedAPIKey represents a key that has just been issued, it is the only time the key is returned
+type IssuedAPIKey struct {
+	*APIKey
+	Key string `json:"key"`
+}
+
+// ScopeList is a list of scopes stored as a comma separated column
+type ScopeList []Scope
+
+// Role returns the highest role granted by the scopes, an empty role if none of them grants one
+func (l ScopeList) Role() Role {
+	var role Role
+	for _, scope := range l {
+		if scope.role().Includes(role) {
+			role = scope.role()
+		}
+	}
+	return role
+}
+
+// Value implements the driver.Valuer interface
+func (l ScopeList) Value() (driver.Value, error) {
+	scopes := make([]string, len(l))
+	for i, scope := range l {
+		scopes[i] = string(scope)
+	}
+	return strings.Join(scopes, ","), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (l *ScopeList) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a scope list", value)
+	}
+	*l = nil
+	for _, scope := range strings.Split(s, ",") {
+		if scope != "" {
+			*l = append(*l, Scope(scope))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Attachment represents the metadata of a file attached to a task, its content is kept in the blob store under BlobKey.
+// The metadata is removed along with the task.
+type Attachment struct {
+	ID          string    `gorm:"primary_key" json:"id"`
+	TenantID    string    `gorm:"not null;default:default;index" json:"-"`
+	TaskID      string    `gorm:"not null;index" json:"taskId"`
+	Name        string    `gorm:"not null" json:"name"`        // name of the uploaded file, without its directories
+	Size        int64     `gorm:"not null" json:"size"`        // size of the content in bytes
+	ContentType string    `gorm:"not null" json:"contentType"` // media type sniffed from the content, the one claimed by the client is not trusted
+	Checksum    string    `gorm:"not null" json:"checksum"`    // hexadecimal SHA-256 of the content
+	UploadedBy  string    `json:"uploadedBy"`                  // subject of the principal who uploaded the file
+	CreatedAt   time.Time `json:"createdAt"`
+	BlobKey     string    `gorm:"not null" json:"-"` // key of the content in the blob store
+	Task        *Task     `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
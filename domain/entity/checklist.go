@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// ChecklistItem represents a step of the checklist of a task, the items are removed along with the task
+type ChecklistItem struct {
+	ID        string    `gorm:"primary_key" json:"id"`
+	TenantID  string    `gorm:"not null;default:default;index" json:"-"`
+	TaskID    string    `gorm:"not null;index:idx_checklist_items_task_id_position,priority:1" json:"taskId"`
+	Position  int       `gorm:"not null;index:idx_checklist_items_task_id_position,priority:2" json:"position"` // place of the item in the checklist, from 1
+	Done      bool      `gorm:"not null;default:false" json:"done"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ChecklistItemBody
+	Task *Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ChecklistItemBody represents the values of a checklist item that the user can set
+type ChecklistItemBody struct {
+	Text string `gorm:"not null" json:"text"` // what has to be done, at most 500 characters
+}
+
+// NewChecklistItem represents the request adding an item to the checklist of a task
+type NewChecklistItem struct {
+	ChecklistItemBody
+	Position int `json:"position,omitempty"` // place of the new item, the following ones moving down, at the end when 0 or after the last item
+}
+
+// ChecklistMove represents the request moving an item of a checklist
+type ChecklistMove struct {
+	Position int `json:"position"` // new place of the item from 1, the last place when after the last item
+}
+
+// ChecklistProgress represents how much of the checklist of a task is done
+type ChecklistProgress struct {
+	Done    int    `json:"done"`    // number of items checked
+	Total   int    `json:"total"`   // number of items
+	Summary string `json:"summary"` // progress for display, e.g. "3/5 done"
+}
+
+// NewChecklistProgress returns the progress of a checklist, nil for a task without checklist
+func NewChecklistProgress(done int, total int) *ChecklistProgress {
+	if total == 0 {
+		return nil
+	}
+	return &ChecklistProgress{Done: done, Total: total, Summary: fmt.Sprintf("%d/%d done", done, total)}
+}
@@ -0,0 +1,46 @@
+package entity
+
+import "time"
+
+// Comment represents a comment posted on a task, the comments are removed along with the task
+type Comment struct {
+	ID        string     `gorm:"primary_key" json:"id"`
+	TenantID  string     `gorm:"not null;default:default;index" json:"-"`
+	TaskID    string     `gorm:"not null;index:idx_comments_task_id_created_at,priority:1" json:"taskId"`
+	Author    string     `gorm:"not null" json:"author"` // subject of the principal who posted the comment, the only one who can edit it
+	CreatedAt time.Time  `gorm:"index:idx_comments_task_id_created_at,priority:2" json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	EditedAt  *time.Time `json:"editedAt,omitempty"` // when the body was last edited, nil if it never was
+	CommentBody
+	Task *Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// CommentBody represents the values of a comment that the user can set
+type CommentBody struct {
+	Body string `gorm:"type:text;not null" json:"body"` // text of the comment in Markdown, rendered by the clients
+}
+
+// CommentRevision represents a previous body of an edited comment, a revision is recorded on every edit and never modified afterwards
+type CommentRevision struct {
+	ID        string    `gorm:"primary_key" json:"id"`
+	TenantID  string    `gorm:"not null;default:default;index" json:"-"`
+	CommentID string    `gorm:"not null;index" json:"commentId"`
+	Body      string    `gorm:"type:text;not null" json:"body"` // body of the comment before the edit
+	EditedBy  string    `json:"editedBy"`                       // subject of the principal who made the edit
+	EditedAt  time.Time `gorm:"not null" json:"editedAt"`
+	Comment   *Comment  `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// CommentQuery represents the pagination of the comments of a task, comments are listed from the oldest one as in a conversation
+type CommentQuery struct {
+	Limit  int
+	Offset int
+}
+
+// CommentList represents a page of the comments of a task together with the total number of comments
+type CommentList struct {
+	Comments []*Comment
+	Total    int64
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// TaskDependency represents that a task is blocked by another one: the work on it cannot start or end before its blocker is closed.
+// The dependencies of a task are removed along with it when it is purged.
+type TaskDependency struct {
+	TaskID    string    `gorm:"primary_key" json:"taskId"`          // ID of the blocked task
+	BlockerID string    `gorm:"primary_key;index" json:"blockerId"` // ID of the task blocking it
+	TenantID  string    `gorm:"not null;default:default;index" json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy string    `json:"createdBy"` // subject of the principal who added the dependency
+	Task      *Task     `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Blocker   *Task     `gorm:"foreignKey:BlockerID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// PlannedTask represents a task at its place in the order the open tasks can be worked on, along with its blockers still open
+type PlannedTask struct {
+	Task      *Task    `json:"task"`
+	BlockedBy []string `json:"blockedBy"` // IDs of the open blockers of the task, it can be worked on right away when empty
+}
@@ -1,6 +1,9 @@
 package entity
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // string mapping with the possible values for status
 const (
@@ -10,21 +13,79 @@ const (
 	OnHold Status = "on-hold"
 )
 
+// DefaultTenantID is the tenant of the data created before tenants existed, and of the callers not assigned to another one
+const DefaultTenantID = "default"
+
 // Status represents the current state of the task
 type Status string
 
 // Task Represents the whole task that will be modeled with gorm DB
 type Task struct {
-	ID        string    `gorm:"primary_key" json:"id"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string     `gorm:"primary_key;index:idx_tasks_created_at_id,priority:2" json:"id"`
+	TenantID  string     `gorm:"not null;default:default;index" json:"-"` // tenant owning the task, set by the repository and never exposed
+	CreatedAt time.Time  `gorm:"index:idx_tasks_created_at_id,priority:1" json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	Version   int        `gorm:"not null;default:1" json:"version"` // incremented on every update, used to detect concurrent modifications
+	DeletedAt *time.Time `gorm:"index" json:"deletedAt,omitempty"`  // set when the task is moved to the trash, nil otherwise
+	DeletedBy string     `json:"deletedBy,omitempty"`               // subject of the principal who moved the task to the trash
+	// the people involved with the task, named by the subject of their principal like the actors of the history
+	CreatedBy  string `gorm:"not null;default:''" json:"createdBy,omitempty"`        // who created the task, the scheduler for the spawned occurrences
+	ReporterID string `gorm:"not null;default:'';index" json:"reporterId,omitempty"` // who asked for the task, its creator or the reporter of the template of its series
+	AssigneeID string `gorm:"not null;default:'';index" json:"assigneeId,omitempty"` // who is responsible for the task, empty when nobody is
+	// the occurrences of a recurring task form a series, the task holding the recurrence rule being the template and first occurrence
+	TemplateID  string `gorm:"uniqueIndex:idx_tasks_series,priority:1,where:template_id <> ''" json:"templateId,omitempty"` // ID of the template of the series
+	Occurrence  int    `gorm:"uniqueIndex:idx_tasks_series,priority:2,where:template_id <> ''" json:"occurrence,omitempty"` // position in the series, from 1
+	SeriesEnded bool   `gorm:"not null;default:false" json:"-"`                                                             // set on the last occurrence once no other one can follow it
+	// rolled up from the subtasks whenever one of them changes, the ones in the trash are not counted
+	Subtasks   int  `gorm:"not null;default:0" json:"subtasks,omitempty"` // number of direct subtasks
+	Completion *int `json:"completion,omitempty"`                         // percentage of the subtasks done, including the progress of their own subtasks, nil without subtasks
+	Comments   int  `gorm:"not null;default:0" json:"comments"`           // number of comments posted on the task, kept by the comment repository
+	// kept by the checklist repository, the progress computed from them is added to the JSON representation
+	ChecklistItems int `gorm:"not null;default:0" json:"-"` // number of items of the checklist
+	ChecklistDone  int `gorm:"not null;default:0" json:"-"` // number of items of the checklist that are checked
 	TaskDescription
+	Labels []*Label `gorm:"-" json:"labels,omitempty"` // labels attached to the task, ordered by name
 }
 
 // TaskDescription represents the description of the task to be created. Those are the values that the user can set.
 type TaskDescription struct {
-	Title       string `json:"title"`                                         // title of the task
-	Description string `json:"description"`                                   // description of the task
-	Priority    int    `json:"priority" minimum:"1" maximum:"10" default:"1"` // priority is represented by an int from 1 to 10
-	Status      Status `json:"status"`                                        // current status of the task
+	Title       string     `json:"title"`                                           // title of the task
+	Description string     `json:"description"`                                     // description of the task
+	Priority    int        `json:"priority" minimum:"1" maximum:"10" default:"1"`   // priority is represented by an int from 1 to 10
+	Status      Status     `json:"status"`                                          // current status of the task
+	StartAt     *time.Time `json:"startAt,omitempty"`                               // when the work on the task is planned to start, optional
+	DueAt       *time.Time `json:"dueAt,omitempty"`                                 // when the task should be closed at the latest, optional
+	Recurrence  string     `json:"recurrence,omitempty"`                            // RFC 5545 RRULE subset, e.g. FREQ=WEEKLY;BYDAY=MO, only set on the template of a series
+	Timezone    string     `json:"timezone,omitempty"`                              // IANA time zone the occurrences are computed in, e.g. Europe/Paris, UTC when empty
+	ParentID    string     `gorm:"index" json:"parentId,omitempty"`                 // ID of the task this one is a subtask of, empty for a top-level task
+	ProjectID   string     `gorm:"not null;default:default;index" json:"projectId"` // ID of the project of the task, the default project when not given
+}
+
+// IsOverdue reports whether the task is still open after its due time
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.DueAt != nil && t.Status != Closed && t.DueAt.Before(now)
+}
+
+// MarshalJSON adds the computed overdue flag and checklist progress to the JSON representation of the task
+func (t Task) MarshalJSON() ([]byte, error) {
+	type task Task // same fields without the methods, otherwise json.Marshal would call MarshalJSON again
+	return json.Marshal(struct {
+		task
+		Overdue   bool               `json:"overdue"`             // whether the task is still open after its due time
+		Checklist *ChecklistProgress `json:"checklist,omitempty"` // progress of the checklist, absent without checklist
+	}{task: task(t), Overdue: t.IsOverdue(time.Now()), Checklist: NewChecklistProgress(t.ChecklistDone, t.ChecklistItems)})
+}
+
+// AssigneeMe stands for the caller in the assignee filter of the listing, so that everyone can list their own queue
+const AssigneeMe = "me"
+
+// TaskAssignment represents the request assigning a task, the task is unassigned when AssigneeID is empty
+type TaskAssignment struct {
+	AssigneeID string `json:"assigneeId"` // subject of the principal responsible for the task, e.g. a username
+}
+
+// TaskNode represents a task along with its subtasks in the tree of a hierarchy
+type TaskNode struct {
+	Task     *Task       `json:"task"`
+	Children []*TaskNode `json:"children"`
 }
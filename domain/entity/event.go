@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+// string mapping with the possible types of change events
+const (
+	Created  EventType = "created"
+	Updated  EventType = "updated"
+	Deleted  EventType = "deleted"
+	Restored EventType = "restored"
+	// the blocker added or removed is the new or old value of the "blockedBy" field of the change
+	BlockerAdded   EventType = "blocker-added"
+	BlockerRemoved EventType = "blocker-removed"
+	// the label attached or detached is the new or old value of the "labels" field of the change
+	LabelAdded   EventType = "label-added"
+	LabelRemoved EventType = "label-removed"
+)
+
+// EventType represents the kind of change recorded by a TaskEvent
+type EventType string
+
+// TaskEvent represents a change made to a task, it is recorded once and never modified afterwards
+type TaskEvent struct {
+	ID       string        `gorm:"primary_key" json:"id"`
+	TenantID string        `gorm:"not null;default:default;index" json:"-"`
+	TaskID   string        `gorm:"not null;index:idx_task_events_task_id_at,priority:1" json:"taskId"`
+	Type     EventType     `gorm:"not null" json:"type"`
+	Actor    string        `json:"actor"` // subject of the principal who made the change
+	At       time.Time     `gorm:"not null;index:idx_task_events_task_id_at,priority:2" json:"at"`
+	Changes  []FieldChange `gorm:"serializer:json;type:jsonb" json:"changes,omitempty"` // fields that were set by the change, empty for deletions and restorations
+}
+
+// FieldChange represents the value of a task field before and after a change, Old is nil when the task is created
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// HistoryQuery represents the pagination of the history of a task, events are listed from the most recent one
+type HistoryQuery struct {
+	Limit  int
+	Offset int
+}
+
+// TaskHistory represents a page of the change events of a task together with the total number of events
+type TaskHistory struct {
+	Events []*TaskEvent
+	Total  int64
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Label represents a label of the catalogue, labels are attached to the tasks to categorise them
+type Label struct {
+	ID        string    `gorm:"primary_key" json:"id"`
+	TenantID  string    `gorm:"not null;default:default;uniqueIndex:idx_labels_tenant_name,priority:1" json:"-"` // tenant owning the label
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	LabelDescription
+}
+
+// LabelDescription represents the values of a label that the user can set
+type LabelDescription struct {
+	Name  string `gorm:"not null;uniqueIndex:idx_labels_tenant_name,priority:2" json:"name"` // unique name of the label in its tenant, in lower case
+	Color string `json:"color,omitempty"`                                                    // colour of the label as #rrggbb, optional
+}
+
+// TaskLabel represents a label attached to a task, the attachment is removed along with the task or the label
+type TaskLabel struct {
+	TaskID    string    `gorm:"primary_key" json:"taskId"`
+	LabelID   string    `gorm:"primary_key;index" json:"labelId"`
+	TenantID  string    `gorm:"not null;default:default;index" json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	Task      *Task     `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Label     *Label    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
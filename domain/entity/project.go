@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultProjectID is the ID of the project the tasks are created in when none is given, every tenant gets one the first time it is needed
+const DefaultProjectID = "default"
+
+// Project represents a list of tasks shared by a team, every task belongs to one.
+// Every tenant has its own default project, which is why the tenant is part of the key.
+type Project struct {
+	TenantID  string    `gorm:"primary_key;default:default;uniqueIndex:idx_projects_tenant_name,priority:1" json:"-"`
+	ID        string    `gorm:"primary_key" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	ProjectDescription
+}
+
+// ProjectDescription represents the values of a project that the user can set
+type ProjectDescription struct {
+	Name        string `gorm:"not null;uniqueIndex:idx_projects_tenant_name,priority:2" json:"name"` // unique name of the project in its tenant
+	Description string `json:"description"`                                                          // description of the project
+	ProjectSettings
+}
+
+// ProjectSettings represents the rules applied to the tasks of a project when they are created or updated
+type ProjectSettings struct {
+	DefaultPriority int        `json:"defaultPriority"`                            // priority of the tasks created without one
+	AllowedStatuses StatusList `gorm:"type:text" json:"allowedStatuses,omitempty"` // statuses the tasks can have, all of them when empty
+	// whether the tasks can only be closed once all the items of their checklist are checked
+	RequireChecklist bool `gorm:"not null;default:false" json:"requireChecklist"`
+}
+
+// Allows reports whether the tasks of the project can have the status
+func (s *ProjectSettings) Allows(status Status) bool {
+	if len(s.AllowedStatuses) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedStatuses {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusList is a list of statuses stored as a comma separated column
+type StatusList []Status
+
+// Value implements the driver.Valuer interface
+func (l StatusList) Value() (driver.Value, error) {
+	statuses := make([]string, len(l))
+	for i, status := range l {
+		statuses[i] = string(status)
+	}
+	return strings.Join(statuses, ","), nil
+}
+
+// Scan implements the sql.Scanner interface
+func (l *StatusList) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a status list", value)
+	}
+	*l = nil
+	for _, status := range strings.Split(s, ",") {
+		if status != "" {
+			*l = append(*l, Status(status))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// TaskQuery represents the options used to select, order and paginate the tasks when listing them
+type TaskQuery struct {
+	Limit         int        // maximum number of tasks to return
+	Offset        int        // number of tasks to skip before the first returned one
+	ProjectID     string     // only the tasks of this project are returned, the tasks of all projects if empty
+	Statuses      []Status   // only tasks having one of those statuses are returned, all statuses if empty
+	MinPriority   *int       // lower bound (inclusive) of the priority
+	MaxPriority   *int       // upper bound (inclusive) of the priority
+	CreatedAfter  *time.Time // lower bound (inclusive) of the creation time
+	CreatedBefore *time.Time // upper bound (exclusive) of the creation time
+	UpdatedAfter  *time.Time // lower bound (inclusive) of the last update time
+	UpdatedBefore *time.Time // upper bound (exclusive) of the last update time
+	DueAfter      *time.Time // lower bound (inclusive) of the due time, tasks without due time are excluded
+	DueBefore     *time.Time // upper bound (exclusive) of the due time, tasks without due time are excluded
+	Overdue       *bool      // when set, only the tasks that are (or are not) still open after their due time are listed
+	Assignee      string     // only the tasks assigned to this subject are returned, AssigneeMe for the caller, all tasks if empty
+	Unassigned    *bool      // when set, only the tasks that are (or are not) assigned to nobody are listed
+	Labels        []string   // names of the labels the listed tasks have, all tasks if empty
+	LabelMode     LabelMode  // whether the tasks need any or all of the Labels
+	Sort          []SortField
+	Keyset        bool        // when set, tasks are paginated by their (createdAt, id) position instead of the offset
+	After         *TaskCursor // position of the last task already seen in keyset mode, nil to start from the beginning
+	Trashed       bool        // when set, only the tasks in the trash are listed instead of the live ones
+}
+
+// string mapping with the ways the labels of a TaskQuery are matched
+const (
+	AnyLabel  LabelMode = "any" // tasks having at least one of the labels
+	AllLabels LabelMode = "all" // tasks having every one of the labels
+)
+
+// LabelMode represents how the labels of a TaskQuery are matched against the labels of the tasks
+type LabelMode string
+
+// TaskCursor represents the position of a task in the (createdAt, id) ordering used by keyset pagination.
+// Unlike an offset, it still points to the same place when tasks are inserted or deleted between two pages.
+type TaskCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// SortField represents a task field used to order the listed tasks, e.g. "-createdAt" is {Field: "createdAt", Desc: true}
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TaskList represents a page of tasks together with the total number of tasks matching the query
+type TaskList struct {
+	Tasks  []*Task
+	Total  int64 // not computed in keyset mode since counting is as expensive as walking the whole table
+	Limit  int   // limit actually applied, it is set to the default one when none was requested
+	Offset int
+	Next   *TaskCursor // position to continue from in keyset mode, nil when the last page is reached
+}
@@ -0,0 +1,47 @@
+package entity
+
+import "time"
+
+// the roles that can be granted, each one allows what the previous one does
+const (
+	Viewer Role = "viewer" // reads the tasks
+	Editor Role = "editor" // also creates the tasks, changes some of their values, comments them, keeps their checklists, and links them to labels and other tasks
+	Admin  Role = "admin"  // also replaces, deletes and restores the tasks and changes the settings of the project, on all the projects also manages the projects and the labels
+)
+
+// Role represents what a caller is allowed to do with the tasks
+type Role string
+
+// rank orders the roles, 0 for an unknown role
+func (r Role) rank() int {
+	switch r {
+	case Viewer:
+		return 1
+	case Editor:
+		return 2
+	case Admin:
+		return 3
+	}
+	return 0
+}
+
+// Includes reports whether the role allows everything the other role does
+func (r Role) Includes(other Role) bool {
+	return r.rank() > 0 && r.rank() >= other.rank()
+}
+
+// RoleAssignment grants a role to a subject, the username or the subject of the bearer token of the caller.
+// The role applies to one project, or to all the projects of the tenant when ProjectID is empty, the role of a project taking precedence.
+type RoleAssignment struct {
+	TenantID  string    `gorm:"primary_key;default:default" json:"-"`
+	Subject   string    `gorm:"primary_key" json:"subject"`
+	ProjectID string    `gorm:"primary_key" json:"projectId,omitempty"` // project the role applies to, empty for all the projects
+	Role      Role      `gorm:"not null" json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RoleGrant represents the request granting a role
+type RoleGrant struct {
+	Role Role `json:"role"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// Session represents the session of a browser client logged in with the OpenID provider, the cookie of the client refers to it
+type Session struct {
+	ID        string `gorm:"primary_key"`                    // SHA-256 of the cookie, the cookie itself is only known by the client
+	TenantID  string `gorm:"not null;default:default;index"` // tenant of the caller
+	Subject   string `gorm:"not null"`                       // name of the caller, as given by the ID token
+	Admin     bool   `gorm:"not null;default:false"`         // whether the caller can manage the users of its tenant
+	Role      Role   // role of the caller on all the projects as given by the ID token, empty when it gives none
+	IDToken   string `gorm:"type:text"` // ID token of the login, sent back to the provider when logging out
+	CreatedAt time.Time
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// LoginAttempt represents a login started at the OpenID provider, it is used once by the callback ending the login
+type LoginAttempt struct {
+	ID           string `gorm:"primary_key"` // SHA-256 of the state sent to the provider
+	Nonce        string `gorm:"not null"`    // nonce the ID token must carry, so that it cannot be replayed from another login
+	CodeVerifier string `gorm:"not null"`    // PKCE verifier of the code challenge sent to the provider
+	ReturnTo     string `gorm:"not null"`    // path the browser goes back to once logged in
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"not null;index"`
+}
+
+// IssuedSession represents a session that has just been opened, it is the only time its cookie is known by the service
+type IssuedSession struct {
+	Token     string    // value of the cookie
+	ExpiresAt time.Time // when the session and its cookie expire
+	ReturnTo  string    // path the browser goes back to
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// User represents an account authenticating to the service, it belongs to a tenant and only sees the data of this tenant
+type User struct {
+	ID           string    `gorm:"primary_key" json:"id"`
+	TenantID     string    `gorm:"not null;default:default;index" json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	PasswordHash string    `gorm:"not null" json:"-"`                      // bcrypt hash of the password, the password itself is never stored
+	Disabled     bool      `gorm:"not null;default:false" json:"disabled"` // a disabled user cannot authenticate until it is enabled again
+	UserDescription
+}
+
+// UserDescription represents the values of a user that an administrator can set
+type UserDescription struct {
+	Username string `gorm:"not null;uniqueIndex" json:"username"` // unique name used to authenticate, in lower case
+	Admin    bool   `gorm:"not null;default:false" json:"admin"`  // whether the user can manage the users of its tenant
+}
+
+// NewUser represents the request creating a user, the password is only kept as a hash
+type NewUser struct {
+	UserDescription
+	Password string `json:"password"`
+}
+
+// PasswordReset represents the request replacing the password of a user
+type PasswordReset struct {
+	Password string `json:"password"`
+}
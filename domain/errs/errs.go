@@ -0,0 +1,93 @@
+// Package errs defines the kinds of errors produced by the repository and the service.
+// The outer layers only check the kind of an error with errors.Is to react to it, e.g. to pick the HTTP status code, without knowing where it comes from.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound when the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrValidation when the input given by the caller is not valid
+	ErrValidation = errors.New("validation failed")
+	// ErrConflict when the request is valid but conflicts with the current state of the resource
+	ErrConflict = errors.New("conflict")
+	// ErrPreconditionFailed when the resource was modified since the version the caller based its request on
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrUnauthenticated when the credentials of the caller are missing or wrong, or its account is disabled
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrForbidden when the caller is authenticated but is not allowed to do what it requested
+	ErrForbidden = errors.New("forbidden")
+	// ErrTooLarge when the content given by the caller exceeds the size the service accepts
+	ErrTooLarge = errors.New("too large")
+	// ErrUnavailable when a dependency like the database cannot be reached, the same request can be retried later
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error is an error of a given kind, the kind is one of the sentinel errors of this package
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// New returns an error of the given kind with a formatted message
+func New(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap returns an error of the given kind wrapping err, nil is returned if err is nil
+func Wrap(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Kind, e.Err)
+}
+
+// Unwrap lets errors.Is and errors.As reach the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error is of the target kind
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Violation represents a constraint that a field of the input does not satisfy
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists all the violations found in an input, so that they can be fixed at once. It is of kind ErrValidation.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Validation returns a ValidationError with the given violations, nil is returned if there are none
+func Validation(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = fmt.Sprintf("invalid %s: %s", v.Field, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrValidation, strings.Join(messages, "; "))
+}
+
+// Is reports whether the target is ErrValidation
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
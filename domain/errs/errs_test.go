@@ -0,0 +1,70 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestError_Is(t *testing.T) {
+	cause := errors.New("cause")
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{
+			name:   "should match its kind",
+			err:    Wrap(ErrNotFound, cause),
+			target: ErrNotFound,
+			want:   true,
+		},
+		{
+			name:   "should match the wrapped error",
+			err:    Wrap(ErrNotFound, cause),
+			target: cause,
+			want:   true,
+		},
+		{
+			name:   "should match its kind when wrapped again",
+			err:    fmt.Errorf("context: %w", New(ErrConflict, "task %s", "1")),
+			target: ErrConflict,
+			want:   true,
+		},
+		{
+			name:   "should not match another kind",
+			err:    Wrap(ErrUnavailable, cause),
+			target: ErrValidation,
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if Wrap(ErrNotFound, nil) != nil {
+		t.Errorf("Wrap() of a nil error should be nil")
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := Validation([]Violation{{Field: "title", Message: "field cannot be empty"}, {Field: "priority", Message: "out of range"}})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("errors.Is() should match ErrValidation")
+	}
+	var validationErr *ValidationError
+	if !errors.As(fmt.Errorf("wrapped: %w", err), &validationErr) || len(validationErr.Violations) != 2 {
+		t.Errorf("errors.As() should find the 2 violations")
+	}
+	want := "validation failed: invalid title: field cannot be empty; invalid priority: out of range"
+	if err.Error() != want {
+		t.Errorf("Error() = %v, want %v", err.Error(), want)
+	}
+	if Validation(nil) != nil {
+		t.Errorf("Validation() without violations should be nil")
+	}
+}
@@ -0,0 +1,32 @@
+// Package hierarchy defines the rules of the parent/child relationship between tasks
+package hierarchy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// string mapping with the policies applied to the subtasks of a deleted task
+const (
+	Cascade DeletePolicy = "cascade" // the subtasks are moved to the trash with their parent
+	Orphan  DeletePolicy = "orphan"  // the subtasks are detached from their parent and become top-level tasks
+	Refuse  DeletePolicy = "refuse"  // a task cannot be deleted while it has subtasks
+)
+
+// DeletePolicy represents what deleting a task does to its subtasks
+type DeletePolicy string
+
+// MaxDepth is the maximum number of levels of a hierarchy, a top-level task being at level 1
+const MaxDepth = 10
+
+// ParsePolicy returns the policy named by value, Refuse is returned if value is empty so that no subtask is lost by default
+func ParsePolicy(value string) (DeletePolicy, error) {
+	policy := DeletePolicy(strings.ToLower(strings.TrimSpace(value)))
+	switch policy {
+	case "":
+		return Refuse, nil
+	case Cascade, Orphan, Refuse:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown delete policy '%s', should be one of %s, %s or %s", value, Cascade, Orphan, Refuse)
+}
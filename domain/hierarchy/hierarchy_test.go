@@ -0,0 +1,27 @@
+package hierarchy
+
+import "testing"
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    DeletePolicy
+		wantErr bool
+	}{
+		{value: "", want: Refuse},
+		{value: "cascade", want: Cascade},
+		{value: " Orphan ", want: Orphan},
+		{value: "refuse", want: Refuse},
+		{value: "delete", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePolicy(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePolicy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParsePolicy(%q) got = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package recurrence implements the subset of the RFC 5545 recurrence rules (RRULE) supported for recurring tasks:
+// DAILY, WEEKLY and MONTHLY frequencies with INTERVAL, BYDAY (without ordinals), UNTIL and COUNT.
+// The occurrences are computed in the time zone of the series, which stands for the TZID of its start.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// string mapping with the supported frequencies
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Frequency represents the unit of time between the occurrences of a rule
+type Frequency string
+
+// MaxInterval is the maximum number of frequency units between two occurrences
+const MaxInterval = 1000
+
+// weekdays maps the RFC 5545 day names to the days of the week
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// ErrInvalidRule when the rule cannot be parsed or uses a part of RFC 5545 that is not supported
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Rule represents a parsed recurrence rule, the occurrences are computed in its location from the due time of the previous one
+type Rule struct {
+	Freq     Frequency
+	Interval int            // number of frequency units between two occurrences, 1 if not set
+	ByDay    []time.Weekday // days of the week the occurrences fall on, only for DAILY and WEEKLY
+	Until    *time.Time     // no occurrence is due after this time
+	Count    int            // total number of occurrences including the first one, 0 for no limit
+	// time zone the days and the time of the day of the occurrences are counted in, like the TZID of the DTSTART of RFC 5545,
+	// so that they keep their local time across the daylight saving time changes. UTC when nil, it is not part of the rule itself.
+	Location *time.Location
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10", the "RRULE:" prefix is optional
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(value)), "RRULE:")
+	rule := Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, found := strings.Cut(part, "=")
+		if !found || val == "" {
+			return nil, fmt.Errorf("%w: '%s' should be a NAME=VALUE pair", ErrInvalidRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s is set more than once", ErrInvalidRule, key)
+		}
+		seen[key] = true
+		var err error
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(val)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				err = fmt.Errorf("%w: frequency should be DAILY, WEEKLY or MONTHLY", ErrInvalidRule)
+			}
+		case "INTERVAL":
+			rule.Interval, err = positive(key, val, MaxInterval)
+		case "COUNT":
+			rule.Count, err = positive(key, val, 0)
+		case "UNTIL":
+			rule.Until, err = parseUntil(val)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		default:
+			err = fmt.Errorf("%w: %s is not supported", ErrInvalidRule, key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Until != nil && rule.Count != 0 {
+		return nil, fmt.Errorf("%w: UNTIL and COUNT cannot be used together", ErrInvalidRule)
+	}
+	if rule.Freq == Monthly && len(rule.ByDay) > 0 {
+		return nil, fmt.Errorf("%w: BYDAY is only supported with DAILY and WEEKLY", ErrInvalidRule)
+	}
+	return &rule, nil
+}
+
+// String returns the canonical form of the rule, the parts are always in the same order
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence following the one due at prev, in UTC. The time of the day is kept in the location of the rule,
+// except when it does not exist on the day of the occurrence because of a daylight saving time change.
+// ok is false when there is no such occurrence, because the rule ended or can never match its days.
+// Like in RFC 5545, months without the day of prev (e.g. the 31st) are skipped.
+func (r *Rule) Next(prev time.Time) (next time.Time, ok bool) {
+	prev = prev.In(r.location())
+	switch r.Freq {
+	case Daily:
+		next, ok = r.nextDaily(prev)
+	case Weekly:
+		next, ok = r.nextWeekly(prev)
+	case Monthly:
+		next, ok = r.nextMonthly(prev)
+	}
+	if !ok || (r.Until != nil && next.After(*r.Until)) {
+		return time.Time{}, false
+	}
+	return next.UTC(), true
+}
+
+func (r *Rule) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+func (r *Rule) nextDaily(prev time.Time) (time.Time, bool) {
+	// the weekdays repeat every 7 days, so if none of the next 7 candidates matches, none ever will
+	for i := 1; i <= 7; i++ {
+		next := prev.AddDate(0, 0, i*r.Interval)
+		if len(r.ByDay) == 0 || r.onDay(next.Weekday()) {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) nextWeekly(prev time.Time) (time.Time, bool) {
+	if len(r.ByDay) == 0 {
+		return prev.AddDate(0, 0, 7*r.Interval), true
+	}
+	// weeks start on Monday as in RFC 5545, the remaining days of the week of prev are tried first
+	offset := (int(prev.Weekday()) + 6) % 7
+	for i := offset + 1; i < 7; i++ {
+		if r.onDay(time.Weekday((i + 1) % 7)) {
+			return prev.AddDate(0, 0, i-offset), true
+		}
+	}
+	monday := prev.AddDate(0, 0, -offset+7*r.Interval)
+	for i := 0; i < 7; i++ {
+		if r.onDay(time.Weekday((i + 1) % 7)) {
+			return monday.AddDate(0, 0, i), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) nextMonthly(prev time.Time) (time.Time, bool) {
+	// the 29th of February is found again after at most 4 years, more than enough for any other day
+	for i := 1; i*r.Interval <= 12*8; i++ {
+		next := time.Date(prev.Year(), prev.Month()+time.Month(i*r.Interval), prev.Day(),
+			prev.Hour(), prev.Minute(), prev.Second(), prev.Nanosecond(), prev.Location())
+		if next.Day() == prev.Day() {
+			return next, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r *Rule) onDay(day time.Weekday) bool {
+	for _, d := range r.ByDay {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func positive(key, value string, max int) (int, error) {
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 1 || (max > 0 && i > max) {
+		if max > 0 {
+			return 0, fmt.Errorf("%w: %s should be an integer from 1 to %d", ErrInvalidRule, key, max)
+		}
+		return 0, fmt.Errorf("%w: %s should be a positive integer", ErrInvalidRule, key)
+	}
+	return i, nil
+}
+
+// parseUntil accepts the UTC date-time and the date forms of RFC 5545, a date includes the whole day
+func parseUntil(value string) (*time.Time, error) {
+	if until, err := time.Parse("20060102T150405Z", value); err == nil {
+		return &until, nil
+	}
+	if until, err := time.Parse("20060102", value); err == nil {
+		until = until.Add(24*time.Hour - time.Second)
+		return &until, nil
+	}
+	return nil, fmt.Errorf("%w: UNTIL should be a UTC date-time such as 20220131T235959Z or a date such as 20220131", ErrInvalidRule)
+}
+
+func parseByDay(value string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, name := range strings.Split(value, ",") {
+		day, ok := weekdays[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: BYDAY should list days among MO,TU,WE,TH,FR,SA,SU without ordinals, got '%s'", ErrInvalidRule, name)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
@@ -0,0 +1,102 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "should parse a weekly rule", value: "RRULE:freq=weekly;interval=2;byday=MO,th;count=10", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10"},
+		{name: "should parse a daily rule until a date", value: "FREQ=DAILY;UNTIL=20220131", want: "FREQ=DAILY;UNTIL=20220131T235959Z"},
+		{name: "should parse a monthly rule", value: "FREQ=MONTHLY;INTERVAL=1", want: "FREQ=MONTHLY"},
+		{name: "should fail without frequency", value: "INTERVAL=2", wantErr: true},
+		{name: "should fail with unsupported frequency", value: "FREQ=YEARLY", wantErr: true},
+		{name: "should fail with unsupported part", value: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "should fail with both until and count", value: "FREQ=DAILY;UNTIL=20220131;COUNT=3", wantErr: true},
+		{name: "should fail with ordinal days", value: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "should fail with days on a monthly rule", value: "FREQ=MONTHLY;BYDAY=MO", wantErr: true},
+		{name: "should fail with a null interval", value: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidRule) {
+					t.Errorf("Parse() error = %v, want %v", err, ErrInvalidRule)
+				}
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("Parse() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRule_Next(t *testing.T) {
+	// Wednesday the 12th of January 2022 at 9:00 UTC
+	wednesday := time.Date(2022, time.January, 12, 9, 0, 0, 0, time.UTC)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		rule     string
+		location *time.Location
+		prev     time.Time
+		want     time.Time
+		wantOk   bool
+	}{
+		{name: "should move to the next day", rule: "FREQ=DAILY", prev: wednesday, want: wednesday.AddDate(0, 0, 1), wantOk: true},
+		{name: "should skip the week-end", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", prev: wednesday.AddDate(0, 0, 2), want: wednesday.AddDate(0, 0, 5), wantOk: true},
+		{name: "should never match days out of the interval", rule: "FREQ=DAILY;INTERVAL=7;BYDAY=MO", prev: wednesday, wantOk: false},
+		{name: "should move to the same day two weeks later", rule: "FREQ=WEEKLY;INTERVAL=2", prev: wednesday, want: wednesday.AddDate(0, 0, 14), wantOk: true},
+		{name: "should move to a later day of the same week", rule: "FREQ=WEEKLY;BYDAY=MO,FR", prev: wednesday, want: wednesday.AddDate(0, 0, 2), wantOk: true},
+		{name: "should move to the first day of the next period", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TU", prev: wednesday, want: wednesday.AddDate(0, 0, 12), wantOk: true},
+		{name: "should treat sunday as the end of the week", rule: "FREQ=WEEKLY;BYDAY=SU,MO", prev: wednesday, want: wednesday.AddDate(0, 0, 4), wantOk: true},
+		{name: "should move to the next month", rule: "FREQ=MONTHLY", prev: wednesday, want: wednesday.AddDate(0, 1, 0), wantOk: true},
+		{name: "should skip months without the day", rule: "FREQ=MONTHLY",
+			prev: time.Date(2022, time.January, 31, 9, 0, 0, 0, time.UTC), want: time.Date(2022, time.March, 31, 9, 0, 0, 0, time.UTC), wantOk: true},
+		{name: "should stop after until", rule: "FREQ=DAILY;UNTIL=20220112", prev: wednesday, wantOk: false},
+		// the 27th of March 2022 starts the summer time in Paris, 9:00 moves from 8:00 to 7:00 UTC
+		{name: "should keep the local time of the day across the start of the summer time", rule: "FREQ=DAILY", location: paris,
+			prev: time.Date(2022, time.March, 26, 8, 0, 0, 0, time.UTC), want: time.Date(2022, time.March, 27, 7, 0, 0, 0, time.UTC), wantOk: true},
+		{name: "should keep the local time of the day across the end of the summer time", rule: "FREQ=WEEKLY", location: paris,
+			prev: time.Date(2022, time.October, 24, 7, 0, 0, 0, time.UTC), want: time.Date(2022, time.October, 31, 8, 0, 0, 0, time.UTC), wantOk: true},
+		{name: "should keep the local day of the month across the summer time", rule: "FREQ=MONTHLY", location: paris,
+			prev: time.Date(2022, time.March, 1, 8, 0, 0, 0, time.UTC), want: time.Date(2022, time.April, 1, 7, 0, 0, 0, time.UTC), wantOk: true},
+		// Monday the 3rd of January 2022 at 20:00 in Los Angeles is already Tuesday in UTC
+		{name: "should match the days in the time zone of the rule", rule: "FREQ=WEEKLY;BYDAY=MO,WE", location: losAngeles,
+			prev: time.Date(2022, time.January, 4, 4, 0, 0, 0, time.UTC), want: time.Date(2022, time.January, 6, 4, 0, 0, 0, time.UTC), wantOk: true},
+		{name: "should skip the local week-end", rule: "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", location: losAngeles,
+			prev: time.Date(2022, time.January, 8, 4, 0, 0, 0, time.UTC), want: time.Date(2022, time.January, 11, 4, 0, 0, 0, time.UTC), wantOk: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rule.Location = tt.location
+			got, ok := rule.Next(tt.prev)
+			if ok != tt.wantOk || !got.Equal(tt.want) {
+				t.Errorf("Next() got = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
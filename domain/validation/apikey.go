@@ -0,0 +1,78 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidScope when a scope is not one of the scopes an API key can carry
+	ErrInvalidScope = fmt.Errorf("scopes should be %s or %s", entity.ScopeTasksRead, entity.ScopeTasksWrite)
+	// ErrInvalidAPIKeyName when the name of an API key contains other characters than the ones allowed
+	ErrInvalidAPIKeyName = errors.New("name can only contain letters, digits and the characters . _ - @")
+	// ErrExpiredKey when an API key would expire before it is issued
+	ErrExpiredKey = errors.New("expiry should be in the future")
+)
+
+// ValidateAPIKey validates the values of a new API key and returns them normalized: the name in lower case, the scopes sorted
+// without duplicates. The expiry is optional, it has to be after now when it is given.
+func ValidateAPIKey(req *entity.APIKeyDescription, now time.Time) (*entity.APIKeyDescription, error) {
+	var violations []errs.Violation
+	name, err := ValidateAPIKeyName(req.Name)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "name", Message: err.Error()})
+	}
+	scopes, err := ValidateScopes(req.Scopes)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "scopes", Message: err.Error()})
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		violations = append(violations, errs.Violation{Field: "expiresAt", Message: ErrExpiredKey.Error()})
+	}
+	if err = errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Name, req.Scopes = name, scopes
+	return req, nil
+}
+
+// ValidateAPIKeyName returns the name in lower case, it has the characters of a username since it names the subject of the requests
+func ValidateAPIKeyName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", ErrEmptyField
+	}
+	if len(name) < 2 || len(name) > 64 {
+		return "", fmt.Errorf("%s: name length should be from 2 to 64 characters", ErrInvalidLength)
+	}
+	if !usernamePattern.MatchString(name) {
+		return "", ErrInvalidAPIKeyName
+	}
+	return name, nil
+}
+
+// ValidateScopes returns the scopes in lower case, sorted and without duplicates, at least one scope is needed
+func ValidateScopes(scopes entity.ScopeList) (entity.ScopeList, error) {
+	if len(scopes) == 0 {
+		return nil, ErrEmptyField
+	}
+	seen := make(map[entity.Scope]bool)
+	var normalized entity.ScopeList
+	for _, scope := range scopes {
+		scope = entity.Scope(strings.ToLower(strings.TrimSpace(string(scope))))
+		if scope != entity.ScopeTasksRead && scope != entity.ScopeTasksWrite {
+			return nil, ErrInvalidScope
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i] < normalized[j] })
+	return normalized, nil
+}
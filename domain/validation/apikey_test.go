@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateAPIKey(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tests := []struct {
+		name    string
+		req     *entity.APIKeyDescription
+		want    *entity.APIKeyDescription
+		wantErr bool
+	}{
+		{name: "should normalize the name and the scopes", req: &entity.APIKeyDescription{Name: " CI-Pipeline ", Scopes: entity.ScopeList{"tasks:write", " Tasks:Read", "tasks:write"}},
+			want: &entity.APIKeyDescription{Name: "ci-pipeline", Scopes: entity.ScopeList{entity.ScopeTasksRead, entity.ScopeTasksWrite}}},
+		{name: "should accept an expiry in the future", req: &entity.APIKeyDescription{Name: "bot", Scopes: entity.ScopeList{"tasks:read"}, ExpiresAt: &future},
+			want: &entity.APIKeyDescription{Name: "bot", Scopes: entity.ScopeList{entity.ScopeTasksRead}, ExpiresAt: &future}},
+		{name: "should fail because the expiry is in the past", req: &entity.APIKeyDescription{Name: "bot", Scopes: entity.ScopeList{"tasks:read"}, ExpiresAt: &past}, wantErr: true},
+		{name: "should fail because the scope is unknown", req: &entity.APIKeyDescription{Name: "bot", Scopes: entity.ScopeList{"users:write"}}, wantErr: true},
+		{name: "should fail because there is no scope", req: &entity.APIKeyDescription{Name: "bot"}, wantErr: true},
+		{name: "should fail because the name is invalid", req: &entity.APIKeyDescription{Name: "my bot", Scopes: entity.ScopeList{"tasks:read"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateAPIKey(tt.req, now)
+			if tt.wantErr {
+				if !errors.Is(err, errs.ErrValidation) {
+					t.Errorf("ValidateAPIKey() error = %v, want %v", err, errs.ErrValidation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAPIKey() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateAPIKey() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopeList_Role(t *testing.T) {
+	tests := []struct {
+		scopes entity.ScopeList
+		want   entity.Role
+	}{
+		{scopes: entity.ScopeList{entity.ScopeTasksRead}, want: entity.Viewer},
+		{scopes: entity.ScopeList{entity.ScopeTasksWrite, entity.ScopeTasksRead}, want: entity.Editor},
+		{scopes: entity.ScopeList{"users:write"}, want: ""},
+		{scopes: nil, want: ""},
+	}
+	for _, tt := range tests {
+		if got := tt.scopes.Role(); got != tt.want {
+			t.Errorf("Role() of %v got = %v, want %v", tt.scopes, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidFileName when the name of an attached file cannot be used as a file name
+var ErrInvalidFileName = errors.New("name cannot contain control characters or be . or ..")
+
+// ValidateFileName returns the name of an uploaded file without the directories some clients send along with it, trimmed.
+// The name is sent back in the Content-Disposition of the downloads, so it cannot contain control characters.
+func ValidateFileName(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", ErrInvalidEncoding
+	}
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "/" {
+		return "", ErrEmptyField
+	}
+	if name == "." || name == ".." {
+		return "", ErrInvalidFileName
+	}
+	if utf8.RuneCountInString(name) > 255 {
+		return "", fmt.Errorf("%s: name length should be under 255 characters", ErrInvalidLength)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", ErrInvalidFileName
+		}
+	}
+	return name, nil
+}
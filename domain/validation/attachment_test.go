@@ -0,0 +1,35 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		want     string
+		wantErr  bool
+	}{
+		{name: "should keep a plain name", fileName: "server.log", want: "server.log"},
+		{name: "should remove the directories", fileName: "/var/log/server.log", want: "server.log"},
+		{name: "should remove the directories of windows clients", fileName: `C:\Users\alice\screenshot 1.png`, want: "screenshot 1.png"},
+		{name: "should fail because name is empty", fileName: " ", wantErr: true},
+		{name: "should keep the last part of a name ending with a slash", fileName: "logs/", want: "logs"},
+		{name: "should fail because name is the parent directory", fileName: "a/..", wantErr: true},
+		{name: "should fail because name contains a line break", fileName: "a\nb.txt", wantErr: true},
+		{name: "should fail because name is too long", fileName: strings.Repeat("a", 256), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateFileName(tt.fileName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateFileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ValidateFileName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
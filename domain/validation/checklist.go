@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxChecklistTextLength is the maximum number of characters of the text of a checklist item
+const MaxChecklistTextLength = 500
+
+var (
+	// ErrNotSingleLine when a text that is displayed on a single line contains line breaks or other control characters
+	ErrNotSingleLine = errors.New("text should be a single line without control characters")
+	// ErrInvalidPosition when a position in a list is not a positive number
+	ErrInvalidPosition = errors.New("position should be a positive number")
+)
+
+// ValidateChecklistItem validates a new checklist item and returns it with its text trimmed, a position of 0 adds it at the end
+func ValidateChecklistItem(req *entity.NewChecklistItem) (*entity.NewChecklistItem, error) {
+	var violations []errs.Violation
+	text, err := ValidateChecklistText(req.Text)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "text", Message: err.Error()})
+	}
+	if req.Position < 0 {
+		violations = append(violations, errs.Violation{Field: "position", Message: ErrInvalidPosition.Error()})
+	}
+	if err = errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Text = text
+	return req, nil
+}
+
+// ValidateChecklistMove validates the new position of a checklist item, positions start at 1
+func ValidateChecklistMove(req *entity.ChecklistMove) error {
+	if req.Position < 1 {
+		return errs.Validation([]errs.Violation{{Field: "position", Message: ErrInvalidPosition.Error()}})
+	}
+	return nil
+}
+
+// ValidateChecklistText returns the text trimmed, its length is counted in characters rather than bytes
+func ValidateChecklistText(text string) (string, error) {
+	if !utf8.ValidString(text) {
+		return "", ErrInvalidEncoding
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", ErrEmptyField
+	}
+	if utf8.RuneCountInString(text) > MaxChecklistTextLength {
+		return "", fmt.Errorf("%s: text length should be under %d characters", ErrInvalidLength, MaxChecklistTextLength)
+	}
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			return "", ErrNotSingleLine
+		}
+	}
+	return text, nil
+}
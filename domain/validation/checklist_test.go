@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateChecklistItem(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.NewChecklistItem
+		want    *entity.NewChecklistItem
+		wantErr bool
+	}{
+		{
+			name: "should trim the text",
+			req:  &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "  Write the tests "}, Position: 2},
+			want: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "Write the tests"}, Position: 2},
+		},
+		{
+			name: "should count the length in characters",
+			req:  &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: strings.Repeat("é", MaxChecklistTextLength)}},
+			want: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: strings.Repeat("é", MaxChecklistTextLength)}},
+		},
+		{name: "should fail because text is blank", req: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: " "}}, wantErr: true},
+		{name: "should fail because text is too long", req: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: strings.Repeat("a", MaxChecklistTextLength+1)}}, wantErr: true},
+		{name: "should fail because text has several lines", req: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "first\nsecond"}}, wantErr: true},
+		{name: "should fail because text is not UTF-8", req: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "caf\xe9"}}, wantErr: true},
+		{name: "should fail because position is negative", req: &entity.NewChecklistItem{ChecklistItemBody: entity.ChecklistItemBody{Text: "test"}, Position: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateChecklistItem(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateChecklistItem() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("ValidateChecklistItem() error = %v, want %v", err, errs.ErrValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateChecklistItem() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateChecklistMove(t *testing.T) {
+	if err := ValidateChecklistMove(&entity.ChecklistMove{Position: 1}); err != nil {
+		t.Errorf("ValidateChecklistMove() error = %v", err)
+	}
+	if err := ValidateChecklistMove(&entity.ChecklistMove{}); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidateChecklistMove() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
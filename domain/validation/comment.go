@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxCommentLength is the maximum number of characters of the body of a comment
+const MaxCommentLength = 10000
+
+var (
+	// ErrInvalidEncoding when a text is not valid UTF-8
+	ErrInvalidEncoding = errors.New("text should be valid UTF-8")
+	// ErrControlCharacter when a text contains control characters other than line breaks and tabs
+	ErrControlCharacter = errors.New("text cannot contain control characters other than line breaks and tabs")
+)
+
+// ValidateComment validates the body of a comment and returns it with the surrounding blank lines and spaces removed,
+// the Markdown itself is kept as written since it is rendered by the clients
+func ValidateComment(req *entity.CommentBody) (*entity.CommentBody, error) {
+	body, err := ValidateCommentBody(req.Body)
+	if err != nil {
+		return nil, errs.Validation([]errs.Violation{{Field: "body", Message: err.Error()}})
+	}
+	req.Body = body
+	return req, nil
+}
+
+// ValidateCommentBody returns the body trimmed, its length is counted in characters rather than bytes
+func ValidateCommentBody(body string) (string, error) {
+	if !utf8.ValidString(body) {
+		return "", ErrInvalidEncoding
+	}
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", ErrEmptyField
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return "", fmt.Errorf("%s: body length should be under %d characters", ErrInvalidLength, MaxCommentLength)
+	}
+	for _, r := range body {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return "", ErrControlCharacter
+		}
+	}
+	return body, nil
+}
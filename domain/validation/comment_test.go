@@ -0,0 +1,61 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.CommentBody
+		want    *entity.CommentBody
+		wantErr bool
+	}{
+		{
+			name: "should trim the body and keep the Markdown",
+			req:  &entity.CommentBody{Body: "\n  **Done**, see:\n\n\t- [x] tests\n"},
+			want: &entity.CommentBody{Body: "**Done**, see:\n\n\t- [x] tests"},
+		},
+		{
+			name: "should count the length in characters",
+			req:  &entity.CommentBody{Body: strings.Repeat("é", MaxCommentLength)},
+			want: &entity.CommentBody{Body: strings.Repeat("é", MaxCommentLength)},
+		},
+		{name: "should fail because body is blank", req: &entity.CommentBody{Body: " \n "}, wantErr: true},
+		{name: "should fail because body is too long", req: &entity.CommentBody{Body: strings.Repeat("a", MaxCommentLength+1)}, wantErr: true},
+		{name: "should fail because body is not UTF-8", req: &entity.CommentBody{Body: "caf\xe9"}, wantErr: true},
+		{name: "should fail because body contains a control character", req: &entity.CommentBody{Body: "bell\a"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateComment(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("ValidateComment() error = %v, want %v", err, errs.ErrValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateComment() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCommentQuery(t *testing.T) {
+	got, err := ValidateCommentQuery(&entity.CommentQuery{Offset: 5})
+	if err != nil {
+		t.Fatalf("ValidateCommentQuery() error = %v", err)
+	}
+	if want := (&entity.CommentQuery{Limit: DefaultLimit, Offset: 5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateCommentQuery() got = %v, want %v", got, want)
+	}
+	if _, err = ValidateCommentQuery(&entity.CommentQuery{Limit: -1}); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidateCommentQuery() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
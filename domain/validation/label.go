@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"strings"
+)
+
+// MaxLabelFilter is the maximum number of labels a list of tasks can be filtered by
+const MaxLabelFilter = 20
+
+// ErrInvalidColor when the colour of a label is not a #rrggbb hexadecimal colour
+var ErrInvalidColor = errors.New("color should be a hexadecimal colour such as #1f77b4")
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// ValidateLabel validates all the values of a label and returns them normalized: the name in lower case and trimmed, the colour in lower case
+func ValidateLabel(req *entity.LabelDescription) (*entity.LabelDescription, error) {
+	var violations []errs.Violation
+	name, err := ValidateLabelName(req.Name)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "name", Message: err.Error()})
+	}
+	color, err := ValidateColor(req.Color)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "color", Message: err.Error()})
+	}
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Name, req.Color = name, color
+	return req, nil
+}
+
+// ValidatePartialLabel validates and normalizes the values set in a partial update of a label
+func ValidatePartialLabel(req *entity.LabelDescription) error {
+	var violations []errs.Violation
+	if req.Name != "" {
+		name, err := ValidateLabelName(req.Name)
+		if err != nil {
+			violations = append(violations, errs.Violation{Field: "name", Message: err.Error()})
+		}
+		req.Name = name
+	}
+	color, err := ValidateColor(req.Color)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "color", Message: err.Error()})
+	}
+	req.Color = color
+	return errs.Validation(violations)
+}
+
+// ValidateLabelName returns the name normalized, names are compared without case so "Bug" and "bug" are the same label.
+// Commas are not allowed since they separate the labels in the list filter.
+func ValidateLabelName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", ErrEmptyField
+	}
+	if len(name) > 50 {
+		return "", fmt.Errorf("%s: name length should be under 50 characters", ErrInvalidLength)
+	}
+	if strings.Contains(name, ",") {
+		return "", errors.New("name cannot contain commas")
+	}
+	return name, nil
+}
+
+// ValidateColor returns the colour in lower case, an empty colour is allowed
+func ValidateColor(color string) (string, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if color != "" && !colorPattern.MatchString(color) {
+		return "", ErrInvalidColor
+	}
+	return color, nil
+}
@@ -0,0 +1,59 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+)
+
+func TestValidateLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.LabelDescription
+		want    *entity.LabelDescription
+		wantErr bool
+	}{
+		{
+			name: "should normalize the name and the colour",
+			req:  &entity.LabelDescription{Name: " Urgent ", Color: "#FF0000"},
+			want: &entity.LabelDescription{Name: "urgent", Color: "#ff0000"},
+		},
+		{
+			name: "should accept a label without colour",
+			req:  &entity.LabelDescription{Name: "bug"},
+			want: &entity.LabelDescription{Name: "bug"},
+		},
+		{name: "should fail because name is empty", req: &entity.LabelDescription{Name: " ", Color: "#ff0000"}, wantErr: true},
+		{name: "should fail because name contains a comma", req: &entity.LabelDescription{Name: "bug,urgent"}, wantErr: true},
+		{name: "should fail because colour is not hexadecimal", req: &entity.LabelDescription{Name: "bug", Color: "red"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateLabel(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateLabel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("ValidateLabel() error = %v, want %v", err, errs.ErrValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateLabel() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePartialLabel(t *testing.T) {
+	req := &entity.LabelDescription{Color: "#00FF00"}
+	if err := ValidatePartialLabel(req); err != nil {
+		t.Fatalf("ValidatePartialLabel() error = %v", err)
+	}
+	if req.Name != "" || req.Color != "#00ff00" {
+		t.Errorf("ValidatePartialLabel() got = %v, want only the colour normalized", req)
+	}
+	if err := ValidatePartialLabel(&entity.LabelDescription{Name: "a,b"}); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidatePartialLabel() error = %v, want %v", err, errs.ErrValidation)
+	}
+}
@@ -0,0 +1,88 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+)
+
+// ValidateProject validates all the values of a project, the name is trimmed and the allowed statuses normalized
+func ValidateProject(req *entity.ProjectDescription) (*entity.ProjectDescription, error) {
+	var violations []errs.Violation
+	name, err := ValidateProjectName(req.Name)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "name", Message: err.Error()})
+	}
+	if err = ValidateDescription(req.Description); err != nil {
+		violations = append(violations, errs.Violation{Field: "description", Message: err.Error()})
+	}
+	if err = ValidatePriority(req.DefaultPriority); err != nil {
+		violations = append(violations, errs.Violation{Field: "defaultPriority", Message: err.Error()})
+	}
+	statuses, err := ValidateAllowedStatuses(req.AllowedStatuses)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "allowedStatuses", Message: err.Error()})
+	}
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Name, req.AllowedStatuses = name, statuses
+	return req, nil
+}
+
+// ValidatePartialProject validates and normalizes the values set in a partial update of a project
+func ValidatePartialProject(req *entity.ProjectDescription) error {
+	var violations []errs.Violation
+	if req.Name != "" {
+		name, err := ValidateProjectName(req.Name)
+		if err != nil {
+			violations = append(violations, errs.Violation{Field: "name", Message: err.Error()})
+		}
+		req.Name = name
+	}
+	if err := ValidateDescription(req.Description); err != nil {
+		violations = append(violations, errs.Violation{Field: "description", Message: err.Error()})
+	}
+	if err := ValidatePriority(req.DefaultPriority); err != nil {
+		violations = append(violations, errs.Violation{Field: "defaultPriority", Message: err.Error()})
+	}
+	statuses, err := ValidateAllowedStatuses(req.AllowedStatuses)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "allowedStatuses", Message: err.Error()})
+	}
+	req.AllowedStatuses = statuses
+	return errs.Validation(violations)
+}
+
+// ValidateProjectName returns the name trimmed, it cannot be empty
+func ValidateProjectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrEmptyField
+	}
+	if len(name) > 100 {
+		return "", fmt.Errorf("%s: name length should be under 100 characters", ErrInvalidLength)
+	}
+	return name, nil
+}
+
+// ValidateAllowedStatuses returns the statuses normalized without duplicates, an empty list allows all the statuses
+func ValidateAllowedStatuses(statuses entity.StatusList) (entity.StatusList, error) {
+	var allowed entity.StatusList
+	seen := make(map[entity.Status]bool)
+	for _, status := range statuses {
+		if status == "" {
+			return nil, ErrEmptyField
+		}
+		normalized, err := ValidateStatus(status)
+		if err != nil {
+			return nil, fmt.Errorf("%v: '%s'", err, status)
+		}
+		if !seen[normalized] {
+			allowed = append(allowed, normalized)
+		}
+		seen[normalized] = true
+	}
+	return allowed, nil
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+)
+
+func TestValidateProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.ProjectDescription
+		want    *entity.ProjectDescription
+		wantErr bool
+	}{
+		{
+			name: "should normalize the name and the allowed statuses",
+			req: &entity.ProjectDescription{Name: " Backend ", ProjectSettings: entity.ProjectSettings{
+				DefaultPriority: 3, AllowedStatuses: entity.StatusList{"New", "closed", "new"}}},
+			want: &entity.ProjectDescription{Name: "Backend", ProjectSettings: entity.ProjectSettings{
+				DefaultPriority: 3, AllowedStatuses: entity.StatusList{entity.New, entity.Closed}}},
+		},
+		{
+			name: "should accept a project allowing all the statuses",
+			req:  &entity.ProjectDescription{Name: "backend"},
+			want: &entity.ProjectDescription{Name: "backend"},
+		},
+		{name: "should fail because name is empty", req: &entity.ProjectDescription{Name: " "}, wantErr: true},
+		{name: "should fail because default priority is out of range", req: &entity.ProjectDescription{Name: "backend",
+			ProjectSettings: entity.ProjectSettings{DefaultPriority: 11}}, wantErr: true},
+		{name: "should fail because a status is unknown", req: &entity.ProjectDescription{Name: "backend",
+			ProjectSettings: entity.ProjectSettings{AllowedStatuses: entity.StatusList{"new", "done"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateProject(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrValidation) {
+				t.Errorf("ValidateProject() error = %v, want %v", err, errs.ErrValidation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateProject() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateParams_ProjectSettings(t *testing.T) {
+	settings := &entity.ProjectSettings{DefaultPriority: 4, AllowedStatuses: entity.StatusList{entity.Active, entity.Closed}}
+
+	// the task takes the default priority of the project and its first allowed status
+	got, err := ValidateParams(&entity.TaskDescription{Title: "title"}, settings)
+	if err != nil {
+		t.Fatalf("ValidateParams() error = %v", err)
+	}
+	if got.Priority != 4 || got.Status != entity.Active {
+		t.Errorf("ValidateParams() got priority %d and status %s, want 4 and %s", got.Priority, got.Status, entity.Active)
+	}
+	if _, err = ValidateParams(&entity.TaskDescription{Title: "title", Status: entity.OnHold}, settings); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidateParams() error = %v, want %v for a status not allowed", err, errs.ErrValidation)
+	}
+	if err = ValidatePartialParams(&entity.TaskDescription{Status: entity.New}, settings); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidatePartialParams() error = %v, want %v for a status not allowed", err, errs.ErrValidation)
+	}
+	if err = ValidatePartialParams(&entity.TaskDescription{Status: "Closed"}, settings); err != nil {
+		t.Errorf("ValidatePartialParams() error = %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+)
+
+const (
+	// DefaultLimit is the number of tasks returned in a page when no limit is requested
+	DefaultLimit = 20
+	// MaxLimit is the maximum number of tasks that can be returned in a single page
+	MaxLimit = 100
+)
+
+// SortableFields are the task fields that can be used to order the listed tasks
+var SortableFields = []string{"title", "priority", "status", "createdAt", "updatedAt", "deletedAt", "startAt", "dueAt"}
+
+// ValidateQuery validates the listing options and sets the default values for the ones that were not provided.
+// The fields of the returned errs.ValidationError are named after the query parameters of the list endpoint.
+func ValidateQuery(query *entity.TaskQuery) (*entity.TaskQuery, error) {
+	var violations []errs.Violation
+	invalid := func(field, format string, args ...interface{}) {
+		violations = append(violations, errs.Violation{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxLimit {
+		invalid("limit", "limit should be a value from 1 to %d", MaxLimit)
+	}
+	if query.Offset < 0 {
+		invalid("offset", "offset cannot be negative")
+	}
+
+	for i := range query.Statuses {
+		if query.Statuses[i] == "" {
+			invalid("status", "status filter cannot be empty")
+			continue
+		}
+		status, err := ValidateStatus(query.Statuses[i])
+		if err != nil {
+			invalid("status", "%v: '%s'", err, query.Statuses[i])
+			continue
+		}
+		query.Statuses[i] = status
+	}
+
+	if query.MinPriority != nil {
+		if err := ValidatePriority(*query.MinPriority); err != nil {
+			invalid("priorityMin", "%v", err)
+		}
+	}
+	if query.MaxPriority != nil {
+		if err := ValidatePriority(*query.MaxPriority); err != nil {
+			invalid("priorityMax", "%v", err)
+		}
+	}
+	if query.MinPriority != nil && query.MaxPriority != nil && *query.MinPriority > *query.MaxPriority {
+		invalid("priorityMin", "minimum priority is greater than maximum priority")
+	}
+
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		invalid("createdAfter", "createdAfter should be before createdBefore")
+	}
+	if query.UpdatedAfter != nil && query.UpdatedBefore != nil && !query.UpdatedAfter.Before(*query.UpdatedBefore) {
+		invalid("updatedAfter", "updatedAfter should be before updatedBefore")
+	}
+	if query.DueAfter != nil && query.DueBefore != nil && !query.DueAfter.Before(*query.DueBefore) {
+		invalid("dueAfter", "dueAfter should be before dueBefore")
+	}
+
+	if query.Assignee != "" {
+		if err := ValidateSubject(query.Assignee); err != nil {
+			invalid("assignee", "%v", err)
+		}
+		if query.Unassigned != nil && *query.Unassigned {
+			invalid("unassigned", "the tasks assigned to nobody cannot be filtered by assignee")
+		}
+	}
+
+	if len(query.Labels) > MaxLabelFilter {
+		invalid("labels", "tasks cannot be filtered by more than %d labels", MaxLabelFilter)
+	}
+	if len(query.Labels) > 0 {
+		labels := make([]string, 0, len(query.Labels))
+		for _, label := range query.Labels {
+			name, err := ValidateLabelName(label)
+			if err != nil {
+				invalid("labels", "%v: '%s'", err, label)
+			} else if !containsString(labels, name) {
+				labels = append(labels, name)
+			}
+		}
+		query.Labels = labels
+	}
+	// the labels are matched with AnyLabel when no mode is set
+	if query.LabelMode != "" && query.LabelMode != entity.AnyLabel && query.LabelMode != entity.AllLabels {
+		invalid("labelMode", "label mode should be %s or %s", entity.AnyLabel, entity.AllLabels)
+	}
+
+	if query.Keyset {
+		if query.Offset != 0 {
+			invalid("offset", "offset cannot be used together with a cursor")
+		}
+		if len(query.Sort) > 0 {
+			invalid("sort", "tasks are always sorted by creation time when using a cursor")
+		}
+	} else if query.After != nil {
+		invalid("cursor", "a position can only be given in cursor mode")
+	}
+
+	seen := make(map[string]bool)
+	for _, sort := range query.Sort {
+		if !isSortable(sort.Field) {
+			invalid("sort", "cannot sort by '%s', sortable fields are %v", sort.Field, SortableFields)
+		} else if seen[sort.Field] {
+			invalid("sort", "field '%s' is used more than once for sorting", sort.Field)
+		}
+		seen[sort.Field] = true
+	}
+
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// ValidateHistoryQuery validates the pagination of the history of a task and sets the default limit when none was requested
+func ValidateHistoryQuery(query *entity.HistoryQuery) (*entity.HistoryQuery, error) {
+	var violations []errs.Violation
+	if query.Limit == 0 {
+		query.Limit = DefaultLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxLimit {
+		violations = append(violations, errs.Violation{Field: "limit", Message: fmt.Sprintf("limit should be a value from 1 to %d", MaxLimit)})
+	}
+	if query.Offset < 0 {
+		violations = append(violations, errs.Violation{Field: "offset", Message: "offset cannot be negative"})
+	}
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// ValidateCommentQuery validates the pagination of the comments of a task and sets the default limit when none was requested
+func ValidateCommentQuery(query *entity.CommentQuery) (*entity.CommentQuery, error) {
+	var violations []errs.Violation
+	if query.Limit == 0 {
+		query.Limit = DefaultLimit
+	}
+	if query.Limit < 0 || query.Limit > MaxLimit {
+		violations = append(violations, errs.Violation{Field: "limit", Message: fmt.Sprintf("limit should be a value from 1 to %d", MaxLimit)})
+	}
+	if query.Offset < 0 {
+		violations = append(violations, errs.Violation{Field: "offset", Message: "offset cannot be negative"})
+	}
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// ValidateLimit validates the maximum number of items of a list that is not paginated, DefaultLimit is returned when it is not set
+func ValidateLimit(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultLimit, nil
+	}
+	if limit < 0 || limit > MaxLimit {
+		return 0, errs.Validation([]errs.Violation{{Field: "limit", Message: fmt.Sprintf("limit should be a value from 1 to %d", MaxLimit)}})
+	}
+	return limit, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func isSortable(field string) bool {
+	for _, f := range SortableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
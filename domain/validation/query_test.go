@@ -0,0 +1,161 @@
+package validation
+
+import (
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestValidateQuery(t *testing.T) {
+	low, high := 2, 8
+	yes := true
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	tests := []struct {
+		name    string
+		query   *entity.TaskQuery
+		want    *entity.TaskQuery
+		wantErr bool
+	}{
+		{
+			name:  "should set the default limit",
+			query: &entity.TaskQuery{},
+			want:  &entity.TaskQuery{Limit: DefaultLimit},
+		},
+		{
+			name: "should pass and normalize the status filters",
+			query: &entity.TaskQuery{
+				Limit:       10,
+				Offset:      30,
+				Statuses:    []entity.Status{"Active", "on-hold"},
+				MinPriority: &low,
+				MaxPriority: &high,
+				Sort:        []entity.SortField{{Field: "priority"}, {Field: "createdAt", Desc: true}},
+			},
+			want: &entity.TaskQuery{
+				Limit:       10,
+				Offset:      30,
+				Statuses:    []entity.Status{entity.Active, entity.OnHold},
+				MinPriority: &low,
+				MaxPriority: &high,
+				Sort:        []entity.SortField{{Field: "priority"}, {Field: "createdAt", Desc: true}},
+			},
+		},
+		{
+			name:    "should fail because limit is too big",
+			query:   &entity.TaskQuery{Limit: MaxLimit + 1},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because offset is negative",
+			query:   &entity.TaskQuery{Offset: -1},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because of invalid status filter",
+			query:   &entity.TaskQuery{Statuses: []entity.Status{"invalid"}},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because priority range is inverted",
+			query:   &entity.TaskQuery{MinPriority: &high, MaxPriority: &low},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because creation window is inverted",
+			query:   &entity.TaskQuery{CreatedAfter: &now, CreatedBefore: &earlier},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because due window is inverted",
+			query:   &entity.TaskQuery{DueAfter: &now, DueBefore: &earlier},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because field is not sortable",
+			query:   &entity.TaskQuery{Sort: []entity.SortField{{Field: "description"}}},
+			wantErr: true,
+		},
+		{
+			name:  "should pass in cursor mode",
+			query: &entity.TaskQuery{Keyset: true, After: &entity.TaskCursor{CreatedAt: now, ID: "1"}},
+			want:  &entity.TaskQuery{Limit: DefaultLimit, Keyset: true, After: &entity.TaskCursor{CreatedAt: now, ID: "1"}},
+		},
+		{
+			name:    "should fail because offset is used with a cursor",
+			query:   &entity.TaskQuery{Keyset: true, Offset: 10},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because sort is used with a cursor",
+			query:   &entity.TaskQuery{Keyset: true, Sort: []entity.SortField{{Field: "priority"}}},
+			wantErr: true,
+		},
+		{
+			name:  "should pass with an assignee",
+			query: &entity.TaskQuery{Assignee: "alice"},
+			want:  &entity.TaskQuery{Limit: DefaultLimit, Assignee: "alice"},
+		},
+		{
+			name:    "should fail because the unassigned tasks are filtered by assignee",
+			query:   &entity.TaskQuery{Assignee: "alice", Unassigned: &yes},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because field is sorted twice",
+			query:   &entity.TaskQuery{Sort: []entity.SortField{{Field: "priority"}, {Field: "priority", Desc: true}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateQuery() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateHistoryQuery(t *testing.T) {
+	got, err := ValidateHistoryQuery(&entity.HistoryQuery{Offset: 5})
+	if err != nil {
+		t.Fatalf("ValidateHistoryQuery() error = %v", err)
+	}
+	if want := (&entity.HistoryQuery{Limit: DefaultLimit, Offset: 5}); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateHistoryQuery() got = %v, want %v", got, want)
+	}
+	if _, err = ValidateHistoryQuery(&entity.HistoryQuery{Limit: MaxLimit + 1, Offset: -1}); err == nil {
+		t.Errorf("ValidateHistoryQuery() expected error for invalid limit and offset")
+	}
+}
+
+func TestValidateLimit(t *testing.T) {
+	if got, err := ValidateLimit(0); err != nil || got != DefaultLimit {
+		t.Errorf("ValidateLimit() = %d, %v, want %d", got, err, DefaultLimit)
+	}
+	if got, err := ValidateLimit(5); err != nil || got != 5 {
+		t.Errorf("ValidateLimit() = %d, %v, want 5", got, err)
+	}
+	if _, err := ValidateLimit(MaxLimit + 1); err == nil {
+		t.Errorf("ValidateLimit() expected error for limit above %d", MaxLimit)
+	}
+}
+
+func TestValidateQuery_Labels(t *testing.T) {
+	got, err := ValidateQuery(&entity.TaskQuery{Labels: []string{"Bug", "urgent", "bug"}, LabelMode: entity.AllLabels})
+	if err != nil {
+		t.Fatalf("ValidateQuery() error = %v", err)
+	}
+	if want := []string{"bug", "urgent"}; !reflect.DeepEqual(got.Labels, want) {
+		t.Errorf("ValidateQuery() got labels %v, want %v", got.Labels, want)
+	}
+	if _, err = ValidateQuery(&entity.TaskQuery{Labels: []string{"bug"}, LabelMode: "some"}); err == nil {
+		t.Errorf("ValidateQuery() expected error for invalid label mode")
+	}
+}
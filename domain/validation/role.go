@@ -0,0 +1,52 @@
+package validation
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+)
+
+// ErrInvalidRole when a role is not one of the roles that can be granted
+var ErrInvalidRole = fmt.Errorf("role should be one of %s, %s or %s", entity.Viewer, entity.Editor, entity.Admin)
+
+// ValidateRoleAssignment validates the assignment and returns it with its role normalized, the subject is kept as it is
+// since the subjects of the bearer tokens are case sensitive
+func ValidateRoleAssignment(req *entity.RoleAssignment) (*entity.RoleAssignment, error) {
+	var violations []errs.Violation
+	if err := ValidateSubject(req.Subject); err != nil {
+		violations = append(violations, errs.Violation{Field: "subject", Message: err.Error()})
+	}
+	role, err := ValidateRole(req.Role)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "role", Message: err.Error()})
+	}
+	if err = errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Role = role
+	return req, nil
+}
+
+// ValidateSubject checks the subject naming a caller: a username, the subject of a bearer token or of an ID token, or an API key
+func ValidateSubject(subject string) error {
+	if subject == "" {
+		return ErrEmptyField
+	}
+	if len(subject) > 255 || strings.TrimSpace(subject) != subject {
+		return fmt.Errorf("%w: subject should be under 255 characters without surrounding spaces", ErrInvalidLength)
+	}
+	return nil
+}
+
+// ValidateRole returns the role in lower case
+func ValidateRole(role entity.Role) (entity.Role, error) {
+	role = entity.Role(strings.ToLower(strings.TrimSpace(string(role))))
+	if role == "" {
+		return "", ErrEmptyField
+	}
+	if !role.Includes(entity.Viewer) {
+		return "", ErrInvalidRole
+	}
+	return role, nil
+}
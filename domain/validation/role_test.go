@@ -0,0 +1,50 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"testing"
+)
+
+func TestValidateRoleAssignment(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.RoleAssignment
+		role    entity.Role
+		wantErr bool
+	}{
+		{name: "should normalize the role", req: &entity.RoleAssignment{Subject: "alice", Role: " Editor "}, role: entity.Editor},
+		{name: "should keep the case of the subject", req: &entity.RoleAssignment{Subject: "Auth0|42", ProjectID: "p1", Role: "admin"}, role: entity.Admin},
+		{name: "should fail because the role is unknown", req: &entity.RoleAssignment{Subject: "alice", Role: "owner"}, wantErr: true},
+		{name: "should fail because the role is empty", req: &entity.RoleAssignment{Subject: "alice"}, wantErr: true},
+		{name: "should fail because the subject is empty", req: &entity.RoleAssignment{Role: "viewer"}, wantErr: true},
+		{name: "should fail because the subject has surrounding spaces", req: &entity.RoleAssignment{Subject: " alice", Role: "viewer"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateRoleAssignment(tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, errs.ErrValidation) {
+					t.Errorf("ValidateRoleAssignment() error = %v, want %v", err, errs.ErrValidation)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateRoleAssignment() error = %v", err)
+			}
+			if got.Role != tt.role {
+				t.Errorf("ValidateRoleAssignment() role = %v, want %v", got.Role, tt.role)
+			}
+		})
+	}
+}
+
+func TestRole_Includes(t *testing.T) {
+	if !entity.Admin.Includes(entity.Editor) || !entity.Editor.Includes(entity.Viewer) || !entity.Viewer.Includes(entity.Viewer) {
+		t.Errorf("Includes() should allow the lower roles")
+	}
+	if entity.Viewer.Includes(entity.Editor) || entity.Role("owner").Includes(entity.Viewer) || entity.Role("").Includes("") {
+		t.Errorf("Includes() should not allow the higher or unknown roles")
+	}
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"regexp"
+	"strings"
+)
+
+const (
+	// MinPasswordLength is the minimum number of characters of a password
+	MinPasswordLength = 8
+	// MaxPasswordLength is the maximum number of bytes of a password, bcrypt ignores the bytes after the 72th
+	MaxPasswordLength = 72
+)
+
+// ErrInvalidUsername when a username contains other characters than the ones allowed
+var ErrInvalidUsername = errors.New("username can only contain letters, digits and the characters . _ - @")
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9._@-]+$`)
+
+// ValidateNewUser validates the username and the password of a new user and returns the request with the username normalized
+func ValidateNewUser(req *entity.NewUser) (*entity.NewUser, error) {
+	var violations []errs.Violation
+	username, err := ValidateUsername(req.Username)
+	if err != nil {
+		violations = append(violations, errs.Violation{Field: "username", Message: err.Error()})
+	}
+	if err = ValidatePassword(req.Password); err != nil {
+		violations = append(violations, errs.Violation{Field: "password", Message: err.Error()})
+	}
+	if err = errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Username = username
+	return req, nil
+}
+
+// ValidateUsername returns the username normalized, usernames are compared without case so "Alice" and "alice" are the same user
+func ValidateUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username == "" {
+		return "", ErrEmptyField
+	}
+	if len(username) < 3 || len(username) > 64 {
+		return "", fmt.Errorf("%s: username length should be from 3 to 64 characters", ErrInvalidLength)
+	}
+	if !usernamePattern.MatchString(username) {
+		return "", ErrInvalidUsername
+	}
+	return username, nil
+}
+
+// ValidatePassword checks the length of a password, it is not trimmed since spaces may be part of it
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLength {
+		return fmt.Errorf("%s: password should have at least %d characters", ErrInvalidLength, MinPasswordLength)
+	}
+	if len(password) > MaxPasswordLength {
+		return fmt.Errorf("%s: password should be under %d bytes", ErrInvalidLength, MaxPasswordLength)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package validation
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+	"testing"
+)
+
+func TestValidateNewUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *entity.NewUser
+		username string
+		wantErr  bool
+	}{
+		{
+			name:     "should normalize the username",
+			req:      &entity.NewUser{UserDescription: entity.UserDescription{Username: " Alice@Example.com "}, Password: "correct horse"},
+			username: "alice@example.com",
+		},
+		{name: "should fail because username is empty", req: &entity.NewUser{Password: "correct horse"}, wantErr: true},
+		{name: "should fail because username is too short", req: &entity.NewUser{UserDescription: entity.UserDescription{Username: "al"}, Password: "correct horse"}, wantErr: true},
+		{name: "should fail because username contains a space", req: &entity.NewUser{UserDescription: entity.UserDescription{Username: "al ice"}, Password: "correct horse"}, wantErr: true},
+		{name: "should fail because password is too short", req: &entity.NewUser{UserDescription: entity.UserDescription{Username: "alice"}, Password: "horse"}, wantErr: true},
+		{name: "should fail because password is too long", req: &entity.NewUser{UserDescription: entity.UserDescription{Username: "alice"}, Password: strings.Repeat("x", 73)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateNewUser(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateNewUser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, errs.ErrValidation) {
+					t.Errorf("ValidateNewUser() error = %v, want %v", err, errs.ErrValidation)
+				}
+				return
+			}
+			if got.Username != tt.username {
+				t.Errorf("ValidateNewUser() username = %v, want %v", got.Username, tt.username)
+			}
+		})
+	}
+}
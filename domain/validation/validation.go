@@ -4,7 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/recurrence"
 	"strings"
+	"time"
 )
 
 var (
@@ -12,32 +15,112 @@ var (
 	ErrEmptyField = errors.New("field cannot be empty")
 	// ErrInvalidLength when the content of the field is invalid
 	ErrInvalidLength = errors.New("field length is invalid")
+	// ErrInvalidSchedule when a task is due before it starts
+	ErrInvalidSchedule = errors.New("due time cannot be before start time")
+	// ErrRecurrenceWithoutDue when a recurring task has no due time to compute its next occurrences from
+	ErrRecurrenceWithoutDue = errors.New("a recurring task needs a due time")
+	// ErrInvalidTimezone when the time zone is not an IANA time zone name
+	ErrInvalidTimezone = errors.New("time zone should be an IANA time zone name such as Europe/Paris")
 )
 
-// ValidateParams Validates the parameters given in the request, req is returned also in case in the future we want to set some default values here
-func ValidateParams(req *entity.TaskDescription) (*entity.TaskDescription, error) {
-	err := ValidateTitle(req.Title)
-	if err != nil {
-		return nil, fmt.Errorf("invalid title: %v", err)
+// ValidateParams Validates the parameters given in the request, req is returned with the default values of the project set.
+// All the fields are checked, so the returned errs.ValidationError lists every violation rather than only the first one.
+// The settings of the project of the task give the default priority and the allowed statuses, nothing is restricted when they are nil.
+func ValidateParams(req *entity.TaskDescription, settings *entity.ProjectSettings) (*entity.TaskDescription, error) {
+	var violations []errs.Violation
+	if req.Priority == 0 && settings != nil {
+		req.Priority = settings.DefaultPriority
 	}
-
-	err = ValidateDescription(req.Description)
-	if err != nil {
-		return nil, fmt.Errorf("invalid description: %v", err)
+	if err := ValidateTitle(req.Title); err != nil {
+		violations = append(violations, errs.Violation{Field: "title", Message: err.Error()})
 	}
-
-	err = ValidatePriority(req.Priority)
-	if err != nil {
-		return nil, fmt.Errorf("invalid priority: %v", err)
+	if err := ValidateDescription(req.Description); err != nil {
+		violations = append(violations, errs.Violation{Field: "description", Message: err.Error()})
 	}
-
-	req.Status, err = ValidateStatus(req.Status)
+	if err := ValidatePriority(req.Priority); err != nil {
+		violations = append(violations, errs.Violation{Field: "priority", Message: err.Error()})
+	}
+	status, err := ValidateStatus(req.Status)
 	if err != nil {
-		return nil, fmt.Errorf("invalid status: %v", err)
+		violations = append(violations, errs.Violation{Field: "status", Message: err.Error()})
+	} else if req.Status == "" && settings != nil && !settings.Allows(status) {
+		// the tasks of a project not allowing new ones start in its first allowed status
+		status = settings.AllowedStatuses[0]
+	} else if err = ValidateAllowedStatus(status, settings); err != nil {
+		violations = append(violations, errs.Violation{Field: "status", Message: err.Error()})
+	}
+	req.StartAt, req.DueAt = NormalizeTime(req.StartAt), NormalizeTime(req.DueAt)
+	if err := ValidateSchedule(req.StartAt, req.DueAt); err != nil {
+		violations = append(violations, errs.Violation{Field: "dueAt", Message: err.Error()})
+	}
+	if req.Recurrence != "" {
+		rule, err := ValidateRecurrence(req.Recurrence)
+		if err != nil {
+			violations = append(violations, errs.Violation{Field: "recurrence", Message: err.Error()})
+		} else if req.DueAt == nil {
+			violations = append(violations, errs.Violation{Field: "recurrence", Message: ErrRecurrenceWithoutDue.Error()})
+		}
+		req.Recurrence = rule
+	}
+	if err := ValidateTimezone(req.Timezone); err != nil {
+		violations = append(violations, errs.Violation{Field: "timezone", Message: err.Error()})
 	}
+	if err := errs.Validation(violations); err != nil {
+		return nil, err
+	}
+	req.Status = status
 	return req, nil
 }
 
+// ValidatePartialParams validates only the fields that are set in the request, the empty ones are left unchanged by a partial update.
+// The status of the request is normalized like in ValidateParams, and checked against the settings of the project if they are not nil.
+func ValidatePartialParams(req *entity.TaskDescription, settings *entity.ProjectSettings) error {
+	var violations []errs.Violation
+	if req.Title != "" {
+		if err := ValidateTitle(req.Title); err != nil {
+			violations = append(violations, errs.Violation{Field: "title", Message: err.Error()})
+		}
+	}
+	if req.Description != "" {
+		if err := ValidateDescription(req.Description); err != nil {
+			violations = append(violations, errs.Violation{Field: "description", Message: err.Error()})
+		}
+	}
+	if req.Priority != 0 {
+		if err := ValidatePriority(req.Priority); err != nil {
+			violations = append(violations, errs.Violation{Field: "priority", Message: err.Error()})
+		}
+	}
+	if req.Status != "" {
+		status, err := ValidateStatus(req.Status)
+		if err == nil {
+			err = ValidateAllowedStatus(status, settings)
+		}
+		if err != nil {
+			violations = append(violations, errs.Violation{Field: "status", Message: err.Error()})
+		} else {
+			req.Status = status
+		}
+	}
+	// the dates are only checked against each other here when both are set, the service checks them against the stored ones otherwise
+	req.StartAt, req.DueAt = NormalizeTime(req.StartAt), NormalizeTime(req.DueAt)
+	if err := ValidateSchedule(req.StartAt, req.DueAt); err != nil {
+		violations = append(violations, errs.Violation{Field: "dueAt", Message: err.Error()})
+	}
+	if req.Recurrence != "" {
+		// the due time may be the stored one, the service checks that there is one
+		rule, err := ValidateRecurrence(req.Recurrence)
+		if err != nil {
+			violations = append(violations, errs.Violation{Field: "recurrence", Message: err.Error()})
+		}
+		req.Recurrence = rule
+	}
+	if err := ValidateTimezone(req.Timezone); err != nil {
+		violations = append(violations, errs.Violation{Field: "timezone", Message: err.Error()})
+	}
+	return errs.Validation(violations)
+}
+
 func ValidatePriority(priority int) error {
 	if priority < 0 || priority > 10 {
 		return fmt.Errorf("invalid priority range, should be a value from 0 to 10")
@@ -62,6 +145,53 @@ func ValidateTitle(title string) error {
 	return nil
 }
 
+// ValidateSchedule checks that a task is not due before it starts. The times are compared as instants,
+// so a start and a due time given in different time zones are ordered correctly. Unset times are not checked.
+func ValidateSchedule(startAt, dueAt *time.Time) error {
+	if startAt != nil && dueAt != nil && dueAt.Before(*startAt) {
+		return ErrInvalidSchedule
+	}
+	return nil
+}
+
+// ValidateRecurrence checks the recurrence rule of a task and returns it in its canonical form
+func ValidateRecurrence(rule string) (string, error) {
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// ValidateTimezone checks that the time zone is known, the empty one standing for UTC.
+// Local is refused since it would depend on the time zone of the server.
+func ValidateTimezone(name string) error {
+	if name == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// NormalizeTime converts the time to UTC, so that the stored times do not depend on the time zone of the client that set them
+func NormalizeTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// ValidateAllowedStatus checks that the tasks of the project can have the status, any status is allowed when settings is nil
+func ValidateAllowedStatus(status entity.Status, settings *entity.ProjectSettings) error {
+	if settings != nil && !settings.Allows(status) {
+		return fmt.Errorf("status '%s' is not allowed in the project, allowed statuses are %v", status, settings.AllowedStatuses)
+	}
+	return nil
+}
+
 func ValidateStatus(status entity.Status) (entity.Status, error) {
 	if status == "" {
 		return entity.New, nil
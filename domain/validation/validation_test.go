@@ -1,9 +1,12 @@
 package validation
 
 import (
+	"errors"
 	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestValidateParams(t *testing.T) {
@@ -54,10 +57,48 @@ func TestValidateParams(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "should succeed because of a known time zone",
+			args: args{req: &entity.TaskDescription{
+				Title:    "test",
+				Priority: 5,
+				Status:   "new",
+				Timezone: "Europe/Paris",
+			}},
+			want: &entity.TaskDescription{
+				Title:    "test",
+				Priority: 5,
+				Status:   "new",
+				Timezone: "Europe/Paris",
+			},
+			wantErr: false,
+		},
+		{
+			name: "should fail because of an unknown time zone",
+			args: args{req: &entity.TaskDescription{
+				Title:    "test",
+				Priority: 5,
+				Status:   "new",
+				Timezone: "Mars/Olympus_Mons",
+			}},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "should fail because of the time zone of the server",
+			args: args{req: &entity.TaskDescription{
+				Title:    "test",
+				Priority: 5,
+				Status:   "new",
+				Timezone: "Local",
+			}},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ValidateParams(tt.args.req)
+			got, err := ValidateParams(tt.args.req, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ValidateParams() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -192,3 +233,102 @@ func TestValidateStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateParams_CollectsAllViolations(t *testing.T) {
+	_, err := ValidateParams(&entity.TaskDescription{
+		Title:       "",
+		Description: string(make([]rune, 505)),
+		Priority:    50,
+		Status:      "invalid",
+	}, nil)
+	var validationErr *errs.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("ValidateParams() error = %v, want a validation error", err)
+	}
+	var fields []string
+	for _, v := range validationErr.Violations {
+		fields = append(fields, v.Field)
+	}
+	want := []string{"title", "description", "priority", "status"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("ValidateParams() violations on %v, want %v", fields, want)
+	}
+}
+
+func TestValidatePartialParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *entity.TaskDescription
+		wantErr bool
+	}{
+		{
+			name:    "should pass because empty fields are not updated",
+			req:     &entity.TaskDescription{Priority: 3},
+			wantErr: false,
+		},
+		{
+			name:    "should fail because of invalid priority",
+			req:     &entity.TaskDescription{Title: "title", Priority: 11},
+			wantErr: true,
+		},
+		{
+			name:    "should fail because of invalid status",
+			req:     &entity.TaskDescription{Status: "done"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePartialParams(tt.req, nil); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePartialParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	req := &entity.TaskDescription{Status: "On-Hold"}
+	if err := ValidatePartialParams(req, nil); err != nil || req.Status != entity.OnHold {
+		t.Errorf("ValidatePartialParams() got status %s with error %v, want %s", req.Status, err, entity.OnHold)
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	// 10:30 in Paris is 09:30 UTC in winter, so a task starting at 10:30 Paris time and due at 10:00 UTC is valid
+	startAt := time.Date(2022, time.January, 10, 10, 30, 0, 0, paris)
+	dueAt := time.Date(2022, time.January, 10, 10, 0, 0, 0, time.UTC)
+	if err := ValidateSchedule(&startAt, &dueAt); err != nil {
+		t.Errorf("ValidateSchedule() error = %v, want nil", err)
+	}
+	earlier := dueAt.Add(-2 * time.Hour)
+	if err := ValidateSchedule(&startAt, &earlier); !errors.Is(err, ErrInvalidSchedule) {
+		t.Errorf("ValidateSchedule() error = %v, want %v", err, ErrInvalidSchedule)
+	}
+	if err := ValidateSchedule(nil, &earlier); err != nil {
+		t.Errorf("ValidateSchedule() error = %v, want nil when the start is not set", err)
+	}
+
+	req := &entity.TaskDescription{Title: "title", Priority: 1, StartAt: &startAt, DueAt: &dueAt}
+	if _, err := ValidateParams(req, nil); err != nil {
+		t.Fatalf("ValidateParams() error = %v", err)
+	}
+	if req.StartAt.Location() != time.UTC || !req.StartAt.Equal(startAt) {
+		t.Errorf("ValidateParams() got start %v, want %v in UTC", req.StartAt, startAt)
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	dueAt := time.Date(2022, time.January, 10, 9, 0, 0, 0, time.UTC)
+	req := &entity.TaskDescription{Title: "title", Priority: 1, DueAt: &dueAt, Recurrence: "rrule:freq=weekly;byday=mo"}
+	if _, err := ValidateParams(req, nil); err != nil || req.Recurrence != "FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("ValidateParams() got recurrence %s with error %v, want the canonical rule", req.Recurrence, err)
+	}
+	if _, err := ValidateParams(&entity.TaskDescription{Title: "title", Priority: 1, Recurrence: "FREQ=DAILY"}, nil); !errors.Is(err, errs.ErrValidation) {
+		t.Errorf("ValidateParams() error = %v, want %v for a recurring task without due time", err, errs.ErrValidation)
+	}
+	if _, err := ValidateRecurrence("FREQ=HOURLY"); err == nil {
+		t.Errorf("ValidateRecurrence() expected error for unsupported frequency")
+	}
+}
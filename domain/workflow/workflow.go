@@ -0,0 +1,128 @@
+// Package workflow defines the transitions allowed between the statuses of a task
+package workflow
+
+import (
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"strings"
+)
+
+// Statuses are all the statuses a task can have, in the order they are presented to the clients
+var Statuses = []entity.Status{entity.New, entity.Active, entity.OnHold, entity.Closed}
+
+// DefaultTransitions is the graph used when none is configured: work can be started, paused and finished,
+// and a closed task can only be reopened as active rather than going back to new.
+var DefaultTransitions = map[entity.Status][]entity.Status{
+	entity.New:    {entity.Active, entity.OnHold, entity.Closed},
+	entity.Active: {entity.OnHold, entity.Closed},
+	entity.OnHold: {entity.Active, entity.Closed},
+	entity.Closed: {entity.Active},
+}
+
+// BlockedStatuses are the statuses a task cannot move to while some of its blockers are open: the work on it can neither start nor end
+var BlockedStatuses = []entity.Status{entity.Active, entity.Closed}
+
+// Workflow is the graph of the transitions allowed between the statuses of a task.
+// Keeping the same status is always allowed, so that a task can be updated without moving it.
+type Workflow struct {
+	transitions map[entity.Status][]entity.Status
+}
+
+// New returns the workflow allowing the given transitions, every status of the graph must be a known one
+func New(transitions map[entity.Status][]entity.Status) (*Workflow, error) {
+	graph := make(map[entity.Status][]entity.Status, len(Statuses))
+	for _, status := range Statuses {
+		graph[status] = []entity.Status{}
+	}
+	for from, targets := range transitions {
+		if !isStatus(from) {
+			return nil, fmt.Errorf("unknown status '%s' in workflow", from)
+		}
+		for _, to := range targets {
+			if !isStatus(to) {
+				return nil, fmt.Errorf("unknown status '%s' in workflow", to)
+			}
+			if to != from && !contains(graph[from], to) {
+				graph[from] = append(graph[from], to)
+			}
+		}
+	}
+	return &Workflow{transitions: graph}, nil
+}
+
+// Default returns the workflow allowing the DefaultTransitions
+func Default() *Workflow {
+	w, err := New(DefaultTransitions)
+	if err != nil {
+		panic(err) // the default transitions only use known statuses
+	}
+	return w
+}
+
+// Parse returns the workflow described by spec, e.g. "new:active,closed;active:closed" allows new to go to active or closed
+// and active to go to closed, while closed is final. An empty spec returns the default workflow.
+func Parse(spec string) (*Workflow, error) {
+	if strings.TrimSpace(spec) == "" {
+		return Default(), nil
+	}
+	transitions := make(map[entity.Status][]entity.Status)
+	for _, rule := range strings.Split(spec, ";") {
+		if strings.TrimSpace(rule) == "" {
+			continue
+		}
+		from, targets, found := strings.Cut(rule, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid workflow rule '%s', expected from:to1,to2", rule)
+		}
+		status := entity.Status(strings.TrimSpace(from))
+		if _, ok := transitions[status]; !ok {
+			transitions[status] = nil // a status without targets is final, it is kept so that it is still checked
+		}
+		for _, to := range strings.Split(targets, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				transitions[status] = append(transitions[status], entity.Status(to))
+			}
+		}
+	}
+	return New(transitions)
+}
+
+// Next returns the statuses a task can move to from the given one, not including the status itself
+func (w *Workflow) Next(from entity.Status) []entity.Status {
+	return w.transitions[from]
+}
+
+// Transitions returns the whole graph, each status being mapped to the statuses it can move to
+func (w *Workflow) Transitions() map[entity.Status][]entity.Status {
+	return w.transitions
+}
+
+// Check returns an errs.ErrConflict error if a task cannot move from one status to the other
+func (w *Workflow) Check(from, to entity.Status) error {
+	if from == to || contains(w.transitions[from], to) {
+		return nil
+	}
+	return errs.New(errs.ErrConflict, "task cannot move from status '%s' to '%s', allowed next statuses are %v", from, to, w.transitions[from])
+}
+
+// CheckBlockers returns an errs.ErrConflict error if a task cannot move to the status because of its open blockers
+func (w *Workflow) CheckBlockers(to entity.Status, openBlockers int64) error {
+	if openBlockers > 0 && contains(BlockedStatuses, to) {
+		return errs.New(errs.ErrConflict, "task cannot move to status '%s' while it is blocked by %d open tasks", to, openBlockers)
+	}
+	return nil
+}
+
+func isStatus(status entity.Status) bool {
+	return contains(Statuses, status)
+}
+
+func contains(statuses []entity.Status, status entity.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
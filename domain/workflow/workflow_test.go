@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"reflect"
+	"testing"
+)
+
+func TestWorkflow_Check(t *testing.T) {
+	w := Default()
+	tests := []struct {
+		name    string
+		from    entity.Status
+		to      entity.Status
+		wantErr bool
+	}{
+		{name: "should allow starting a new task", from: entity.New, to: entity.Active},
+		{name: "should allow keeping the same status", from: entity.Closed, to: entity.Closed},
+		{name: "should allow reopening a closed task", from: entity.Closed, to: entity.Active},
+		{name: "should refuse moving a closed task back to new", from: entity.Closed, to: entity.New, wantErr: true},
+		{name: "should refuse moving an active task back to new", from: entity.Active, to: entity.New, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := w.Check(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, errs.ErrConflict) {
+				t.Errorf("Check() error = %v, want %v", err, errs.ErrConflict)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	w, err := Parse("new: active, closed ; active:closed;closed:")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := map[entity.Status][]entity.Status{
+		entity.New:    {entity.Active, entity.Closed},
+		entity.Active: {entity.Closed},
+		entity.OnHold: {},
+		entity.Closed: {},
+	}
+	if !reflect.DeepEqual(w.Transitions(), want) {
+		t.Errorf("Parse() got = %v, want %v", w.Transitions(), want)
+	}
+
+	if w, err = Parse(""); err != nil || !reflect.DeepEqual(w, Default()) {
+		t.Errorf("Parse() got = %v, %v, want the default workflow", w, err)
+	}
+	for _, spec := range []string{"new", "new:done", "archived:new"} {
+		if _, err = Parse(spec); err == nil {
+			t.Errorf("Parse(%s) expected error", spec)
+		}
+	}
+}
+
+func TestWorkflow_CheckBlockers(t *testing.T) {
+	w := Default()
+	if err := w.CheckBlockers(entity.Active, 2); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("CheckBlockers() error = %v, want %v for a blocked task started", err, errs.ErrConflict)
+	}
+	if err := w.CheckBlockers(entity.Closed, 1); !errors.Is(err, errs.ErrConflict) {
+		t.Errorf("CheckBlockers() error = %v, want %v for a blocked task closed", err, errs.ErrConflict)
+	}
+	if err := w.CheckBlockers(entity.OnHold, 1); err != nil {
+		t.Errorf("CheckBlockers() error = %v, want a blocked task to be put on hold", err)
+	}
+	if err := w.CheckBlockers(entity.Closed, 0); err != nil {
+		t.Errorf("CheckBlockers() error = %v, want a task without open blockers to be closed", err)
+	}
+}
@@ -0,0 +1,21 @@
+// Package blob implements the stores of the content of the files attached to the tasks: a directory of the local filesystem,
+// or a bucket of a service speaking the S3 API such as AWS S3 or MinIO.
+package blob
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkKey refuses the keys that are empty or have empty, "." or ".." segments, so that a key cannot name a blob outside of the store
+func checkKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("empty blob key")
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsRune(segment, '\\') {
+			return fmt.Errorf("invalid blob key '%s'", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore keeps the blobs as files of a directory, a key being the path of its file relative to the directory.
+// It suits a single replica or replicas sharing a volume, it implements interfaces.BlobStore.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore returns the store of the directory, which is created if it does not exist
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the blob directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// Put writes the content to a temporary file that is renamed once its size and checksum are verified,
+// so that a blob is never read while it is written and a failed upload leaves nothing behind
+func (l *LocalStore) Put(ctx context.Context, key string, content io.Reader, size int64, checksum string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// removing the temporary file fails once it is renamed, which is expected
+		_ = os.Remove(tmp.Name())
+	}()
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(tmp, hash), content)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size || hex.EncodeToString(hash.Sum(nil)) != checksum {
+		return fmt.Errorf("content of blob '%s' does not match its size or checksum", key)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Get opens the file of the blob, errs.ErrNotFound is returned if there is none
+func (l *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errs.New(errs.ErrNotFound, "could not find blob '%s'", key)
+	}
+	return f, err
+}
+
+// Delete removes the file of the blob, the directories are kept
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path returns the path of the file of the blob
+func (l *LocalStore) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
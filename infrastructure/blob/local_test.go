@@ -0,0 +1,69 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	l, err := NewLocalStore(filepath.Join(dir, "attachments"))
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+	content := "2024-03-01 panic: runtime error"
+
+	if err = l.Put(ctx, "acme/1/a1", strings.NewReader(content), int64(len(content)), checksum(content)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	r, err := l.Get(ctx, "acme/1/a1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(got) != content {
+		t.Errorf("Get() got = %s, want %s", got, content)
+	}
+
+	// a corrupted upload is refused and does not replace the blob
+	if err = l.Put(ctx, "acme/1/a1", strings.NewReader("corrupted"), int64(len(content)), checksum(content)); err == nil {
+		t.Errorf("Put() expected error for content not matching its checksum")
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "attachments", "acme", "1", "a1")); string(got) != content {
+		t.Errorf("Put() replaced the blob with %s after a failed upload", got)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "attachments", "acme", "1")); len(entries) != 1 {
+		t.Errorf("Put() left %d files behind, want only the blob", len(entries))
+	}
+
+	for _, key := range []string{"", "../escape", "acme//a1", "acme/./a1", `acme\..\a1`} {
+		if err = l.Put(ctx, key, strings.NewReader(content), int64(len(content)), checksum(content)); err == nil {
+			t.Errorf("Put() expected error for key %q", key)
+		}
+	}
+
+	if err = l.Delete(ctx, "acme/1/a1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = l.Get(ctx, "acme/1/a1"); !errors.Is(err, errs.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v after the deletion", err, errs.ErrNotFound)
+	}
+	if err = l.Delete(ctx, "acme/1/a1"); err != nil {
+		t.Errorf("Delete() error = %v for a blob that does not exist", err)
+	}
+}
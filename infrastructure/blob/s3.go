@@ -0,0 +1,208 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// emptyPayloadHash is the SHA-256 of an empty body, the payload hash of the requests without content
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// maxErrorSize is the maximum size of the error documents of the service that are read into the errors
+	maxErrorSize = 1 << 10
+)
+
+// S3Store keeps the blobs as the objects of a bucket of a service speaking the S3 API, a key being the key of its object.
+// The requests are signed with AWS Signature Version 4, it implements interfaces.BlobStore.
+type S3Store struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	pathStyle bool
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Store returns the store of the bucket of the config, the bucket is not checked until the first request
+func NewS3Store(conf config.AttachmentConfig, client *http.Client) (*S3Store, error) {
+	endpoint, err := url.Parse(conf.S3Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("the S3 endpoint should be an http or https URL")
+	}
+	if conf.S3Bucket == "" || conf.S3Region == "" || conf.S3AccessKey == "" || conf.S3SecretKey == "" {
+		return nil, fmt.Errorf("the S3 bucket, region, access key and secret key must be set")
+	}
+	return &S3Store{endpoint: endpoint, region: conf.S3Region, bucket: conf.S3Bucket, accessKey: conf.S3AccessKey, secretKey: conf.S3SecretKey,
+		pathStyle: conf.S3PathStyle, client: client, now: time.Now}, nil
+}
+
+// Put uploads the content as the object of the key, the checksum is sent as the payload hash so that the service refuses a corrupted upload
+func (s *S3Store) Put(ctx context.Context, key string, content io.Reader, size int64, checksum string) error {
+	req, err := s.request(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	res, err := s.do(req, checksum)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	return s.check(res, key)
+}
+
+// Get downloads the object of the key, errs.ErrNotFound is returned if there is none
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.check(res, key); err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Delete removes the object of the key, the service does not fail when there is none
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return s.check(res, key)
+}
+
+// request returns the request on the object of the key, the bucket is named in the path or in the host name depending on the config
+func (s *S3Store) request(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path, u.RawPath = base+"/"+s.bucket+"/"+key, uriEncode(base+"/"+s.bucket+"/"+key)
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path, u.RawPath = base+"/"+key, uriEncode(base+"/"+key)
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs and sends the request, the failures to reach the service are errs.ErrUnavailable errors
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash)
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, errs.Wrap(errs.ErrUnavailable, err)
+	}
+	return res, nil
+}
+
+// check returns the error matching the status of the response, the error document of the service is included in the errors
+func (s *S3Store) check(res *http.Response, key string) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+	document, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorSize))
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return errs.New(errs.ErrNotFound, "could not find blob '%s': %s", key, document)
+	case res.StatusCode >= 500:
+		return errs.New(errs.ErrUnavailable, "S3 service answered %d: %s", res.StatusCode, document)
+	}
+	return fmt.Errorf("S3 service answered %d: %s", res.StatusCode, document)
+}
+
+// sign adds the Signature Version 4 of the request to its Authorization header. The host and all the headers set on the request are signed,
+// the payload hash is the hexadecimal SHA-256 of the body. The requests of the store have no query string.
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	date, day := now.Format("20060102T150405Z"), now.Format("20060102")
+	req.Header.Set("X-Amz-Date", date)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	values := map[string]string{"host": req.URL.Host}
+	for name, v := range req.Header {
+		values[strings.ToLower(name)] = canonicalValue(v)
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var headers strings.Builder
+	for _, name := range names {
+		headers.WriteString(name + ":" + values[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), "", headers.String(), signedHeaders, payloadHash}, "\n")
+	scope := day + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + hexSHA256(canonicalRequest)
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), day)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalValue joins the values of a header with commas, trimmed and with their sequences of spaces collapsed
+func canonicalValue(values []string) string {
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.Join(strings.Fields(v), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+// uriEncode percent-encodes every byte of the path but the unreserved characters and the slashes, as the signature expects
+func uriEncode(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '/' || c == '-' || c == '_' || c == '.' || c == '~' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
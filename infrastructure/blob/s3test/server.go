@@ -0,0 +1,168 @@
+// Package s3test provides a stand-in of an S3-compatible service, so that the S3 blob store can be tested without a bucket
+package s3test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Region is the region the requests to the stand-in are signed for
+const Region = "us-east-1"
+
+// maxClockSkew is how far the date of a request can be from the clock of the stand-in, as for AWS S3
+const maxClockSkew = 15 * time.Minute
+
+// Server is a stand-in of an S3-compatible service holding a single bucket in memory, addressed in the path of the requests.
+// Like the service, it refuses the requests whose Signature Version 4 does not match the secret of the access key,
+// and the uploads whose content does not match their payload hash.
+type Server struct {
+	*httptest.Server
+	Bucket    string
+	AccessKey string
+	SecretKey string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// NewServer starts a stand-in holding the empty bucket, for the requests signed with the access key
+func NewServer(bucket string, accessKey string, secretKey string) *Server {
+	s := &Server{Bucket: bucket, AccessKey: accessKey, SecretKey: secretKey, objects: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// Object returns the content of the object of the key, ok is false if there is none
+func (s *Server) Object(key string) (content []byte, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok = s.objects[key]
+	return content, ok
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if code, err := s.verify(r); err != nil {
+		writeError(w, http.StatusForbidden, code, err.Error())
+		return
+	}
+	rawPath, _, _ := strings.Cut(r.RequestURI, "?")
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.Bucket {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", fmt.Sprintf("the bucket of %s does not exist", rawPath))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		content, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+		if sum := sha256.Sum256(content); hex.EncodeToString(sum[:]) != r.Header.Get("X-Amz-Content-Sha256") {
+			writeError(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch", "the content does not match its payload hash")
+			return
+		}
+		s.objects[key] = content
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		content, ok := s.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", fmt.Sprintf("the object %s does not exist", key))
+			return
+		}
+		_, _ = w.Write(content)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// verify computes the signature of the request from what was received and compares it to the one of its Authorization header
+func (s *Server) verify(r *http.Request) (code string, err error) {
+	credential, signedHeaders, signature, ok := parseAuthorization(r.Header.Get("Authorization"))
+	if !ok {
+		return "AccessDenied", fmt.Errorf("missing or malformed Authorization header")
+	}
+	parts := strings.Split(credential, "/")
+	if len(parts) != 5 || parts[0] != s.AccessKey || parts[2] != Region || parts[3] != "s3" || parts[4] != "aws4_request" {
+		return "InvalidAccessKeyId", fmt.Errorf("unknown credential %s", credential)
+	}
+	date, err := time.Parse("20060102T150405Z", r.Header.Get("X-Amz-Date"))
+	if err != nil || date.Format("20060102") != parts[1] {
+		return "AccessDenied", fmt.Errorf("missing or invalid X-Amz-Date")
+	}
+	if skew := time.Since(date); skew > maxClockSkew || skew < -maxClockSkew {
+		return "RequestTimeTooSkewed", fmt.Errorf("the request was signed at %s", date)
+	}
+
+	names := strings.Split(signedHeaders, ";")
+	if !sort.StringsAreSorted(names) || names[0] == "" {
+		return "AccessDenied", fmt.Errorf("the signed headers are not sorted")
+	}
+	var headers strings.Builder
+	for _, name := range names {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+	if !strings.Contains(";"+signedHeaders+";", ";x-amz-content-sha256;") || !strings.Contains(";"+signedHeaders+";", ";host;") {
+		return "AccessDenied", fmt.Errorf("the host and the payload hash must be signed")
+	}
+	rawPath, rawQuery, _ := strings.Cut(r.RequestURI, "?")
+	canonicalRequest := strings.Join([]string{r.Method, rawPath, rawQuery, headers.String(), signedHeaders, r.Header.Get("X-Amz-Content-Sha256")}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", r.Header.Get("X-Amz-Date"), strings.Join(parts[1:], "/"), hex.EncodeToString(hash[:])}, "\n")
+	key := []byte("AWS4" + s.SecretKey)
+	for _, part := range append(parts[1:], stringToSign) {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature)) {
+		return "SignatureDoesNotMatch", fmt.Errorf("the signature does not match")
+	}
+	return "", nil
+}
+
+// parseAuthorization returns the members of a Signature Version 4 Authorization header
+func parseAuthorization(header string) (credential string, signedHeaders string, signature string, ok bool) {
+	if !strings.HasPrefix(header, "AWS4-HMAC-SHA256 ") {
+		return "", "", "", false
+	}
+	for _, param := range strings.Split(strings.TrimPrefix(header, "AWS4-HMAC-SHA256 "), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "Credential":
+			credential = value
+		case "SignedHeaders":
+			signedHeaders = value
+		case "Signature":
+			signature = value
+		}
+	}
+	return credential, signedHeaders, signature, credential != "" && signedHeaders != "" && signature != ""
+}
+
+// writeError writes the error document of the S3 API
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<Error><Code>%s</Code><Message>%s</Message></Error>", code, html.EscapeString(message))
+}
@@ -49,10 +49,13 @@ func (d *Database) SetDBConn() error {
 	}
 
 	// AutoMigrate will create tables, missing foreign keys, constraints, columns and indexes. You can give it multiple structs.
-	err = db.AutoMigrate(&entity.Task{})
+	err = db.AutoMigrate(&entity.Project{}, &entity.Task{}, &entity.TaskEvent{}, &entity.TaskDependency{}, &entity.Label{}, &entity.TaskLabel{}, &entity.User{}, &entity.RoleAssignment{}, &entity.APIKey{}, &entity.Session{}, &entity.LoginAttempt{}, &entity.Comment{}, &entity.CommentRevision{}, &entity.Attachment{}, &entity.ChecklistItem{})
 	if err != nil {
 		return err
 	}
+	if err = migrateTenants(db); err != nil {
+		return fmt.Errorf("failed to migrate the tenants: %w", err)
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -73,6 +76,28 @@ func (d *Database) SetDBConn() error {
 	return nil
 }
 
+// migrateTenants upgrades a schema created before the tenants existed, AutoMigrate adds the tenant columns but leaves the keys as they were.
+// The names of the projects and labels become unique per tenant, and the projects are keyed by tenant since every tenant has its default project.
+func migrateTenants(db *gorm.DB) error {
+	for _, index := range []struct {
+		model interface{}
+		name  string
+	}{{&entity.Project{}, "idx_projects_name"}, {&entity.Label{}, "idx_labels_name"}} {
+		if db.Migrator().HasIndex(index.model, index.name) {
+			if err := db.Migrator().DropIndex(index.model, index.name); err != nil {
+				return err
+			}
+		}
+	}
+	var keyColumns int64
+	err := db.Raw("SELECT count(*) FROM information_schema.key_column_usage WHERE table_name = 'projects' AND constraint_name = 'projects_pkey'").
+		Scan(&keyColumns).Error
+	if err != nil || keyColumns != 1 {
+		return err
+	}
+	return db.Exec("ALTER TABLE projects DROP CONSTRAINT projects_pkey, ADD PRIMARY KEY (tenant_id, id)").Error
+}
+
 // GetDBConf returns the actual gormDB connection that can be used by the repository out of the interface given as input in SetupHandlers in main
 func (d *Database) GetDBConf() *config.DbConfig {
 	return d.Conf
@@ -0,0 +1,239 @@
+// Package oidc talks to the OpenID Connect provider the browser clients log in with: it discovers its endpoints, builds the authorization
+// requests with PKCE, exchanges the authorization codes and verifies the ID tokens with the keys the provider publishes.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/token"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// leeway is the clock skew tolerated when checking the expiry of the ID tokens
+	leeway = time.Minute
+	// keysRefreshInterval is the minimum time between two downloads of the keys of the provider, they are downloaded again when
+	// an ID token is not verified by the known ones since the provider may have rotated them
+	keysRefreshInterval = time.Minute
+	// maxResponseSize is the maximum size of the documents read from the provider
+	maxResponseSize = 1 << 20
+)
+
+// metadata represents the members of the discovery document of the provider that the login uses
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"` // optional, the session is only closed on the service when missing
+}
+
+// Provider is the OpenID provider of the config, it implements interfaces.IIdentityProvider
+type Provider struct {
+	conf     config.OIDCConfig
+	client   *http.Client
+	metadata metadata
+
+	mu       sync.Mutex
+	verifier *token.Verifier
+	fetched  time.Time // when the keys of the verifier were downloaded
+}
+
+// Enabled reports whether the config sets an OpenID provider, the browser login is disabled otherwise
+func Enabled(conf config.OIDCConfig) bool {
+	return conf.Issuer != ""
+}
+
+// Discover reads the discovery document of the provider of the config and downloads its keys. The issuer of the document must be
+// the one of the config, as the ID tokens are checked against it.
+func Discover(ctx context.Context, conf config.OIDCConfig, client *http.Client) (*Provider, error) {
+	if conf.ClientID == "" || conf.RedirectURL == "" {
+		return nil, fmt.Errorf("the client ID and the redirect URL must be set")
+	}
+	p := &Provider{conf: conf, client: client}
+	if err := p.getJSON(ctx, strings.TrimSuffix(conf.Issuer, "/")+"/.well-known/openid-configuration", &p.metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover the provider: %w", err)
+	}
+	if p.metadata.Issuer != conf.Issuer {
+		return nil, fmt.Errorf("the provider claims to be '%s' instead of '%s'", p.metadata.Issuer, conf.Issuer)
+	}
+	if p.metadata.AuthorizationEndpoint == "" || p.metadata.TokenEndpoint == "" || p.metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("the discovery document of the provider misses the authorization, token or JWKS endpoint")
+	}
+	if err := p.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// AuthCodeURL returns the URL of the provider the browser is redirected to for the login, the code it gives back can only be exchanged
+// with the verifier of the S256 challenge
+func (p *Provider) AuthCodeURL(state string, nonce string, codeChallenge string) string {
+	u, _ := url.Parse(p.metadata.AuthorizationEndpoint)
+	query := u.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.conf.ClientID)
+	query.Set("redirect_uri", p.conf.RedirectURL)
+	query.Set("scope", p.scope())
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// Exchange sends the authorization code and the PKCE verifier to the token endpoint and returns the ID token of the login.
+// errs.ErrUnauthenticated is returned if the provider refuses the code, errs.ErrUnavailable if it cannot be reached.
+func (p *Provider) Exchange(ctx context.Context, code string, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.conf.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	if p.conf.ClientSecret == "" {
+		form.Set("client_id", p.conf.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.conf.ClientSecret != "" {
+		// client_secret_basic, the credentials are form encoded before being put in the header (RFC 6749 section 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+	res, err := p.client.Do(req)
+	if err != nil {
+		return "", errs.Wrap(errs.ErrUnavailable, err)
+	}
+	defer res.Body.Close()
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(&body); err != nil && res.StatusCode == http.StatusOK {
+		return "", errs.New(errs.ErrUnavailable, "invalid response of the token endpoint: %v", err)
+	}
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		return "", errs.New(errs.ErrUnavailable, "the token endpoint failed with status %d", res.StatusCode)
+	case res.StatusCode != http.StatusOK:
+		return "", errs.New(errs.ErrUnauthenticated, "the provider refused the code: %s %s", body.Error, body.ErrorDescription)
+	case body.IDToken == "":
+		return "", errs.New(errs.ErrUnauthenticated, "the provider returned no ID token, is the openid scope requested?")
+	}
+	return body.IDToken, nil
+}
+
+// VerifyIDToken checks the signature, issuer, audience and expiry of the ID token, and that it carries the nonce of the login
+func (p *Provider) VerifyIDToken(ctx context.Context, idToken string, nonce string) (principal.Claims, error) {
+	claims, err := p.currentVerifier().Claims(idToken)
+	if err != nil && p.mayRefreshKeys() {
+		if refreshErr := p.refreshKeys(ctx); refreshErr != nil {
+			return nil, refreshErr
+		}
+		claims, err = p.currentVerifier().Claims(idToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if claims["nonce"] != nonce {
+		return nil, errs.New(errs.ErrUnauthenticated, "invalid ID token: the nonce is not the one of the login")
+	}
+	return claims, nil
+}
+
+// LogoutURL returns the URL ending the session at the provider, which then redirects the browser to the post logout URL.
+// The post logout URL is directly returned when the provider does not publish an end session endpoint.
+func (p *Provider) LogoutURL(idToken string) string {
+	if p.metadata.EndSessionEndpoint == "" {
+		return p.conf.PostLogoutRedirectURL
+	}
+	u, _ := url.Parse(p.metadata.EndSessionEndpoint)
+	query := u.Query()
+	query.Set("client_id", p.conf.ClientID)
+	if idToken != "" {
+		query.Set("id_token_hint", idToken)
+	}
+	// the provider only redirects to the absolute URLs registered for the client
+	if redirect, err := url.Parse(p.conf.PostLogoutRedirectURL); err == nil && redirect.IsAbs() {
+		query.Set("post_logout_redirect_uri", p.conf.PostLogoutRedirectURL)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// scope returns the scopes of the config, with openid added if it is missing since there would be no ID token without it
+func (p *Provider) scope() string {
+	scopes := strings.Fields(p.conf.Scopes)
+	for _, scope := range scopes {
+		if scope == "openid" {
+			return strings.Join(scopes, " ")
+		}
+	}
+	return strings.Join(append([]string{"openid"}, scopes...), " ")
+}
+
+func (p *Provider) currentVerifier() *token.Verifier {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.verifier
+}
+
+// mayRefreshKeys reports whether the keys were downloaded long enough ago to be downloaded again,
+// so that forged tokens cannot make the service download the keys on every request
+func (p *Provider) mayRefreshKeys() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return time.Since(p.fetched) >= keysRefreshInterval
+}
+
+// refreshKeys downloads the keys of the provider and replaces the verifier of the ID tokens
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	var jwks json.RawMessage
+	if err := p.getJSON(ctx, p.metadata.JWKSURI, &jwks); err != nil {
+		return fmt.Errorf("failed to download the keys of the provider: %w", err)
+	}
+	verifier, err := token.NewIDTokenVerifier(jwks, p.metadata.Issuer, p.conf.ClientID, leeway)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.verifier, p.fetched = verifier, time.Now()
+	return nil
+}
+
+// getJSON decodes the JSON document of the URL, errs.ErrUnavailable is returned if the provider cannot be reached or fails
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	res, err := p.client.Do(req)
+	if err != nil {
+		return errs.Wrap(errs.ErrUnavailable, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errs.New(errs.ErrUnavailable, "GET %s returned status %d", url, res.StatusCode)
+	}
+	if err = json.NewDecoder(io.LimitReader(res.Body, maxResponseSize)).Decode(v); err != nil {
+		return errs.New(errs.ErrUnavailable, "GET %s returned invalid JSON: %v", url, err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/oidc/oidctest"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testVerifier = "a verifier long enough for the PKCE rules of the provider"
+
+func newTestProvider(t *testing.T, idp *oidctest.Server) *Provider {
+	conf := config.OIDCConfig{
+		Issuer:                idp.URL,
+		ClientID:              idp.ClientID,
+		ClientSecret:          idp.ClientSecret,
+		RedirectURL:           "http://localhost:8080/v1/api/auth/callback",
+		PostLogoutRedirectURL: "http://localhost:8080/",
+		Scopes:                "profile email",
+	}
+	p, err := Discover(context.Background(), conf, idp.Client())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	return p
+}
+
+// authorize follows the authorization URL as a browser does and returns the code the provider redirects back with
+func authorize(t *testing.T, p *Provider, state string, nonce string) string {
+	challenge := sha256.Sum256([]byte(testVerifier))
+	authURL := p.AuthCodeURL(state, nonce, base64.RawURLEncoding.EncodeToString(challenge[:]))
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("the provider did not redirect back, status: %d", res.StatusCode)
+	}
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callback.Query().Get("state"); got != state {
+		t.Fatalf("invalid state, expected: %s, got: %s", state, got)
+	}
+	return callback.Query().Get("code")
+}
+
+func TestProvider(t *testing.T) {
+	idp := oidctest.NewServer("tasks", "s3cr3t&")
+	defer idp.Close()
+	p := newTestProvider(t, idp)
+
+	authURL, _ := url.Parse(p.AuthCodeURL("state", "nonce", "challenge"))
+	if got := authURL.Query().Get("scope"); got != "openid profile email" {
+		t.Errorf("invalid scope, expected: openid profile email, got: %s", got)
+	}
+
+	idToken, err := p.Exchange(context.Background(), authorize(t, p, "state", "nonce"), testVerifier)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	claims, err := p.VerifyIDToken(context.Background(), idToken, "nonce")
+	if err != nil {
+		t.Fatalf("VerifyIDToken() error = %v", err)
+	}
+	if claims["sub"] != "alice" {
+		t.Errorf("invalid subject, expected: alice, got: %v", claims["sub"])
+	}
+	if _, err = p.VerifyIDToken(context.Background(), idToken, "another login"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("VerifyIDToken() error = %v, want %v for the nonce of another login", err, errs.ErrUnauthenticated)
+	}
+
+	logoutURL, _ := url.Parse(p.LogoutURL(idToken))
+	if logoutURL.Path != "/logout" || logoutURL.Query().Get("id_token_hint") != idToken || logoutURL.Query().Get("post_logout_redirect_uri") != "http://localhost:8080/" {
+		t.Errorf("invalid logout URL: %s", logoutURL)
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	idp := oidctest.NewServer("tasks", "s3cr3t")
+	defer idp.Close()
+	p := newTestProvider(t, idp)
+
+	code := authorize(t, p, "state", "nonce")
+	if _, err := p.Exchange(context.Background(), code, "a verifier guessed by someone who stole the code"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("Exchange() error = %v, want %v for a wrong verifier", err, errs.ErrUnauthenticated)
+	}
+	if _, err := p.Exchange(context.Background(), code, testVerifier); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("Exchange() error = %v, want %v for a code used twice", err, errs.ErrUnauthenticated)
+	}
+
+	idp.ClientSecret = "rotated"
+	if _, err := p.Exchange(context.Background(), authorize(t, p, "state", "nonce"), testVerifier); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("Exchange() error = %v, want %v for a wrong client secret", err, errs.ErrUnauthenticated)
+	}
+
+	idp.Close()
+	if _, err := p.Exchange(context.Background(), "code", testVerifier); !errors.Is(err, errs.ErrUnavailable) {
+		t.Errorf("Exchange() error = %v, want %v when the provider is down", err, errs.ErrUnavailable)
+	}
+}
+
+func TestProvider_VerifyIDToken_KeyRotation(t *testing.T) {
+	idp := oidctest.NewServer("tasks", "s3cr3t")
+	defer idp.Close()
+	p := newTestProvider(t, idp)
+
+	idp.RotateKey()
+	idToken := idp.IDToken("nonce")
+	// the keys were just downloaded, they are not downloaded again for every unknown key
+	if _, err := p.VerifyIDToken(context.Background(), idToken, "nonce"); !errors.Is(err, errs.ErrUnauthenticated) {
+		t.Errorf("VerifyIDToken() error = %v, want %v right after the keys were downloaded", err, errs.ErrUnauthenticated)
+	}
+	p.fetched = time.Now().Add(-keysRefreshInterval)
+	if _, err := p.VerifyIDToken(context.Background(), idToken, "nonce"); err != nil {
+		t.Errorf("VerifyIDToken() error = %v, want the new key to be downloaded", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	idp := oidctest.NewServer("tasks", "s3cr3t")
+	defer idp.Close()
+
+	tests := []struct {
+		name string
+		conf config.OIDCConfig
+		want string
+	}{
+		{name: "should refuse a provider claiming another issuer", conf: config.OIDCConfig{Issuer: idp.URL + "/", ClientID: "tasks", RedirectURL: "http://localhost/cb"}, want: "claims to be"},
+		{name: "should refuse a config without client ID", conf: config.OIDCConfig{Issuer: idp.URL, RedirectURL: "http://localhost/cb"}, want: "client ID"},
+		{name: "should refuse a provider without discovery document", conf: config.OIDCConfig{Issuer: idp.URL + "/missing", ClientID: "tasks", RedirectURL: "http://localhost/cb"}, want: "status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Discover(context.Background(), tt.conf, idp.Client()); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Discover() error = %v, want an error containing '%s'", err, tt.want)
+			}
+		})
+	}
+}
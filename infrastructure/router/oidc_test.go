@@ -0,0 +1,177 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/application/service"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/oidc"
+	"github.com/FirasYousfi/tasks-web-servcie/infrastructure/oidc/oidctest"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memorySessions keeps the sessions and the logins in memory
+type memorySessions struct {
+	mu       sync.Mutex
+	sessions map[string]*entity.Session
+	logins   map[string]*entity.LoginAttempt
+}
+
+func (m *memorySessions) Create(session *entity.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memorySessions) FindByID(id string) (*entity.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if session, ok := m.sessions[id]; ok {
+		return session, nil
+	}
+	return nil, errs.New(errs.ErrNotFound, "session not found")
+}
+
+func (m *memorySessions) DeleteByID(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memorySessions) CreateLogin(attempt *entity.LoginAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logins[attempt.ID] = attempt
+	return nil
+}
+
+func (m *memorySessions) TakeLogin(id string) (*entity.LoginAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	attempt, ok := m.logins[id]
+	if !ok {
+		return nil, errs.New(errs.ErrNotFound, "login not found")
+	}
+	delete(m.logins, id)
+	return attempt, nil
+}
+
+func (m *memorySessions) PurgeExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// whoAmI answers the listing of the roles with the principal of the caller as its only assignment
+type whoAmI struct {
+	interfaces.IRoleService
+}
+
+func (w whoAmI) List(ctx context.Context) ([]*entity.RoleAssignment, error) {
+	p, _ := principal.FromContext(ctx)
+	role := entity.Role(p.Role)
+	if p.Admin {
+		role = entity.Admin
+	}
+	return []*entity.RoleAssignment{{Subject: p.Subject, Role: role}}, nil
+}
+
+// TestOIDCLogin logs a browser in with the fake provider, uses its session and logs it out
+func TestOIDCLogin(t *testing.T) {
+	idp := oidctest.NewServer("tasks", "s3cr3t")
+	defer idp.Close()
+	idp.SetClaims(map[string]interface{}{"sub": "0f3a", "preferred_username": "alice", "tenant": "acme", "roles": []string{"editor"}})
+
+	// the cookies are secure, so the service is served over HTTPS
+	var routes http.Handler
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { routes.ServeHTTP(w, r) }))
+	defer srv.Close()
+	provider, err := oidc.Discover(context.Background(), config.OIDCConfig{
+		Issuer:                idp.URL,
+		ClientID:              idp.ClientID,
+		ClientSecret:          idp.ClientSecret,
+		RedirectURL:           srv.URL + basePath + "/auth/callback",
+		PostLogoutRedirectURL: srv.URL + "/",
+		Scopes:                "openid profile",
+	}, idp.Client())
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	repo := &memorySessions{sessions: make(map[string]*entity.Session), logins: make(map[string]*entity.LoginAttempt)}
+	sessions := service.NewSessionService(repo, provider, service.ClaimMapping{UsernameClaim: "sub", TenantClaim: "tenant", RolesClaim: "roles", DefaultTenant: "default"}, time.Hour)
+	routes = SetupRoutes(struct{ interfaces.ITaskService }{}, struct{ interfaces.ILabelService }{}, struct{ interfaces.ICommentService }{}, struct{ interfaces.IAttachmentService }{}, struct{ interfaces.IChecklistService }{}, struct{ interfaces.IProjectService }{}, mockUsers{}, whoAmI{}, mockKeys{}, sessions, nil)
+
+	jar, _ := cookiejar.New(nil)
+	browser := srv.Client()
+	browser.Jar = jar
+
+	res, err := browser.Get(srv.URL + basePath + "/roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("invalid status code before the login, expected: %d, got: %d", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	// the browser follows the redirects to the provider, back to the callback and then to the path it asked for
+	res, err = browser.Get(srv.URL + basePath + "/auth/login?returnTo=" + basePath + "/roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Request.URL.Path != basePath+"/roles" {
+		t.Fatalf("the login did not end on the roles, status: %d, path: %s", res.StatusCode, res.Request.URL.Path)
+	}
+	var body struct {
+		Roles []*entity.RoleAssignment `json:"roles"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Roles) != 1 {
+		t.Fatalf("expected the principal of the session, got %d assignments", len(body.Roles))
+	}
+	if body.Roles[0].Subject != "oidc:0f3a" || body.Roles[0].Role != entity.Editor {
+		t.Errorf("invalid principal of the session: %+v", *body.Roles[0])
+	}
+	if len(repo.sessions) != 1 || len(repo.logins) != 0 {
+		t.Fatalf("expected 1 session and no pending login, got %d sessions and %d logins", len(repo.sessions), len(repo.logins))
+	}
+	for _, session := range repo.sessions {
+		if session.TenantID != "acme" {
+			t.Errorf("invalid tenant of the session, expected: acme, got: %s", session.TenantID)
+		}
+	}
+
+	// the logout closes the session and sends the browser to the provider, which is not followed here
+	browser.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+	res, err = browser.Post(srv.URL+basePath+"/auth/logout", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusSeeOther {
+		t.Fatalf("invalid status code of the logout, expected: %d, got: %d", http.StatusSeeOther, res.StatusCode)
+	}
+	if len(repo.sessions) != 0 {
+		t.Errorf("the session is not closed")
+	}
+	res, err = browser.Get(srv.URL + basePath + "/roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("invalid status code after the logout, expected: %d, got: %d", http.StatusUnauthorized, res.StatusCode)
+	}
+}
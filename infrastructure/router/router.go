@@ -1,31 +1,123 @@
 package router
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
-	"crypto/subtle"
+	"errors"
 	"fmt"
 	"github.com/FirasYousfi/tasks-web-servcie/adapters/web/handlers"
 	"github.com/FirasYousfi/tasks-web-servcie/application/interfaces"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
 	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
 	"github.com/FirasYousfi/tasks-web-servcie/k8s"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"net/http"
+	"strings"
 )
 
 const basePath = "/v1/api"
 
-func SetupRoutes(service interfaces.ITaskService) *mux.Router {
-	if service == nil {
+// TokenVerifier verifies the bearer tokens, it returns the principal of the caller or an errs.ErrUnauthenticated error
+type TokenVerifier interface {
+	Verify(token string) (principal.Principal, error)
+}
+
+// SetupRoutes registers the routes of the API, the requests authenticate with basic auth, or with a bearer token when a verifier is given.
+// The routes of the tasks also accept the API keys. When a session service is given, the browser clients log in with the OpenID provider
+// on the /auth routes and authenticate with their session cookie.
+func SetupRoutes(service interfaces.ITaskService, labelService interfaces.ILabelService, commentService interfaces.ICommentService, attachmentService interfaces.IAttachmentService, checklistService interfaces.IChecklistService, projectService interfaces.IProjectService, userService interfaces.IUserService, roleService interfaces.IRoleService, apiKeyService interfaces.IAPIKeyService, sessionService interfaces.ISessionService, verifier TokenVerifier) *mux.Router {
+	if service == nil || labelService == nil || commentService == nil || attachmentService == nil || checklistService == nil || projectService == nil || userService == nil || roleService == nil || apiKeyService == nil {
 		log.Fatal().Msgf("nil service provided")
 	}
 	r := mux.NewRouter()
-	r.Handle(fmt.Sprintf("%s/tasks", basePath), attachMiddleware(&handlers.Create{TaskService: service}, basicAuth)).Methods("POST")
-	r.Handle(fmt.Sprintf("%s/tasks", basePath), attachMiddleware(&handlers.List{TaskService: service}, basicAuth)).Methods("GET")
-	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Delete{TaskService: service}, basicAuth)).Methods("DELETE")
-	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Get{TaskService: service}, basicAuth)).Methods("GET")
-	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Update{TaskService: service}, basicAuth)).Methods("PATCH")
-	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Update{TaskService: service}, basicAuth)).Methods("PUT")
+	key := cursorKey()
+	schemes := map[string]Middleware{}
+	if verifier != nil {
+		schemes["Bearer"] = bearerAuth(verifier)
+	}
+	basic := basicAuth(userService)
+	if sessionService != nil {
+		basic = sessionAuth(sessionService, basic)
+	}
+	auth := authenticate(basic, schemes)
+	// the API keys only carry scopes on the tasks, they are refused by the other routes rather than checked by each of their services
+	taskSchemes := map[string]Middleware{"ApiKey": apiKeyAuth(apiKeyService)}
+	for scheme, m := range schemes {
+		taskSchemes[scheme] = m
+	}
+	taskAuth := authenticate(basic, taskSchemes)
+	r.Handle(fmt.Sprintf("%s/tasks", basePath), attachMiddleware(&handlers.Create{TaskService: service}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks", basePath), attachMiddleware(&handlers.List{TaskService: service, CursorKey: key}, taskAuth)).Methods("GET")
+	// registered before the /tasks/{id} routes, otherwise "trash" and "next" would be matched as task IDs
+	r.Handle(fmt.Sprintf("%s/tasks/trash", basePath), attachMiddleware(&handlers.Trash{TaskService: service, CursorKey: key}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/next", basePath), attachMiddleware(&handlers.Next{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/restore", basePath), attachMiddleware(&handlers.Restore{TaskService: service}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/history", basePath), attachMiddleware(&handlers.History{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/children", basePath), attachMiddleware(&handlers.Children{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/tree", basePath), attachMiddleware(&handlers.Tree{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/parent", basePath), attachMiddleware(&handlers.RemoveParent{TaskService: service}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/blockers", basePath), attachMiddleware(&handlers.Blockers{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/blockers", basePath), attachMiddleware(&handlers.AddBlocker{TaskService: service}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/blockers/{blockerId}", basePath), attachMiddleware(&handlers.RemoveBlocker{TaskService: service}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/comments", basePath), attachMiddleware(&handlers.ListComments{CommentService: commentService}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/comments", basePath), attachMiddleware(&handlers.CreateComment{CommentService: commentService}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/comments/{commentId}", basePath), attachMiddleware(&handlers.UpdateComment{CommentService: commentService}, taskAuth)).Methods("PATCH", "PUT")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/comments/{commentId}", basePath), attachMiddleware(&handlers.DeleteComment{CommentService: commentService}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/comments/{commentId}/revisions", basePath), attachMiddleware(&handlers.CommentRevisions{CommentService: commentService}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/attachments", basePath), attachMiddleware(&handlers.ListAttachments{AttachmentService: attachmentService}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/attachments", basePath), attachMiddleware(&handlers.UploadAttachment{AttachmentService: attachmentService, MaxSize: config.Config.Attachment.MaxSize}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/attachments/{attachmentId}", basePath), attachMiddleware(&handlers.DownloadAttachment{AttachmentService: attachmentService}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/attachments/{attachmentId}", basePath), attachMiddleware(&handlers.DeleteAttachment{AttachmentService: attachmentService}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist", basePath), attachMiddleware(&handlers.ListChecklist{ChecklistService: checklistService}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist", basePath), attachMiddleware(&handlers.AddChecklistItem{ChecklistService: checklistService}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist/{itemId}", basePath), attachMiddleware(&handlers.RemoveChecklistItem{ChecklistService: checklistService}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist/{itemId}/position", basePath), attachMiddleware(&handlers.MoveChecklistItem{ChecklistService: checklistService}, taskAuth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist/{itemId}/check", basePath), attachMiddleware(&handlers.SetChecklistItemDone{ChecklistService: checklistService, Done: true}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/checklist/{itemId}/uncheck", basePath), attachMiddleware(&handlers.SetChecklistItemDone{ChecklistService: checklistService, Done: false}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/assignee", basePath), attachMiddleware(&handlers.Assign{TaskService: service}, taskAuth)).Methods("PUT", "DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/labels/{labelId}", basePath), attachMiddleware(&handlers.AttachLabel{TaskService: service}, taskAuth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}/labels/{labelId}", basePath), attachMiddleware(&handlers.DetachLabel{TaskService: service}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Delete{TaskService: service}, taskAuth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Get{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Update{TaskService: service}, taskAuth)).Methods("PATCH")
+	r.Handle(fmt.Sprintf("%s/tasks/{id}", basePath), attachMiddleware(&handlers.Update{TaskService: service}, taskAuth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/workflow", basePath), attachMiddleware(&handlers.Workflow{TaskService: service}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/projects", basePath), attachMiddleware(&handlers.ListProjects{ProjectService: projectService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/projects", basePath), attachMiddleware(&handlers.CreateProject{ProjectService: projectService}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/projects/{id}", basePath), attachMiddleware(&handlers.GetProject{ProjectService: projectService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/projects/{id}", basePath), attachMiddleware(&handlers.UpdateProject{ProjectService: projectService}, auth)).Methods("PATCH")
+	r.Handle(fmt.Sprintf("%s/projects/{id}", basePath), attachMiddleware(&handlers.UpdateProject{ProjectService: projectService}, auth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/projects/{id}", basePath), attachMiddleware(&handlers.DeleteProject{ProjectService: projectService}, auth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/projects/{id}/roles/{subject}", basePath), attachMiddleware(&handlers.AssignRole{RoleService: roleService}, auth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/projects/{id}/roles/{subject}", basePath), attachMiddleware(&handlers.RevokeRole{RoleService: roleService}, auth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/projects/{pid}/tasks", basePath), attachMiddleware(&handlers.Create{TaskService: service}, taskAuth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/projects/{pid}/tasks", basePath), attachMiddleware(&handlers.List{TaskService: service, CursorKey: key}, taskAuth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/labels", basePath), attachMiddleware(&handlers.ListLabels{LabelService: labelService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/labels", basePath), attachMiddleware(&handlers.CreateLabel{LabelService: labelService}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/labels/{id}", basePath), attachMiddleware(&handlers.GetLabel{LabelService: labelService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/labels/{id}", basePath), attachMiddleware(&handlers.UpdateLabel{LabelService: labelService}, auth)).Methods("PATCH")
+	r.Handle(fmt.Sprintf("%s/labels/{id}", basePath), attachMiddleware(&handlers.UpdateLabel{LabelService: labelService}, auth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/labels/{id}", basePath), attachMiddleware(&handlers.DeleteLabel{LabelService: labelService}, auth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/users", basePath), attachMiddleware(&handlers.ListUsers{UserService: userService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/users", basePath), attachMiddleware(&handlers.CreateUser{UserService: userService}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/users/{id}", basePath), attachMiddleware(&handlers.GetUser{UserService: userService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/users/{id}/disable", basePath), attachMiddleware(&handlers.SetUserDisabled{UserService: userService, Disabled: true}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/users/{id}/enable", basePath), attachMiddleware(&handlers.SetUserDisabled{UserService: userService, Disabled: false}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/users/{id}/password", basePath), attachMiddleware(&handlers.ResetPassword{UserService: userService}, auth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/apikeys", basePath), attachMiddleware(&handlers.ListAPIKeys{APIKeyService: apiKeyService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/apikeys", basePath), attachMiddleware(&handlers.IssueAPIKey{APIKeyService: apiKeyService}, auth)).Methods("POST")
+	r.Handle(fmt.Sprintf("%s/apikeys/{id}", basePath), attachMiddleware(&handlers.RevokeAPIKey{APIKeyService: apiKeyService}, auth)).Methods("DELETE")
+	r.Handle(fmt.Sprintf("%s/roles", basePath), attachMiddleware(&handlers.ListRoles{RoleService: roleService}, auth)).Methods("GET")
+	r.Handle(fmt.Sprintf("%s/roles/{subject}", basePath), attachMiddleware(&handlers.AssignRole{RoleService: roleService}, auth)).Methods("PUT")
+	r.Handle(fmt.Sprintf("%s/roles/{subject}", basePath), attachMiddleware(&handlers.RevokeRole{RoleService: roleService}, auth)).Methods("DELETE")
+	if sessionService != nil {
+		// the login is what authenticates the browser, the logout only needs the session cookie
+		r.Handle(fmt.Sprintf("%s/auth/login", basePath), &handlers.Login{SessionService: sessionService}).Methods("GET")
+		r.Handle(fmt.Sprintf("%s/auth/callback", basePath), &handlers.LoginCallback{SessionService: sessionService}).Methods("GET")
+		r.Handle(fmt.Sprintf("%s/auth/logout", basePath), &handlers.Logout{SessionService: sessionService}).Methods("POST")
+	}
 
 	// liveness and readiness probes, no need for auth middleware for those
 	r.Handle(fmt.Sprintf("/healthz"), &k8s.Liveness{}).Methods("GET")
@@ -33,38 +125,142 @@ func SetupRoutes(service interfaces.ITaskService) *mux.Router {
 	return r
 }
 
+// cursorKey returns the key signing the list cursors. Without a configured secret a random one is used,
+// which means cursors are not accepted by other replicas and become invalid when the server restarts.
+func cursorKey() []byte {
+	if config.Config.Pagination.CursorSecret != "" {
+		return []byte(config.Config.Pagination.CursorSecret)
+	}
+	log.Warn().Msg("CURSOR_SECRET is not set, list cursors will be signed with a random key")
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatal().Err(err).Msg("failed to generate cursor key")
+	}
+	return key
+}
+
+// basicAuth returns the middleware authenticating the requests against the user store, the handlers run with the principal of the user.
 // To use middleware with the r.Use(MiddlewareFunc) provided by gorilla/mux, or with our attachMiddleware function,
 // the signature needs to be: type MiddlewareFunc func(http.Handler) http.Handler
-func basicAuth(next http.Handler) http.Handler {
-	//if 'f' is a function with the appropriate signature, HandlerFunc(f) is a Handler that calls f.
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Extract the username and password from the request header. If the Authentication header is not present or is invalid we know through the 'ok' variable
-		username, password, ok := r.BasicAuth()
-		if ok {
-			// Calculate SHA-256 hashes for the provided and expected usernames and passwords so that we ensure they have the same length
-			// that way the comparison with subtle.ConstantTimeCompare would always take the same time
-			usernameHash := sha256.Sum256([]byte(username))
-			passwordHash := sha256.Sum256([]byte(password))
-			expectedUsernameHash := sha256.Sum256([]byte(config.Config.Auth.Username))
-			expectedPasswordHash := sha256.Sum256([]byte(config.Config.Auth.Password))
+func basicAuth(users interfaces.IUserService) Middleware {
+	return func(next http.Handler) http.Handler {
+		//if 'f' is a function with the appropriate signature, HandlerFunc(f) is a Handler that calls f.
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Extract the username and password from the request header. If the Authentication header is not present or is invalid we know through the 'ok' variable
+			username, password, ok := r.BasicAuth()
+			if ok {
+				// The service compares the password with its bcrypt hash, and does as much work when the username is unknown so that
+				// the response time does not tell which usernames exist. Disabled users are refused like a wrong password.
+				p, err := users.Authenticate(r.Context(), username, password)
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(principal.NewContext(r.Context(), p)))
+					return
+				}
+				if !errors.Is(err, errs.ErrUnauthenticated) {
+					log.Error().Err(err).Msg("failed to authenticate user")
+					http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+					return
+				}
+			}
+
+			// If the Authentication header is not present, is invalid, or the username or password is wrong, then set a WWW-Authenticate
+			// header to inform the client that we expect them to use basic authentication and send a 401 Unauthorized response.
+			w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+// bearerAuth returns the middleware authenticating the requests with the JWT of their "Authorization: Bearer" header,
+// the handlers run with the subject of the token as principal and its claims.
+func bearerAuth(verifier TokenVerifier) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+				p, err := verifier.Verify(strings.TrimSpace(token))
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(principal.NewContext(r.Context(), p)))
+					return
+				}
+				log.Warn().Err(err).Msg("refused bearer token")
+			}
+			// the reason is only logged, the response does not tell which check the token failed
+			w.Header().Set("WWW-Authenticate", `Bearer realm="restricted", error="invalid_token"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
 
-			// Use the subtle.ConstantTimeCompare() function to check if the provided username and password hashes equal the expected ones.
-			// Importantly, we should to do the work to evaluate both the username and password before checking the return values to avoid leaking information.
-			usernameMatch := subtle.ConstantTimeCompare(usernameHash[:], expectedUsernameHash[:]) == 1
-			passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], expectedPasswordHash[:]) == 1
+// apiKeyAuth returns the middleware authenticating the requests with the API key of their "Authorization: ApiKey" header,
+// the handlers run with the key as principal, whose scopes give its role on the tasks.
+func apiKeyAuth(keys interfaces.IAPIKeyService) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, key, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if strings.EqualFold(scheme, "ApiKey") && strings.TrimSpace(key) != "" {
+				p, err := keys.Authenticate(r.Context(), strings.TrimSpace(key))
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(principal.NewContext(r.Context(), p)))
+					return
+				}
+				if !errors.Is(err, errs.ErrUnauthenticated) {
+					log.Error().Err(err).Msg("failed to authenticate API key")
+					http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+					return
+				}
+			}
+			w.Header().Set("WWW-Authenticate", `ApiKey realm="restricted"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
 
-			// If the username and password are correct, then call
-			// the next handler in the chain. Make sure to return
-			// afterwards, so that none of the code below is run.
-			if usernameMatch && passwordMatch {
-				next.ServeHTTP(w, r)
+// sessionAuth returns the middleware authenticating the requests of the browser clients with their session cookie, the handlers run
+// with the principal of the login. The requests carrying credentials in their Authorization header, or no cookie, go to the fallback.
+// The cookie is SameSite=Lax, so the browsers do not send it with the POST, PUT, PATCH and DELETE requests other sites make.
+func sessionAuth(sessions interfaces.ISessionService, fallback Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		withoutSession := fallback(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cookie, err := r.Cookie(handlers.SessionCookie)
+			if err != nil || r.Header.Get("Authorization") != "" {
+				withoutSession.ServeHTTP(w, r)
 				return
 			}
-		}
+			p, err := sessions.Authenticate(r.Context(), cookie.Value)
+			if err == nil {
+				next.ServeHTTP(w, r.WithContext(principal.NewContext(r.Context(), p)))
+				return
+			}
+			if !errors.Is(err, errs.ErrUnauthenticated) {
+				log.Error().Err(err).Msg("failed to authenticate session")
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			// the session expired or was closed, the browser has to log in again
+			http.SetCookie(w, &http.Cookie{Name: handlers.SessionCookie, Path: "/", MaxAge: -1, HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode})
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
 
-		// If the Authentication header is not present, is invalid, or the username or password is wrong, then set a WWW-Authenticate
-		// header to inform the client that we expect them to use basic authentication and send a 401 Unauthorized response.
-		w.Header().Set("WWW-Authenticate", `Basic realm="restricted", charset="UTF-8"`)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-	})
+// authenticate returns the middleware passing the requests to the authentication of the scheme of their Authorization header,
+// the schemes are case insensitive. The requests without a known scheme go to basic auth, which asks the client for its credentials.
+func authenticate(basic Middleware, schemes map[string]Middleware) Middleware {
+	return func(next http.Handler) http.Handler {
+		handlers := make(map[string]http.Handler, len(schemes))
+		for scheme, m := range schemes {
+			handlers[strings.ToLower(scheme)] = m(next)
+		}
+		fallback := basic(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, _, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			if h, ok := handlers[strings.ToLower(scheme)]; ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			fallback.ServeHTTP(w, r)
+		})
+	}
 }
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"context"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/entity"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mockVerifier accepts the token "valid" as alice of the tenant acme
+type mockVerifier struct{}
+
+func (m mockVerifier) Verify(token string) (principal.Principal, error) {
+	if token != "valid" {
+		return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "invalid token")
+	}
+	return principal.Principal{Subject: "alice", Tenant: "acme", Claims: principal.Claims{"scope": "tasks"}}, nil
+}
+
+// mockUsers accepts the password "password" of bob
+type mockUsers struct{}
+
+func (m mockUsers) Authenticate(ctx context.Context, username string, password string) (principal.Principal, error) {
+	if username != "bob" || password != "password" {
+		return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "wrong username or password")
+	}
+	return principal.Principal{Subject: "bob", Tenant: "acme"}, nil
+}
+
+func (m mockUsers) Create(ctx context.Context, req *entity.NewUser) (*entity.User, error) {
+	return nil, nil
+}
+
+func (m mockUsers) List(ctx context.Context) ([]*entity.User, error) {
+	return nil, nil
+}
+
+func (m mockUsers) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	return nil, nil
+}
+
+func (m mockUsers) SetDisabled(ctx context.Context, id string, disabled bool) (*entity.User, error) {
+	return nil, nil
+}
+
+func (m mockUsers) ResetPassword(ctx context.Context, id string, req *entity.PasswordReset) error {
+	return nil
+}
+
+// mockKeys accepts the key "tsk_valid" as the key ci of the tenant acme, and fails as if the store was down for "tsk_down"
+type mockKeys struct{}
+
+func (m mockKeys) Authenticate(ctx context.Context, key string) (principal.Principal, error) {
+	switch key {
+	case "tsk_valid":
+		return principal.Principal{Subject: "apikey:ci", Tenant: "acme", Scopes: []string{"tasks:read"}}, nil
+	case "tsk_down":
+		return principal.Principal{}, errs.New(errs.ErrUnavailable, "database is down")
+	}
+	return principal.Principal{}, errs.New(errs.ErrUnauthenticated, "invalid API key")
+}
+
+func (m mockKeys) Issue(ctx context.Context, req *entity.APIKeyDescription) (*entity.IssuedAPIKey, error) {
+	return nil, nil
+}
+
+func (m mockKeys) List(ctx context.Context) ([]*entity.APIKey, error) {
+	return nil, nil
+}
+
+func (m mockKeys) Revoke(ctx context.Context, id string) error {
+	return nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	// the handler writes the subject of the principal the request is authenticated as
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(principal.Subject(r.Context())))
+	})
+	withBearer := authenticate(basicAuth(mockUsers{}), map[string]Middleware{"Bearer": bearerAuth(mockVerifier{})})(echo)
+	withoutBearer := authenticate(basicAuth(mockUsers{}), map[string]Middleware{})(echo)
+	withAPIKey := authenticate(basicAuth(mockUsers{}), map[string]Middleware{"ApiKey": apiKeyAuth(mockKeys{})})(echo)
+
+	tests := []struct {
+		name          string
+		handler       http.Handler
+		authorization string
+		status        int
+		subject       string
+		challenge     string
+	}{
+		{name: "should authenticate with basic auth", handler: withBearer, authorization: "Basic Ym9iOnBhc3N3b3Jk", status: http.StatusOK, subject: "bob"},
+		{name: "should authenticate with a bearer token", handler: withBearer, authorization: "Bearer valid", status: http.StatusOK, subject: "alice"},
+		{name: "should accept the scheme in any case", handler: withBearer, authorization: "bearer valid", status: http.StatusOK, subject: "alice"},
+		{name: "should refuse an invalid bearer token", handler: withBearer, authorization: "Bearer forged", status: http.StatusUnauthorized, challenge: `Bearer realm="restricted", error="invalid_token"`},
+		{name: "should refuse an empty bearer token", handler: withBearer, authorization: "Bearer ", status: http.StatusUnauthorized, challenge: `Bearer realm="restricted", error="invalid_token"`},
+		{name: "should refuse a wrong password", handler: withBearer, authorization: "Basic Ym9iOndyb25n", status: http.StatusUnauthorized, challenge: `Basic realm="restricted", charset="UTF-8"`},
+		{name: "should ask for basic auth without credentials", handler: withBearer, status: http.StatusUnauthorized, challenge: `Basic realm="restricted", charset="UTF-8"`},
+		{name: "should refuse bearer tokens when they are disabled", handler: withoutBearer, authorization: "Bearer valid", status: http.StatusUnauthorized, challenge: `Basic realm="restricted", charset="UTF-8"`},
+		{name: "should authenticate with an API key", handler: withAPIKey, authorization: "ApiKey tsk_valid", status: http.StatusOK, subject: "apikey:ci"},
+		{name: "should refuse an unknown API key", handler: withAPIKey, authorization: "ApiKey tsk_forged", status: http.StatusUnauthorized, challenge: `ApiKey realm="restricted"`},
+		{name: "should not refuse an API key when the key store is down", handler: withAPIKey, authorization: "ApiKey tsk_down", status: http.StatusServiceUnavailable},
+		{name: "should refuse API keys on the routes which do not accept them", handler: withBearer, authorization: "ApiKey tsk_valid", status: http.StatusUnauthorized, challenge: `Basic realm="restricted", charset="UTF-8"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "http://localhost:8080/v1/api/tasks", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			response := httptest.NewRecorder()
+			tt.handler.ServeHTTP(response, req)
+
+			if response.Code != tt.status {
+				t.Fatalf("invalid status code, expected: %d, got: %d", tt.status, response.Code)
+			}
+			if tt.status == http.StatusOK && response.Body.String() != tt.subject {
+				t.Errorf("invalid principal, expected: %s, got: %s", tt.subject, response.Body.String())
+			}
+			if got := response.Header().Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("invalid challenge, expected: %s, got: %s", tt.challenge, got)
+			}
+		})
+	}
+}
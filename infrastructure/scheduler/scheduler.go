@@ -0,0 +1,28 @@
+// Package scheduler runs the background jobs of the service, next to the HTTP server
+package scheduler
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// Job is a unit of background work, it is run again at the next tick when it fails
+type Job func(ctx context.Context) error
+
+// Every runs the job once per interval until the context is done. It blocks, so it is meant to be started in its own goroutine.
+func Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info().Msgf("stopping job %s", name)
+			return
+		case <-ticker.C:
+			if err := job(ctx); err != nil {
+				log.Error().Err(err).Msgf("job %s failed, it will be retried in %s", name, interval)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		Every(ctx, "test", time.Millisecond, func(ctx context.Context) error {
+			runs <- struct{}{}
+			return errors.New("failing jobs are run again")
+		})
+		close(done)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-runs:
+		case <-time.After(time.Second):
+			t.Fatalf("job was run %d times, expected 3", i)
+		}
+	}
+	cancel()
+	// drain a tick that may have fired concurrently with the cancellation
+	for {
+		select {
+		case <-runs:
+		case <-done:
+			return
+		case <-time.After(time.Second):
+			t.Fatal("Every() did not return after the context was done")
+		}
+	}
+}
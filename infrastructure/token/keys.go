@@ -0,0 +1,144 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// jwk represents a key of a JWKS file (RFC 7517), only the members of the RSA, P-256 and symmetric keys are read
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// readJWKS reads the keys of a JWKS file. The keys the service cannot use, e.g. the encryption keys or the other curves,
+// are skipped since the identity providers publish them in the same set, but a set without any usable key is refused.
+func readJWKS(path string) ([]key, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the JWKS file: %w", err)
+	}
+	return parseJWKS(content)
+}
+
+// parseJWKS reads the keys of a JWKS document, as readJWKS does for a file
+func parseJWKS(content []byte) ([]key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, fmt.Errorf("the JWKS is not valid JSON: %w", err)
+	}
+	var keys []key
+	for i, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		value, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d of the JWKS: %w", i, err)
+		}
+		if value != nil {
+			keys = append(keys, key{id: k.Kid, alg: k.Alg, value: value})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("the JWKS has no RSA, P-256 or symmetric signing key")
+	}
+	return keys, nil
+}
+
+// publicKey returns the key verifying the signatures, or nil for the types of keys the service does not use
+func (k jwk) publicKey() (interface{}, error) {
+	switch {
+	case k.Kty == "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case k.Kty == "EC" && k.Crv == "P-256":
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("the point is not on the P-256 curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	case k.Kty == "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil || len(secret) == 0 {
+			return nil, fmt.Errorf("invalid symmetric key")
+		}
+		return secret, nil
+	}
+	return nil, nil
+}
+
+// decodeInt decodes a big-endian unsigned integer encoded in base64url without padding, as the members of the JWKs are
+func decodeInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer '%s'", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// readPublicKey reads the RSA or P-256 public key of a PEM file, either as a public key or as the certificate holding it
+func readPublicKey(path string) (interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the public key file: %w", err)
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("the public key file is not PEM encoded")
+	}
+	var publicKey interface{}
+	switch block.Type {
+	case "PUBLIC KEY":
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			publicKey = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %s in the public key file", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if fits("RS256", publicKey) || fits("ES256", publicKey) {
+		return publicKey, nil
+	}
+	return nil, fmt.Errorf("the public key is neither an RSA nor a P-256 key")
+}
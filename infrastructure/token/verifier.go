@@ -0,0 +1,150 @@
+// Package token verifies the JWT bearer tokens issued by the identity provider the service trusts
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"github.com/FirasYousfi/tasks-web-servcie/application/principal"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/golang-jwt/jwt/v5"
+	"time"
+)
+
+// methods are the signing algorithms accepted, the tokens signed with any other one, "none" included, are refused before any key is looked up
+var methods = []string{"HS256", "RS256", "ES256"}
+
+// key is a key verifying the tokens, value is a []byte secret, an *rsa.PublicKey or a P-256 *ecdsa.PublicKey
+type key struct {
+	id    string // kid of the key in the JWKS, empty for the keys set directly in the config
+	alg   string // algorithm the JWKS restricts the key to, empty when any algorithm of its type is allowed
+	value interface{}
+}
+
+// Verifier checks the signature, expiry, issuer and audience of the bearer tokens and returns the principal of their subject
+type Verifier struct {
+	keys          []key
+	parser        *jwt.Parser
+	tenantClaim   string
+	defaultTenant string
+}
+
+// Enabled reports whether the config sets a key verifying the bearer tokens, they are refused otherwise
+func Enabled(conf config.JWTConfig) bool {
+	return conf.Secret != "" || conf.PublicKeyFile != "" || conf.JWKSFile != ""
+}
+
+// NewVerifier returns a verifier of the tokens signed with the keys of the config, the tokens without the tenant claim
+// belong to the default tenant. The issuer and the audience are required, otherwise the tokens issued for other services would be accepted.
+func NewVerifier(conf config.JWTConfig, defaultTenant string) (*Verifier, error) {
+	if conf.Issuer == "" || conf.Audience == "" {
+		return nil, fmt.Errorf("the issuer and the audience of the tokens must be set")
+	}
+	var keys []key
+	if conf.Secret != "" {
+		keys = append(keys, key{value: []byte(conf.Secret)})
+	}
+	if conf.PublicKeyFile != "" {
+		publicKey, err := readPublicKey(conf.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key{value: publicKey})
+	}
+	if conf.JWKSFile != "" {
+		set, err := readJWKS(conf.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, set...)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key verifying the tokens is set")
+	}
+	return &Verifier{
+		keys: keys,
+		parser: jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithIssuer(conf.Issuer), jwt.WithAudience(conf.Audience),
+			jwt.WithExpirationRequired(), jwt.WithLeeway(conf.Leeway)),
+		tenantClaim:   conf.TenantClaim,
+		defaultTenant: defaultTenant,
+	}, nil
+}
+
+// NewIDTokenVerifier returns a verifier of the ID tokens of an OpenID provider, signed with the keys of its JWKS document
+// and issued for the client. The ID tokens are verified as the bearer tokens are, their audience being the client ID.
+func NewIDTokenVerifier(jwks []byte, issuer string, clientID string, leeway time.Duration) (*Verifier, error) {
+	if issuer == "" || clientID == "" {
+		return nil, fmt.Errorf("the issuer and the client ID must be set")
+	}
+	keys, err := parseJWKS(jwks)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		keys: keys,
+		parser: jwt.NewParser(jwt.WithValidMethods(methods), jwt.WithIssuer(issuer), jwt.WithAudience(clientID),
+			jwt.WithExpirationRequired(), jwt.WithIssuedAt(), jwt.WithLeeway(leeway)),
+	}, nil
+}
+
+// Verify returns the principal of the subject of the token along with its claims, an errs.ErrUnauthenticated error is returned
+// if the token is not signed by one of the keys, is expired or was issued by another issuer or for another audience
+func (v *Verifier) Verify(raw string) (principal.Principal, error) {
+	claims, err := v.Claims(raw)
+	if err != nil {
+		return principal.Principal{}, err
+	}
+	tenant, _ := claims[v.tenantClaim].(string)
+	if tenant == "" {
+		tenant = v.defaultTenant
+	}
+	return principal.Principal{Subject: claims["sub"].(string), Tenant: tenant, Claims: claims}, nil
+}
+
+// Claims returns the claims of the token, it is checked as by Verify and has a subject
+func (v *Verifier) Claims(raw string) (principal.Claims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(raw, claims, v.keyFor); err != nil {
+		return nil, errs.New(errs.ErrUnauthenticated, "invalid token: %v", err)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, errs.New(errs.ErrUnauthenticated, "invalid token: the subject is missing")
+	}
+	return principal.Claims(claims), nil
+}
+
+// keyFor returns the keys that may have signed the token: the keys of the config and the ones of the JWKS with its kid, or all of them
+// when it has no kid, restricted to the ones of the type of its algorithm. Checking the type prevents a public key from being used as an HS256 secret by a forged token.
+func (v *Verifier) keyFor(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	kid, _ := token.Header["kid"].(string)
+	var set jwt.VerificationKeySet
+	for _, k := range v.keys {
+		if kid != "" && k.id != "" && k.id != kid {
+			continue
+		}
+		if (k.alg == "" || k.alg == alg) && fits(alg, k.value) {
+			set.Keys = append(set.Keys, k.value)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("no %s key with kid '%s'", alg, kid)
+	}
+	return set, nil
+}
+
+// fits reports whether the key can verify a signature of the algorithm
+func fits(alg string, value interface{}) bool {
+	switch k := value.(type) {
+	case []byte:
+		return alg == "HS256"
+	case *rsa.PublicKey:
+		return alg == "RS256"
+	case *ecdsa.PublicKey:
+		return alg == "ES256" && k.Curve == elliptic.P256()
+	}
+	return false
+}
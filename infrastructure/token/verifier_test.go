@@ -0,0 +1,190 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"github.com/FirasYousfi/tasks-web-servcie/config"
+	"github.com/FirasYousfi/tasks-web-servcie/domain/errs"
+	"github.com/golang-jwt/jwt/v5"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "tasks-web-service"
+	testSecret   = "a secret shared with the issuer"
+)
+
+// testKeys are the keys of the identity provider in the tests
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+// writeFile writes the content to a file of the temporary directory of the test and returns its path
+func writeFile(t *testing.T, name string, content []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// writeJWKS publishes the RSA key with the kid "rsa-1" and the EC key with the kid "ec-1"
+func (k testKeys) writeJWKS(t *testing.T) string {
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	set := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256", "n": encode(k.rsa.N.Bytes()), "e": encode([]byte{1, 0, 1})},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(k.ec.X.FillBytes(make([]byte, 32))), "y": encode(k.ec.Y.FillBytes(make([]byte, 32)))},
+		// skipped, the service does not use the encryption keys nor the other curves
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": encode(k.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+	}}
+	content, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, "jwks.json", content)
+}
+
+func (k testKeys) writePublicKey(t *testing.T) string {
+	der, err := x509.MarshalPKIXPublicKey(&k.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writeFile(t, "public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// sign returns a token with valid claims for the subject alice of the tenant acme, changed by the given claims
+func sign(t *testing.T, method jwt.SigningMethod, kid string, signingKey interface{}, changes jwt.MapClaims) string {
+	claims := jwt.MapClaims{"sub": "alice", "iss": testIssuer, "aud": []string{testAudience}, "exp": time.Now().Add(time.Hour).Unix(), "tenant": "acme"}
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+	}
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier, err := NewVerifier(config.JWTConfig{Secret: testSecret, JWKSFile: keys.writeJWKS(t), Issuer: testIssuer, Audience: testAudience,
+		TenantClaim: "tenant", Leeway: time.Minute}, "default")
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+		tenant  string
+	}{
+		{name: "should accept an HS256 token signed with the secret", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), nil), tenant: "acme"},
+		{name: "should accept an RS256 token signed with the key of its kid", token: sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, nil), tenant: "acme"},
+		{name: "should accept an ES256 token without kid", token: sign(t, jwt.SigningMethodES256, "", keys.ec, nil), tenant: "acme"},
+		{name: "should put the token without tenant in the default tenant", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"tenant": nil}), tenant: "default"},
+		{name: "should accept a token expired within the leeway", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"exp": time.Now().Add(-30 * time.Second).Unix()}), tenant: "acme"},
+		{name: "should refuse an expired token", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}), wantErr: true},
+		{name: "should refuse a token without expiry", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"exp": nil}), wantErr: true},
+		{name: "should refuse a token of another issuer", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"iss": "https://evil.example.com"}), wantErr: true},
+		{name: "should refuse a token for another audience", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"aud": "another-service"}), wantErr: true},
+		{name: "should refuse a token without subject", token: sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"sub": nil}), wantErr: true},
+		{name: "should refuse a token signed with another secret", token: sign(t, jwt.SigningMethodHS256, "", []byte("another secret"), nil), wantErr: true},
+		{name: "should refuse a token signed with another key", token: sign(t, jwt.SigningMethodES256, "ec-1", otherKey, nil), wantErr: true},
+		{name: "should refuse a token with the kid of a key of another type", token: sign(t, jwt.SigningMethodES256, "rsa-1", keys.ec, nil), wantErr: true},
+		{name: "should refuse a token with an unknown kid", token: sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, nil), wantErr: true},
+		{name: "should refuse an HS256 token signed with the public key", token: sign(t, jwt.SigningMethodHS256, "rsa-1", publicDER, nil), wantErr: true},
+		{name: "should refuse an unsigned token", token: sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, nil), wantErr: true},
+		{name: "should refuse a token signed with another algorithm", token: sign(t, jwt.SigningMethodHS512, "", []byte(testSecret), nil), wantErr: true},
+		{name: "should refuse a malformed token", token: "not.a.token", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if !errors.Is(err, errs.ErrUnauthenticated) {
+					t.Errorf("Verify() error = %v, want %v", err, errs.ErrUnauthenticated)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got.Subject != "alice" || got.Tenant != tt.tenant || got.Claims["iss"] != testIssuer {
+				t.Errorf("Verify() got = %v, want alice of %s with the claims of the token", got, tt.tenant)
+			}
+		})
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier, err := NewVerifier(config.JWTConfig{PublicKeyFile: keys.writePublicKey(t), Issuer: testIssuer, Audience: testAudience}, "default")
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	if _, err = verifier.Verify(sign(t, jwt.SigningMethodRS256, "", keys.rsa, nil)); err != nil {
+		t.Errorf("Verify() error = %v with the key of the PEM file", err)
+	}
+
+	tests := []struct {
+		name string
+		conf config.JWTConfig
+	}{
+		{name: "should fail without issuer", conf: config.JWTConfig{Secret: testSecret, Audience: testAudience}},
+		{name: "should fail without audience", conf: config.JWTConfig{Secret: testSecret, Issuer: testIssuer}},
+		{name: "should fail without key", conf: config.JWTConfig{Issuer: testIssuer, Audience: testAudience}},
+		{name: "should fail because the PEM file does not exist", conf: config.JWTConfig{PublicKeyFile: "missing.pem", Issuer: testIssuer, Audience: testAudience}},
+		{name: "should fail because the JWKS file is not JSON", conf: config.JWTConfig{JWKSFile: writeFile(t, "jwks.json", []byte("keys")), Issuer: testIssuer, Audience: testAudience}},
+		{name: "should fail because the JWKS has no usable key", conf: config.JWTConfig{JWKSFile: writeFile(t, "jwks.json", []byte(`{"keys": [{"kty": "OKP"}]}`)), Issuer: testIssuer, Audience: testAudience}},
+		{name: "should fail because an EC key is not on the curve", conf: config.JWTConfig{JWKSFile: writeFile(t, "jwks.json", []byte(`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQ", "y": "AQ"}]}`)), Issuer: testIssuer, Audience: testAudience}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewVerifier(tt.conf, "default"); err == nil {
+				t.Errorf("NewVerifier() should fail")
+			}
+		})
+	}
+}